@@ -0,0 +1,21 @@
+// Package v1alpha1 contains the kube-iam-assume.io/v1alpha1 API types, which let
+// GitOps tooling manage part of the controller configuration as custom resources.
+// +kubebuilder:object:generate=true
+// +groupName=kube-iam-assume.io
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is the group version used to register these objects.
+	GroupVersion = schema.GroupVersion{Group: "kube-iam-assume.io", Version: "v1alpha1"}
+
+	// SchemeBuilder adds the types in this group version to a scheme.
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the types in this group version to a scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
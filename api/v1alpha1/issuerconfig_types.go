@@ -0,0 +1,62 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IssuerConfigConditionReady is the condition type reporting whether the spec was applied.
+const IssuerConfigConditionReady = "Ready"
+
+// IssuerConfigSpec holds the issuer and federation settings that override the
+// controller's file configuration while the resource exists. The publisher itself
+// is still configured in the file, since it is created at startup.
+type IssuerConfigSpec struct {
+	// IssuerURL overrides the public issuer URL derived from the publisher, e.g. a
+	// CDN domain in front of the bucket. It must be an https URL.
+	// +optional
+	IssuerURL string `json:"issuerURL,omitempty"`
+
+	// Audiences the federation provider must accept. Overrides
+	// controller.federationDriftCheck.audiences.
+	// +optional
+	Audiences []string `json:"audiences,omitempty"`
+}
+
+// IssuerConfigStatus reports whether the controller applied the spec.
+type IssuerConfigStatus struct {
+	// ObservedGeneration is the generation of the spec the conditions refer to
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions holds the Ready condition
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Issuer",type=string,JSONPath=`.spec.issuerURL`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+
+// IssuerConfig overrides the issuer URL and federation audiences of the controller
+// running in its namespace.
+type IssuerConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IssuerConfigSpec   `json:"spec,omitempty"`
+	Status IssuerConfigStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// IssuerConfigList contains a list of IssuerConfig.
+type IssuerConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IssuerConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IssuerConfig{}, &IssuerConfigList{})
+}
@@ -3,11 +3,16 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
+
+	"github.com/hixichen/kube-iam-assume/pkg/config"
+	"github.com/hixichen/kube-iam-assume/pkg/constants"
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
 )
 
 // newGenerateCommand creates the generate-bucket-name command.
@@ -91,8 +96,8 @@ func runGenerate(ctx context.Context, prefix, region, cluster string, customTags
 
 	// 4. Apply tags
 	allTags := map[string]string{
-		"kube-iam-assume/managed-by": "kube-iam-assume",
-		"kube-iam-assume/cluster":    cluster,
+		iface.TagManagedBy:           "kube-iam-assume",
+		iface.TagCluster:             cluster,
 		"kube-iam-assume/prefix":     prefix,
 		"kube-iam-assume/created-at": time.Now().UTC().Format(time.RFC3339),
 		"kube-iam-assume/issuer-url": issuerURL,
@@ -131,10 +136,19 @@ func runGenerate(ctx context.Context, prefix, region, cluster string, customTags
 		fmt.Printf("  --set config.publisher.s3.region=%s\n", region)
 	case "json":
 		// TODO
-	case "helm":
-		// TODO
-	case "configmap":
-		// TODO
+	case "helm", "configmap":
+		cfg := &config.Config{Publisher: config.PublisherConfig{
+			Type: "s3",
+			S3: &config.S3Config{
+				Bucket:  bucketName,
+				Region:  region,
+				UseIRSA: true,
+			},
+		}}
+		fmt.Println()
+		if err := renderConfig(os.Stdout, cfg, outputFormat, defaultConfigMapName, constants.DefaultNamespace); err != nil {
+			return err
+		}
 	default:
 		return fmt.Errorf("unsupported output format: %s", outputFormat)
 	}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hixichen/kube-iam-assume/pkg/config"
+	"github.com/hixichen/kube-iam-assume/pkg/publisher"
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
+)
+
+// clusterStore lists and deletes the cluster sub-paths of a cluster group.
+type clusterStore interface {
+	iface.MultiClusterAggregator
+	iface.ClusterDeleter
+}
+
+// pruneOptions configures pruneClusters.
+type pruneOptions struct {
+	olderThan time.Duration
+	dryRun    bool
+	// yes skips the confirmation prompt
+	yes bool
+	now time.Time
+}
+
+// newClustersCommand creates the clusters command and its subcommands.
+func newClustersCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clusters",
+		Short: "Manage the cluster sub-paths of a cluster group",
+	}
+	cmd.AddCommand(newClustersPruneCommand())
+	return cmd
+}
+
+// newClustersPruneCommand creates the clusters prune command.
+func newClustersPruneCommand() *cobra.Command {
+	var (
+		configPath string
+		olderThan  time.Duration
+		dryRun     bool
+		yes        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete the JWKS of clusters that stopped publishing",
+		Long: `Lists the cluster sub-paths of the cluster group configured in a controller
+config file and deletes the JWKS of every cluster whose JWKS was last modified
+longer ago than --older-than, e.g. after clusters were decommissioned.
+
+The aggregation leader drops the deleted clusters' keys from the group JWKS on
+its next pass. Run it with credentials that can delete objects in the bucket.`,
+		Example: `  # List the clusters that would be deleted
+  kube-iam-assume clusters prune --config config.yaml --dry-run
+
+  # Delete clusters that have not published for a week, without prompting
+  kube-iam-assume clusters prune --config config.yaml --older-than 168h --yes`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if olderThan <= 0 {
+				return fmt.Errorf("--older-than must be positive, got %s", olderThan)
+			}
+			ctx := cmd.Context()
+			cfg, err := config.LoadConfig(configPath)
+			if err != nil {
+				return err
+			}
+			if cfg.Controller.ClusterGroup == "" {
+				return fmt.Errorf("clusters prune requires controller.clusterGroup to be set in %s", configPath)
+			}
+
+			pub, err := publisher.NewFactory(slog.Default()).Create(ctx, cfg)
+			if err != nil {
+				return fmt.Errorf("failed to create publisher: %w", err)
+			}
+			defer func() { _ = pub.Close() }()
+			store, ok := pub.(clusterStore)
+			if !ok {
+				return fmt.Errorf("%s publisher does not support deleting cluster sub-paths", pub.Type())
+			}
+
+			return pruneClusters(ctx, store, pruneOptions{
+				olderThan: olderThan,
+				dryRun:    dryRun,
+				yes:       yes,
+				now:       time.Now(),
+			}, cmd.InOrStdin(), cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&configPath, "config", "", "Path to the controller config file")
+	cmd.Flags().DurationVar(&olderThan, "older-than", 48*time.Hour, "Prune clusters whose JWKS was last modified longer ago than this")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the clusters that would be pruned without deleting them")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Delete without asking for confirmation")
+
+	if err := cmd.MarkFlagRequired("config"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
+
+// staleClusters returns the sorted IDs of clusters last modified more than
+// olderThan before now.
+func staleClusters(lastModified map[string]time.Time, olderThan time.Duration, now time.Time) []string {
+	var stale []string
+	for clusterID, modified := range lastModified {
+		if now.Sub(modified) > olderThan {
+			stale = append(stale, clusterID)
+		}
+	}
+	slices.Sort(stale)
+	return stale
+}
+
+// pruneClusters lists the stale clusters in store to out and, unless opts.dryRun
+// is set, deletes their JWKS after the user confirms on in.
+func pruneClusters(ctx context.Context, store clusterStore, opts pruneOptions, in io.Reader, out io.Writer) error {
+	lastModified, err := store.GetClusterLastModified(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list clusters: %w", err)
+	}
+
+	stale := staleClusters(lastModified, opts.olderThan, opts.now)
+	if len(stale) == 0 {
+		_, _ = fmt.Fprintf(out, "No clusters older than %s out of %d\n", opts.olderThan, len(lastModified))
+		return nil
+	}
+
+	_, _ = fmt.Fprintf(out, "%d of %d clusters older than %s:\n", len(stale), len(lastModified), opts.olderThan)
+	for _, clusterID := range stale {
+		modified := lastModified[clusterID]
+		_, _ = fmt.Fprintf(out, "  %s\tlast modified %s (%s ago)\n",
+			clusterID, modified.UTC().Format(time.RFC3339), opts.now.Sub(modified).Truncate(time.Second))
+	}
+
+	if opts.dryRun {
+		_, _ = fmt.Fprintln(out, "Dry run: nothing deleted")
+		return nil
+	}
+	if !opts.yes && !confirm(in, out, fmt.Sprintf("Delete the JWKS of %d clusters?", len(stale))) {
+		_, _ = fmt.Fprintln(out, "Aborted: nothing deleted")
+		return nil
+	}
+
+	for i, clusterID := range stale {
+		if err := store.DeleteClusterJWKS(ctx, clusterID); err != nil {
+			return fmt.Errorf("failed to delete cluster %s after deleting %d: %w", clusterID, i, err)
+		}
+		_, _ = fmt.Fprintf(out, "Deleted cluster %s\n", clusterID)
+	}
+	return nil
+}
+
+// confirm asks question on out and reports whether the answer read from in is yes.
+func confirm(in io.Reader, out io.Writer, question string) bool {
+	_, _ = fmt.Fprintf(out, "%s [y/N]: ", question)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/memory"
+)
+
+var pruneNow = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+// newPruneStore returns an in-memory cluster group whose clusters last published
+// the given durations before pruneNow.
+func newPruneStore(t *testing.T, ages map[string]time.Duration) *memory.Publisher {
+	t.Helper()
+	bucket := memory.NewBucket()
+	for clusterID, age := range ages {
+		pub, err := memory.New(memory.Config{PublicURL: "https://oidc.example.com/prod", Prefix: "prod", MultiClusterEnabled: true, ClusterID: clusterID}, bucket)
+		require.NoError(t, err)
+		pub.SetTimeFunc(func() time.Time { return pruneNow.Add(-age) })
+		require.NoError(t, pub.Publish(context.Background(), nil, &bridge.JWKS{Keys: []bridge.JWK{{Kid: clusterID + "-key"}}}))
+	}
+	store, err := memory.New(memory.Config{PublicURL: "https://oidc.example.com/prod", Prefix: "prod", MultiClusterEnabled: true, ClusterID: "admin"}, bucket)
+	require.NoError(t, err)
+	return store
+}
+
+func TestStaleClusters(t *testing.T) {
+	lastModified := map[string]time.Time{
+		"fresh":    pruneNow.Add(-time.Hour),
+		"boundary": pruneNow.Add(-48 * time.Hour),
+		"stale-b":  pruneNow.Add(-72 * time.Hour),
+		"stale-a":  pruneNow.Add(-49 * time.Hour),
+	}
+
+	assert.Equal(t, []string{"stale-a", "stale-b"}, staleClusters(lastModified, 48*time.Hour, pruneNow))
+	assert.Empty(t, staleClusters(lastModified, 100*time.Hour, pruneNow))
+	assert.Empty(t, staleClusters(nil, time.Hour, pruneNow))
+}
+
+func TestPruneClusters(t *testing.T) {
+	ages := map[string]time.Duration{
+		"cluster-a": time.Hour,
+		"cluster-b": 72 * time.Hour,
+		"cluster-c": 96 * time.Hour,
+	}
+
+	tests := []struct {
+		name        string
+		opts        pruneOptions
+		input       string
+		wantDeleted []string
+		wantOutput  []string
+	}{
+		{
+			name:       "dry run lists without deleting",
+			opts:       pruneOptions{olderThan: 48 * time.Hour, dryRun: true},
+			wantOutput: []string{"2 of 3 clusters older than 48h0m0s", "cluster-b\tlast modified 2026-03-07T12:00:00Z (72h0m0s ago)", "cluster-c", "Dry run: nothing deleted"},
+		},
+		{
+			name:        "confirmed delete",
+			opts:        pruneOptions{olderThan: 48 * time.Hour},
+			input:       "y\n",
+			wantDeleted: []string{"cluster-b", "cluster-c"},
+			wantOutput:  []string{"[y/N]", "Deleted cluster cluster-b", "Deleted cluster cluster-c"},
+		},
+		{
+			name:       "declined delete",
+			opts:       pruneOptions{olderThan: 48 * time.Hour},
+			input:      "n\n",
+			wantOutput: []string{"Aborted: nothing deleted"},
+		},
+		{
+			name:        "yes skips the prompt",
+			opts:        pruneOptions{olderThan: 80 * time.Hour, yes: true},
+			wantDeleted: []string{"cluster-c"},
+			wantOutput:  []string{"1 of 3 clusters older than 80h0m0s", "Deleted cluster cluster-c"},
+		},
+		{
+			name:       "nothing stale",
+			opts:       pruneOptions{olderThan: 200 * time.Hour},
+			wantOutput: []string{"No clusters older than 200h0m0s out of 3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newPruneStore(t, ages)
+			ctx := context.Background()
+			tt.opts.now = pruneNow
+
+			var out bytes.Buffer
+			require.NoError(t, pruneClusters(ctx, store, tt.opts, strings.NewReader(tt.input), &out))
+			for _, want := range tt.wantOutput {
+				assert.Contains(t, out.String(), want)
+			}
+			if tt.opts.yes {
+				assert.NotContains(t, out.String(), "[y/N]")
+			}
+
+			remaining, err := store.GetClusterLastModified(ctx)
+			require.NoError(t, err)
+			for clusterID := range ages {
+				_, ok := remaining[clusterID]
+				assert.Equal(t, !slices.Contains(tt.wantDeleted, clusterID), ok, clusterID)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/hixichen/kube-iam-assume/pkg/constants"
+)
+
+// maxDiagnosticEvents caps how many of the most recent events the bundle includes.
+const maxDiagnosticEvents = 50
+
+// diagnosticSection is one file of the diagnostics bundle.
+type diagnosticSection struct {
+	Name    string
+	Content []byte
+}
+
+// newDiagnosticsCommand creates the diagnostics command.
+func newDiagnosticsCommand() *cobra.Command {
+	var (
+		kubeconfig string
+		namespace  string
+		issuerURL  string
+		output     string
+		allowed    []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diagnostics",
+		Short: "Collect a diagnostics bundle for support",
+		Long: `Collects, read-only, the controller deployment status, the OIDC metadata and
+rotation-state ConfigMaps, recent events in the controller namespace, and the
+reachability and validity of the published issuer.
+
+The bundle never includes pod specs, environment variables or Secrets. Without
+--output a report is printed to stdout; with --output a .tar.gz is written.`,
+		Example: `  # Print a report
+  kube-iam-assume diagnostics --issuer-url https://oidc.example.com/prod
+
+  # Write a bundle to attach to a support ticket
+  kube-iam-assume diagnostics --issuer-url https://oidc.example.com/prod --output diagnostics.tar.gz`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if issuerURL == "" && len(allowed) > 0 {
+				return fmt.Errorf("--allowed-issuer-suffix requires --issuer-url")
+			}
+			if err := checkIssuerAllowed(issuerURL, allowed); err != nil {
+				return err
+			}
+			clientset, err := buildClientset(kubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to connect to cluster: %w", err)
+			}
+
+			sections := collectDiagnostics(cmd.Context(), clientset, http.DefaultClient, namespace, issuerURL)
+			if output == "" {
+				return writeDiagnosticsReport(os.Stdout, sections)
+			}
+
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", output, err)
+			}
+			if err := writeDiagnosticsBundle(f, sections, time.Now()); err != nil {
+				_ = f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("failed to write %s: %w", output, err)
+			}
+			fmt.Printf("Wrote diagnostics bundle to %s\n", output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file (defaults to the standard loading rules)")
+	cmd.Flags().StringVar(&namespace, "namespace", constants.DefaultNamespace, "Namespace the controller runs in")
+	cmd.Flags().StringVar(&issuerURL, "issuer-url", "", "Public issuer URL to check (skipped when empty)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write a .tar.gz bundle to this path instead of printing a report")
+	addAllowedIssuerSuffixFlag(cmd, &allowed)
+
+	return cmd
+}
+
+// collectDiagnostics gathers every section of the bundle. Collection is best
+// effort: a section that cannot be read records the error instead.
+func collectDiagnostics(ctx context.Context, clientset kubernetes.Interface, httpClient *http.Client, namespace, issuerURL string) []diagnosticSection {
+	return []diagnosticSection{
+		{Name: "status.txt", Content: diagnoseStatus(ctx, clientset, namespace)},
+		{Name: "deployment.json", Content: diagnoseDeployments(ctx, clientset, namespace)},
+		{Name: "oidc-metadata.json", Content: diagnoseConfigMap(ctx, clientset, namespace, constants.DefaultOIDCConfigMapName)},
+		{Name: "rotation-state.json", Content: diagnoseConfigMap(ctx, clientset, namespace, constants.DefaultRotationConfigMapName)},
+		{Name: "events.txt", Content: diagnoseEvents(ctx, clientset, namespace)},
+		{Name: "issuer.txt", Content: diagnoseIssuer(ctx, httpClient, issuerURL)},
+	}
+}
+
+// diagnoseStatus renders the same summary as the status command.
+func diagnoseStatus(ctx context.Context, clientset kubernetes.Interface, namespace string) []byte {
+	var buf bytes.Buffer
+	info, err := collectStatus(ctx, clientset, namespace)
+	if err != nil {
+		fmt.Fprintf(&buf, "warning: could not retrieve full status: %v\n\n", err)
+	}
+	if info != nil {
+		printStatus(&buf, info)
+	}
+	return buf.Bytes()
+}
+
+// deploymentSummary is the redacted view of a controller deployment: status and
+// images only, never the pod template's environment or volumes.
+type deploymentSummary struct {
+	Name              string                    `json:"name"`
+	Replicas          int32                     `json:"replicas"`
+	ReadyReplicas     int32                     `json:"readyReplicas"`
+	UpdatedReplicas   int32                     `json:"updatedReplicas"`
+	AvailableReplicas int32                     `json:"availableReplicas"`
+	Images            []string                  `json:"images"`
+	Conditions        []deploymentConditionInfo `json:"conditions,omitempty"`
+}
+
+// deploymentConditionInfo is one deployment condition.
+type deploymentConditionInfo struct {
+	Type    string    `json:"type"`
+	Status  string    `json:"status"`
+	Reason  string    `json:"reason,omitempty"`
+	Message string    `json:"message,omitempty"`
+	Updated time.Time `json:"lastUpdateTime"`
+}
+
+// diagnoseDeployments summarizes the controller deployments.
+func diagnoseDeployments(ctx context.Context, clientset kubernetes.Interface, namespace string) []byte {
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: controllerSelector,
+	})
+	if err != nil {
+		return diagnosticError("failed to list deployments", err)
+	}
+
+	summaries := make([]deploymentSummary, 0, len(deployments.Items))
+	for _, deploy := range deployments.Items {
+		summary := deploymentSummary{
+			Name:              deploy.Name,
+			ReadyReplicas:     deploy.Status.ReadyReplicas,
+			UpdatedReplicas:   deploy.Status.UpdatedReplicas,
+			AvailableReplicas: deploy.Status.AvailableReplicas,
+		}
+		if deploy.Spec.Replicas != nil {
+			summary.Replicas = *deploy.Spec.Replicas
+		}
+		for _, container := range deploy.Spec.Template.Spec.Containers {
+			summary.Images = append(summary.Images, container.Image)
+		}
+		for _, cond := range deploy.Status.Conditions {
+			summary.Conditions = append(summary.Conditions, deploymentConditionInfo{
+				Type:    string(cond.Type),
+				Status:  string(cond.Status),
+				Reason:  cond.Reason,
+				Message: cond.Message,
+				Updated: cond.LastUpdateTime.Time,
+			})
+		}
+		summaries = append(summaries, summary)
+	}
+	return diagnosticJSON(summaries)
+}
+
+// diagnoseConfigMap returns the data of a ConfigMap. The OIDC metadata and rotation
+// state hold public keys and timestamps only, so they are included unredacted.
+func diagnoseConfigMap(ctx context.Context, clientset kubernetes.Interface, namespace, name string) []byte {
+	cm, err := clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return diagnosticError(fmt.Sprintf("failed to get ConfigMap %s/%s", namespace, name), err)
+	}
+	return diagnosticJSON(map[string]interface{}{
+		"resourceVersion": cm.ResourceVersion,
+		"data":            cm.Data,
+	})
+}
+
+// diagnoseEvents lists the most recent events in the namespace, oldest first.
+func diagnoseEvents(ctx context.Context, clientset kubernetes.Interface, namespace string) []byte {
+	events, err := clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return diagnosticError("failed to list events", err)
+	}
+
+	items := events.Items
+	sort.Slice(items, func(i, j int) bool {
+		return eventTime(items[i]).Before(eventTime(items[j]))
+	})
+	if len(items) > maxDiagnosticEvents {
+		items = items[len(items)-maxDiagnosticEvents:]
+	}
+
+	var buf bytes.Buffer
+	if len(items) == 0 {
+		buf.WriteString("no events\n")
+	}
+	for _, e := range items {
+		fmt.Fprintf(&buf, "%s %s %s/%s %s: %s\n",
+			eventTime(e).UTC().Format(time.RFC3339), e.Type,
+			e.InvolvedObject.Kind, e.InvolvedObject.Name, e.Reason, e.Message)
+	}
+	return buf.Bytes()
+}
+
+// eventTime returns when an event last occurred.
+func eventTime(e corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.CreationTimestamp.Time
+	}
+}
+
+// diagnoseIssuer checks that the published issuer is reachable and valid.
+func diagnoseIssuer(ctx context.Context, httpClient *http.Client, issuerURL string) []byte {
+	if issuerURL == "" {
+		return []byte("skipped: no --issuer-url given\n")
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Issuer URL: %s\n", issuerURL)
+	published, err := checkPublishedIssuer(ctx, httpClient, issuerURL)
+	if published.Discovery != nil {
+		fmt.Fprintf(&buf, "Discovery:  reachable (issuer %s, jwks_uri %s)\n", published.Discovery.Issuer, published.Discovery.JWKSURI)
+	}
+	if published.JWKS != nil {
+		fmt.Fprintf(&buf, "JWKS:       %d keys\n", len(published.JWKS.Keys))
+	}
+	if err != nil {
+		fmt.Fprintf(&buf, "Result:     FAILED: %v\n", err)
+	} else {
+		buf.WriteString("Result:     OK\n")
+	}
+	return buf.Bytes()
+}
+
+// diagnosticJSON renders v as indented JSON.
+func diagnosticJSON(v interface{}) []byte {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return diagnosticError("failed to encode section", err)
+	}
+	return append(data, '\n')
+}
+
+// diagnosticError renders a collection failure as the section content.
+func diagnosticError(message string, err error) []byte {
+	return []byte(fmt.Sprintf("error: %s: %v\n", message, err))
+}
+
+// writeDiagnosticsReport prints every section under a heading.
+func writeDiagnosticsReport(w io.Writer, sections []diagnosticSection) error {
+	for _, section := range sections {
+		if _, err := fmt.Fprintf(w, "===== %s =====\n%s\n", section.Name, section.Content); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeDiagnosticsBundle writes the sections as a gzipped tarball, one file per
+// section under a kube-iam-assume-diagnostics/ directory.
+func writeDiagnosticsBundle(w io.Writer, sections []diagnosticSection, now time.Time) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, section := range sections {
+		hdr := &tar.Header{
+			Name:    "kube-iam-assume-diagnostics/" + section.Name,
+			Mode:    0o644,
+			Size:    int64(len(section.Content)),
+			ModTime: now,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("failed to write bundle header for %s: %w", section.Name, err)
+		}
+		if _, err := tw.Write(section.Content); err != nil {
+			return fmt.Errorf("failed to write bundle section %s: %w", section.Name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finish bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to finish bundle: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
+	"github.com/hixichen/kube-iam-assume/pkg/constants"
+	kaerrors "github.com/hixichen/kube-iam-assume/pkg/errors"
+)
+
+// issuerServer serves discovery and JWKS documents for the issuer at its own URL.
+// The discovery issuer can be overridden and the JWKS body replaced.
+func issuerServer(t *testing.T, discoveryIssuer string, jwksBody string) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			issuer := discoveryIssuer
+			if issuer == "" {
+				issuer = srv.URL
+			}
+			_ = json.NewEncoder(w).Encode(bridge.DiscoveryDocument{
+				Issuer:                  issuer,
+				JWKSURI:                 srv.URL + "/openid/v1/jwks",
+				ResponseTypesSupported:  []string{"id_token"},
+				SubjectTypesSupported:   []string{"public"},
+				IDTokenSigningAlgValues: []string{"RS256"},
+			})
+		case "/openid/v1/jwks":
+			_, _ = io.WriteString(w, jwksBody)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+const validJWKSBody = `{"keys":[{"kty":"RSA","kid":"key-1","alg":"RS256","use":"sig","n":"AQAB","e":"AQAB"}]}`
+
+func TestCheckPublishedIssuer(t *testing.T) {
+	tests := []struct {
+		name            string
+		discoveryIssuer string
+		jwksBody        string
+		unreachable     bool
+		wantCode        kaerrors.ErrorCode
+	}{
+		{
+			name:     "valid issuer",
+			jwksBody: validJWKSBody,
+		},
+		{
+			name:            "issuer mismatch",
+			discoveryIssuer: "https://attacker.example.com",
+			jwksBody:        validJWKSBody,
+			wantCode:        kaerrors.CodeIssuerMismatch,
+		},
+		{
+			name:     "empty JWKS",
+			jwksBody: `{"keys":[]}`,
+			wantCode: kaerrors.CodeInvalidJWKS,
+		},
+		{
+			name:     "malformed JWKS",
+			jwksBody: `<html>`,
+			wantCode: kaerrors.CodeInvalidJWKS,
+		},
+		{
+			name:        "unreachable",
+			unreachable: true,
+			wantCode:    kaerrors.CodeFetch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := issuerServer(t, tt.discoveryIssuer, tt.jwksBody)
+			issuerURL := srv.URL
+			if tt.unreachable {
+				srv.Close()
+			}
+
+			published, err := checkPublishedIssuer(context.Background(), srv.Client(), issuerURL)
+			if tt.wantCode == "" {
+				require.NoError(t, err)
+				assert.Len(t, published.JWKS.Keys, 1)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.wantCode, kaerrors.GetCode(err))
+		})
+	}
+}
+
+func diagnosticsCluster() *fake.Clientset {
+	replicas := int32(2)
+	return fake.NewClientset(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      constants.ControllerName,
+				Namespace: constants.DefaultNamespace,
+				Labels:    map[string]string{"app.kubernetes.io/name": "kube-iam-assume"},
+			},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: &replicas,
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name:  "controller",
+					Image: "ghcr.io/hixichen/kube-iam-assume:v1.2.3",
+					Env:   []corev1.EnvVar{{Name: "AZURE_CLIENT_SECRET", Value: "hunter2"}},
+				}}}},
+			},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: 2},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultOIDCConfigMapName, Namespace: constants.DefaultNamespace},
+			Data:       map[string]string{"jwks.json": validJWKSBody},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultRotationConfigMapName, Namespace: constants.DefaultNamespace},
+			Data:       map[string]string{"state": `{"keys":{"key-1":{}}}`},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: constants.DefaultClusterHealthConfigMapName, Namespace: constants.DefaultNamespace},
+			Data:       map[string]string{"clusters.json": `[{"clusterID":"prod-b","lastModified":"2026-01-01T00:00:00Z","healthy":false}]`},
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "synced.1", Namespace: constants.DefaultNamespace},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "kube-iam-assume-controller-abc"},
+			Type:           corev1.EventTypeNormal,
+			Reason:         "Synced",
+			Message:        "OIDC metadata synced successfully",
+			LastTimestamp:  metav1.NewTime(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)),
+		},
+	)
+}
+
+func TestCheckIssuerAllowed(t *testing.T) {
+	tests := []struct {
+		name      string
+		issuerURL string
+		allowed   []string
+		wantErr   bool
+	}{
+		{name: "no allowlist", issuerURL: "https://attacker.example.net/bucket"},
+		{name: "exact domain", issuerURL: "https://oidc.example.com/prod", allowed: []string{"oidc.example.com"}},
+		{name: "subdomain", issuerURL: "https://my-bucket.s3.us-west-2.amazonaws.com", allowed: []string{"s3.us-west-2.amazonaws.com"}},
+		{name: "case and dots are ignored", issuerURL: "https://OIDC.Example.com./prod", allowed: []string{".example.com"}},
+		{name: "any of several", issuerURL: "https://storage.googleapis.com/my-bucket", allowed: []string{"example.com", "storage.googleapis.com"}},
+		{name: "port is ignored", issuerURL: "https://oidc.example.com:8443", allowed: []string{"example.com"}},
+		{name: "other domain", issuerURL: "https://attacker.example.net/bucket", allowed: []string{"example.com"}, wantErr: true},
+		{name: "suffix without label boundary", issuerURL: "https://badexample.com", allowed: []string{"example.com"}, wantErr: true},
+		{name: "allowed domain in path", issuerURL: "https://attacker.example.net/example.com", allowed: []string{"example.com"}, wantErr: true},
+		{name: "no host", issuerURL: "/prod", allowed: []string{"example.com"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkIssuerAllowed(tt.issuerURL, tt.allowed)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.True(t, kaerrors.IsValidationError(err))
+		})
+	}
+}
+
+func TestDiagnosticsCommand_RejectsDisallowedIssuer(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "issuer outside the allowed domains",
+			args:    []string{"--issuer-url", "https://attacker.example.net/bucket", "--allowed-issuer-suffix", "example.com"},
+			wantErr: `issuer host "attacker.example.net" is not under an allowed domain`,
+		},
+		{
+			name:    "allowed suffix without an issuer",
+			args:    []string{"--allowed-issuer-suffix", "example.com"},
+			wantErr: "--allowed-issuer-suffix requires --issuer-url",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newDiagnosticsCommand()
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+			assert.ErrorContains(t, cmd.Execute(), tt.wantErr)
+		})
+	}
+}
+
+func TestSetupCommands_RejectDisallowedIssuer(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  *cobra.Command
+		args []string
+	}{
+		{name: "aws", cmd: newAWSCommand(), args: []string{"--region", "us-west-2"}},
+		{name: "gcp", cmd: newGCPCommand(), args: []string{"--project", "my-project"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cmd.SetArgs(append(tt.args, "--issuer-url", "https://attacker.example.net/bucket", "--allowed-issuer-suffix", "example.com"))
+			tt.cmd.SetOut(io.Discard)
+			tt.cmd.SetErr(io.Discard)
+			err := tt.cmd.Execute()
+			assert.ErrorContains(t, err, `issuer host "attacker.example.net" is not under an allowed domain`)
+			assert.True(t, kaerrors.IsValidationError(err))
+		})
+	}
+}
+
+func TestCollectDiagnostics(t *testing.T) {
+	srv := issuerServer(t, "", validJWKSBody)
+	sections := collectDiagnostics(context.Background(), diagnosticsCluster(), srv.Client(), constants.DefaultNamespace, srv.URL)
+
+	contents := make(map[string]string, len(sections))
+	for _, section := range sections {
+		contents[section.Name] = string(section.Content)
+	}
+
+	assert.Contains(t, contents["status.txt"], "Controller:      Running")
+	assert.Regexp(t, `prod-b\s+lagging`, contents["status.txt"])
+	assert.Regexp(t, `Thumbprints:\n\s+key-1\s+[A-Za-z0-9_-]{43}\n`, contents["status.txt"])
+	assert.Contains(t, contents["deployment.json"], "ghcr.io/hixichen/kube-iam-assume:v1.2.3")
+	assert.Contains(t, contents["oidc-metadata.json"], "key-1")
+	assert.Contains(t, contents["rotation-state.json"], "key-1")
+	assert.Contains(t, contents["events.txt"], "2026-01-02T03:04:05Z Normal Pod/kube-iam-assume-controller-abc Synced")
+	assert.Contains(t, contents["issuer.txt"], "Result:     OK")
+
+	// Secrets from the pod template never reach the bundle
+	for name, content := range contents {
+		assert.NotContains(t, content, "hunter2", name)
+	}
+}
+
+func TestCollectDiagnostics_MissingResources(t *testing.T) {
+	sections := collectDiagnostics(context.Background(), fake.NewClientset(), http.DefaultClient, constants.DefaultNamespace, "")
+
+	contents := make(map[string]string, len(sections))
+	for _, section := range sections {
+		contents[section.Name] = string(section.Content)
+	}
+
+	assert.Contains(t, contents["oidc-metadata.json"], "error: failed to get ConfigMap")
+	assert.Contains(t, contents["rotation-state.json"], "error: failed to get ConfigMap")
+	assert.Equal(t, "no events\n", contents["events.txt"])
+	assert.Contains(t, contents["issuer.txt"], "skipped")
+}
+
+func TestWriteDiagnosticsBundle(t *testing.T) {
+	sections := []diagnosticSection{
+		{Name: "status.txt", Content: []byte("status")},
+		{Name: "events.txt", Content: []byte("events")},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeDiagnosticsBundle(&buf, sections, time.Now()))
+
+	gz, err := gzip.NewReader(&buf)
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	got := map[string]string{}
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		got[hdr.Name] = string(data)
+	}
+
+	assert.Equal(t, map[string]string{
+		"kube-iam-assume-diagnostics/status.txt": "status",
+		"kube-iam-assume-diagnostics/events.txt": "events",
+	}, got)
+}
+
+func TestWriteDiagnosticsReport(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeDiagnosticsReport(&buf, []diagnosticSection{
+		{Name: "status.txt", Content: []byte("status\n")},
+		{Name: "issuer.txt", Content: []byte("skipped\n")},
+	}))
+
+	report := buf.String()
+	assert.True(t, strings.Index(report, "===== status.txt =====") < strings.Index(report, "===== issuer.txt ====="))
+	assert.Contains(t, report, "skipped")
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	kaerrors "github.com/hixichen/kube-iam-assume/pkg/errors"
+)
+
+// Exit codes returned by the CLI so CI jobs can tell failure classes apart.
+// 2 is left unused because shells and many tools reserve it for usage errors.
+const (
+	// ExitOK means the command succeeded.
+	ExitOK = 0
+	// ExitError is any failure without a more specific class.
+	ExitError = 1
+	// ExitUnreachable means the issuer or published metadata could not be fetched.
+	ExitUnreachable = 3
+	// ExitIssuerMismatch means the published metadata names a different issuer.
+	ExitIssuerMismatch = 4
+	// ExitJWKSInvalid means the published JWKS is missing keys or malformed.
+	ExitJWKSInvalid = 5
+	// ExitDriftDetected means the published metadata differs from the cluster's.
+	ExitDriftDetected = 6
+)
+
+// exitCodes maps error codes to CLI exit codes; unlisted codes exit with ExitError.
+var exitCodes = map[kaerrors.ErrorCode]int{
+	kaerrors.CodeFetch:          ExitUnreachable,
+	kaerrors.CodeIssuerMismatch: ExitIssuerMismatch,
+	kaerrors.CodeInvalidJWKS:    ExitJWKSInvalid,
+	kaerrors.CodeDrift:          ExitDriftDetected,
+}
+
+// exitCode returns the process exit code for the error returned by a command.
+func exitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	if code, ok := exitCodes[kaerrors.GetCode(err)]; ok {
+		return code
+	}
+	return ExitError
+}
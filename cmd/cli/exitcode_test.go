@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	kaerrors "github.com/hixichen/kube-iam-assume/pkg/errors"
+)
+
+func TestExitCode(t *testing.T) {
+	cause := errors.New("boom")
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", err: nil, want: ExitOK},
+		{name: "plain error", err: cause, want: ExitError},
+		{name: "unreachable", err: kaerrors.NewFetchError("verify", "GET discovery failed", cause), want: ExitUnreachable},
+		{name: "issuer mismatch", err: kaerrors.NewIssuerMismatchError("verify", "issuer differs", nil), want: ExitIssuerMismatch},
+		{name: "jwks invalid", err: kaerrors.NewInvalidJWKSError("verify", "no keys", nil), want: ExitJWKSInvalid},
+		{name: "drift detected", err: kaerrors.NewDriftError("drift", "jwks differs", nil), want: ExitDriftDetected},
+		{
+			name: "wrapped drift",
+			err:  fmt.Errorf("check failed: %w", kaerrors.NewDriftError("drift", "jwks differs", nil)),
+			want: ExitDriftDetected,
+		},
+		{name: "unmapped code", err: kaerrors.NewConfigError("config", "bad", nil), want: ExitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, exitCode(tt.err))
+		})
+	}
+}
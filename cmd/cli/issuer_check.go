@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
+	kaerrors "github.com/hixichen/kube-iam-assume/pkg/errors"
+)
+
+// issuerCheckTimeout bounds each request made while checking a published issuer.
+const issuerCheckTimeout = 10 * time.Second
+
+// publishedIssuer is the OIDC metadata served at a public issuer URL.
+type publishedIssuer struct {
+	Discovery *bridge.DiscoveryDocument
+	JWKS      *bridge.JWKS
+}
+
+// checkPublishedIssuer fetches the discovery document and JWKS served at issuerURL
+// anonymously and validates them. Errors carry the error code of the failure class:
+// CodeFetch when the metadata cannot be fetched, CodeIssuerMismatch when the discovery
+// document names a different issuer, and CodeInvalidJWKS for a missing or malformed JWKS.
+// The metadata fetched before a failure is returned alongside the error.
+func checkPublishedIssuer(ctx context.Context, httpClient *http.Client, issuerURL string) (*publishedIssuer, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	issuerURL = strings.TrimSuffix(issuerURL, "/")
+	result := &publishedIssuer{}
+
+	discoveryURL := issuerURL + "/.well-known/openid-configuration"
+	var discovery bridge.DiscoveryDocument
+	if err := getJSON(ctx, httpClient, discoveryURL, &discovery); err != nil {
+		return result, kaerrors.NewFetchError("issuer-check", "failed to fetch discovery document", err)
+	}
+	result.Discovery = &discovery
+
+	if strings.TrimSuffix(discovery.Issuer, "/") != issuerURL {
+		return result, kaerrors.NewIssuerMismatchError("issuer-check",
+			fmt.Sprintf("discovery document issuer %q does not match %q", discovery.Issuer, issuerURL), nil)
+	}
+	if err := discovery.Validate(); err != nil {
+		return result, kaerrors.NewValidationError("issuer-check", "invalid discovery document", err)
+	}
+
+	var jwks bridge.JWKS
+	if err := getJSON(ctx, httpClient, discovery.JWKSURI, &jwks); err != nil {
+		if kaerrors.IsValidationError(err) {
+			return result, kaerrors.NewInvalidJWKSError("issuer-check", "malformed JWKS", err)
+		}
+		return result, kaerrors.NewFetchError("issuer-check", "failed to fetch JWKS", err)
+	}
+	result.JWKS = &jwks
+
+	if err := bridge.ValidateJWKS(&jwks); err != nil {
+		return result, kaerrors.NewInvalidJWKSError("issuer-check", "invalid JWKS", err)
+	}
+	if err := bridge.ValidateSigningAlgs(&discovery, &jwks); err != nil {
+		return result, kaerrors.NewValidationError("issuer-check", "discovery document does not advertise every key algorithm", err)
+	}
+	return result, nil
+}
+
+// addAllowedIssuerSuffixFlag registers the --allowed-issuer-suffix flag on cmd.
+func addAllowedIssuerSuffixFlag(cmd *cobra.Command, suffixes *[]string) {
+	cmd.Flags().StringArrayVar(suffixes, "allowed-issuer-suffix", nil,
+		"Fail unless the issuer host is this domain or a subdomain of it, e.g. s3.us-west-2.amazonaws.com (repeatable)")
+}
+
+// checkIssuerAllowed fails unless the host of issuerURL equals one of the allowed
+// domain suffixes or is a subdomain of one. Matching is on whole DNS labels, so
+// "example.com" allows "oidc.example.com" but not "badexample.com". Every issuer
+// is allowed when suffixes is empty.
+func checkIssuerAllowed(issuerURL string, suffixes []string) error {
+	if len(suffixes) == 0 {
+		return nil
+	}
+	parsed, err := url.Parse(issuerURL)
+	if err != nil {
+		return kaerrors.NewValidationError("issuer-check", "invalid issuer URL", err)
+	}
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if host == "" {
+		return kaerrors.NewValidationError("issuer-check", fmt.Sprintf("issuer URL %q has no host", issuerURL), nil)
+	}
+	for _, suffix := range suffixes {
+		suffix = strings.Trim(strings.ToLower(suffix), ".")
+		if suffix != "" && (host == suffix || strings.HasSuffix(host, "."+suffix)) {
+			return nil
+		}
+	}
+	return kaerrors.NewValidationError("issuer-check",
+		fmt.Sprintf("issuer host %q is not under an allowed domain (%s)", host, strings.Join(suffixes, ", ")), nil)
+}
+
+// getJSON fetches url and decodes the JSON body into dst. Decoding failures are
+// returned as validation errors so callers can tell them apart from fetch failures.
+func getJSON(ctx context.Context, httpClient *http.Client, url string, dst interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, issuerCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request for %s: %w", url, err)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("GET %s failed: %w", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned HTTP %d", url, resp.StatusCode)
+	}
+	data, err := bridge.ReadLimited(resp.Body, 0)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", url, err)
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return kaerrors.NewValidationError("issuer-check", fmt.Sprintf("%s is not valid JSON", url), err)
+	}
+	return nil
+}
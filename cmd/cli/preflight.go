@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hixichen/kube-iam-assume/pkg/config"
+	kaerrors "github.com/hixichen/kube-iam-assume/pkg/errors"
+	"github.com/hixichen/kube-iam-assume/pkg/federation"
+	awsfederation "github.com/hixichen/kube-iam-assume/pkg/federation/aws"
+	"github.com/hixichen/kube-iam-assume/pkg/federation/gcp"
+	"github.com/hixichen/kube-iam-assume/pkg/publisher"
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
+)
+
+// preflightCheck is the outcome of one preflight permission check.
+type preflightCheck struct {
+	Name   string
+	Detail string
+	Err    error
+}
+
+// newPreflightCommand creates the preflight command.
+func newPreflightCommand() *cobra.Command {
+	var (
+		configPath   string
+		federationTo string
+		region       string
+		projectID    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "preflight",
+		Short: "Check cloud credentials and permissions without publishing",
+		Long: `Builds the publisher from a controller config file with the current credentials
+and checks that the bucket can be reached and written, without publishing any
+OIDC metadata. Optionally checks that the federation provider can be read.
+
+Run it with the same credentials the controller will use.`,
+		Example: `  # Check bucket access
+  kube-iam-assume preflight --config config.yaml
+
+  # Also check read access to the AWS IAM OIDC provider
+  kube-iam-assume preflight --config config.yaml --federation aws --region us-west-2`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			cfg, err := config.LoadConfig(configPath)
+			if err != nil {
+				return err
+			}
+
+			logger := slog.Default()
+			pub, err := publisher.NewFactory(logger).Create(ctx, cfg)
+			if err != nil {
+				return fmt.Errorf("failed to create publisher: %w", err)
+			}
+			defer func() { _ = pub.Close() }()
+
+			var provider federation.Provider
+			switch federationTo {
+			case "":
+			case string(federation.ProviderTypeAWS):
+				provider, err = awsfederation.NewProvider(ctx, region, logger)
+			case string(federation.ProviderTypeGCP):
+				provider, err = gcp.NewProvider(ctx, projectID, logger)
+			default:
+				return fmt.Errorf("unsupported --federation %q (use aws or gcp)", federationTo)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to create %s federation provider: %w", federationTo, err)
+			}
+			if provider != nil {
+				provider = federation.Instrument(provider, logOperationRecorder{logger: logger})
+			}
+
+			return printPreflight(os.Stdout, runPreflight(ctx, pub, provider))
+		},
+	}
+
+	cmd.Flags().StringVar(&configPath, "config", "", "Path to the controller config file")
+	cmd.Flags().StringVar(&federationTo, "federation", "", "Also check the federation provider (aws, gcp)")
+	cmd.Flags().StringVar(&region, "region", "", "AWS region for --federation aws")
+	cmd.Flags().StringVar(&projectID, "project", "", "GCP project ID for --federation gcp")
+
+	if err := cmd.MarkFlagRequired("config"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
+
+// runPreflight runs the publisher checks and, when provider is set, the federation check.
+func runPreflight(ctx context.Context, pub iface.Publisher, provider federation.Provider) []preflightCheck {
+	checks := []preflightCheck{
+		{
+			Name:   "publisher access",
+			Detail: fmt.Sprintf("%s bucket reachable and writable", pub.Type()),
+			Err:    pub.Validate(ctx),
+		},
+		{
+			Name:   "publisher health",
+			Detail: fmt.Sprintf("%s health check passed", pub.Type()),
+			Err:    pub.HealthCheck(ctx),
+		},
+	}
+
+	if provider != nil {
+		check := preflightCheck{Name: provider.Type() + " federation provider"}
+		info, err := provider.GetProviderInfo(ctx, pub.GetPublicURL())
+		switch {
+		case kaerrors.IsNotFoundError(err):
+			check.Detail = "no provider for the issuer yet; setup will create one"
+		case err != nil:
+			check.Err = err
+		case info.Status == federation.ProviderStatusDisabled:
+			check.Err = fmt.Errorf("%s is disabled (state %s)", info.ProviderARN, info.RawStatus)
+		default:
+			check.Detail = "found " + info.ProviderARN
+			if info.Status != "" {
+				check.Detail += " (" + string(info.Status) + ")"
+			}
+		}
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// printPreflight writes one line per check and returns an error naming the failed checks.
+func printPreflight(w io.Writer, checks []preflightCheck) error {
+	var failed []string
+	for _, check := range checks {
+		if check.Err != nil {
+			failed = append(failed, check.Name)
+			_, _ = fmt.Fprintf(w, "✗ %s: %v\n", check.Name, check.Err)
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "✓ %s: %s\n", check.Name, check.Detail)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("preflight failed: %d of %d checks failed %v", len(failed), len(checks), failed)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
+	kaerrors "github.com/hixichen/kube-iam-assume/pkg/errors"
+	"github.com/hixichen/kube-iam-assume/pkg/federation"
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
+)
+
+// preflightPublisher is a publisher whose checks return fixed errors.
+type preflightPublisher struct {
+	validateErr error
+	healthErr   error
+}
+
+func (p *preflightPublisher) Publish(context.Context, *bridge.DiscoveryDocument, *bridge.JWKS) error {
+	return errors.New("preflight must not publish")
+}
+func (p *preflightPublisher) Validate(context.Context) error    { return p.validateErr }
+func (p *preflightPublisher) GetPublicURL() string              { return "https://oidc.example.com" }
+func (p *preflightPublisher) HealthCheck(context.Context) error { return p.healthErr }
+func (p *preflightPublisher) Type() iface.PublisherType         { return iface.PublisherTypeS3 }
+func (p *preflightPublisher) Close() error                      { return nil }
+
+// preflightProvider is a federation provider whose lookup returns a fixed result.
+type preflightProvider struct {
+	federation.Provider
+	info *federation.ProviderInfo
+	err  error
+}
+
+func (p *preflightProvider) GetProviderInfo(context.Context, string) (*federation.ProviderInfo, error) {
+	return p.info, p.err
+}
+func (p *preflightProvider) Type() string { return "aws" }
+
+func TestRunPreflight(t *testing.T) {
+	tests := []struct {
+		name       string
+		pub        *preflightPublisher
+		provider   federation.Provider
+		wantFailed []string
+		wantOutput string
+	}{
+		{
+			name:       "all checks pass",
+			pub:        &preflightPublisher{},
+			wantOutput: "✓ publisher access: s3 bucket reachable and writable",
+		},
+		{
+			name:       "validate fails",
+			pub:        &preflightPublisher{validateErr: errors.New("failed to upload test object: AccessDenied")},
+			wantFailed: []string{"publisher access"},
+			wantOutput: "✗ publisher access: failed to upload test object: AccessDenied",
+		},
+		{
+			name:       "missing federation provider is not a failure",
+			pub:        &preflightPublisher{},
+			provider:   &preflightProvider{err: kaerrors.NewNotFoundError("aws-federation", "no OIDC provider found", nil)},
+			wantOutput: "✓ aws federation provider: no provider for the issuer yet",
+		},
+		{
+			name:       "federation provider not readable",
+			pub:        &preflightPublisher{},
+			provider:   &preflightProvider{err: errors.New("iam:ListOpenIDConnectProviders denied")},
+			wantFailed: []string{"aws federation provider"},
+			wantOutput: "✗ aws federation provider: iam:ListOpenIDConnectProviders denied",
+		},
+		{
+			name:       "existing federation provider",
+			pub:        &preflightPublisher{},
+			provider:   &preflightProvider{info: &federation.ProviderInfo{ProviderARN: "arn:aws:iam::123456789012:oidc-provider/oidc.example.com"}},
+			wantOutput: "✓ aws federation provider: found arn:aws:iam::123456789012:oidc-provider/oidc.example.com",
+		},
+		{
+			name: "active federation provider",
+			pub:  &preflightPublisher{},
+			provider: &preflightProvider{info: &federation.ProviderInfo{
+				ProviderARN: "arn:aws:iam::123456789012:oidc-provider/oidc.example.com",
+				Status:      federation.ProviderStatusActive,
+			}},
+			wantOutput: "✓ aws federation provider: found arn:aws:iam::123456789012:oidc-provider/oidc.example.com (active)",
+		},
+		{
+			name: "disabled federation provider",
+			pub:  &preflightPublisher{},
+			provider: &preflightProvider{info: &federation.ProviderInfo{
+				ProviderARN: "projects/p/locations/global/workloadIdentityPools/kubeassume/providers/kubeassume",
+				Status:      federation.ProviderStatusDisabled,
+				RawStatus:   "DELETED",
+			}},
+			wantFailed: []string{"aws federation provider"},
+			wantOutput: "is disabled (state DELETED)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checks := runPreflight(context.Background(), tt.pub, tt.provider)
+
+			var out bytes.Buffer
+			err := printPreflight(&out, checks)
+			assert.Contains(t, out.String(), tt.wantOutput)
+			if len(tt.wantFailed) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, name := range tt.wantFailed {
+				assert.Contains(t, err.Error(), name)
+			}
+		})
+	}
+}
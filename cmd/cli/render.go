@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/hixichen/kube-iam-assume/pkg/config"
+	"github.com/hixichen/kube-iam-assume/pkg/constants"
+)
+
+// defaultConfigMapName is the ConfigMap name used when rendering a manifest.
+const defaultConfigMapName = "kube-iam-assume"
+
+// newRenderCommand creates the render command.
+func newRenderCommand() *cobra.Command {
+	var (
+		configPath string
+		output     string
+		name       string
+		namespace  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "render",
+		Short: "Render Helm values or a ConfigMap from a controller config file",
+		Long: `Loads and validates a controller config file and renders it as Helm --set
+flags, a values.yaml snippet, or a ConfigMap manifest for the controller.
+
+Only explicitly configured settings are rendered, so chart defaults still apply
+to everything else.`,
+		Example: `  # Print Helm --set flags
+  kube-iam-assume render --config config.yaml --output helm
+
+  # Write a values.yaml snippet
+  kube-iam-assume render --config config.yaml --output values > values-oidc.yaml
+
+  # Apply the controller ConfigMap directly
+  kube-iam-assume render --config config.yaml --output configmap | kubectl apply -f -`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.LoadConfig(configPath)
+			if err != nil {
+				return err
+			}
+			return renderConfig(os.Stdout, cfg, output, name, namespace)
+		},
+	}
+
+	cmd.Flags().StringVar(&configPath, "config", "", "Path to the controller config file")
+	cmd.Flags().StringVar(&output, "output", "helm", "Output format (helm, values, configmap)")
+	cmd.Flags().StringVar(&name, "name", defaultConfigMapName, "ConfigMap name (configmap output)")
+	cmd.Flags().StringVar(&namespace, "namespace", constants.DefaultNamespace, "ConfigMap namespace (configmap output)")
+
+	if err := cmd.MarkFlagRequired("config"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
+
+// renderConfig writes cfg to w in the requested output format.
+func renderConfig(w io.Writer, cfg *config.Config, outputFormat, name, namespace string) error {
+	var (
+		out []byte
+		err error
+	)
+	switch outputFormat {
+	case "helm":
+		out = []byte(renderHelmSetFlags(cfg))
+	case "values":
+		out, err = renderHelmValues(cfg)
+	case "configmap":
+		out, err = renderConfigMap(cfg, name, namespace)
+	default:
+		return fmt.Errorf("unsupported output format: %s", outputFormat)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(out)
+	return err
+}
+
+// renderHelmSetFlags renders one "--set config.<path>=<value>" flag per configured setting, sorted by path.
+func renderHelmSetFlags(cfg *config.Config) string {
+	var flags []string
+	var walk func(prefix string, values map[string]interface{})
+	walk = func(prefix string, values map[string]interface{}) {
+		for key, value := range values {
+			path := prefix + "." + key
+			switch v := value.(type) {
+			case map[string]interface{}:
+				walk(path, v)
+			case map[string]string:
+				for k, item := range v {
+					flags = append(flags, "--set "+shellQuote(path+"."+k+"="+escapeHelmValue(item)))
+				}
+			case []string:
+				items := make([]string, len(v))
+				for i, item := range v {
+					items[i] = escapeHelmValue(item)
+				}
+				flags = append(flags, "--set "+shellQuote(path+"={"+strings.Join(items, ",")+"}"))
+			default:
+				flags = append(flags, "--set "+shellQuote(path+"="+escapeHelmValue(fmt.Sprint(value))))
+			}
+		}
+	}
+	walk("config", cfg.Values())
+	sort.Strings(flags)
+
+	var b strings.Builder
+	for _, flag := range flags {
+		b.WriteString(flag)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// escapeHelmValue escapes the characters --set treats as separators.
+func escapeHelmValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`).Replace(value)
+}
+
+// shellQuote single-quotes s unless it only contains characters that are safe in a shell word.
+func shellQuote(s string) string {
+	safe := strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("._-/:=@", r))
+	}) == -1
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'''`) + "'"
+}
+
+// renderHelmValues renders the config as a values.yaml snippet for the chart.
+func renderHelmValues(cfg *config.Config) ([]byte, error) {
+	out, err := yaml.Marshal(map[string]interface{}{"config": cfg.Values()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render Helm values: %w", err)
+	}
+	return out, nil
+}
+
+// renderConfigMap renders the controller ConfigMap that holds config.yaml.
+func renderConfigMap(cfg *config.Config, name, namespace string) ([]byte, error) {
+	configYAML, err := yaml.Marshal(cfg.Values())
+	if err != nil {
+		return nil, fmt.Errorf("failed to render config: %w", err)
+	}
+
+	cm := corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/name": "kube-iam-assume",
+			},
+		},
+		Data: map[string]string{
+			"config.yaml": string(configYAML),
+		},
+	}
+
+	out, err := yaml.Marshal(cm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render ConfigMap: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hixichen/kube-iam-assume/pkg/config"
+	"github.com/hixichen/kube-iam-assume/pkg/constants"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestRenderConfig_S3Golden(t *testing.T) {
+	assertRenderGolden(t, "s3-config.yaml", "render-s3-")
+}
+
+func TestRenderConfig_GroupGolden(t *testing.T) {
+	// Explicit false booleans, lists and maps
+	assertRenderGolden(t, "group-config.yaml", "render-group-")
+}
+
+// assertRenderGolden renders the config file in testdata in every output format
+// and compares the results with the golden files named goldenPrefix+format.
+func assertRenderGolden(t *testing.T, configFile, goldenPrefix string) {
+	t.Helper()
+	cfg, err := config.LoadConfig(filepath.Join("testdata", configFile))
+	require.NoError(t, err)
+
+	for _, format := range []string{"helm", "values", "configmap"} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, renderConfig(&buf, cfg, format, defaultConfigMapName, constants.DefaultNamespace))
+
+			golden := filepath.Join("testdata", goldenPrefix+format+".golden")
+			if *update {
+				require.NoError(t, os.WriteFile(golden, buf.Bytes(), 0o600))
+			}
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), buf.String())
+		})
+	}
+}
+
+func TestRenderConfig_UnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	err := renderConfig(&buf, &config.Config{}, "xml", defaultConfigMapName, constants.DefaultNamespace)
+	assert.Error(t, err)
+}
@@ -27,7 +27,7 @@ bridging the gap between K8s service account tokens and cloud provider identity
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
@@ -35,5 +35,13 @@ func init() {
 	rootCmd.AddCommand(newSetupCommand())
 	rootCmd.AddCommand(NewStatusCommand())
 	rootCmd.AddCommand(NewBucketCommand())
+	rootCmd.AddCommand(newStepDownCommand())
+	rootCmd.AddCommand(newRenderCommand())
+	rootCmd.AddCommand(newPreflightCommand())
+	rootCmd.AddCommand(newDiagnosticsCommand())
+	rootCmd.AddCommand(newStateCommand())
+	rootCmd.AddCommand(newClustersCommand())
+	rootCmd.AddCommand(newRotationCommand())
+	rootCmd.AddCommand(newTeardownCommand())
 	rootCmd.AddCommand(versionCmd)
 }
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
+	"github.com/hixichen/kube-iam-assume/pkg/constants"
+	"github.com/hixichen/kube-iam-assume/pkg/rotation"
+)
+
+// keyPreviewStatus is what one sync would do to a key.
+type keyPreviewStatus string
+
+const (
+	// keyStatusNew is a key in the source JWKS that the state does not track yet.
+	keyStatusNew keyPreviewStatus = "new"
+	// keyStatusCurrent is a tracked key that is still in the source JWKS.
+	keyStatusCurrent keyPreviewStatus = "current"
+	// keyStatusMissing is a key absent from the source JWKS that is not marked for removal yet.
+	keyStatusMissing keyPreviewStatus = "missing"
+	// keyStatusMarked is a key the sync would mark for removal; it is kept during the overlap.
+	keyStatusMarked keyPreviewStatus = "marked for removal"
+	// keyStatusOverlap is a key that was already marked for removal and is still kept.
+	keyStatusOverlap keyPreviewStatus = "overlap"
+	// keyStatusRemoved is a key whose overlap period has ended; the sync would remove it.
+	keyStatusRemoved keyPreviewStatus = "removed"
+)
+
+// keyPreview describes what one sync would do to a key.
+type keyPreview struct {
+	KeyID  string
+	Status keyPreviewStatus
+	// Until is when a key marked for removal stops being published (zero otherwise)
+	Until time.Time
+}
+
+// rotationPreview is the outcome of applying a source JWKS to the rotation state
+// without saving it.
+type rotationPreview struct {
+	// JWKS is the JWKS the controller would publish
+	JWKS *bridge.JWKS
+	// Events are the rotation events the sync would emit
+	Events []rotation.Event
+	// Keys lists every key in the state before or after the sync, sorted by kid
+	Keys []keyPreview
+}
+
+// newRotationCommand creates the rotation command and its subcommands.
+func newRotationCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotation",
+		Short: "Inspect signing-key rotation",
+	}
+	cmd.AddCommand(newRotationPreviewCommand())
+	return cmd
+}
+
+// newRotationPreviewCommand creates the rotation preview command.
+func newRotationPreviewCommand() *cobra.Command {
+	var (
+		kubeconfig       string
+		namespace        string
+		name             string
+		jwksFile         string
+		live             bool
+		overlap          time.Duration
+		missingThreshold int
+		keyOrder         string
+	)
+
+	defaults := rotation.DefaultConfig()
+	cmd := &cobra.Command{
+		Use:   "preview",
+		Short: "Preview the JWKS published after a signing-key rotation",
+		Long: `Loads the controller's key rotation state and applies a source JWKS to it the
+way the controller's next sync would, without saving anything. Prints which keys
+are new, which would be marked for removal or kept during the overlap period,
+the rotation events and the JWKS that would be published.
+
+The source JWKS is read from a file, e.g. the JWKS of a planned new signing key,
+or fetched from the API server with --live. Pass the controller's rotation
+settings so the preview matches its behaviour.`,
+		Example: `  # Preview publishing a planned JWKS
+  kube-iam-assume rotation preview --jwks-file new-jwks.json
+
+  # Preview the controller's next sync
+  kube-iam-assume rotation preview --live --overlap 48h`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (jwksFile == "") == !live {
+				return fmt.Errorf("exactly one of --jwks-file and --live is required")
+			}
+			order := rotation.KeyOrder(keyOrder)
+			if order != rotation.KeyOrderKid && order != rotation.KeyOrderNewestFirst {
+				return fmt.Errorf("--key-order must be one of %s, %s", rotation.KeyOrderKid, rotation.KeyOrderNewestFirst)
+			}
+
+			ctx := cmd.Context()
+			clientset, err := buildClientset(kubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to connect to cluster: %w", err)
+			}
+			source, err := loadSourceJWKS(ctx, clientset, jwksFile)
+			if err != nil {
+				return err
+			}
+			state, err := rotation.NewConfigMapStore(clientset, namespace, name, slog.Default()).Load(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to load rotation state: %w", err)
+			}
+
+			rotCfg := defaults
+			rotCfg.OverlapPeriod = overlap
+			rotCfg.MissingThreshold = missingThreshold
+			rotCfg.KeyOrder = order
+			now := time.Now()
+			preview, err := previewRotation(state, source, rotCfg, now)
+			if err != nil {
+				return err
+			}
+			return printRotationPreview(cmd.OutOrStdout(), preview, now)
+		},
+	}
+
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file (defaults to the standard loading rules)")
+	cmd.Flags().StringVar(&namespace, "namespace", constants.DefaultNamespace, "Namespace the controller runs in")
+	cmd.Flags().StringVar(&name, "configmap", constants.DefaultRotationConfigMapName, "Name of the rotation state ConfigMap")
+	cmd.Flags().StringVarP(&jwksFile, "jwks-file", "f", "", "Source JWKS to apply to the state")
+	cmd.Flags().BoolVar(&live, "live", false, "Fetch the source JWKS from the API server")
+	cmd.Flags().DurationVar(&overlap, "overlap", defaults.OverlapPeriod, "Rotation overlap period of the controller")
+	cmd.Flags().IntVar(&missingThreshold, "missing-threshold", defaults.MissingThreshold, "Consecutive syncs a key must be absent before it is marked for removal")
+	cmd.Flags().StringVar(&keyOrder, "key-order", string(defaults.KeyOrder), "Order of keys in the published JWKS (kid or newestFirst)")
+
+	return cmd
+}
+
+// loadSourceJWKS reads the source JWKS from file, or fetches it from the API
+// server when file is empty.
+func loadSourceJWKS(ctx context.Context, clientset kubernetes.Interface, file string) (*bridge.JWKS, error) {
+	var (
+		data   []byte
+		err    error
+		origin = file
+	)
+	if file != "" {
+		data, err = os.ReadFile(file)
+	} else {
+		origin = "the API server"
+		data, err = clientset.Discovery().RESTClient().Get().AbsPath("/openid/v1/jwks").DoRaw(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source JWKS from %s: %w", origin, err)
+	}
+
+	var jwks bridge.JWKS
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to parse source JWKS from %s: %w", origin, err)
+	}
+	if err := bridge.ValidateJWKS(&jwks); err != nil {
+		return nil, fmt.Errorf("invalid source JWKS from %s: %w", origin, err)
+	}
+	return &jwks, nil
+}
+
+// previewRotation applies source to a copy of state as one controller sync at now
+// would, and reports the result. state is not modified.
+func previewRotation(state *rotation.State, source *bridge.JWKS, cfg rotation.Config, now time.Time) (*rotationPreview, error) {
+	merger := rotation.NewMerger(cfg.OverlapPeriod).
+		WithMissingGrace(cfg.MissingThreshold, cfg.MissingGracePeriod).
+		WithKeyOrder(cfg.KeyOrder)
+	after := state.Clone()
+	jwks, events, err := merger.Process(source, after, now)
+	if err != nil {
+		return nil, err
+	}
+
+	inSource := make(map[string]bool, len(source.Keys))
+	for _, key := range source.Keys {
+		inSource[key.Kid] = true
+	}
+	var keyIDs []string
+	for keyID := range state.Keys {
+		keyIDs = append(keyIDs, keyID)
+	}
+	for keyID := range after.Keys {
+		if _, ok := state.Keys[keyID]; !ok {
+			keyIDs = append(keyIDs, keyID)
+		}
+	}
+	slices.Sort(keyIDs)
+
+	preview := &rotationPreview{JWKS: jwks, Events: events}
+	for _, keyID := range keyIDs {
+		before, tracked := state.Keys[keyID]
+		next, kept := after.Keys[keyID]
+		key := keyPreview{KeyID: keyID}
+		switch {
+		case !kept:
+			key.Status = keyStatusRemoved
+		case inSource[keyID] && !tracked:
+			key.Status = keyStatusNew
+		case inSource[keyID]:
+			key.Status = keyStatusCurrent
+		case next.MarkedForRemoval == nil:
+			key.Status = keyStatusMissing
+		case before.MarkedForRemoval == nil:
+			key.Status = keyStatusMarked
+			key.Until = next.MarkedForRemoval.Add(cfg.OverlapPeriod)
+		default:
+			key.Status = keyStatusOverlap
+			key.Until = next.MarkedForRemoval.Add(cfg.OverlapPeriod)
+		}
+		preview.Keys = append(preview.Keys, key)
+	}
+	return preview, nil
+}
+
+// printRotationPreview writes the key changes, events and published JWKS of preview to w.
+func printRotationPreview(w io.Writer, preview *rotationPreview, now time.Time) error {
+	_, _ = fmt.Fprintf(w, "Keys after a sync at %s:\n", now.UTC().Format(time.RFC3339))
+	width := 0
+	for _, key := range preview.Keys {
+		width = max(width, len(key.KeyID))
+	}
+	for _, key := range preview.Keys {
+		line := fmt.Sprintf("  %-*s  %s", width, key.KeyID, key.Status)
+		if !key.Until.IsZero() {
+			line += fmt.Sprintf(" (published until %s)", key.Until.UTC().Format(time.RFC3339))
+		}
+		_, _ = fmt.Fprintln(w, line)
+	}
+
+	_, _ = fmt.Fprintln(w, "Events:")
+	if len(preview.Events) == 0 {
+		_, _ = fmt.Fprintln(w, "  none")
+	}
+	for _, event := range preview.Events {
+		_, _ = fmt.Fprintf(w, "  %s: %s\n", event.Type, event.Message)
+	}
+
+	data, err := json.MarshalIndent(preview.JWKS, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JWKS: %w", err)
+	}
+	_, _ = fmt.Fprintf(w, "Published JWKS (%d keys):\n%s\n", len(preview.JWKS.Keys), data)
+	return nil
+}
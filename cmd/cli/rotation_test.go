@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
+	"github.com/hixichen/kube-iam-assume/pkg/rotation"
+)
+
+func TestPreviewRotation(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	key := func(kid string) bridge.JWK { return bridge.JWK{Kid: kid, Kty: "RSA", N: "AQAB", E: "AQAB"} }
+	tracked := func(kid string, missing int, markedAgo time.Duration) *rotation.KeyState {
+		ks := &rotation.KeyState{KeyID: kid, Key: key(kid), FirstSeen: now.Add(-72 * time.Hour), LastSeen: now.Add(-time.Hour), MissingCount: missing}
+		if markedAgo > 0 {
+			marked := now.Add(-markedAgo)
+			ks.MarkedForRemoval = &marked
+		}
+		return ks
+	}
+	state := &rotation.State{
+		Keys: map[string]*rotation.KeyState{
+			"key-current":  tracked("key-current", 0, 0),
+			"key-missing":  tracked("key-missing", 0, 0),
+			"key-marked":   tracked("key-marked", 1, 0),
+			"key-overlap":  tracked("key-overlap", 3, time.Hour),
+			"key-expiring": tracked("key-expiring", 5, 25*time.Hour),
+		},
+		Version: 4,
+	}
+	source := &bridge.JWKS{Keys: []bridge.JWK{key("key-current"), key("key-new")}}
+	cfg := rotation.DefaultConfig()
+
+	preview, err := previewRotation(state, source, cfg, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, []keyPreview{
+		{KeyID: "key-current", Status: keyStatusCurrent},
+		{KeyID: "key-expiring", Status: keyStatusRemoved},
+		{KeyID: "key-marked", Status: keyStatusMarked, Until: now.Add(24 * time.Hour)},
+		{KeyID: "key-missing", Status: keyStatusMissing},
+		{KeyID: "key-new", Status: keyStatusNew},
+		{KeyID: "key-overlap", Status: keyStatusOverlap, Until: now.Add(23 * time.Hour)},
+	}, preview.Keys)
+
+	var kids []string
+	for _, k := range preview.JWKS.Keys {
+		kids = append(kids, k.Kid)
+	}
+	assert.Equal(t, []string{"key-current", "key-marked", "key-missing", "key-new", "key-overlap"}, kids)
+
+	require.Len(t, preview.Events, 2)
+	assert.Equal(t, rotation.EventNewKey, preview.Events[0].Type)
+	assert.Equal(t, "key-new", preview.Events[0].KeyID)
+	assert.Equal(t, rotation.EventKeyExpired, preview.Events[1].Type)
+	assert.Equal(t, "key-expiring", preview.Events[1].KeyID)
+
+	// The preview is a dry run
+	assert.Equal(t, int64(4), state.Version)
+	assert.Len(t, state.Keys, 5)
+	assert.Nil(t, state.Keys["key-marked"].MarkedForRemoval)
+
+	var out bytes.Buffer
+	require.NoError(t, printRotationPreview(&out, preview, now))
+	assert.Contains(t, out.String(), "  key-marked    marked for removal (published until 2026-03-02T12:00:00Z)\n")
+	assert.Contains(t, out.String(), "  NewKey: New key detected: key-new\n")
+	assert.Contains(t, out.String(), "Published JWKS (5 keys):\n{")
+}
+
+func TestLoadSourceJWKS_File(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "jwks.json")
+	require.NoError(t, os.WriteFile(valid, []byte(`{"keys":[{"kty":"RSA","kid":"key-1","n":"AQAB","e":"AQAB"}]}`), 0o600))
+	empty := filepath.Join(dir, "empty.json")
+	require.NoError(t, os.WriteFile(empty, []byte(`{"keys":[]}`), 0o600))
+
+	jwks, err := loadSourceJWKS(t.Context(), nil, valid)
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", jwks.Keys[0].Kid)
+
+	_, err = loadSourceJWKS(t.Context(), nil, empty)
+	assert.ErrorContains(t, err, "invalid source JWKS")
+
+	_, err = loadSourceJWKS(t.Context(), nil, filepath.Join(dir, "missing.json"))
+	assert.ErrorContains(t, err, "failed to read source JWKS")
+}
@@ -1,7 +1,15 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
 	"github.com/spf13/cobra"
+
+	"github.com/hixichen/kube-iam-assume/pkg/federation"
 )
 
 // newSetupCommand creates the setup parent command.
@@ -26,3 +34,63 @@ to enable secretless authentication from Kubernetes to cloud services.`,
 
 	return cmd
 }
+
+// clusterAudienceFlags holds the flags for deriving audiences from the cluster.
+type clusterAudienceFlags struct {
+	fromCluster    bool
+	kubeconfig     string
+	serviceAccount string
+}
+
+// addFlags registers the cluster audience flags on cmd.
+func (f *clusterAudienceFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&f.fromCluster, "audiences-from-cluster", false, "Add the audiences the cluster puts into service account tokens")
+	cmd.Flags().StringVar(&f.kubeconfig, "kubeconfig", "", "Path to kubeconfig file used with --audiences-from-cluster")
+	cmd.Flags().StringVar(&f.serviceAccount, "audience-service-account", "default/default", "Service account (namespace/name) whose token is inspected for --audiences-from-cluster")
+}
+
+// resolve returns the explicit audiences merged with the cluster's, when requested.
+func (f *clusterAudienceFlags) resolve(ctx context.Context, explicit []string) ([]string, error) {
+	if !f.fromCluster {
+		return explicit, nil
+	}
+
+	namespace, name, ok := strings.Cut(f.serviceAccount, "/")
+	if !ok || namespace == "" || name == "" {
+		return nil, fmt.Errorf("invalid --audience-service-account %q: expected namespace/name", f.serviceAccount)
+	}
+
+	clientset, err := buildClientset(f.kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	derived, err := federation.ClusterAudiences(ctx, clientset, namespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster audiences: %w", err)
+	}
+	return federation.MergeAudiences(explicit, derived), nil
+}
+
+// printAudienceWarnings prints a warning for each audience problem the cloud
+// behind providerType is known to have.
+func printAudienceWarnings(providerType federation.ProviderType, audiences []string) {
+	for _, warning := range federation.AudienceWarnings(providerType, audiences) {
+		fmt.Printf("  ⚠ %s\n", warning)
+	}
+}
+
+// logOperationRecorder records federation provider calls as log lines, so the
+// duration and outcome of each cloud call made by the CLI is visible.
+type logOperationRecorder struct {
+	logger *slog.Logger
+}
+
+// RecordFederationOperation logs one call to the federation provider.
+func (r logOperationRecorder) RecordFederationOperation(provider, operation, result string, duration time.Duration) {
+	r.logger.Info("Federation provider operation finished",
+		"provider", provider,
+		"operation", operation,
+		"result", result,
+		"duration", duration,
+	)
+}
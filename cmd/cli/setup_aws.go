@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -15,9 +16,14 @@ import (
 // newAWSCommand creates the AWS setup subcommand.
 func newAWSCommand() *cobra.Command {
 	var (
-		issuerURL string
-		region    string
-		audience  []string
+		issuerURL     string
+		region        string
+		audience      []string
+		trusted       []string
+		subjectTmpl   string
+		expiryWarning time.Duration
+		clusterAud    clusterAudienceFlags
+		allowed       []string
 	)
 
 	cmd := &cobra.Command{
@@ -35,15 +41,43 @@ using service account tokens.`,
   kubeassume setup aws \
     --issuer-url https://my-bucket.s3.us-west-2.amazonaws.com \
     --region us-west-2 \
-    --audience sts.amazonaws.com`,
+    --audience sts.amazonaws.com
+
+  # Print a trust-policy condition for specific service accounts
+  kubeassume setup aws \
+    --issuer-url https://my-bucket.s3.us-west-2.amazonaws.com \
+    --trusted-subject payments/api
+
+  # Build the "sub" values for an issuer that formats subjects differently
+  kubeassume setup aws \
+    --issuer-url https://my-bucket.s3.us-west-2.amazonaws.com \
+    --trusted-subject payments/api \
+    --subject-template 'prod:{{.Namespace}}:{{.ServiceAccount}}'
+
+  # Also trust the audiences the cluster puts into service account tokens
+  kubeassume setup aws \
+    --issuer-url https://my-bucket.s3.us-west-2.amazonaws.com \
+    --audiences-from-cluster`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runAWSSetup(cmd.Context(), issuerURL, region, audience)
+			if err := checkIssuerAllowed(issuerURL, allowed); err != nil {
+				return err
+			}
+			audiences, err := clusterAud.resolve(cmd.Context(), audience)
+			if err != nil {
+				return err
+			}
+			return runAWSSetup(cmd.Context(), issuerURL, region, audiences, trusted, subjectTmpl, expiryWarning)
 		},
 	}
 
 	cmd.Flags().StringVar(&issuerURL, "issuer-url", "", "OIDC issuer URL (required)")
 	cmd.Flags().StringVar(&region, "region", "", "AWS region (required, or use AWS_REGION env var)")
-	cmd.Flags().StringArrayVar(&audience, "audience", []string{"sts.amazonaws.com"}, "OIDC audience(s)")
+	cmd.Flags().StringArrayVar(&audience, "audience", federation.DefaultAudiences(federation.ProviderTypeAWS), "OIDC audience(s)")
+	cmd.Flags().StringArrayVar(&trusted, "trusted-subject", []string{}, "Service account allowed to assume roles, as namespace/serviceaccount (repeatable)")
+	cmd.Flags().StringVar(&subjectTmpl, "subject-template", federation.DefaultSubjectTemplate, "Go template building the \"sub\" value of each trusted subject from {{.Namespace}} and {{.ServiceAccount}}")
+	cmd.Flags().DurationVar(&expiryWarning, "cert-expiry-warning", 30*24*time.Hour, "Warn when the thumbprinted issuer certificate expires within this window")
+	clusterAud.addFlags(cmd)
+	addAllowedIssuerSuffixFlag(cmd, &allowed)
 
 	if err := cmd.MarkFlagRequired("issuer-url"); err != nil {
 		panic(err)
@@ -52,7 +86,7 @@ using service account tokens.`,
 	return cmd
 }
 
-func runAWSSetup(ctx context.Context, issuerURL, region string, audiences []string) error {
+func runAWSSetup(ctx context.Context, issuerURL, region string, audiences, trustedSubjects []string, subjectTemplate string, expiryWarning time.Duration) error {
 	// Get region from environment if not provided
 	if region == "" {
 		region = os.Getenv("AWS_REGION")
@@ -68,6 +102,7 @@ func runAWSSetup(ctx context.Context, issuerURL, region string, audiences []stri
 	fmt.Printf("  Region:    %s\n", region)
 	fmt.Printf("  Issuer:    %s\n", issuerURL)
 	fmt.Printf("  Audiences: %v\n", audiences)
+	printAudienceWarnings(federation.ProviderTypeAWS, audiences)
 
 	// Create provider with logger
 	logger := slog.Default()
@@ -75,11 +110,14 @@ func runAWSSetup(ctx context.Context, issuerURL, region string, audiences []stri
 	if err != nil {
 		return fmt.Errorf("failed to create AWS provider: %w", err)
 	}
+	provider = federation.Instrument(provider, logOperationRecorder{logger: logger})
 
 	// Setup OIDC provider
 	result, err := provider.Setup(ctx, federation.SetupConfig{
-		IssuerURL: issuerURL,
-		Audiences: audiences,
+		IssuerURL:       issuerURL,
+		Audiences:       audiences,
+		TrustedSubjects: trustedSubjects,
+		SubjectTemplate: subjectTemplate,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to setup OIDC provider: %w", err)
@@ -88,10 +126,20 @@ func runAWSSetup(ctx context.Context, issuerURL, region string, audiences []stri
 	fmt.Printf("\n✓ AWS IAM OIDC Provider created successfully!\n")
 	fmt.Printf("  ARN:        %s\n", result.ProviderARN)
 	fmt.Printf("  Thumbprint: %s\n", result.Thumbprint)
+	if !result.CertNotAfter.IsZero() {
+		fmt.Printf("  Cert expiry: %s (chain length %d)\n", result.CertNotAfter.UTC().Format(time.RFC3339), result.CertChainLength)
+		if time.Until(result.CertNotAfter) <= expiryWarning {
+			fmt.Printf("\n⚠ The thumbprinted certificate expires within %s; update the provider thumbprint after the issuer certificate is rotated\n", expiryWarning)
+		}
+	}
 	fmt.Printf("\nNext steps:\n")
 	fmt.Printf("  1. Create IAM roles with trust policies referencing this provider\n")
 	fmt.Printf("  2. Annotate your Kubernetes service accounts with the IAM role ARN\n")
 	fmt.Printf("  3. Configure your pods to use the service account\n")
 
+	if result.TrustCondition != "" {
+		fmt.Printf("\nTrust policy condition for the trusted subjects:\n%s\n", result.TrustCondition)
+	}
+
 	return nil
 }
@@ -14,11 +14,15 @@ import (
 // newGCPCommand creates the GCP setup subcommand.
 func newGCPCommand() *cobra.Command {
 	var (
-		issuerURL string
-		projectID string
-		poolID    string
-		poolName  string
-		audience  []string
+		issuerURL   string
+		projectID   string
+		poolID      string
+		poolName    string
+		audience    []string
+		trusted     []string
+		subjectTmpl string
+		clusterAud  clusterAudienceFlags
+		allowed     []string
 	)
 
 	cmd := &cobra.Command{
@@ -37,9 +41,23 @@ impersonate GCP service accounts using Kubernetes service account tokens.`,
     --issuer-url https://storage.googleapis.com/my-bucket \
     --project my-project \
     --pool-id my-k8s-pool \
-    --pool-name "My Kubernetes Pool"`,
+    --pool-name "My Kubernetes Pool"
+
+  # Only accept tokens from specific service accounts
+  kubeassume setup gcp \
+    --issuer-url https://storage.googleapis.com/my-bucket \
+    --project my-project \
+    --trusted-subject payments/api \
+    --trusted-subject batch/worker`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runGCPSetup(cmd.Context(), issuerURL, projectID, poolID, poolName, audience)
+			if err := checkIssuerAllowed(issuerURL, allowed); err != nil {
+				return err
+			}
+			audiences, err := clusterAud.resolve(cmd.Context(), audience)
+			if err != nil {
+				return err
+			}
+			return runGCPSetup(cmd.Context(), issuerURL, projectID, poolID, poolName, audiences, trusted, subjectTmpl)
 		},
 	}
 
@@ -47,7 +65,11 @@ impersonate GCP service accounts using Kubernetes service account tokens.`,
 	cmd.Flags().StringVar(&projectID, "project", "", "GCP project ID (required, or use GOOGLE_CLOUD_PROJECT env var)")
 	cmd.Flags().StringVar(&poolID, "pool-id", "", "Workload Identity Pool ID (optional, auto-generated)")
 	cmd.Flags().StringVar(&poolName, "pool-name", "", "Workload Identity Pool display name (optional)")
-	cmd.Flags().StringArrayVar(&audience, "audience", []string{}, "OIDC audience(s)")
+	cmd.Flags().StringArrayVar(&audience, "audience", federation.DefaultAudiences(federation.ProviderTypeGCP), "OIDC audience(s) (default: the provider's resource name)")
+	cmd.Flags().StringArrayVar(&trusted, "trusted-subject", []string{}, "Service account allowed to federate, as namespace/serviceaccount (repeatable)")
+	cmd.Flags().StringVar(&subjectTmpl, "subject-template", federation.DefaultSubjectTemplate, "Go template building the \"sub\" value of each trusted subject from {{.Namespace}} and {{.ServiceAccount}}")
+	clusterAud.addFlags(cmd)
+	addAllowedIssuerSuffixFlag(cmd, &allowed)
 
 	if err := cmd.MarkFlagRequired("issuer-url"); err != nil {
 		panic(err)
@@ -59,7 +81,7 @@ impersonate GCP service accounts using Kubernetes service account tokens.`,
 	return cmd
 }
 
-func runGCPSetup(ctx context.Context, issuerURL, projectID, poolID, poolName string, audiences []string) error {
+func runGCPSetup(ctx context.Context, issuerURL, projectID, poolID, poolName string, audiences, trustedSubjects []string, subjectTemplate string) error {
 	fmt.Printf("Setting up GCP Workload Identity Federation...\n")
 	fmt.Printf("  Project:   %s\n", projectID)
 	fmt.Printf("  Issuer:    %s\n", issuerURL)
@@ -67,6 +89,14 @@ func runGCPSetup(ctx context.Context, issuerURL, projectID, poolID, poolName str
 		fmt.Printf("  Pool ID:   %s\n", poolID)
 	}
 	fmt.Printf("  Audiences: %v\n", audiences)
+	printAudienceWarnings(federation.ProviderTypeGCP, audiences)
+	if len(trustedSubjects) > 0 {
+		condition, err := gcp.TrustedSubjectsCondition(trustedSubjects, subjectTemplate)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  Condition: %s\n", condition)
+	}
 
 	// Create provider with logger
 	logger := slog.Default()
@@ -74,6 +104,7 @@ func runGCPSetup(ctx context.Context, issuerURL, projectID, poolID, poolName str
 	if err != nil {
 		return fmt.Errorf("failed to create GCP provider: %w", err)
 	}
+	provider = federation.Instrument(provider, logOperationRecorder{logger: logger})
 
 	// Setup Workload Identity Federation
 	options := make(map[string]interface{})
@@ -85,9 +116,11 @@ func runGCPSetup(ctx context.Context, issuerURL, projectID, poolID, poolName str
 	}
 
 	result, err := provider.Setup(ctx, federation.SetupConfig{
-		IssuerURL: issuerURL,
-		Audiences: audiences,
-		Options:   options,
+		IssuerURL:       issuerURL,
+		Audiences:       audiences,
+		TrustedSubjects: trustedSubjects,
+		SubjectTemplate: subjectTemplate,
+		Options:         options,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to setup Workload Identity Federation: %w", err)
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/hixichen/kube-iam-assume/pkg/constants"
+	"github.com/hixichen/kube-iam-assume/pkg/rotation"
+)
+
+// newStateCommand creates the state command and its export/import subcommands.
+func newStateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "Export or import the key rotation state",
+		Long: `Exports the controller's key rotation state, or imports it into another
+namespace or cluster, for disaster recovery and controller migrations.
+
+Importing keeps keys that are in their overlap period, including when they are
+due to be removed, so relying parties do not lose keys during the move.`,
+	}
+	cmd.AddCommand(newStateExportCommand())
+	cmd.AddCommand(newStateImportCommand())
+	return cmd
+}
+
+// newStateExportCommand creates the state export command.
+func newStateExportCommand() *cobra.Command {
+	var (
+		kubeconfig string
+		namespace  string
+		name       string
+		output     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write the rotation state as JSON",
+		Example: `  # Print the rotation state
+  kube-iam-assume state export
+
+  # Save it to a file
+  kube-iam-assume state export --output rotation-state.json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientset, err := buildClientset(kubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to connect to cluster: %w", err)
+			}
+
+			if output == "" {
+				return exportState(cmd.Context(), clientset, namespace, name, os.Stdout)
+			}
+			f, err := os.Create(output)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", output, err)
+			}
+			if err := exportState(cmd.Context(), clientset, namespace, name, f); err != nil {
+				_ = f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("failed to write %s: %w", output, err)
+			}
+			fmt.Printf("Wrote rotation state to %s\n", output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file (defaults to the standard loading rules)")
+	cmd.Flags().StringVar(&namespace, "namespace", constants.DefaultNamespace, "Namespace the controller runs in")
+	cmd.Flags().StringVar(&name, "configmap", constants.DefaultRotationConfigMapName, "Name of the rotation state ConfigMap")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the state to this file instead of stdout")
+
+	return cmd
+}
+
+// newStateImportCommand creates the state import command.
+func newStateImportCommand() *cobra.Command {
+	var (
+		kubeconfig string
+		namespace  string
+		name       string
+		file       string
+		force      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Write exported rotation state into the state ConfigMap",
+		Long: `Validates an exported rotation state and writes it into the rotation state
+ConfigMap. Stop the target controller first, or it may overwrite the import.
+A target that already tracks keys is only overwritten with --force.`,
+		Example: `  # Import into a new namespace
+  kube-iam-assume state import --file rotation-state.json --namespace oidc`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", file, err)
+			}
+			clientset, err := buildClientset(kubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to connect to cluster: %w", err)
+			}
+
+			state, err := importState(cmd.Context(), clientset, namespace, name, data, force)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Imported %d keys into ConfigMap %s/%s\n", len(state.Keys), namespace, name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file (defaults to the standard loading rules)")
+	cmd.Flags().StringVar(&namespace, "namespace", constants.DefaultNamespace, "Namespace to import the state into")
+	cmd.Flags().StringVar(&name, "configmap", constants.DefaultRotationConfigMapName, "Name of the rotation state ConfigMap")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Exported state file to import")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite a target that already tracks keys")
+	_ = cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+// exportState writes the rotation state stored in namespace/name to w as indented JSON.
+func exportState(ctx context.Context, clientset kubernetes.Interface, namespace, name string, w io.Writer) error {
+	state, err := rotation.NewConfigMapStore(clientset, namespace, name, slog.Default()).Load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load rotation state: %w", err)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode rotation state: %w", err)
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write rotation state: %w", err)
+	}
+	return nil
+}
+
+// importState validates data and saves it as the rotation state in namespace/name.
+// A target that already tracks keys is only overwritten when force is set.
+func importState(ctx context.Context, clientset kubernetes.Interface, namespace, name string, data []byte, force bool) (*rotation.State, error) {
+	state, err := rotation.DecodeState(data)
+	if err != nil {
+		return nil, err
+	}
+
+	store := rotation.NewConfigMapStore(clientset, namespace, name, slog.Default())
+	existing, err := store.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load target rotation state: %w", err)
+	}
+	if len(existing.Keys) > 0 && !force {
+		return nil, fmt.Errorf("ConfigMap %s/%s already tracks %d keys; use --force to overwrite", namespace, name, len(existing.Keys))
+	}
+
+	if err := store.Save(ctx, state); err != nil {
+		return nil, fmt.Errorf("failed to save rotation state: %w", err)
+	}
+	return state, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
+	"github.com/hixichen/kube-iam-assume/pkg/constants"
+	"github.com/hixichen/kube-iam-assume/pkg/rotation"
+)
+
+func TestExportImportState_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewClientset()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	removal := now.Add(-time.Hour)
+
+	original := &rotation.State{
+		Keys: map[string]*rotation.KeyState{
+			"key-new": {
+				KeyID:     "key-new",
+				Key:       bridge.JWK{Kid: "key-new", Kty: "RSA", N: "AQAB", E: "AQAB"},
+				FirstSeen: now,
+				LastSeen:  now,
+			},
+			"key-old": {
+				KeyID:            "key-old",
+				Key:              bridge.JWK{Kid: "key-old", Kty: "RSA", N: "AQAB", E: "AQAB"},
+				FirstSeen:        now.Add(-48 * time.Hour),
+				LastSeen:         removal,
+				MarkedForRemoval: &removal,
+				MissingCount:     3,
+			},
+		},
+		LastUpdated:   now,
+		Version:       7,
+		PublishedHash: "abc123",
+	}
+	source := rotation.NewConfigMapStore(clientset, constants.DefaultNamespace, constants.DefaultRotationConfigMapName, slog.Default())
+	require.NoError(t, source.Save(ctx, original))
+
+	var exported bytes.Buffer
+	require.NoError(t, exportState(ctx, clientset, constants.DefaultNamespace, constants.DefaultRotationConfigMapName, &exported))
+
+	imported, err := importState(ctx, clientset, "oidc", constants.DefaultRotationConfigMapName, exported.Bytes(), false)
+	require.NoError(t, err)
+	assert.Len(t, imported.Keys, 2)
+
+	target := rotation.NewConfigMapStore(clientset, "oidc", constants.DefaultRotationConfigMapName, slog.Default())
+	loaded, err := target.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, original, loaded)
+	require.NotNil(t, loaded.Keys["key-old"].MarkedForRemoval)
+	assert.True(t, removal.Equal(*loaded.Keys["key-old"].MarkedForRemoval))
+
+	// A target that already tracks keys is only overwritten with force
+	_, err = importState(ctx, clientset, "oidc", constants.DefaultRotationConfigMapName, exported.Bytes(), false)
+	assert.ErrorContains(t, err, "--force")
+	_, err = importState(ctx, clientset, "oidc", constants.DefaultRotationConfigMapName, exported.Bytes(), true)
+	assert.NoError(t, err)
+}
+
+func TestImportState_RejectsInvalidState(t *testing.T) {
+	clientset := fake.NewClientset()
+	_, err := importState(context.Background(), clientset, constants.DefaultNamespace, constants.DefaultRotationConfigMapName,
+		[]byte(`{"keys":{"key-1":{"keyId":"key-2"}},"version":1}`), false)
+	assert.ErrorContains(t, err, "invalid state")
+
+	loaded, err := rotation.NewConfigMapStore(clientset, constants.DefaultNamespace, constants.DefaultRotationConfigMapName, slog.Default()).Load(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, loaded.Keys, "nothing must be written for an invalid state")
+}
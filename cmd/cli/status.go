@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -12,7 +14,9 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
 	"github.com/hixichen/kube-iam-assume/pkg/constants"
+	"github.com/hixichen/kube-iam-assume/pkg/health"
 	"github.com/hixichen/kube-iam-assume/pkg/rotation"
 )
 
@@ -42,6 +46,8 @@ type StatusInfo struct {
 	// Keys status
 	PublishedKeyCount int
 	ActiveKeyIDs      []string
+	// KeyThumbprints maps key IDs in the stored JWKS to their RFC 7638 thumbprints
+	KeyThumbprints map[string]string
 
 	// Rotation status
 	RotationActive     bool
@@ -51,6 +57,9 @@ type StatusInfo struct {
 	// OIDC provider status
 	OIDCProviderARN string
 	IssuerURL       string
+
+	// Clusters is the per-cluster JWKS freshness reported by the group leader (multi-cluster mode only)
+	Clusters []health.ClusterStatus
 }
 
 // StatusChecker retrieves status information.
@@ -75,13 +84,21 @@ func (c *StatusChecker) GetStatus(ctx context.Context) (*StatusInfo, error) {
 		return nil, fmt.Errorf("failed to connect to cluster: %w", err)
 	}
 
+	return collectStatus(ctx, clientset, c.namespace)
+}
+
+// controllerSelector selects the controller deployment installed by the Helm chart.
+const controllerSelector = "app.kubernetes.io/name=kube-iam-assume"
+
+// collectStatus reads the controller deployment and rotation state in namespace.
+func collectStatus(ctx context.Context, clientset kubernetes.Interface, namespace string) (*StatusInfo, error) {
 	info := &StatusInfo{
-		ControllerNamespace: c.namespace,
+		ControllerNamespace: namespace,
 	}
 
 	// Find kubeassume controller deployment
-	deployments, err := clientset.AppsV1().Deployments(c.namespace).List(ctx, metav1.ListOptions{
-		LabelSelector: "app.kubernetes.io/name=kube-iam-assume",
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: controllerSelector,
 	})
 	if err != nil {
 		return info, fmt.Errorf("failed to list deployments: %w", err)
@@ -94,7 +111,7 @@ func (c *StatusChecker) GetStatus(ctx context.Context) (*StatusInfo, error) {
 	}
 
 	// Read rotation state ConfigMap
-	cm, err := clientset.CoreV1().ConfigMaps(c.namespace).Get(ctx, "kube-iam-assume-rotation-state", metav1.GetOptions{})
+	cm, err := clientset.CoreV1().ConfigMaps(namespace).Get(ctx, constants.DefaultRotationConfigMapName, metav1.GetOptions{})
 	if err == nil {
 		stateData, ok := cm.Data["state"]
 		if ok {
@@ -116,14 +133,35 @@ func (c *StatusChecker) GetStatus(ctx context.Context) (*StatusInfo, error) {
 		}
 	}
 
+	// Read the stored JWKS for key thumbprints
+	if cm, err := clientset.CoreV1().ConfigMaps(namespace).Get(ctx, constants.DefaultOIDCConfigMapName, metav1.GetOptions{}); err == nil {
+		var jwks bridge.JWKS
+		if jsonErr := json.Unmarshal([]byte(cm.Data["jwks.json"]), &jwks); jsonErr == nil {
+			info.KeyThumbprints = bridge.JWKSThumbprints(&jwks)
+		}
+	}
+
+	// Read per-cluster health written by the aggregation leader
+	clusters, err := health.LoadClusterHealth(ctx, clientset, namespace, constants.DefaultClusterHealthConfigMapName)
+	if err != nil {
+		return info, err
+	}
+	info.Clusters = clusters
+
 	return info, nil
 }
 
 // buildClient creates a kubernetes clientset.
 func (c *StatusChecker) buildClient() (*kubernetes.Clientset, error) {
+	return buildClientset(c.kubeconfig)
+}
+
+// buildClientset creates a kubernetes clientset from the given kubeconfig path,
+// falling back to the default loading rules when it is empty.
+func buildClientset(kubeconfig string) (*kubernetes.Clientset, error) {
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
-	if c.kubeconfig != "" {
-		loadingRules.ExplicitPath = c.kubeconfig
+	if kubeconfig != "" {
+		loadingRules.ExplicitPath = kubeconfig
 	}
 
 	configOverrides := &clientcmd.ConfigOverrides{}
@@ -144,16 +182,21 @@ func (c *StatusChecker) buildClient() (*kubernetes.Clientset, error) {
 
 // PrintStatus prints the status in a formatted way.
 func PrintStatus(info *StatusInfo) {
-	fmt.Println("KubeAssume Status")
-	fmt.Println("=================")
-	fmt.Println()
+	printStatus(os.Stdout, info)
+}
+
+// printStatus writes the formatted status to w.
+func printStatus(w io.Writer, info *StatusInfo) {
+	fmt.Fprintln(w, "KubeAssume Status")
+	fmt.Fprintln(w, "=================")
+	fmt.Fprintln(w)
 
 	// Controller status
 	controllerStatus := "Not Running"
 	if info.ControllerRunning {
 		controllerStatus = "Running"
 	}
-	fmt.Printf("Controller:      %s (%s/%s)\n",
+	fmt.Fprintf(w, "Controller:      %s (%s/%s)\n",
 		controllerStatus, info.ControllerNamespace, info.ControllerName)
 
 	// Last sync
@@ -162,44 +205,69 @@ func PrintStatus(info *StatusInfo) {
 		syncStatus = fmt.Sprintf("error: %s", info.LastSyncError)
 	}
 	timeSince := formatTimeSince(info.LastSyncTime)
-	fmt.Printf("Last Sync:       %s (%s)\n", timeSince, syncStatus)
+	fmt.Fprintf(w, "Last Sync:       %s (%s)\n", timeSince, syncStatus)
 
 	// Published keys
-	fmt.Printf("Published Keys:  %d\n", info.PublishedKeyCount)
+	fmt.Fprintf(w, "Published Keys:  %d\n", info.PublishedKeyCount)
 
 	// Key IDs
 	if len(info.ActiveKeyIDs) > 0 {
-		fmt.Printf("Key IDs:         ")
+		fmt.Fprintf(w, "Key IDs:         ")
 		for i, kid := range info.ActiveKeyIDs {
 			if i > 0 {
-				fmt.Printf(", ")
+				fmt.Fprintf(w, ", ")
 			}
 			// Truncate long key IDs for display
 			if len(kid) > 16 {
-				fmt.Printf("%s...", kid[:16])
+				fmt.Fprintf(w, "%s...", kid[:16])
 			} else {
-				fmt.Printf("%s", kid)
+				fmt.Fprintf(w, "%s", kid)
 			}
 		}
-		fmt.Println()
+		fmt.Fprintln(w)
+	}
+
+	// Key thumbprints
+	if len(info.KeyThumbprints) > 0 {
+		fmt.Fprintln(w, "Thumbprints:")
+		kids := make([]string, 0, len(info.KeyThumbprints))
+		for kid := range info.KeyThumbprints {
+			kids = append(kids, kid)
+		}
+		sort.Strings(kids)
+		for _, kid := range kids {
+			fmt.Fprintf(w, "  %-30s %s\n", kid, info.KeyThumbprints[kid])
+		}
 	}
 
 	// Rotation status
 	if info.RotationActive {
-		fmt.Printf("Rotation:        Active (%d keys pending removal)\n", info.KeysPendingRemoval)
+		fmt.Fprintf(w, "Rotation:        Active (%d keys pending removal)\n", info.KeysPendingRemoval)
 	} else {
-		fmt.Println("Rotation:        None")
+		fmt.Fprintln(w, "Rotation:        None")
+	}
+
+	// Cluster group members
+	if len(info.Clusters) > 0 {
+		fmt.Fprintln(w, "Clusters:")
+		for _, cluster := range info.Clusters {
+			state := "healthy"
+			if !cluster.Healthy {
+				state = "lagging"
+			}
+			fmt.Fprintf(w, "  %-30s %-8s (last update %s)\n", cluster.ClusterID, state, formatTimeSince(cluster.LastModified))
+		}
 	}
 
 	// OIDC provider
 	if info.OIDCProviderARN != "" {
-		fmt.Printf("OIDC Provider:   %s\n", info.OIDCProviderARN)
+		fmt.Fprintf(w, "OIDC Provider:   %s\n", info.OIDCProviderARN)
 	}
 	if info.IssuerURL != "" {
-		fmt.Printf("Issuer URL:      %s\n", info.IssuerURL)
+		fmt.Fprintf(w, "Issuer URL:      %s\n", info.IssuerURL)
 	}
 
-	fmt.Println()
+	fmt.Fprintln(w)
 }
 
 // formatTimeSince formats a time as "X minutes ago".
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/hixichen/kube-iam-assume/pkg/constants"
+)
+
+// newStepDownCommand creates the step-down command.
+func newStepDownCommand() *cobra.Command {
+	var (
+		kubeconfig string
+		namespace  string
+		leaseName  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "step-down",
+		Short: "Force the current controller leader to step down",
+		Long: `Deletes the pod of the current controller leader. The leader releases its
+leader-election Lease while shutting down, so one of the remaining replicas is
+elected right away, and the Deployment replaces the deleted pod.
+
+Use this for controlled failover testing, e.g. to verify that the OIDC poller
+and multi-cluster aggregation move to the new leader.`,
+		Example: `  # Step down the holder of the default leader-election lease
+  kube-iam-assume step-down
+
+  # Step down the holder of a custom lease in another namespace
+  kube-iam-assume step-down --namespace oidc --lease-name my-leader-election`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clientset, err := buildClientset(kubeconfig)
+			if err != nil {
+				return fmt.Errorf("failed to connect to cluster: %w", err)
+			}
+
+			holder, pod, err := stepDownLeader(cmd.Context(), clientset, namespace, leaseName)
+			if err != nil {
+				return err
+			}
+
+			if holder == "" {
+				fmt.Printf("Lease %s/%s has no current holder; nothing to step down\n", namespace, leaseName)
+				return nil
+			}
+			fmt.Printf("Deleted leader pod %s/%s holding lease %s; it releases the lease on shutdown and a new leader will be elected\n", namespace, pod, holder)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to the kubeconfig file (defaults to the standard loading rules)")
+	cmd.Flags().StringVar(&namespace, "namespace", constants.DefaultNamespace, "Namespace of the controller's leader-election lease")
+	cmd.Flags().StringVar(&leaseName, "lease-name", constants.DefaultLeaderElectionID, "Name of the leader-election lease (controller.leaderElection.id)")
+
+	return cmd
+}
+
+// stepDownLeader deletes the pod of the leader-election Lease holder. The
+// controller releases the Lease when it shuts down, so another replica is elected
+// without waiting for the Lease to expire. Clearing the holder instead would not
+// work: the running leader takes the Lease back on its next renew.
+// It returns the identity that held the lease and the deleted pod.
+func stepDownLeader(ctx context.Context, clientset kubernetes.Interface, namespace, name string) (string, string, error) {
+	lease, err := clientset.CoordinationV1().Leases(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", "", fmt.Errorf("leader-election lease %s/%s not found; is leader election enabled?", namespace, name)
+		}
+		return "", "", fmt.Errorf("failed to get leader-election lease: %w", err)
+	}
+
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity == "" {
+		return "", "", nil
+	}
+	holder := *lease.Spec.HolderIdentity
+
+	// controller-runtime identifies the holder as <hostname>_<uuid>, and the
+	// hostname of a pod is its name
+	pod, _, ok := strings.Cut(holder, "_")
+	if !ok || pod == "" {
+		return "", "", fmt.Errorf("cannot tell the leader pod from lease holder %q", holder)
+	}
+	if err := clientset.CoreV1().Pods(namespace).Delete(ctx, pod, metav1.DeleteOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", "", fmt.Errorf("leader pod %s/%s not found; the lease expires on its own", namespace, pod)
+		}
+		return "", "", fmt.Errorf("failed to delete leader pod: %w", err)
+	}
+
+	return holder, pod, nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/hixichen/kube-iam-assume/pkg/constants"
+)
+
+func leaderLease(holder string) *coordinationv1.Lease {
+	leaseDuration := int32(15)
+	return &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      constants.DefaultLeaderElectionID,
+			Namespace: constants.DefaultNamespace,
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: &leaseDuration,
+		},
+	}
+}
+
+func leaderPod(name string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: constants.DefaultNamespace}}
+}
+
+func TestStepDownLeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		objects    []runtime.Object
+		wantHolder string
+		wantPod    string
+		wantPods   []string
+		wantErr    string
+	}{
+		{
+			name:       "deletes the leader pod",
+			objects:    []runtime.Object{leaderLease("controller-abc_1234"), leaderPod("controller-abc"), leaderPod("controller-def")},
+			wantHolder: "controller-abc_1234",
+			wantPod:    "controller-abc",
+			wantPods:   []string{"controller-def"},
+		},
+		{
+			name:     "lease without holder is a no-op",
+			objects:  []runtime.Object{leaderLease(""), leaderPod("controller-abc")},
+			wantPods: []string{"controller-abc"},
+		},
+		{
+			name:    "holder without pod name",
+			objects: []runtime.Object{leaderLease("controller-abc")},
+			wantErr: "cannot tell the leader pod",
+		},
+		{
+			name:    "leader pod already gone",
+			objects: []runtime.Object{leaderLease("controller-abc_1234")},
+			wantErr: "not found",
+		},
+		{
+			name:    "missing lease",
+			wantErr: "lease",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientset := fake.NewClientset(tt.objects...)
+			ctx := context.Background()
+
+			holder, pod, err := stepDownLeader(ctx, clientset, constants.DefaultNamespace, constants.DefaultLeaderElectionID)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantHolder, holder)
+			assert.Equal(t, tt.wantPod, pod)
+
+			// Only the leader pod is deleted; the lease is left for it to release
+			pods, err := clientset.CoreV1().Pods(constants.DefaultNamespace).List(ctx, metav1.ListOptions{})
+			require.NoError(t, err)
+			var names []string
+			for _, p := range pods.Items {
+				names = append(names, p.Name)
+			}
+			assert.Equal(t, tt.wantPods, names)
+			lease, err := clientset.CoordinationV1().Leases(constants.DefaultNamespace).Get(ctx, constants.DefaultLeaderElectionID, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantHolder, *lease.Spec.HolderIdentity)
+		})
+	}
+}
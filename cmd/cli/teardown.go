@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	"github.com/hixichen/kube-iam-assume/pkg/config"
+	kaerrors "github.com/hixichen/kube-iam-assume/pkg/errors"
+	"github.com/hixichen/kube-iam-assume/pkg/federation"
+	awsfederation "github.com/hixichen/kube-iam-assume/pkg/federation/aws"
+	"github.com/hixichen/kube-iam-assume/pkg/federation/gcp"
+	"github.com/hixichen/kube-iam-assume/pkg/publisher"
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
+)
+
+// teardownSummary records which parts of an issuer a teardown removed and which
+// were already gone.
+type teardownSummary struct {
+	removed     []string
+	alreadyGone []string
+}
+
+// newTeardownCommand creates the teardown command.
+func newTeardownCommand() *cobra.Command {
+	var (
+		configPath   string
+		federationTo string
+		region       string
+		projectID    string
+		yes          bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "teardown",
+		Short: "Delete the federation provider and published objects of an issuer",
+		Long: `Removes what kube-iam-assume created for the issuer configured in a controller
+config file: the federation provider trusting the issuer (with --federation) and
+the discovery documents, JWKS and JWKS history in the bucket.
+
+Each step checks what still exists before deleting it, so an interrupted teardown
+can be run again to finish. Stop the controller first, or it publishes again.
+
+For a cluster group member only the cluster's JWKS and history are deleted; the
+group's documents and federation provider are shared with the other clusters.`,
+		Example: `  # Delete the published objects
+  kube-iam-assume teardown --config config.yaml
+
+  # Also delete the AWS IAM OIDC provider, without prompting
+  kube-iam-assume teardown --config config.yaml --federation aws --region us-west-2 --yes`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			cfg, err := config.LoadConfig(configPath)
+			if err != nil {
+				return err
+			}
+			if cfg.Controller.ClusterGroup != "" && federationTo != "" {
+				return fmt.Errorf("--federation cannot be used with controller.clusterGroup: the group's federation provider is shared")
+			}
+
+			logger := slog.Default()
+			pub, err := publisher.NewFactory(logger).Create(ctx, cfg)
+			if err != nil {
+				return fmt.Errorf("failed to create publisher: %w", err)
+			}
+			defer func() { _ = pub.Close() }()
+			store, ok := pub.(iface.ManagedObjectStore)
+			if !ok {
+				return fmt.Errorf("%s publisher does not support deleting published objects", pub.Type())
+			}
+
+			var provider federation.Provider
+			switch federationTo {
+			case "":
+			case string(federation.ProviderTypeAWS):
+				provider, err = awsfederation.NewProvider(ctx, region, logger)
+			case string(federation.ProviderTypeGCP):
+				provider, err = gcp.NewProvider(ctx, projectID, logger)
+			default:
+				return fmt.Errorf("unsupported --federation %q (use aws or gcp)", federationTo)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to create %s federation provider: %w", federationTo, err)
+			}
+			if provider != nil {
+				provider = federation.Instrument(provider, logOperationRecorder{logger: logger})
+			}
+
+			return runTeardown(ctx, pub.GetPublicURL(), provider, store, yes, cmd.InOrStdin(), cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&configPath, "config", "", "Path to the controller config file")
+	cmd.Flags().StringVar(&federationTo, "federation", "", "Also delete the federation provider (aws, gcp)")
+	cmd.Flags().StringVar(&region, "region", "", "AWS region for --federation aws")
+	cmd.Flags().StringVar(&projectID, "project", "", "GCP project ID for --federation gcp")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Delete without asking for confirmation")
+
+	if err := cmd.MarkFlagRequired("config"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
+
+// runTeardown deletes the federation provider trusting issuerURL, when provider is
+// set, and then the objects left in store, after the user confirms on in. Parts
+// that no longer exist are reported as already gone, so re-running an interrupted
+// teardown completes it.
+func runTeardown(ctx context.Context, issuerURL string, provider federation.Provider, store iface.ManagedObjectStore, yes bool, in io.Reader, out io.Writer) error {
+	var summary teardownSummary
+
+	// The provider goes first: once it is gone, tokens are no longer accepted and
+	// the published keys are unused
+	var providerInfo *federation.ProviderInfo
+	if provider != nil {
+		info, err := provider.GetProviderInfo(ctx, issuerURL)
+		switch {
+		case kaerrors.IsNotFoundError(err):
+			summary.alreadyGone = append(summary.alreadyGone, provider.Type()+" federation provider")
+		case err != nil:
+			return fmt.Errorf("failed to look up the %s federation provider: %w", provider.Type(), err)
+		default:
+			providerInfo = info
+		}
+	}
+
+	keys, err := store.ListManagedObjects(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list published objects: %w", err)
+	}
+	if len(keys) == 0 {
+		summary.alreadyGone = append(summary.alreadyGone, "published objects")
+	}
+
+	if providerInfo != nil || len(keys) > 0 {
+		_, _ = fmt.Fprintf(out, "Tearing down issuer %s:\n", issuerURL)
+		if providerInfo != nil {
+			_, _ = fmt.Fprintf(out, "  %s federation provider %s\n", provider.Type(), providerInfo.ProviderARN)
+		}
+		for _, key := range keys {
+			_, _ = fmt.Fprintf(out, "  object %s\n", key)
+		}
+		if !yes && !confirm(in, out, "Delete them?") {
+			_, _ = fmt.Fprintln(out, "Aborted: nothing deleted")
+			return nil
+		}
+	}
+
+	if providerInfo != nil {
+		if err := provider.Delete(ctx, issuerURL); err != nil {
+			return fmt.Errorf("failed to delete the %s federation provider: %w", provider.Type(), err)
+		}
+		summary.removed = append(summary.removed, fmt.Sprintf("%s federation provider %s", provider.Type(), providerInfo.ProviderARN))
+	}
+	for i, key := range keys {
+		if err := store.DeleteManagedObject(ctx, key); err != nil {
+			return fmt.Errorf("failed to delete %s after deleting %d objects: %w", key, i, err)
+		}
+		summary.removed = append(summary.removed, "object "+key)
+	}
+
+	printTeardownSummary(out, summary)
+	return nil
+}
+
+// printTeardownSummary writes what a teardown removed and what was already gone.
+func printTeardownSummary(out io.Writer, summary teardownSummary) {
+	_, _ = fmt.Fprintf(out, "Removed %d, already gone %d\n", len(summary.removed), len(summary.alreadyGone))
+	for _, item := range summary.removed {
+		_, _ = fmt.Fprintf(out, "  removed: %s\n", item)
+	}
+	for _, item := range summary.alreadyGone {
+		_, _ = fmt.Fprintf(out, "  already gone: %s\n", item)
+	}
+}
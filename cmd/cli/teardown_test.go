@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
+	kaerrors "github.com/hixichen/kube-iam-assume/pkg/errors"
+	"github.com/hixichen/kube-iam-assume/pkg/federation"
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/memory"
+	s3publisher "github.com/hixichen/kube-iam-assume/pkg/publisher/s3"
+)
+
+// teardownProvider is a federation provider holding at most one provider.
+type teardownProvider struct {
+	federation.Provider
+	exists  bool
+	deletes int
+}
+
+func (p *teardownProvider) GetProviderInfo(context.Context, string) (*federation.ProviderInfo, error) {
+	if !p.exists {
+		return nil, kaerrors.NewNotFoundError("aws-federation", "no OIDC provider found", nil)
+	}
+	return &federation.ProviderInfo{ProviderARN: "arn:aws:iam::123456789012:oidc-provider/oidc.example.com"}, nil
+}
+func (p *teardownProvider) Delete(context.Context, string) error {
+	p.exists = false
+	p.deletes++
+	return nil
+}
+func (p *teardownProvider) Type() string { return "aws" }
+
+// failingStore is an in-memory publisher whose deletes fail after failAfter deletes.
+type failingStore struct {
+	*memory.Publisher
+	failAfter int
+	deletes   int
+}
+
+func (s *failingStore) DeleteManagedObject(ctx context.Context, key string) error {
+	if s.deletes == s.failAfter {
+		return errors.New("connection reset")
+	}
+	s.deletes++
+	return s.Publisher.DeleteManagedObject(ctx, key)
+}
+
+// newTeardownStore returns an in-memory publisher with a published issuer and two JWKS history snapshots.
+func newTeardownStore(t *testing.T) *memory.Publisher {
+	t.Helper()
+	pub, err := memory.New(memory.Config{PublicURL: "https://oidc.example.com/prod", Prefix: "prod", JWKSHistory: 2}, nil)
+	require.NoError(t, err)
+	discovery := &bridge.DiscoveryDocument{Issuer: "https://oidc.example.com/prod"}
+	for _, kid := range []string{"key-1", "key-2"} {
+		require.NoError(t, pub.Publish(context.Background(), discovery, &bridge.JWKS{Keys: []bridge.JWK{{Kid: kid}}}))
+	}
+	return pub
+}
+
+func TestRunTeardown_ResumesPartialTeardown(t *testing.T) {
+	t.Run("memory", func(t *testing.T) {
+		ctx := context.Background()
+		pub := newTeardownStore(t)
+		keys, err := pub.ListManagedObjects(ctx)
+		require.NoError(t, err)
+		require.Len(t, keys, 3)
+		provider := &teardownProvider{exists: true}
+
+		// The first run deletes the provider and one object before failing
+		var out bytes.Buffer
+		err = runTeardown(ctx, pub.GetPublicURL(), provider, &failingStore{Publisher: pub, failAfter: 1}, true, strings.NewReader(""), &out)
+		require.ErrorContains(t, err, "after deleting 1 objects")
+		assert.False(t, provider.exists)
+		remaining, err := pub.ListManagedObjects(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, keys[1:], remaining)
+
+		// The second run finishes
+		out.Reset()
+		require.NoError(t, runTeardown(ctx, pub.GetPublicURL(), provider, pub, true, strings.NewReader(""), &out))
+		assert.Equal(t, 1, provider.deletes)
+		assert.Contains(t, out.String(), "Removed 2, already gone 1")
+		assert.Contains(t, out.String(), "already gone: aws federation provider")
+		assert.Contains(t, out.String(), "removed: object "+keys[2])
+		assert.Empty(t, pub.Bucket().Keys())
+
+		// Running it again finds nothing left
+		out.Reset()
+		require.NoError(t, runTeardown(ctx, pub.GetPublicURL(), provider, pub, false, strings.NewReader(""), &out))
+		assert.Equal(t, "Removed 0, already gone 2\n  already gone: aws federation provider\n  already gone: published objects\n", out.String())
+	})
+
+	// A failed delete from the secondary bucket keeps the primary copy, so the
+	// key is listed again and both copies are deleted on the next run
+	t.Run("s3 secondary bucket", func(t *testing.T) {
+		ctx := context.Background()
+		buckets := newFakeS3Buckets(t)
+		cfg := s3publisher.Config{
+			Bucket:         "primary",
+			Region:         "us-west-2",
+			Endpoint:       buckets.url,
+			ForcePathStyle: true,
+			Secondary:      &s3publisher.SecondaryConfig{Bucket: "secondary", Region: "us-east-1", Endpoint: buckets.url},
+		}
+		documents, err := cfg.GetDiscoveryObjectKeys()
+		require.NoError(t, err)
+		documents = append(documents, cfg.GetJWKSPath())
+		buckets.seed("primary", append(documents, "history/jwks-1.json")...)
+		buckets.seed("secondary", documents...)
+		buckets.failDeletes("secondary", 1)
+
+		pub, err := s3publisher.New(ctx, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		require.NoError(t, err)
+		defer func() { _ = pub.Close() }()
+		store, ok := pub.(iface.ManagedObjectStore)
+		require.True(t, ok)
+
+		var out bytes.Buffer
+		err = runTeardown(ctx, pub.GetPublicURL(), nil, store, true, strings.NewReader(""), &out)
+		require.ErrorContains(t, err, "after deleting 0 objects")
+		assert.Equal(t, documents[0], buckets.keys("secondary")[0])
+		assert.Contains(t, buckets.keys("primary"), documents[0])
+
+		out.Reset()
+		require.NoError(t, runTeardown(ctx, pub.GetPublicURL(), nil, store, true, strings.NewReader(""), &out))
+		assert.Contains(t, out.String(), "removed: object "+documents[0])
+		assert.Empty(t, buckets.keys("primary"))
+		assert.Empty(t, buckets.keys("secondary"))
+	})
+}
+func TestRunTeardown_Aborted(t *testing.T) {
+	ctx := context.Background()
+	pub := newTeardownStore(t)
+	provider := &teardownProvider{exists: true}
+
+	var out bytes.Buffer
+	require.NoError(t, runTeardown(ctx, pub.GetPublicURL(), provider, pub, false, strings.NewReader("n\n"), &out))
+	assert.Contains(t, out.String(), "Aborted: nothing deleted")
+	assert.True(t, provider.exists)
+	keys, err := pub.ListManagedObjects(ctx)
+	require.NoError(t, err)
+	assert.Len(t, keys, 3)
+}
+
+// fakeS3Buckets is a path-style S3 endpoint serving object listings and deletes
+// for several in-memory buckets.
+type fakeS3Buckets struct {
+	url string
+
+	mu       sync.Mutex
+	objects  map[string][]string
+	failures map[string]int
+}
+
+func newFakeS3Buckets(t *testing.T) *fakeS3Buckets {
+	t.Helper()
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	b := &fakeS3Buckets{objects: map[string][]string{}, failures: map[string]int{}}
+	srv := httptest.NewServer(http.HandlerFunc(b.serveHTTP))
+	t.Cleanup(srv.Close)
+	b.url = srv.URL
+	return b
+}
+
+func (b *fakeS3Buckets) seed(bucket string, keys ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.objects[bucket] = append(b.objects[bucket], keys...)
+}
+
+// failDeletes makes the next n deletes from bucket fail.
+func (b *fakeS3Buckets) failDeletes(bucket string, n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures[bucket] = n
+}
+
+func (b *fakeS3Buckets) keys(bucket string) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return slices.Clone(b.objects[bucket])
+}
+
+func (b *fakeS3Buckets) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+		var listing strings.Builder
+		listing.WriteString("<ListBucketResult><IsTruncated>false</IsTruncated>")
+		for _, k := range b.objects[bucket] {
+			if strings.HasPrefix(k, r.URL.Query().Get("prefix")) {
+				listing.WriteString("<Contents><Key>" + k + "</Key></Contents>")
+			}
+		}
+		listing.WriteString("</ListBucketResult>")
+		_, _ = w.Write([]byte(listing.String()))
+	case r.Method == http.MethodDelete:
+		if b.failures[bucket] > 0 {
+			b.failures[bucket]--
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>"))
+			return
+		}
+		b.objects[bucket] = slices.DeleteFunc(b.objects[bucket], func(k string) bool { return k == key })
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
@@ -2,29 +2,51 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
+	"net"
+	"net/http"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 
+	"github.com/hixichen/kube-iam-assume/api/v1alpha1"
 	"github.com/hixichen/kube-iam-assume/internal/controller"
 	"github.com/hixichen/kube-iam-assume/pkg/bridge"
 	"github.com/hixichen/kube-iam-assume/pkg/config"
 	"github.com/hixichen/kube-iam-assume/pkg/constants"
+	kaerrors "github.com/hixichen/kube-iam-assume/pkg/errors"
+	"github.com/hixichen/kube-iam-assume/pkg/eventstream"
+	"github.com/hixichen/kube-iam-assume/pkg/faultinject"
+	"github.com/hixichen/kube-iam-assume/pkg/federation"
+	awsfederation "github.com/hixichen/kube-iam-assume/pkg/federation/aws"
+	gcpfederation "github.com/hixichen/kube-iam-assume/pkg/federation/gcp"
+	"github.com/hixichen/kube-iam-assume/pkg/health"
+	"github.com/hixichen/kube-iam-assume/pkg/heartbeat"
+	"github.com/hixichen/kube-iam-assume/pkg/metrics"
 	"github.com/hixichen/kube-iam-assume/pkg/publisher"
 	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
 	"github.com/hixichen/kube-iam-assume/pkg/rotation"
@@ -36,16 +58,24 @@ var (
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
 }
 
 func main() {
-	var metricsAddr, probeAddr string
+	var metricsAddr, metricsCertDir, probeAddr string
 	var configPath string
+	var enableFaultInjection bool
+	var faultInjectionConfig string
 
 	// Parse flags
-	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
+	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080",
+		"The address the metric endpoint binds to: host:port, https://host:port for TLS, or unix:///path/to.sock.")
+	flag.StringVar(&metricsCertDir, "metrics-cert-dir", "",
+		"Directory with tls.crt and tls.key for the metrics endpoint. Setting it serves metrics over TLS.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.StringVar(&configPath, "config", "/etc/kubeassume/config.yaml", "Path to the configuration file.")
+	flag.BoolVar(&enableFaultInjection, "enable-fault-injection", false, "Inject the failures from --fault-injection-config. For testing only.")
+	flag.StringVar(&faultInjectionConfig, "fault-injection-config", "", "Path to the JSON fault injection configuration.")
 
 	opts := zap.Options{
 		Development: true,
@@ -63,29 +93,79 @@ func main() {
 		os.Exit(1)
 	}
 
+	faults, err := initializeFaultInjection(enableFaultInjection, faultInjectionConfig, logger)
+	if err != nil {
+		logger.Error("failed to set up fault injection", "error", err)
+		os.Exit(1)
+	}
+
 	// Get Kubernetes config
 	k8sCfg := ctrl.GetConfigOrDie()
 
+	metricsServing, err := parseMetricsServing(metricsAddr, metricsCertDir)
+	if err != nil {
+		logger.Error("invalid metrics serving options", "error", err)
+		os.Exit(1)
+	}
+
+	// The event stream is served next to the metrics
+	events := initializeEventStream(cfg.Controller.EventStream, logger)
+	if events != nil {
+		metricsServing.options.ExtraHandlers = map[string]http.Handler{eventStreamPath: events}
+	}
+
 	// Create manager
 	mgr, err := ctrl.NewManager(k8sCfg, ctrl.Options{
-		Scheme: scheme,
-		Metrics: metricsserver.Options{
-			BindAddress: metricsAddr,
-		},
+		Scheme:                 scheme,
+		Metrics:                metricsServing.options,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         cfg.Controller.LeaderElection.Enabled,
 		LeaderElectionID:       cfg.Controller.LeaderElection.ID,
+		// main returns once the manager stops, so the lease can be released on
+		// shutdown; step-down relies on this to hand over leadership quickly
+		LeaderElectionReleaseOnCancel: true,
+		// The IssuerConfig Role only grants access in the controller namespace
+		Cache: cache.Options{ByObject: map[client.Object]cache.ByObject{
+			&v1alpha1.IssuerConfig{}: {Namespaces: map[string]cache.Config{constants.DefaultNamespace: {}}},
+		}},
 	})
 	if err != nil {
 		logger.Error("unable to create manager", "error", err)
 		os.Exit(1)
 	}
 
+	// The signal handler can only be set up once; startup validation and the manager share it
+	ctx := ctrl.SetupSignalHandler()
+
 	// Initialize components
-	if err := initializeComponents(mgr, cfg, logger); err != nil {
+	rec, err := initializeComponents(ctx, mgr, cfg, faults, logger)
+	if err != nil {
 		logger.Error("failed to initialize components", "error", err)
 		os.Exit(1)
 	}
+	rec.Events = events
+	if events != nil {
+		// Open streams must end on shutdown, or they hold up the metrics servers
+		if err := mgr.Add(events); err != nil {
+			logger.Error("unable to add event stream", "error", err)
+			os.Exit(1)
+		}
+		if metricsServing.socketPath == "" && !metricsServing.options.SecureServing {
+			logger.Warn("event stream is served without authentication; use https or a cert dir for the metrics endpoint to require it")
+		}
+	}
+
+	if metricsServing.socketPath != "" {
+		socketServer := &unixMetricsServer{
+			path:     metricsServing.socketPath,
+			handlers: metricsServing.options.ExtraHandlers,
+			logger:   logger.With("component", "metrics-server"),
+		}
+		if err := mgr.Add(socketServer); err != nil {
+			logger.Error("unable to add unix socket metrics server", "error", err)
+			os.Exit(1)
+		}
+	}
 
 	// Add health checks
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -96,25 +176,152 @@ func main() {
 		logger.Error("unable to set up ready check", "error", err)
 		os.Exit(1)
 	}
+	if err := mgr.AddReadyzCheck("published", rec.PublishReadyzCheck(mgr.Elected())); err != nil {
+		logger.Error("unable to set up publish ready check", "error", err)
+		os.Exit(1)
+	}
+	if err := mgr.Add(&health.Monitor{Health: rec.Health, Interval: health.DefaultCheckInterval}); err != nil {
+		logger.Error("unable to add health monitor", "error", err)
+		os.Exit(1)
+	}
+	if err := mgr.AddReadyzCheck("health", rec.HealthReadyzCheck()); err != nil {
+		logger.Error("unable to set up health ready check", "error", err)
+		os.Exit(1)
+	}
 
 	logger.Info("starting manager",
 		"syncPeriod", cfg.Controller.SyncPeriod,
 		"publisherType", cfg.Publisher.Type,
 	)
 
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	err = mgr.Start(ctx)
+	closePublisher(rec.Publisher, logger)
+	if err != nil {
 		logger.Error("problem running manager", "error", err)
 		os.Exit(1)
 	}
 }
 
+// metricsServing is the parsed metrics endpoint configuration.
+type metricsServing struct {
+	options metricsserver.Options
+	// socketPath is set when metrics are served on a unix socket, in which case the
+	// controller-runtime metrics server is disabled
+	socketPath string
+}
+
+// parseMetricsServing interprets --metrics-bind-address and --metrics-cert-dir. The
+// address is host:port, http:// or https:// followed by host:port, or unix:// followed
+// by a socket path. A cert dir, or an https:// address, enables TLS and requires
+// clients to be authorized for the path with a Kubernetes token.
+func parseMetricsServing(bindAddress, certDir string) (metricsServing, error) {
+	var serving metricsServing
+	switch {
+	case strings.HasPrefix(bindAddress, "unix://"):
+		serving.socketPath = strings.TrimPrefix(bindAddress, "unix://")
+		if serving.socketPath == "" {
+			return metricsServing{}, fmt.Errorf("metrics bind address %q has no socket path", bindAddress)
+		}
+		if certDir != "" {
+			return metricsServing{}, fmt.Errorf("metrics over a unix socket do not support TLS")
+		}
+		serving.options.BindAddress = "0"
+		return serving, nil
+	case strings.HasPrefix(bindAddress, "https://"):
+		serving.options.BindAddress = strings.TrimPrefix(bindAddress, "https://")
+		serving.options.SecureServing = true
+	case strings.HasPrefix(bindAddress, "http://"):
+		serving.options.BindAddress = strings.TrimPrefix(bindAddress, "http://")
+	default:
+		serving.options.BindAddress = bindAddress
+	}
+
+	if certDir != "" {
+		serving.options.SecureServing = true
+		serving.options.CertDir = certDir
+	}
+	// Secure serving authenticates and authorizes every request, including to the
+	// event stream, against the API server
+	if serving.options.SecureServing {
+		serving.options.FilterProvider = filters.WithAuthenticationAndAuthorization
+	}
+	return serving, nil
+}
+
+// unixMetricsServer serves the controller-runtime metrics registry, and any extra
+// handlers, on a unix socket for scraping by a sidecar.
+type unixMetricsServer struct {
+	path     string
+	handlers map[string]http.Handler
+	logger   *slog.Logger
+}
+
+// NeedLeaderElection lets every replica serve its metrics.
+func (u *unixMetricsServer) NeedLeaderElection() bool { return false }
+
+// Start serves until ctx is cancelled.
+func (u *unixMetricsServer) Start(ctx context.Context) error {
+	// A socket left behind by a previous run would make Listen fail
+	if err := os.Remove(u.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stale metrics socket %s: %w", u.path, err)
+	}
+	listener, err := net.Listen("unix", u.path)
+	if err != nil {
+		return fmt.Errorf("failed to listen on metrics socket %s: %w", u.path, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(ctrlmetrics.Registry, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.HTTPErrorOnError,
+	}))
+	for path, handler := range u.handlers {
+		mux.Handle(path, handler)
+	}
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 30 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			u.logger.Warn("failed to shut down metrics socket server", "error", err)
+		}
+	}()
+
+	u.logger.Info("serving metrics on unix socket", "path", u.path)
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("metrics socket server failed: %w", err)
+	}
+	return nil
+}
+
+// closePublisher releases the publisher's backend client on shutdown.
+func closePublisher(pub iface.Publisher, logger *slog.Logger) {
+	if err := pub.Close(); err != nil {
+		logger.Warn("failed to close publisher", "type", pub.Type(), "error", err)
+	}
+}
+
 // aggregationPoller is a leader-only runnable that periodically aggregates
 // JWKS from all cluster sub-paths and publishes the merged result.
+// It also owns the group's root discovery document so clusters don't overwrite each other's.
 type aggregationPoller struct {
 	aggregator          iface.MultiClusterAggregator
+	issuerURL           string
+	claims              bridge.ClaimsCustomization
+	extraFields         map[string]interface{}
 	aggregationInterval time.Duration
 	clusterTTL          time.Duration
 	logger              *slog.Logger
+
+	// clusterFreshness is the JWKS age past which a cluster is reported lagging
+	clusterFreshness time.Duration
+	// excludedClusters are cluster IDs whose keys are never merged, even when fresh
+	excludedClusters []string
+	// metrics receives per-cluster health (nil disables)
+	metrics *metrics.Metrics
+	// saveHealth persists per-cluster health for the status command (nil disables)
+	saveHealth func(ctx context.Context, statuses []health.ClusterStatus) error
 }
 
 // NeedLeaderElection ensures only the elected leader runs aggregation.
@@ -153,39 +360,253 @@ func (a *aggregationPoller) aggregate(ctx context.Context) {
 		return
 	}
 
-	// Prune clusters whose JWKS haven't been updated within the TTL
 	now := time.Now()
-	for clusterID, t := range lastModified {
-		if now.Sub(t) > a.clusterTTL {
-			a.logger.Info("pruning stale cluster from aggregation", "clusterID", clusterID, "lastModified", t)
-			delete(clusterJWKS, clusterID)
-		}
+	a.reportClusterAges(lastModified, now)
+	a.reportClusterHealth(ctx, health.ClusterHealth(lastModified, a.clusterFreshness, now))
+
+	// Prune clusters whose JWKS haven't been updated within the TTL
+	for _, clusterID := range pruneStale(clusterJWKS, lastModified, a.clusterTTL, now) {
+		a.logger.Info("pruning stale cluster from aggregation", "clusterID", clusterID, "lastModified", lastModified[clusterID])
 	}
 
-	if len(clusterJWKS) == 0 {
+	excluded := excludeClusters(clusterJWKS, a.excludedClusters)
+	for _, clusterID := range excluded {
+		a.logger.Warn("excluding cluster from aggregation", "clusterID", clusterID)
+	}
+	if a.metrics != nil {
+		a.metrics.SetClusterExcluded(excluded)
+	}
+
+	if len(clusterJWKS) == 0 && len(excluded) == 0 {
 		a.logger.Debug("no active cluster JWKS to aggregate")
 		return
 	}
 
 	// Merge all keys, deduplicating by kid
 	merged := mergeJWKS(clusterJWKS)
+	if len(clusterJWKS) == 0 {
+		// Keep the excluded keys from staying live in the previously published JWKS
+		a.logger.Warn("every active cluster is excluded, publishing an empty JWKS", "excluded", excluded)
+		merged.Keys = []bridge.JWK{}
+	}
 
 	if err := a.aggregator.PublishAggregatedJWKS(ctx, merged); err != nil {
 		a.logger.Error("failed to publish aggregated JWKS", "error", err)
 		return
 	}
 
+	discovery, err := buildRootDiscovery(a.issuerURL, merged, a.claims, a.extraFields)
+	if err != nil {
+		a.logger.Error("failed to build root discovery document", "error", err)
+		return
+	}
+	if err := a.aggregator.PublishRootDiscovery(ctx, discovery); err != nil {
+		a.logger.Error("failed to publish root discovery document", "error", err)
+		return
+	}
+
 	a.logger.Info("aggregated JWKS published",
 		"clusters", len(clusterJWKS),
 		"total_keys", len(merged.Keys),
 	)
 }
 
-// mergeJWKS merges JWKS from multiple clusters, deduplicating by KeyID.
+// reportClusterAges publishes how long ago each cluster last updated its JWKS, so
+// a cluster approaching the TTL is visible before it is pruned.
+func (a *aggregationPoller) reportClusterAges(lastModified map[string]time.Time, now time.Time) {
+	ages := make(map[string]time.Duration, len(lastModified))
+	for clusterID, modified := range lastModified {
+		age := max(now.Sub(modified), 0)
+		ages[clusterID] = age
+		a.logger.Debug("cluster JWKS age",
+			"clusterID", clusterID,
+			"age", age.Round(time.Second),
+			"prunedIn", max(a.clusterTTL-age, 0).Round(time.Second),
+		)
+	}
+	if a.metrics != nil {
+		a.metrics.SetClusterJWKSAges(ages)
+	}
+}
+
+// reportClusterHealth publishes per-cluster freshness to the metrics and the
+// cluster health ConfigMap, and logs clusters that are lagging.
+func (a *aggregationPoller) reportClusterHealth(ctx context.Context, statuses []health.ClusterStatus) {
+	healthy := make(map[string]bool, len(statuses))
+	for _, status := range statuses {
+		healthy[status.ClusterID] = status.Healthy
+		if !status.Healthy {
+			a.logger.Warn("cluster JWKS is lagging",
+				"clusterID", status.ClusterID,
+				"lastModified", status.LastModified,
+				"freshness", a.clusterFreshness,
+			)
+		}
+	}
+	if a.metrics != nil {
+		a.metrics.SetClusterHealth(healthy)
+	}
+	if a.saveHealth != nil {
+		if err := a.saveHealth(ctx, statuses); err != nil {
+			a.logger.Warn("failed to save cluster health", "error", err)
+		}
+	}
+}
+
+// groupAggregationPoller is a leader-only runnable that merges the root JWKS of every
+// cluster group in the storage into one top-level JWKS ("group of groups"), so a single
+// federation provider can trust all groups. It owns the top-level discovery document.
+type groupAggregationPoller struct {
+	aggregator  iface.GroupAggregator
+	issuerURL   string
+	claims      bridge.ClaimsCustomization
+	extraFields map[string]interface{}
+	interval    time.Duration
+	groupTTL    time.Duration
+	logger      *slog.Logger
+}
+
+// NeedLeaderElection ensures only the elected leader runs group aggregation.
+func (g *groupAggregationPoller) NeedLeaderElection() bool { return true }
+
+// Start begins the group aggregation polling loop.
+func (g *groupAggregationPoller) Start(ctx context.Context) error {
+	g.logger.Info("Starting group aggregation poller", "interval", g.interval, "groupTTL", g.groupTTL)
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+
+	g.aggregate(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			g.aggregate(ctx)
+		}
+	}
+}
+
+// aggregate fetches every group's root JWKS, prunes stale groups, merges keys, and publishes.
+func (g *groupAggregationPoller) aggregate(ctx context.Context) {
+	groupJWKS, err := g.aggregator.ListGroupJWKS(ctx)
+	if err != nil {
+		g.logger.Error("failed to list group JWKS", "error", err)
+		return
+	}
+
+	lastModified, err := g.aggregator.GetGroupLastModified(ctx)
+	if err != nil {
+		g.logger.Error("failed to get group last-modified times", "error", err)
+		return
+	}
+
+	for _, group := range pruneStale(groupJWKS, lastModified, g.groupTTL, time.Now()) {
+		g.logger.Info("pruning stale group from aggregation", "group", group, "lastModified", lastModified[group])
+	}
+
+	if len(groupJWKS) == 0 {
+		g.logger.Debug("no active group JWKS to aggregate")
+		return
+	}
+
+	merged := mergeJWKS(groupJWKS)
+
+	if err := g.aggregator.PublishTopLevelJWKS(ctx, merged); err != nil {
+		g.logger.Error("failed to publish top-level JWKS", "error", err)
+		return
+	}
+
+	discovery, err := buildRootDiscovery(g.issuerURL, merged, g.claims, g.extraFields)
+	if err != nil {
+		g.logger.Error("failed to build top-level discovery document", "error", err)
+		return
+	}
+	if err := g.aggregator.PublishTopLevelDiscovery(ctx, discovery); err != nil {
+		g.logger.Error("failed to publish top-level discovery document", "error", err)
+		return
+	}
+
+	g.logger.Info("group-of-groups JWKS published",
+		"groups", len(groupJWKS),
+		"total_keys", len(merged.Keys),
+	)
+}
+
+// pruneStale removes entries whose last update is older than ttl and returns their
+// sorted IDs. Entries without a last-modified time are kept.
+func pruneStale(jwks map[string]*bridge.JWKS, lastModified map[string]time.Time, ttl time.Duration, now time.Time) []string {
+	var pruned []string
+	for id, t := range lastModified {
+		if _, ok := jwks[id]; ok && now.Sub(t) > ttl {
+			delete(jwks, id)
+			pruned = append(pruned, id)
+		}
+	}
+	sort.Strings(pruned)
+	return pruned
+}
+
+// excludeClusters removes the entries of the excluded cluster IDs and returns the
+// sorted IDs that were removed.
+func excludeClusters(jwks map[string]*bridge.JWKS, excluded []string) []string {
+	var removed []string
+	for _, id := range excluded {
+		if _, ok := jwks[id]; ok {
+			delete(jwks, id)
+			removed = append(removed, id)
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+// buildRootDiscovery builds the group's discovery document for the shared issuer URL.
+// Signing algorithms are the union of the merged keys' algorithms, defaulting to RS256.
+// The configured claims are advertised in claims_supported, and extraFields are added.
+func buildRootDiscovery(issuerURL string, merged *bridge.JWKS, claims bridge.ClaimsCustomization, extraFields map[string]interface{}) (*bridge.DiscoveryDocument, error) {
+	algSet := make(map[string]struct{})
+	for _, key := range merged.Keys {
+		if key.Alg != "" {
+			algSet[key.Alg] = struct{}{}
+		}
+	}
+	algs := make([]string, 0, len(algSet))
+	for alg := range algSet {
+		algs = append(algs, alg)
+	}
+	sort.Strings(algs)
+	if len(algs) == 0 {
+		algs = []string{"RS256"}
+	}
+
+	discovery, err := bridge.TransformDiscoveryDocument(&bridge.DiscoveryDocument{
+		ResponseTypesSupported:  []string{"id_token"},
+		SubjectTypesSupported:   []string{"public"},
+		IDTokenSigningAlgValues: algs,
+		ClaimsSupported:         claims.Apply(nil),
+	}, issuerURL)
+	if err != nil {
+		return nil, err
+	}
+	discovery.Extra = extraFields
+	return discovery, nil
+}
+
+// mergeJWKS merges JWKS from multiple clusters, deduplicating by KeyID. Clusters are
+// visited in ID order, so the first cluster by ID wins a duplicate kid, and the merged
+// keys are sorted by kid, so the same input always yields byte-identical output.
 func mergeJWKS(clusterJWKS map[string]*bridge.JWKS) *bridge.JWKS {
+	clusterIDs := make([]string, 0, len(clusterJWKS))
+	for clusterID := range clusterJWKS {
+		clusterIDs = append(clusterIDs, clusterID)
+	}
+	sort.Strings(clusterIDs)
+
 	seen := make(map[string]struct{})
 	merged := &bridge.JWKS{}
-	for _, jwks := range clusterJWKS {
+	for _, clusterID := range clusterIDs {
+		jwks := clusterJWKS[clusterID]
 		if jwks == nil {
 			continue
 		}
@@ -196,9 +617,13 @@ func mergeJWKS(clusterJWKS map[string]*bridge.JWKS) *bridge.JWKS {
 			}
 		}
 	}
+	sort.Slice(merged.Keys, func(i, j int) bool { return merged.Keys[i].Kid < merged.Keys[j].Kid })
 	return merged
 }
 
+// maxThrottleBackoff caps how long the OIDC poller waits while the API server is throttling it.
+const maxThrottleBackoff = 10 * time.Minute
+
 // oidcPoller is a runnable that periodically fetches OIDC metadata
 // and stores it in a ConfigMap. It's designed to be run by the
 // leader-elected instance of the controller.
@@ -206,25 +631,69 @@ type oidcPoller struct {
 	bridge     bridge.OIDCBridge
 	syncPeriod time.Duration
 	logger     *slog.Logger
+
+	// heartbeat is renewed on every tick when enabled (nil otherwise)
+	heartbeat *heartbeat.Heartbeat
+
+	// backoff is the current delay while the API server is throttling fetches
+	backoff time.Duration
 }
 
 // Start begins the polling loop.
 func (p *oidcPoller) Start(ctx context.Context) error {
 	p.logger.Info("Starting OIDC poller")
-	ticker := time.NewTicker(p.syncPeriod)
-	defer ticker.Stop()
+	timer := time.NewTimer(p.syncPeriod)
+	defer timer.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
-		case <-ticker.C:
-			p.logger.Debug("Polling for OIDC metadata")
-			if _, err := p.bridge.Fetch(ctx); err != nil {
-				p.logger.Error("failed to fetch OIDC metadata", "error", err)
-			}
+		case <-timer.C:
+			timer.Reset(p.poll(ctx))
+		}
+	}
+}
+
+// poll fetches OIDC metadata once and returns the delay before the next fetch.
+func (p *oidcPoller) poll(ctx context.Context) time.Duration {
+	p.logger.Debug("Polling for OIDC metadata")
+	_, err := p.bridge.Fetch(ctx)
+	if p.heartbeat != nil {
+		if hbErr := p.heartbeat.Renew(ctx); hbErr != nil {
+			p.logger.Warn("failed to renew heartbeat lease", "error", hbErr)
 		}
 	}
+	return p.nextDelay(err)
+}
+
+// nextDelay returns the delay before the next fetch given the last fetch error.
+// While the API server is throttling, the delay doubles on each consecutive 429
+// and never undercuts the server's Retry-After.
+func (p *oidcPoller) nextDelay(err error) time.Duration {
+	if err == nil {
+		p.backoff = 0
+		return p.syncPeriod
+	}
+
+	retryAfter, throttled := bridge.RetryAfter(err)
+	if !throttled {
+		p.backoff = 0
+		p.logger.Error("failed to fetch OIDC metadata", "error", err)
+		return p.syncPeriod
+	}
+
+	if p.backoff == 0 {
+		p.backoff = p.syncPeriod
+	}
+	p.backoff = min(2*p.backoff, max(maxThrottleBackoff, p.syncPeriod))
+	delay := max(p.backoff, retryAfter)
+
+	p.logger.Warn("API server is throttling OIDC fetches, backing off",
+		"retryAfter", retryAfter,
+		"nextFetchIn", delay,
+	)
+	return delay
 }
 
 // NeedLeaderElection indicates that this runnable needs leader election.
@@ -232,64 +701,493 @@ func (p *oidcPoller) NeedLeaderElection() bool {
 	return true
 }
 
-// initializeComponents initializes all controller components.
-func initializeComponents(mgr manager.Manager, cfg *config.Config, logger *slog.Logger) error {
-	ctx := ctrl.SetupSignalHandler()
-	_ = ctx
+const (
+	// defaultStartupValidationBackoff is the default delay after the first failed startup validation.
+	defaultStartupValidationBackoff = 2 * time.Second
+	// defaultStartupValidationMaxBackoff is the default cap on the delay between startup validations.
+	defaultStartupValidationMaxBackoff = 30 * time.Second
+)
 
-	k8sClient, err := kubernetes.NewForConfig(mgr.GetConfig())
+// startupValidation retries publisher validation before the controller starts.
+// After giving up it either fails startup (strict) or lets the controller start
+// degraded, reporting not-ready until a publish succeeds.
+type startupValidation struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	timeout        time.Duration
+	strict         bool
+	logger         *slog.Logger
+}
+
+// newStartupValidation builds a startupValidation from config, applying defaults.
+func newStartupValidation(cfg config.StartupValidationConfig, logger *slog.Logger) (*startupValidation, error) {
+	v := &startupValidation{
+		maxAttempts:    max(cfg.MaxAttempts, 1),
+		initialBackoff: defaultStartupValidationBackoff,
+		maxBackoff:     defaultStartupValidationMaxBackoff,
+		strict:         cfg.Strict,
+		logger:         logger,
+	}
+	var err error
+	if cfg.InitialBackoff != "" {
+		if v.initialBackoff, err = time.ParseDuration(cfg.InitialBackoff); err != nil {
+			return nil, fmt.Errorf("invalid startupValidation.initialBackoff: %w", err)
+		}
+	}
+	if cfg.MaxBackoff != "" {
+		if v.maxBackoff, err = time.ParseDuration(cfg.MaxBackoff); err != nil {
+			return nil, fmt.Errorf("invalid startupValidation.maxBackoff: %w", err)
+		}
+	}
+	if cfg.Timeout != "" {
+		if v.timeout, err = time.ParseDuration(cfg.Timeout); err != nil {
+			return nil, fmt.Errorf("invalid startupValidation.timeout: %w", err)
+		}
+	}
+	return v, nil
+}
+
+// validate validates the publisher, retrying with exponential backoff until it
+// succeeds or the attempts or timeout run out. It returns an error only when
+// giving up in strict mode; in degraded mode it reports degraded instead.
+func (v *startupValidation) validate(ctx context.Context, pub iface.Publisher) (degraded bool, err error) {
+	err = v.retry(ctx, pub)
+	if err == nil {
+		return false, nil
+	}
+	if v.strict {
+		return false, err
+	}
+	v.logger.Warn("starting degraded; readiness fails until a publish succeeds", "error", err)
+	return true, nil
+}
+
+// retry runs the validation attempts and returns the last error after giving up.
+func (v *startupValidation) retry(ctx context.Context, pub iface.Publisher) error {
+	if v.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.timeout)
+		defer cancel()
+	}
+
+	backoff := v.initialBackoff
+	for attempt := 1; ; attempt++ {
+		err := pub.Validate(ctx)
+		if err == nil {
+			return nil
+		}
+		if attempt >= v.maxAttempts {
+			return fmt.Errorf("publisher validation gave up after %d attempts: %w", attempt, err)
+		}
+
+		v.logger.Warn("publisher validation failed, retrying",
+			"attempt", attempt,
+			"maxAttempts", v.maxAttempts,
+			"retryIn", backoff,
+			"error", err,
+		)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("publisher validation gave up after %d attempts: %w", attempt, err)
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, v.maxBackoff)
+	}
+}
+
+const (
+	// issuerCertCheckInterval is how often the issuer certificate expiry is re-checked.
+	issuerCertCheckInterval = time.Hour
+	// defaultIssuerCertExpiryWarning is the default window for warning about issuer certificate expiry.
+	defaultIssuerCertExpiryWarning = 30 * 24 * time.Hour
+)
+
+// issuerCertMonitor is a leader-only runnable that tracks when the issuer
+// certificate pinned by the AWS thumbprint expires. AWS rejects tokens once the
+// pinned certificate is replaced, so the expiry is exported as a metric and
+// warned about ahead of time.
+type issuerCertMonitor struct {
+	issuerURL string
+	// runtimeConfig overrides issuerURL from the IssuerConfig resource (nil disables)
+	runtimeConfig *controller.RuntimeConfig
+	interval      time.Duration
+	expiryWarning time.Duration
+	fetch         func(ctx context.Context, issuerURL string) (*awsfederation.ThumbprintInfo, error)
+	metrics       *metrics.Metrics
+	logger        *slog.Logger
+}
+
+// NeedLeaderElection ensures only the elected leader probes the issuer.
+func (m *issuerCertMonitor) NeedLeaderElection() bool { return true }
+
+// Start checks the issuer certificate immediately and then on every interval.
+func (m *issuerCertMonitor) Start(ctx context.Context) error {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.check(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// check fetches the issuer certificate once and records its expiry. Issuers
+// served over plain http have no certificate and are skipped.
+func (m *issuerCertMonitor) check(ctx context.Context) {
+	issuerURL := m.runtimeConfig.IssuerURL(m.issuerURL)
+	if !strings.HasPrefix(issuerURL, "https://") {
+		return
+	}
+	info, err := m.fetch(ctx, issuerURL)
 	if err != nil {
-		return fmt.Errorf("failed to create kubernetes client: %w", err)
+		m.logger.Warn("failed to fetch issuer certificate", "issuer_url", issuerURL, "error", err)
+		return
 	}
 
-	// Create bridge
-	bridgeClient, err := initializeBridge(mgr.GetConfig(), k8sClient, constants.DefaultNamespace, logger)
+	m.metrics.SetIssuerCertExpiry(info.NotAfter)
+	if info.ExpiresWithin(m.expiryWarning, time.Now()) {
+		m.logger.Warn("issuer certificate pinned by the AWS thumbprint expires soon; update the IAM OIDC provider thumbprint after rotation",
+			"issuer_url", issuerURL,
+			"not_after", info.NotAfter,
+			"chain_length", info.ChainLength,
+		)
+	}
+}
+
+// issuerCertExpiryWarning returns the expiry warning window of the issuer
+// certificate monitor and whether the monitor runs. The thumbprint only matters to
+// AWS, so the monitor runs when issuerCertExpiryWarning is set, or with the default
+// window when the federation drift check targets AWS.
+func issuerCertExpiryWarning(cfg config.ControllerConfig) (time.Duration, bool, error) {
+	if cfg.IssuerCertExpiryWarning != "" {
+		window, err := time.ParseDuration(cfg.IssuerCertExpiryWarning)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid issuerCertExpiryWarning: %w", err)
+		}
+		return window, true, nil
+	}
+	drift := cfg.FederationDriftCheck
+	if drift.Enabled && drift.Provider == string(federation.ProviderTypeAWS) {
+		return defaultIssuerCertExpiryWarning, true, nil
+	}
+	return 0, false, nil
+}
+
+// defaultFederationDriftCheckInterval is the default interval between federation provider drift checks.
+const defaultFederationDriftCheckInterval = time.Hour
+
+// federationDriftCheck is a leader-only runnable that periodically verifies the cloud
+// federation provider set up by the CLI still exists and trusts the issuer, the
+// configured audiences and, for AWS, the thumbprint of the issuer certificate.
+type federationDriftCheck struct {
+	provider  federation.Provider
+	issuerURL string
+	// providerID pins the provider by ID, so a provider that trusts another issuer is
+	// reported as an issuer mismatch rather than as missing; empty looks it up by issuer
+	providerID string
+	audiences  []string
+	interval   time.Duration
+	// thumbprint fetches the issuer certificate thumbprint; nil skips the thumbprint comparison
+	thumbprint func(ctx context.Context, issuerURL string) (*awsfederation.ThumbprintInfo, error)
+	metrics    *metrics.Metrics
+	// runtimeConfig overrides issuerURL and audiences from the IssuerConfig resource (nil disables)
+	runtimeConfig *controller.RuntimeConfig
+	// event emits a Kubernetes event on the controller pod
+	event  func(ctx context.Context, eventType, reason, message string)
+	logger *slog.Logger
+
+	// lastDrift is the drift reported by the previous check, so events are only emitted on change
+	lastDrift string
+	// lastMismatch is the issuer trusted by the pinned provider when the previous
+	// check found a mismatch, so mismatch events are only emitted on change
+	lastMismatch string
+}
+
+// issuer returns the published issuer URL, as overridden by the IssuerConfig.
+func (d *federationDriftCheck) issuer() string {
+	return d.runtimeConfig.IssuerURL(d.issuerURL)
+}
+
+// expectedAudiences returns the audiences the provider must accept, as overridden by the IssuerConfig.
+func (d *federationDriftCheck) expectedAudiences() []string {
+	return d.runtimeConfig.Audiences(d.audiences)
+}
+
+// NeedLeaderElection ensures only the elected leader calls the cloud provider.
+func (d *federationDriftCheck) NeedLeaderElection() bool { return true }
+
+// Start checks the federation provider immediately and then on every interval.
+func (d *federationDriftCheck) Start(ctx context.Context) error {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		d.check(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// check looks up the federation provider once and records whether it has drifted.
+// Errors other than a missing provider leave the last result in place.
+func (d *federationDriftCheck) check(ctx context.Context) {
+	info, err := d.lookup(ctx)
+	var drift []string
+	switch {
+	case kaerrors.IsNotFoundError(err) && d.providerID != "":
+		drift = []string{fmt.Sprintf("%s federation provider %s does not exist", d.provider.Type(), d.providerID)}
+	case kaerrors.IsNotFoundError(err):
+		drift = []string{fmt.Sprintf("no %s federation provider trusts issuer %s", d.provider.Type(), d.issuer())}
+	case err != nil:
+		d.logger.Warn("failed to look up federation provider", "issuer_url", d.issuer(), "error", err)
+		return
+	default:
+		if d.providerID != "" && d.checkIssuer(ctx, info) {
+			drift = append(drift, fmt.Sprintf("provider %s trusts issuer %s, not %s", info.ProviderARN, info.IssuerURL, d.issuer()))
+		}
+		drift = append(drift, d.compare(ctx, info)...)
+	}
+
+	d.metrics.SetFederationHealthy(len(drift) == 0)
+	message := strings.Join(drift, "; ")
+	if message == d.lastDrift {
+		return
+	}
+	d.lastDrift = message
+	if message == "" {
+		d.logger.Info("federation provider matches the issuer again", "provider", info.ProviderARN)
+		return
+	}
+	d.logger.Warn("federation provider drifted; workloads may fail to federate", "issuer_url", d.issuer(), "drift", message)
+	if d.event != nil {
+		d.event(ctx, corev1.EventTypeWarning, controller.EventReasonFederationDrift, "Federation provider drifted: "+message)
+	}
+}
+
+// lookup returns the pinned provider, or the provider that trusts the issuer when
+// none is pinned or the provider type cannot look providers up by ID.
+func (d *federationDriftCheck) lookup(ctx context.Context) (*federation.ProviderInfo, error) {
+	if lookup, ok := d.provider.(federation.ProviderLookup); ok && d.providerID != "" {
+		return lookup.GetProviderInfoByID(ctx, d.providerID)
+	}
+	return d.provider.GetProviderInfo(ctx, d.issuer())
+}
+
+// checkIssuer records whether the provider trusts a different issuer than the one
+// the controller publishes, and alerts when that changes. A mismatch means every
+// token the cluster issues is rejected by the cloud, so it is reported on its own
+// metric and event in addition to the drift.
+func (d *federationDriftCheck) checkIssuer(ctx context.Context, info *federation.ProviderInfo) bool {
+	mismatch := !federation.IssuerURLsEqual(info.IssuerURL, d.issuer())
+	d.metrics.SetFederationIssuerMismatch(mismatch)
+
+	trusted := ""
+	if mismatch {
+		trusted = info.IssuerURL
+	}
+	if trusted == d.lastMismatch {
+		return mismatch
+	}
+	d.lastMismatch = trusted
+	if !mismatch {
+		d.logger.Info("federation provider trusts the published issuer again", "provider", info.ProviderARN)
+		return false
+	}
+	d.logger.Error("federation provider trusts a different issuer than the one published; tokens will be rejected",
+		"provider", info.ProviderARN, "trusted_issuer", info.IssuerURL, "issuer_url", d.issuer())
+	if d.event != nil {
+		d.event(ctx, corev1.EventTypeWarning, controller.EventReasonFederationIssuerMismatch,
+			fmt.Sprintf("Federation provider %s trusts issuer %s but the controller publishes %s", info.ProviderARN, info.IssuerURL, d.issuer()))
+	}
+	return true
+}
+
+// compare lists how the provider differs from the issuer it should trust.
+func (d *federationDriftCheck) compare(ctx context.Context, info *federation.ProviderInfo) []string {
+	var drift []string
+	if info.Status != federation.ProviderStatusActive {
+		drift = append(drift, fmt.Sprintf("provider %s is %s (state %q)", info.ProviderARN, info.Status, info.RawStatus))
+	}
+	if missing := federation.NewAudienceSet(d.expectedAudiences()...).Missing(federation.NewAudienceSet(info.Audiences...)); len(missing) > 0 {
+		drift = append(drift, fmt.Sprintf("provider %s does not accept audiences %v", info.ProviderARN, missing))
+	}
+	if d.thumbprint != nil && info.Thumbprint != "" {
+		current, err := d.thumbprint(ctx, d.issuer())
+		if err != nil {
+			d.logger.Warn("failed to fetch issuer certificate thumbprint", "issuer_url", d.issuer(), "error", err)
+		} else if !strings.EqualFold(current.Thumbprint, info.Thumbprint) {
+			drift = append(drift, fmt.Sprintf("provider %s pins thumbprint %s but the issuer presents %s", info.ProviderARN, info.Thumbprint, current.Thumbprint))
+		}
+	}
+	return drift
+}
+
+// newFederationDriftCheck creates the drift check runnable for the configured provider.
+func newFederationDriftCheck(ctx context.Context, cfg config.FederationDriftCheckConfig, issuerURL string, rec *controller.OIDCBridgeReconciler, logger *slog.Logger) (*federationDriftCheck, error) {
+	interval := defaultFederationDriftCheckInterval
+	if cfg.Interval != "" {
+		var err error
+		interval, err = time.ParseDuration(cfg.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid federationDriftCheck.interval: %w", err)
+		}
+	}
+
+	logger = logger.With("component", "federation-drift-check")
+	check := &federationDriftCheck{
+		issuerURL:     issuerURL,
+		providerID:    cfg.ProviderID,
+		audiences:     cfg.Audiences,
+		interval:      interval,
+		metrics:       rec.Metrics,
+		runtimeConfig: rec.RuntimeConfig,
+		event:         rec.RecordControllerEvent,
+		logger:        logger,
+	}
+
+	var (
+		provider federation.Provider
+		err      error
+	)
+	switch cfg.Provider {
+	case string(federation.ProviderTypeAWS):
+		provider, err = awsfederation.NewProvider(ctx, cfg.Region, logger)
+		check.thumbprint = awsfederation.FetchThumbprint
+	case string(federation.ProviderTypeGCP):
+		provider, err = gcpfederation.NewProvider(ctx, cfg.Project, logger)
+	default:
+		return nil, fmt.Errorf("unsupported federationDriftCheck.provider %q", cfg.Provider)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to initialize bridge: %w", err)
+		return nil, fmt.Errorf("failed to create %s federation provider: %w", cfg.Provider, err)
+	}
+	check.provider = federation.Instrument(provider, rec.Metrics)
+	return check, nil
+}
+
+// setupIssuerConfig watches the IssuerConfig resource and applies its overrides to
+// the reconciler and, through the shared RuntimeConfig, to the issuer certificate
+// monitor and the federation drift check.
+func setupIssuerConfig(mgr manager.Manager, rec *controller.OIDCBridgeReconciler, cfg config.IssuerConfigResourceConfig, logger *slog.Logger) error {
+	rec.RuntimeConfig = &controller.RuntimeConfig{}
+	icRec := &controller.IssuerConfigReconciler{
+		Client:        mgr.GetClient(),
+		Recorder:      mgr.GetEventRecorderFor(constants.EventRecorderName),
+		Name:          cmp.Or(cfg.Name, constants.DefaultIssuerConfigName),
+		Namespace:     constants.DefaultNamespace,
+		RuntimeConfig: rec.RuntimeConfig,
+		// Republish right away with the new issuer URL
+		OnChange: rec.RequestResync,
+		Logger:   logger.With("component", "issuer-config"),
+	}
+	if err := icRec.SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("failed to set up IssuerConfig controller: %w", err)
+	}
+	logger.Info("IssuerConfig overrides enabled", "name", icRec.Name, "namespace", icRec.Namespace)
+	return nil
+}
+
+// initializeComponents initializes all controller components and returns the reconciler.
+// faults, when set, is injected into the bridge and publisher.
+func initializeComponents(ctx context.Context, mgr manager.Manager, cfg *config.Config, faults *faultinject.Injector, logger *slog.Logger) (*controller.OIDCBridgeReconciler, error) {
+	k8sClient, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
 	}
 
-	// Create and add the OIDC poller runnable
 	syncPeriod, err := time.ParseDuration(cfg.Controller.SyncPeriod)
 	if err != nil {
-		return fmt.Errorf("invalid sync period: %w", err)
+		return nil, fmt.Errorf("invalid sync period: %w", err)
 	}
+
+	// Create bridge
+	var cacheTTL time.Duration
+	if cfg.Controller.BridgeCacheTTL != "" {
+		cacheTTL, err = time.ParseDuration(cfg.Controller.BridgeCacheTTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bridgeCacheTTL: %w", err)
+		}
+		// The poller must see key rotations on every tick
+		if cacheTTL >= syncPeriod {
+			return nil, fmt.Errorf("bridgeCacheTTL %s must be shorter than syncPeriod %s", cacheTTL, syncPeriod)
+		}
+	}
+	bridgeClient, err := initializeBridge(mgr.GetConfig(), k8sClient, constants.DefaultNamespace, cfg.Controller.MaxFetchBytes, cacheTTL, cfg.Controller.APIServer, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize bridge: %w", err)
+	}
+	bridgeClient = faults.WrapBridge(bridgeClient)
+
+	// Create and add the OIDC poller runnable
 	poller := &oidcPoller{
 		bridge:     bridgeClient,
 		syncPeriod: syncPeriod,
 		logger:     logger.With("component", constants.ComponentNameOidcPoller),
+		heartbeat:  initializeHeartbeat(k8sClient, cfg.Controller.Heartbeat, syncPeriod, logger),
 	}
 	if err := mgr.Add(poller); err != nil {
-		return fmt.Errorf("failed to add OIDC poller to manager: %w", err)
+		return nil, fmt.Errorf("failed to add OIDC poller to manager: %w", err)
 	}
 
-	// Create publisher
-	pub, err := initializePublisher(cfg, logger)
+	// Create publisher; aggregation uses the backend directly since the fault
+	// injection wrapper hides its optional interfaces
+	backend, err := initializePublisher(cfg, logger)
 	if err != nil {
-		return fmt.Errorf("failed to initialize publisher: %w", err)
+		return nil, fmt.Errorf("failed to initialize publisher: %w", err)
 	}
+	pub := faults.WrapPublisher(backend)
 
 	// Validate publisher
-	if err := pub.Validate(ctx); err != nil {
-		logger.Warn("publisher validation failed (will retry)", "error", err)
+	validation, err := newStartupValidation(cfg.Controller.StartupValidation, logger)
+	if err != nil {
+		return nil, err
+	}
+	// A degraded controller needs no extra ready check: the published check already
+	// fails until a publish succeeds.
+	if _, err := validation.validate(ctx, pub); err != nil {
+		return nil, err
 	}
 
 	// Create rotation manager
-	overlapPeriod, err := time.ParseDuration(cfg.Controller.RotationOverlap)
+	rotCfg, err := rotationConfig(cfg)
 	if err != nil {
-		return fmt.Errorf("invalid rotation overlap period: %w", err)
+		return nil, err
 	}
-	rotMgr, err := initializeRotationManager(k8sClient, constants.DefaultNamespace, constants.DefaultRotationConfigMapName, overlapPeriod, logger)
+	rotMgr, err := initializeRotationManager(k8sClient, rotCfg, logger)
 	if err != nil {
-		return fmt.Errorf("failed to initialize rotation manager: %w", err)
+		return nil, fmt.Errorf("failed to initialize rotation manager: %w", err)
 	}
 
 	// Create and register controller
-	// TODO: Make namespace configurable
-	ctrlCfg := controller.Config{
-		SyncPeriod:          syncPeriod,
-		Namespace:           constants.DefaultNamespace,
-		PublicIssuerURL:     pub.GetPublicURL(), // Get public issuer URL from publisher
-		MultiClusterEnabled: cfg.Controller.ClusterGroup != "",
+	ctrlCfg := newControllerConfig(cfg, syncPeriod, pub.GetPublicURL())
+	if cfg.Controller.RepublishInterval != "" {
+		ctrlCfg.RepublishInterval, err = time.ParseDuration(cfg.Controller.RepublishInterval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid republishInterval: %w", err)
+		}
+	}
+	if cfg.Controller.MetadataMaxAge != "" {
+		ctrlCfg.MetadataMaxAge, err = time.ParseDuration(cfg.Controller.MetadataMaxAge)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metadataMaxAge: %w", err)
+		}
+		if ctrlCfg.MetadataMaxAge <= syncPeriod {
+			return nil, fmt.Errorf("metadataMaxAge %s must be longer than syncPeriod %s", ctrlCfg.MetadataMaxAge, syncPeriod)
+		}
+	}
+	if cfg.Controller.MetadataWait.Interval != "" {
+		ctrlCfg.MetadataWaitInterval, err = time.ParseDuration(cfg.Controller.MetadataWait.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metadataWait.interval: %w", err)
+		}
 	}
 
 	rec := controller.NewOIDCBridgeReconciler(
@@ -304,21 +1202,56 @@ func initializeComponents(mgr manager.Manager, cfg *config.Config, logger *slog.
 	)
 
 	if err := rec.SetupWithManager(mgr); err != nil {
-		return fmt.Errorf("failed to set up controller: %w", err)
+		return nil, fmt.Errorf("failed to set up controller: %w", err)
+	}
+	if cfg.Controller.IssuerConfig.Enabled {
+		if err := setupIssuerConfig(mgr, rec, cfg.Controller.IssuerConfig, logger); err != nil {
+			return nil, err
+		}
+	}
+
+	// Track the issuer certificate AWS pins via the thumbprint
+	expiryWarning, monitorCert, err := issuerCertExpiryWarning(cfg.Controller)
+	if err != nil {
+		return nil, err
+	}
+	if monitorCert && (strings.HasPrefix(pub.GetPublicURL(), "https://") || rec.RuntimeConfig != nil) {
+		certMonitor := &issuerCertMonitor{
+			issuerURL:     pub.GetPublicURL(),
+			runtimeConfig: rec.RuntimeConfig,
+			interval:      issuerCertCheckInterval,
+			expiryWarning: expiryWarning,
+			fetch:         awsfederation.FetchThumbprint,
+			metrics:       rec.Metrics,
+			logger:        logger.With("component", "issuer-cert-monitor"),
+		}
+		if err := mgr.Add(certMonitor); err != nil {
+			return nil, fmt.Errorf("failed to add issuer certificate monitor to manager: %w", err)
+		}
+	}
+
+	if cfg.Controller.FederationDriftCheck.Enabled {
+		driftCheck, err := newFederationDriftCheck(ctx, cfg.Controller.FederationDriftCheck, pub.GetPublicURL(), rec, logger)
+		if err != nil {
+			return nil, err
+		}
+		if err := mgr.Add(driftCheck); err != nil {
+			return nil, fmt.Errorf("failed to add federation drift check to manager: %w", err)
+		}
 	}
 
 	// Wire up aggregation poller in multi-cluster mode
 	if cfg.Controller.ClusterGroup != "" {
-		aggregator, ok := pub.(iface.MultiClusterAggregator)
+		aggregator, ok := backend.(iface.MultiClusterAggregator)
 		if !ok {
-			return fmt.Errorf("publisher type %s does not implement MultiClusterAggregator (required when clusterGroup is set)", pub.Type())
+			return nil, fmt.Errorf("publisher type %s does not implement MultiClusterAggregator (required when clusterGroup is set)", pub.Type())
 		}
 
 		aggregationInterval := 5 * time.Minute
 		if cfg.Controller.AggregationInterval != "" {
 			aggregationInterval, err = time.ParseDuration(cfg.Controller.AggregationInterval)
 			if err != nil {
-				return fmt.Errorf("invalid aggregationInterval: %w", err)
+				return nil, fmt.Errorf("invalid aggregationInterval: %w", err)
 			}
 		}
 
@@ -326,37 +1259,216 @@ func initializeComponents(mgr manager.Manager, cfg *config.Config, logger *slog.
 		if cfg.Controller.ClusterTTL != "" {
 			clusterTTL, err = time.ParseDuration(cfg.Controller.ClusterTTL)
 			if err != nil {
-				return fmt.Errorf("invalid clusterTTL: %w", err)
+				return nil, fmt.Errorf("invalid clusterTTL: %w", err)
+			}
+		}
+
+		clusterFreshness := clusterTTL / 2
+		if cfg.Controller.ClusterFreshness != "" {
+			clusterFreshness, err = time.ParseDuration(cfg.Controller.ClusterFreshness)
+			if err != nil {
+				return nil, fmt.Errorf("invalid clusterFreshness: %w", err)
 			}
 		}
 
 		aggPoller := &aggregationPoller{
 			aggregator:          aggregator,
+			issuerURL:           pub.GetPublicURL(),
+			claims:              claimsCustomization(cfg),
+			extraFields:         cfg.Controller.DiscoveryExtraFields,
 			aggregationInterval: aggregationInterval,
 			clusterTTL:          clusterTTL,
-			logger:              logger.With("component", "aggregation-poller"),
+			clusterFreshness:    clusterFreshness,
+			excludedClusters:    cfg.Controller.ExcludedClusters,
+			metrics:             rec.Metrics,
+			saveHealth: func(ctx context.Context, statuses []health.ClusterStatus) error {
+				return health.SaveClusterHealth(ctx, k8sClient, constants.DefaultNamespace, constants.DefaultClusterHealthConfigMapName, statuses)
+			},
+			logger: logger.With("component", "aggregation-poller"),
 		}
 		if err := mgr.Add(aggPoller); err != nil {
-			return fmt.Errorf("failed to add aggregation poller to manager: %w", err)
+			return nil, fmt.Errorf("failed to add aggregation poller to manager: %w", err)
 		}
 		logger.Info("multi-cluster mode enabled",
 			"clusterGroup", cfg.Controller.ClusterGroup,
 			"clusterID", cfg.Controller.ClusterID,
 			"aggregationInterval", aggregationInterval,
+			"excludedClusters", cfg.Controller.ExcludedClusters,
 		)
+
+		if cfg.Controller.GroupAggregation.Enabled {
+			groupPoller, err := newGroupAggregationPoller(backend, cfg, aggregationInterval, logger)
+			if err != nil {
+				return nil, err
+			}
+			if err := mgr.Add(groupPoller); err != nil {
+				return nil, fmt.Errorf("failed to add group aggregation poller to manager: %w", err)
+			}
+		}
 	}
 
-	return nil
+	return rec, nil
+}
+
+// newGroupAggregationPoller builds the group-of-groups poller. The top-level issuer URL
+// is the group's public URL without the trailing group segment.
+func newGroupAggregationPoller(pub iface.Publisher, cfg *config.Config, aggregationInterval time.Duration, logger *slog.Logger) (*groupAggregationPoller, error) {
+	aggregator, ok := pub.(iface.GroupAggregator)
+	if !ok {
+		return nil, fmt.Errorf("publisher type %s does not implement GroupAggregator (required when groupAggregation is enabled)", pub.Type())
+	}
+
+	interval := aggregationInterval
+	if cfg.Controller.GroupAggregation.Interval != "" {
+		var err error
+		interval, err = time.ParseDuration(cfg.Controller.GroupAggregation.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("invalid groupAggregation.interval: %w", err)
+		}
+	}
+
+	groupTTL := 96 * time.Hour
+	if cfg.Controller.GroupAggregation.GroupTTL != "" {
+		var err error
+		groupTTL, err = time.ParseDuration(cfg.Controller.GroupAggregation.GroupTTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid groupAggregation.groupTTL: %w", err)
+		}
+	}
+
+	issuerURL := strings.TrimSuffix(strings.TrimSuffix(pub.GetPublicURL(), "/"), "/"+cfg.Controller.ClusterGroup)
+	logger.Info("group-of-groups aggregation enabled", "issuerURL", issuerURL, "interval", interval, "groupTTL", groupTTL)
+	return &groupAggregationPoller{
+		aggregator:  aggregator,
+		issuerURL:   issuerURL,
+		claims:      claimsCustomization(cfg),
+		extraFields: cfg.Controller.DiscoveryExtraFields,
+		interval:    interval,
+		groupTTL:    groupTTL,
+		logger:      logger.With("component", "group-aggregation-poller"),
+	}, nil
+}
+
+const (
+	// defaultMetadataWaitInterval is how often a missing OIDC metadata ConfigMap is re-checked.
+	defaultMetadataWaitInterval = 10 * time.Second
+	// defaultMetadataWaitAttempts bounds the re-checks to five minutes by default.
+	defaultMetadataWaitAttempts = 30
+)
+
+// newControllerConfig builds the reconciler configuration from the loaded config.
+func newControllerConfig(cfg *config.Config, syncPeriod time.Duration, publicIssuerURL string) controller.Config {
+	// TODO: Make namespace configurable
+	return controller.Config{
+		SyncPeriod:              syncPeriod,
+		Namespace:               constants.DefaultNamespace,
+		PublicIssuerURL:         publicIssuerURL, // Get public issuer URL from publisher
+		MultiClusterEnabled:     cfg.Controller.ClusterGroup != "",
+		SigningKeysOnly:         cfg.Controller.SigningKeysOnly,
+		RequireKeyAlg:           cfg.Controller.RequireKeyAlg,
+		RejectDuplicateKeyIDs:   cfg.Controller.RejectDuplicateKeyIDs,
+		MaxConcurrentReconciles: cfg.Controller.MaxConcurrentReconciles,
+		FailClosedOnPrivate:     cfg.Controller.FailClosedOnPrivate,
+		PublishOnChange:         cfg.Controller.PublishOnChange,
+		Claims:                  claimsCustomization(cfg),
+		DiscoveryExtraFields:    cfg.Controller.DiscoveryExtraFields,
+		MetadataWaitInterval:    defaultMetadataWaitInterval,
+		MetadataWaitAttempts:    cmp.Or(cfg.Controller.MetadataWait.MaxAttempts, defaultMetadataWaitAttempts),
+		MinifyJWKS:              cfg.Publisher.MinifyJWKS,
+		MinifyDiscovery:         cfg.Publisher.MinifyDiscovery,
+		PublishFormat: fmt.Sprintf("layout=%s,minifyJWKS=%t,minifyDiscovery=%t",
+			cfg.Publisher.KeyLayout, cfg.Publisher.MinifyJWKS, cfg.Publisher.MinifyDiscovery),
+	}
+}
+
+// claimsCustomization converts the claims_supported configuration for the bridge.
+func claimsCustomization(cfg *config.Config) bridge.ClaimsCustomization {
+	return bridge.ClaimsCustomization{
+		Claims:   cfg.Controller.ClaimsSupported.Claims,
+		Override: cfg.Controller.ClaimsSupported.Override,
+	}
+}
+
+// initializeHeartbeat creates the heartbeat Lease renewer, or returns nil when it is disabled.
+// The lease stays valid for three sync periods so a single slow sync does not look wedged.
+func initializeHeartbeat(k8sClient kubernetes.Interface, cfg config.HeartbeatConfig, syncPeriod time.Duration, logger *slog.Logger) *heartbeat.Heartbeat {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	name := cfg.Name
+	if name == "" {
+		name = constants.DefaultHeartbeatLeaseName
+	}
+	namespace := cfg.Namespace
+	if namespace == "" {
+		namespace = constants.DefaultNamespace
+	}
+	identity := os.Getenv("POD_NAME")
+	if identity == "" {
+		identity, _ = os.Hostname()
+	}
+
+	logger.Info("heartbeat lease enabled", "name", name, "namespace", namespace)
+	return heartbeat.New(k8sClient, namespace, name, identity, 3*syncPeriod, logger)
+}
+
+// initializeFaultInjection loads the fault injection configuration, or returns nil
+// when fault injection is not enabled.
+func initializeFaultInjection(enabled bool, path string, logger *slog.Logger) (*faultinject.Injector, error) {
+	if !enabled {
+		if path != "" {
+			logger.Warn("ignoring --fault-injection-config because --enable-fault-injection is not set")
+		}
+		return nil, nil
+	}
+	if path == "" {
+		return nil, fmt.Errorf("--enable-fault-injection requires --fault-injection-config")
+	}
+
+	faultCfg, err := faultinject.LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	faults, err := faultinject.New(faultCfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fault injection config: %w", err)
+	}
+	logger.Warn("fault injection enabled; publishes and fetches will fail on purpose", "config", path, "faults", len(faultCfg.Faults))
+	return faults, nil
+}
+
+// eventStreamPath is where the event stream is served on the metrics server.
+const eventStreamPath = "/events"
+
+// initializeEventStream creates the event stream broker, or returns nil when it is disabled.
+func initializeEventStream(cfg config.EventStreamConfig, logger *slog.Logger) *eventstream.Broker {
+	if !cfg.Enabled {
+		return nil
+	}
+	logger.Info("event stream enabled", "path", eventStreamPath,
+		"maxSubscribers", cmp.Or(cfg.MaxSubscribers, eventstream.DefaultMaxSubscribers),
+		"bufferSize", cmp.Or(cfg.BufferSize, eventstream.DefaultBufferSize))
+	return eventstream.NewBroker(cfg.MaxSubscribers, cfg.BufferSize)
 }
 
 // initializeBridge creates and initializes the OIDC bridge.
-func initializeBridge(restConfig *rest.Config, k8sClient kubernetes.Interface, namespace string, logger *slog.Logger) (bridge.OIDCBridge, error) {
+// apiServer, when its host is set, overrides the endpoint the metadata is fetched from.
+func initializeBridge(restConfig *rest.Config, k8sClient kubernetes.Interface, namespace string, maxResponseBytes int64, cacheTTL time.Duration, apiServer config.APIServerConfig, logger *slog.Logger) (bridge.OIDCBridge, error) {
 	// Create bridge config
 	bridgeCfg := bridge.Config{
-		RESTConfig: restConfig,
-		K8sClient:  k8sClient,
-		Namespace:  namespace,
-		Logger:     logger,
+		RESTConfig:             restConfig,
+		K8sClient:              k8sClient,
+		Namespace:              namespace,
+		Logger:                 logger,
+		MaxResponseBytes:       maxResponseBytes,
+		CacheTTL:               cacheTTL,
+		APIServerHost:          apiServer.Host,
+		APIServerTLSServerName: apiServer.TLSServerName,
+		APIServerCAFile:        apiServer.CAFile,
+	}
+	if apiServer.Host != "" {
+		logger.Info("fetching OIDC metadata from a dedicated API server endpoint", "host", apiServer.Host)
 	}
 
 	// Create bridge
@@ -368,11 +1480,14 @@ func initializeBridge(restConfig *rest.Config, k8sClient kubernetes.Interface, n
 	return bridgeClient, nil
 }
 
-// initializePublisher creates and initializes the publisher.
+// initializePublisher creates and initializes the publisher. Its backend client is
+// shared by startup validation, the reconciler and the pollers until shutdown.
 func initializePublisher(cfg *config.Config, logger *slog.Logger) (iface.Publisher, error) {
 	pubFactory := publisher.NewFactory(logger)
 
-	// Initialize publisher (factory receives full config so it can wire clusterGroup as prefix)
+	// Initialize publisher (factory receives full config so it can wire clusterGroup as prefix).
+	// Backend clients keep the context they are created with for credential refreshes,
+	// so they must not be tied to a request-scoped context.
 	pub, err := pubFactory.Create(context.Background(), cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create publisher: %w", err)
@@ -381,23 +1496,49 @@ func initializePublisher(cfg *config.Config, logger *slog.Logger) (iface.Publish
 	return pub, nil
 }
 
+// rotationConfig builds the rotation manager configuration from the controller
+// settings, keeping the defaults for settings that are not set.
+func rotationConfig(cfg *config.Config) (rotation.Config, error) {
+	rotCfg := rotation.DefaultConfig()
+	rotCfg.Namespace = constants.DefaultNamespace
+	rotCfg.ConfigMapName = constants.DefaultRotationConfigMapName
+
+	overlapPeriod, err := time.ParseDuration(cfg.Controller.RotationOverlap)
+	if err != nil {
+		return rotation.Config{}, fmt.Errorf("invalid rotation overlap period: %w", err)
+	}
+	rotCfg.OverlapPeriod = overlapPeriod
+	if cfg.Controller.RotationCleanupMaxSourceAge != "" {
+		rotCfg.CleanupMaxSourceAge, err = time.ParseDuration(cfg.Controller.RotationCleanupMaxSourceAge)
+		if err != nil {
+			return rotation.Config{}, fmt.Errorf("invalid rotationCleanupMaxSourceAge: %w", err)
+		}
+	}
+	if cfg.Controller.RotationMissingThreshold > 0 {
+		rotCfg.MissingThreshold = cfg.Controller.RotationMissingThreshold
+	}
+	if cfg.Controller.RotationMissingGracePeriod != "" {
+		rotCfg.MissingGracePeriod, err = time.ParseDuration(cfg.Controller.RotationMissingGracePeriod)
+		if err != nil {
+			return rotation.Config{}, fmt.Errorf("invalid rotationMissingGracePeriod: %w", err)
+		}
+	}
+	if cfg.Controller.KeyOrder != "" {
+		rotCfg.KeyOrder = rotation.KeyOrder(cfg.Controller.KeyOrder)
+	}
+	return rotCfg, nil
+}
+
 // initializeRotationManager creates and initializes the rotation manager.
-func initializeRotationManager(k8sClient kubernetes.Interface, namespace, configMapName string, overlapPeriod time.Duration, logger *slog.Logger) (rotation.Manager, error) {
+func initializeRotationManager(k8sClient kubernetes.Interface, rotCfg rotation.Config, logger *slog.Logger) (rotation.Manager, error) {
 	// Create ConfigMap store
 	store := rotation.NewConfigMapStore(
 		k8sClient,
-		namespace,
-		configMapName,
+		rotCfg.Namespace,
+		rotCfg.ConfigMapName,
 		logger,
 	)
 
-	// Create rotation config
-	rotCfg := rotation.Config{
-		OverlapPeriod: overlapPeriod,
-		Namespace:     namespace,
-		ConfigMapName: configMapName,
-	}
-
 	// Create rotation manager
 	rotMgr := rotation.NewManager(store, rotCfg, logger)
 
@@ -1,15 +1,54 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	restfake "k8s.io/client-go/rest/fake"
 
+	"github.com/hixichen/kube-iam-assume/internal/controller"
 	"github.com/hixichen/kube-iam-assume/pkg/bridge"
+	"github.com/hixichen/kube-iam-assume/pkg/config"
+	"github.com/hixichen/kube-iam-assume/pkg/constants"
+	kaerrors "github.com/hixichen/kube-iam-assume/pkg/errors"
+	"github.com/hixichen/kube-iam-assume/pkg/faultinject"
+	"github.com/hixichen/kube-iam-assume/pkg/federation"
+	awsfederation "github.com/hixichen/kube-iam-assume/pkg/federation/aws"
+	"github.com/hixichen/kube-iam-assume/pkg/health"
+	"github.com/hixichen/kube-iam-assume/pkg/metrics"
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/memory"
+	"github.com/hixichen/kube-iam-assume/pkg/rotation"
 )
 
+// sharedMetrics is created once; metrics.New(nil) registers with the global registry.
+var (
+	sharedMetrics     *metrics.Metrics
+	sharedMetricsOnce sync.Once
+)
+
+func testMetrics() *metrics.Metrics {
+	sharedMetricsOnce.Do(func() { sharedMetrics = metrics.New(nil) })
+	return sharedMetrics
+}
+
 func makeJWKS(kids ...string) *bridge.JWKS {
 	keys := make([]bridge.JWK, 0, len(kids))
 	for _, kid := range kids {
@@ -41,6 +80,32 @@ func TestMergeJWKS_DeduplicatesByKid(t *testing.T) {
 	assert.Contains(t, kids, "key-c1")
 }
 
+func TestMergeJWKS_Deterministic(t *testing.T) {
+	clusterJWKS := map[string]*bridge.JWKS{
+		"cluster-c": makeJWKS("key-c1", "key-shared"),
+		"cluster-a": makeJWKS("key-a2", "key-a1"),
+		"cluster-b": makeJWKS("key-b1", "key-shared"),
+	}
+	clusterJWKS["cluster-b"].Keys[1].Use = "sig"
+
+	first, err := json.Marshal(mergeJWKS(clusterJWKS))
+	require.NoError(t, err)
+	for range 20 {
+		again, err := json.Marshal(mergeJWKS(clusterJWKS))
+		require.NoError(t, err)
+		assert.Equal(t, string(first), string(again))
+	}
+
+	merged := mergeJWKS(clusterJWKS)
+	var kids []string
+	for _, key := range merged.Keys {
+		kids = append(kids, key.Kid)
+	}
+	assert.Equal(t, []string{"key-a1", "key-a2", "key-b1", "key-c1", "key-shared"}, kids)
+	// The first cluster by ID wins a duplicate kid
+	assert.Equal(t, "sig", merged.Keys[4].Use)
+}
+
 func TestMergeJWKS_EmptyInput(t *testing.T) {
 	merged := mergeJWKS(map[string]*bridge.JWKS{})
 	require.NotNil(t, merged)
@@ -78,12 +143,9 @@ func TestAggregationPoller_TTLPruning(t *testing.T) {
 		"cluster-d": now.Add(-90 * time.Minute), // stale: > 1h TTL
 	}
 
-	// Prune stale clusters (same logic as aggregationPoller.aggregate)
-	for clusterID, t := range lastModified {
-		if now.Sub(t) > clusterTTL {
-			delete(clusterJWKS, clusterID)
-		}
-	}
+	// Prune stale clusters (same helper as aggregationPoller.aggregate)
+	pruned := pruneStale(clusterJWKS, lastModified, clusterTTL, now)
+	assert.Equal(t, []string{"cluster-d"}, pruned)
 
 	// After pruning, cluster-d should be removed
 	assert.Len(t, clusterJWKS, 3)
@@ -94,3 +156,885 @@ func TestAggregationPoller_TTLPruning(t *testing.T) {
 	merged := mergeJWKS(clusterJWKS)
 	assert.Len(t, merged.Keys, 3)
 }
+
+func TestAggregationPoller_ReportsClusterHealth(t *testing.T) {
+	ctx := context.Background()
+	bucket := memory.NewBucket()
+	now := time.Now()
+
+	// cluster-a published recently, cluster-b is past the freshness threshold but not yet pruned
+	ages := map[string]time.Duration{"cluster-a": 10 * time.Minute, "cluster-b": 30 * time.Hour}
+	for clusterID, age := range ages {
+		pub, err := memory.New(memory.Config{
+			PublicURL:           "https://oidc.example.com/prod",
+			Prefix:              "prod",
+			MultiClusterEnabled: true,
+			ClusterID:           clusterID,
+		}, bucket)
+		require.NoError(t, err)
+		pub.SetTimeFunc(func() time.Time { return now.Add(-age) })
+		require.NoError(t, pub.Publish(ctx, &bridge.DiscoveryDocument{}, makeJWKS("key-"+clusterID)))
+	}
+
+	leader, err := memory.New(memory.Config{
+		PublicURL:           "https://oidc.example.com/prod",
+		Prefix:              "prod",
+		MultiClusterEnabled: true,
+		ClusterID:           "cluster-a",
+	}, bucket)
+	require.NoError(t, err)
+
+	var saved []health.ClusterStatus
+	m := testMetrics()
+	poller := &aggregationPoller{
+		aggregator:       leader,
+		issuerURL:        "https://oidc.example.com/prod",
+		clusterTTL:       48 * time.Hour,
+		clusterFreshness: 24 * time.Hour,
+		metrics:          m,
+		saveHealth: func(_ context.Context, statuses []health.ClusterStatus) error {
+			saved = statuses
+			return nil
+		},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	poller.aggregate(ctx)
+
+	require.Len(t, saved, 2)
+	assert.Equal(t, "cluster-a", saved[0].ClusterID)
+	assert.True(t, saved[0].Healthy)
+	assert.Equal(t, "cluster-b", saved[1].ClusterID)
+	assert.False(t, saved[1].Healthy)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.ClusterHealthy.WithLabelValues("cluster-a")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.ClusterHealthy.WithLabelValues("cluster-b")))
+	assert.InDelta(t, (10 * time.Minute).Seconds(), testutil.ToFloat64(m.ClusterJWKSAge.WithLabelValues("cluster-a")), 5)
+	assert.InDelta(t, (30 * time.Hour).Seconds(), testutil.ToFloat64(m.ClusterJWKSAge.WithLabelValues("cluster-b")), 5)
+
+	// The lagging cluster's keys are still aggregated until the TTL prunes them
+	data, ok := bucket.Get("prod/openid/v1/jwks")
+	require.True(t, ok)
+	var jwks bridge.JWKS
+	require.NoError(t, json.Unmarshal(data, &jwks))
+	assert.Len(t, jwks.Keys, 2)
+}
+
+func TestAggregationPoller_ExcludesClusters(t *testing.T) {
+	ctx := context.Background()
+	bucket := memory.NewBucket()
+
+	for _, clusterID := range []string{"cluster-a", "cluster-b", "cluster-c"} {
+		pub, err := memory.New(memory.Config{
+			PublicURL:           "https://oidc.example.com/prod",
+			Prefix:              "prod",
+			MultiClusterEnabled: true,
+			ClusterID:           clusterID,
+		}, bucket)
+		require.NoError(t, err)
+		require.NoError(t, pub.Publish(ctx, &bridge.DiscoveryDocument{}, makeJWKS("key-"+clusterID)))
+	}
+
+	leader, err := memory.New(memory.Config{
+		PublicURL:           "https://oidc.example.com/prod",
+		Prefix:              "prod",
+		MultiClusterEnabled: true,
+		ClusterID:           "cluster-a",
+	}, bucket)
+	require.NoError(t, err)
+
+	m := testMetrics()
+	poller := &aggregationPoller{
+		aggregator:       leader,
+		issuerURL:        "https://oidc.example.com/prod",
+		clusterTTL:       48 * time.Hour,
+		excludedClusters: []string{"cluster-b", "cluster-unknown"},
+		metrics:          m,
+		logger:           slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	poller.aggregate(ctx)
+
+	// The excluded cluster is fresh, but its keys are not merged
+	data, ok := bucket.Get("prod/openid/v1/jwks")
+	require.True(t, ok)
+	var jwks bridge.JWKS
+	require.NoError(t, json.Unmarshal(data, &jwks))
+	var kids []string
+	for _, key := range jwks.Keys {
+		kids = append(kids, key.Kid)
+	}
+	assert.ElementsMatch(t, []string{"key-cluster-a", "key-cluster-c"}, kids)
+
+	// Its sub-path is kept
+	_, ok = bucket.Get("prod/clusters/cluster-b/openid/v1/jwks")
+	assert.True(t, ok)
+
+	assert.Equal(t, 1, testutil.CollectAndCount(m.ClusterExcluded))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.ClusterExcluded.WithLabelValues("cluster-b")))
+}
+
+func TestAggregationPoller_ExcludingLastClusterPublishesEmptyJWKS(t *testing.T) {
+	ctx := context.Background()
+	bucket := memory.NewBucket()
+
+	pub, err := memory.New(memory.Config{
+		PublicURL:           "https://oidc.example.com/prod",
+		Prefix:              "prod",
+		MultiClusterEnabled: true,
+		ClusterID:           "cluster-a",
+	}, bucket)
+	require.NoError(t, err)
+	require.NoError(t, pub.Publish(ctx, &bridge.DiscoveryDocument{}, makeJWKS("key-cluster-a")))
+
+	poller := &aggregationPoller{
+		aggregator: pub,
+		issuerURL:  "https://oidc.example.com/prod",
+		clusterTTL: 48 * time.Hour,
+		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	poller.aggregate(ctx)
+	data, ok := bucket.Get("prod/openid/v1/jwks")
+	require.True(t, ok)
+	assert.Contains(t, string(data), "key-cluster-a")
+
+	// Excluding the only fresh cluster withdraws its keys from the group JWKS
+	poller.excludedClusters = []string{"cluster-a"}
+	poller.aggregate(ctx)
+	data, ok = bucket.Get("prod/openid/v1/jwks")
+	require.True(t, ok)
+	var jwks bridge.JWKS
+	require.NoError(t, json.Unmarshal(data, &jwks))
+	assert.NotNil(t, jwks.Keys)
+	assert.Empty(t, jwks.Keys)
+}
+
+func TestAggregationPoller_OwnsRootDiscovery(t *testing.T) {
+	ctx := context.Background()
+	issuerURL := "https://oidc.example.com/prod"
+	bucket := memory.NewBucket()
+
+	// Two clusters publish their own metadata; neither writes the root discovery document
+	clusters := map[string]bridge.JWK{
+		"cluster-a": {Kid: "key-a", Kty: "RSA", Alg: "RS256"},
+		"cluster-b": {Kid: "key-b", Kty: "EC", Alg: "ES256"},
+	}
+	for clusterID, key := range clusters {
+		pub, err := memory.New(memory.Config{
+			PublicURL:           issuerURL,
+			Prefix:              "prod",
+			MultiClusterEnabled: true,
+			ClusterID:           clusterID,
+		}, bucket)
+		require.NoError(t, err)
+		discovery := &bridge.DiscoveryDocument{Issuer: "https://" + clusterID + ".internal"}
+		require.NoError(t, pub.Publish(ctx, discovery, &bridge.JWKS{Keys: []bridge.JWK{key}}))
+	}
+	_, ok := bucket.Get("prod/.well-known/openid-configuration")
+	require.False(t, ok, "clusters must not write the root discovery document in group mode")
+
+	leader, err := memory.New(memory.Config{
+		PublicURL:           issuerURL,
+		Prefix:              "prod",
+		MultiClusterEnabled: true,
+		ClusterID:           "cluster-a",
+	}, bucket)
+	require.NoError(t, err)
+
+	poller := &aggregationPoller{
+		aggregator: leader,
+		issuerURL:  issuerURL,
+		clusterTTL: time.Hour,
+		logger:     slog.Default(),
+	}
+	poller.aggregate(ctx)
+
+	data, ok := bucket.Get("prod/.well-known/openid-configuration")
+	require.True(t, ok, "leader should publish the root discovery document")
+
+	var discovery bridge.DiscoveryDocument
+	require.NoError(t, json.Unmarshal(data, &discovery))
+	assert.Equal(t, issuerURL, discovery.Issuer)
+	assert.Equal(t, issuerURL+"/openid/v1/jwks", discovery.JWKSURI)
+	assert.Equal(t, []string{"ES256", "RS256"}, discovery.IDTokenSigningAlgValues)
+	require.NoError(t, bridge.ValidateDiscoveryDocument(&discovery))
+
+	data, ok = bucket.Get("prod/openid/v1/jwks")
+	require.True(t, ok)
+	var jwks bridge.JWKS
+	require.NoError(t, json.Unmarshal(data, &jwks))
+	assert.Len(t, jwks.Keys, 2)
+}
+
+func TestBuildRootDiscovery_DefaultsToRS256(t *testing.T) {
+	discovery, err := buildRootDiscovery("https://oidc.example.com", makeJWKS("key-1"), bridge.ClaimsCustomization{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"RS256"}, discovery.IDTokenSigningAlgValues)
+	assert.Empty(t, discovery.ClaimsSupported)
+}
+
+func TestBuildRootDiscovery_AdvertisesConfiguredClaims(t *testing.T) {
+	claims := bridge.ClaimsCustomization{Claims: []string{"sub", "aud", "sub"}}
+	discovery, err := buildRootDiscovery("https://oidc.example.com", makeJWKS("key-1"), claims, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sub", "aud"}, discovery.ClaimsSupported)
+}
+
+func TestBuildRootDiscovery_AddsExtraFields(t *testing.T) {
+	extra := map[string]interface{}{"code_challenge_methods_supported": []interface{}{"S256"}}
+	discovery, err := buildRootDiscovery("https://oidc.example.com", makeJWKS("key-1"), bridge.ClaimsCustomization{}, extra)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(discovery)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"code_challenge_methods_supported":["S256"]`)
+}
+
+func TestOIDCPoller_BacksOffWhenThrottled(t *testing.T) {
+	status := http.StatusTooManyRequests
+	retryAfter := "30"
+	restClient := &restfake.RESTClient{
+		NegotiatedSerializer: clientgoscheme.Codecs.WithoutConversion(),
+		Client: restfake.CreateHTTPClient(func(_ *http.Request) (*http.Response, error) {
+			header := http.Header{}
+			if retryAfter != "" {
+				header.Set("Retry-After", retryAfter)
+			}
+			return &http.Response{
+				StatusCode: status,
+				Header:     header,
+				Body:       io.NopCloser(strings.NewReader("slow down")),
+			}, nil
+		}),
+	}
+	br, err := bridge.New(bridge.Config{}, nil)
+	require.NoError(t, err)
+	br.SetRESTClient(restClient)
+
+	p := &oidcPoller{bridge: br, syncPeriod: 10 * time.Second, logger: slog.Default()}
+	ctx := context.Background()
+
+	// Retry-After longer than the backoff wins
+	assert.Equal(t, 30*time.Second, p.poll(ctx))
+
+	// Consecutive throttling without Retry-After keeps doubling
+	retryAfter = ""
+	assert.Equal(t, 40*time.Second, p.poll(ctx))
+	assert.Equal(t, 80*time.Second, p.poll(ctx))
+
+	// Other errors return to the normal sync period
+	status = http.StatusInternalServerError
+	assert.Equal(t, 10*time.Second, p.poll(ctx))
+	assert.Zero(t, p.backoff)
+}
+
+func TestOIDCPoller_BackoffIsCapped(t *testing.T) {
+	p := &oidcPoller{syncPeriod: time.Minute, logger: slog.Default()}
+	throttled := &bridge.ThrottledError{Err: errors.New("429")}
+
+	var delay time.Duration
+	for range 10 {
+		delay = p.nextDelay(throttled)
+	}
+	assert.Equal(t, maxThrottleBackoff, delay)
+	assert.Equal(t, time.Minute, p.nextDelay(nil))
+}
+
+func TestOIDCPoller_RenewsHeartbeatEachTick(t *testing.T) {
+	restClient := &restfake.RESTClient{
+		NegotiatedSerializer: clientgoscheme.Codecs.WithoutConversion(),
+		Client: restfake.CreateHTTPClient(func(_ *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader(`{"issuer":"https://kubernetes.default.svc","keys":[]}`)),
+			}, nil
+		}),
+	}
+	br, err := bridge.New(bridge.Config{}, nil)
+	require.NoError(t, err)
+	br.SetRESTClient(restClient)
+
+	clientset := fake.NewClientset()
+	hb := initializeHeartbeat(clientset, config.HeartbeatConfig{Enabled: true}, 10*time.Second, slog.Default())
+	require.NotNil(t, hb)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	hb.SetTimeFunc(func() time.Time { return now })
+
+	p := &oidcPoller{bridge: br, syncPeriod: 10 * time.Second, logger: slog.Default(), heartbeat: hb}
+	ctx := context.Background()
+	renewTime := func() time.Time {
+		lease, err := clientset.CoordinationV1().Leases(constants.DefaultNamespace).Get(ctx, constants.DefaultHeartbeatLeaseName, metav1.GetOptions{})
+		require.NoError(t, err)
+		return lease.Spec.RenewTime.Time
+	}
+
+	p.poll(ctx)
+	first := renewTime()
+
+	now = now.Add(10 * time.Second)
+	p.poll(ctx)
+	assert.True(t, renewTime().After(first), "renewTime should advance after a sync tick")
+}
+
+func TestInitializeHeartbeat_Disabled(t *testing.T) {
+	assert.Nil(t, initializeHeartbeat(fake.NewClientset(), config.HeartbeatConfig{}, time.Minute, slog.Default()))
+}
+
+func TestNewControllerConfig_PlumbsControllerOptions(t *testing.T) {
+	cfg := &config.Config{Controller: config.ControllerConfig{
+		ClusterGroup:            "prod",
+		SigningKeysOnly:         true,
+		RejectDuplicateKeyIDs:   true,
+		MaxConcurrentReconciles: 4,
+	}}
+
+	ctrlCfg := newControllerConfig(cfg, time.Minute, "https://oidc.example.com/prod")
+	assert.Equal(t, 4, ctrlCfg.MaxConcurrentReconciles)
+	assert.Equal(t, time.Minute, ctrlCfg.SyncPeriod)
+	assert.Equal(t, "https://oidc.example.com/prod", ctrlCfg.PublicIssuerURL)
+	assert.True(t, ctrlCfg.MultiClusterEnabled)
+	assert.True(t, ctrlCfg.SigningKeysOnly)
+	assert.True(t, ctrlCfg.RejectDuplicateKeyIDs)
+	assert.Equal(t, defaultMetadataWaitInterval, ctrlCfg.MetadataWaitInterval)
+	assert.Equal(t, defaultMetadataWaitAttempts, ctrlCfg.MetadataWaitAttempts)
+}
+
+func TestRotationConfig(t *testing.T) {
+	cfg := &config.Config{Controller: config.ControllerConfig{RotationOverlap: "12h"}}
+	rotCfg, err := rotationConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 12*time.Hour, rotCfg.OverlapPeriod)
+	assert.Equal(t, 2, rotCfg.MissingThreshold, "keys are marked for removal after two syncs by default")
+	assert.Zero(t, rotCfg.MissingGracePeriod)
+	assert.Equal(t, rotation.KeyOrderKid, rotCfg.KeyOrder)
+
+	cfg.Controller.RotationMissingThreshold = 1
+	cfg.Controller.RotationMissingGracePeriod = "10m"
+	rotCfg, err = rotationConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rotCfg.MissingThreshold)
+	assert.Equal(t, 10*time.Minute, rotCfg.MissingGracePeriod)
+
+	cfg.Controller.RotationMissingGracePeriod = "soon"
+	_, err = rotationConfig(cfg)
+	assert.ErrorContains(t, err, "invalid rotationMissingGracePeriod")
+}
+
+func TestIssuerCertMonitor_RecordsExpiry(t *testing.T) {
+	notAfter := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	m := &issuerCertMonitor{
+		issuerURL:     "https://oidc.example.com",
+		expiryWarning: defaultIssuerCertExpiryWarning,
+		fetch: func(ctx context.Context, issuerURL string) (*awsfederation.ThumbprintInfo, error) {
+			return &awsfederation.ThumbprintInfo{NotAfter: notAfter, ChainLength: 2}, nil
+		},
+		metrics: testMetrics(),
+		logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	m.check(context.Background())
+	assert.Equal(t, float64(notAfter.Unix()), testutil.ToFloat64(m.metrics.IssuerCertExpiryTimestamp))
+}
+
+func TestIssuerCertMonitor_SkipsHTTPIssuer(t *testing.T) {
+	m := &issuerCertMonitor{
+		issuerURL: "http://oidc.example.com",
+		fetch: func(ctx context.Context, issuerURL string) (*awsfederation.ThumbprintInfo, error) {
+			t.Fatalf("fetched the certificate of %s", issuerURL)
+			return nil, nil
+		},
+		metrics: testMetrics(),
+		logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	m.check(context.Background())
+}
+
+// fakeFederationProvider returns a fixed provider lookup result.
+type fakeFederationProvider struct {
+	federation.Provider
+	info *federation.ProviderInfo
+	err  error
+}
+
+func (f *fakeFederationProvider) GetProviderInfo(ctx context.Context, issuerURL string) (*federation.ProviderInfo, error) {
+	return f.info, f.err
+}
+
+func (f *fakeFederationProvider) GetProviderInfoByID(ctx context.Context, providerID string) (*federation.ProviderInfo, error) {
+	return f.info, f.err
+}
+
+func (f *fakeFederationProvider) Type() string { return string(federation.ProviderTypeAWS) }
+
+func TestIssuerCertExpiryWarning(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        config.ControllerConfig
+		wantWindow time.Duration
+		wantRun    bool
+		wantErr    bool
+	}{
+		{name: "not configured"},
+		{
+			name: "gcp federation",
+			cfg:  config.ControllerConfig{FederationDriftCheck: config.FederationDriftCheckConfig{Enabled: true, Provider: "gcp"}},
+		},
+		{
+			name:       "aws federation",
+			cfg:        config.ControllerConfig{FederationDriftCheck: config.FederationDriftCheckConfig{Enabled: true, Provider: "aws"}},
+			wantWindow: defaultIssuerCertExpiryWarning,
+			wantRun:    true,
+		},
+		{
+			name:       "explicit window",
+			cfg:        config.ControllerConfig{IssuerCertExpiryWarning: "48h"},
+			wantWindow: 48 * time.Hour,
+			wantRun:    true,
+		},
+		{name: "invalid window", cfg: config.ControllerConfig{IssuerCertExpiryWarning: "soon"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			window, run, err := issuerCertExpiryWarning(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantWindow, window)
+			assert.Equal(t, tt.wantRun, run)
+		})
+	}
+}
+
+func TestFederationDriftCheck(t *testing.T) {
+	const issuerURL = "https://oidc.example.com"
+	matching := &federation.ProviderInfo{
+		ProviderARN: "arn:aws:iam::123456789012:oidc-provider/oidc.example.com",
+		Audiences:   []string{"sts.amazonaws.com"},
+		Thumbprint:  "ABCDEF",
+		Status:      federation.ProviderStatusActive,
+	}
+	provider := &fakeFederationProvider{info: matching}
+	var events []string
+	d := &federationDriftCheck{
+		provider:  provider,
+		issuerURL: issuerURL,
+		audiences: []string{"sts.amazonaws.com"},
+		thumbprint: func(ctx context.Context, issuerURL string) (*awsfederation.ThumbprintInfo, error) {
+			return &awsfederation.ThumbprintInfo{Thumbprint: "abcdef"}, nil
+		},
+		metrics: testMetrics(),
+		event: func(ctx context.Context, eventType, reason, message string) {
+			events = append(events, reason+": "+message)
+		},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	ctx := context.Background()
+
+	d.check(ctx)
+	assert.Equal(t, 1.0, testutil.ToFloat64(d.metrics.FederationHealthy))
+	assert.Empty(t, events)
+
+	// A missing provider flips the gauge and emits one event until the drift changes
+	provider.info, provider.err = nil, kaerrors.NewNotFoundError("aws-federation", "no OIDC provider found", nil)
+	d.check(ctx)
+	d.check(ctx)
+	assert.Equal(t, 0.0, testutil.ToFloat64(d.metrics.FederationHealthy))
+	require.Len(t, events, 1)
+	assert.Contains(t, events[0], "no aws federation provider trusts issuer "+issuerURL)
+
+	// Lookup failures keep the last result
+	provider.err = errors.New("throttled")
+	d.check(ctx)
+	assert.Equal(t, 0.0, testutil.ToFloat64(d.metrics.FederationHealthy))
+
+	// Thumbprint and audience drift are reported together
+	drifted := *matching
+	drifted.Audiences = []string{"other"}
+	drifted.Thumbprint = "123456"
+	provider.info, provider.err = &drifted, nil
+	d.check(ctx)
+	assert.Equal(t, 0.0, testutil.ToFloat64(d.metrics.FederationHealthy))
+	require.Len(t, events, 2)
+	assert.Contains(t, events[1], "does not accept audiences [sts.amazonaws.com]")
+	assert.Contains(t, events[1], "pins thumbprint 123456 but the issuer presents abcdef")
+
+	provider.info = matching
+	d.check(ctx)
+	assert.Equal(t, 1.0, testutil.ToFloat64(d.metrics.FederationHealthy))
+	assert.Len(t, events, 2)
+}
+
+func TestFederationDriftCheck_IssuerMismatch(t *testing.T) {
+	const (
+		issuerURL   = "https://oidc.example.com/prod"
+		providerARN = "arn:aws:iam::123456789012:oidc-provider/oidc.example.com/prod"
+	)
+	provider := &fakeFederationProvider{info: &federation.ProviderInfo{
+		ProviderARN: providerARN,
+		IssuerURL:   "oidc.example.com/prod/", // IAM stores the URL without the scheme
+		Status:      federation.ProviderStatusActive,
+	}}
+	var events []string
+	d := &federationDriftCheck{
+		provider:   provider,
+		issuerURL:  issuerURL,
+		providerID: providerARN,
+		metrics:    testMetrics(),
+		event: func(ctx context.Context, eventType, reason, message string) {
+			events = append(events, reason+": "+message)
+		},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	ctx := context.Background()
+
+	d.check(ctx)
+	assert.Equal(t, 0.0, testutil.ToFloat64(d.metrics.FederationIssuerMismatch))
+	assert.Equal(t, 1.0, testutil.ToFloat64(d.metrics.FederationHealthy))
+	assert.Empty(t, events)
+
+	// The pinned provider still trusts the old issuer after the public URL changed
+	d.issuerURL = "https://oidc.example.com/production"
+	d.check(ctx)
+	d.check(ctx)
+	assert.Equal(t, 1.0, testutil.ToFloat64(d.metrics.FederationIssuerMismatch))
+	assert.Equal(t, 0.0, testutil.ToFloat64(d.metrics.FederationHealthy))
+	require.Len(t, events, 2)
+	assert.Equal(t, controller.EventReasonFederationIssuerMismatch+": Federation provider "+providerARN+
+		" trusts issuer oidc.example.com/prod/ but the controller publishes https://oidc.example.com/production", events[0])
+	assert.Contains(t, events[1], controller.EventReasonFederationDrift+": ")
+
+	// A pinned provider that was deleted is drift, not a mismatch
+	provider.info, provider.err = nil, kaerrors.NewNotFoundError("aws-federation", "no OIDC provider found", nil)
+	d.check(ctx)
+	require.Len(t, events, 3)
+	assert.Contains(t, events[2], "aws federation provider "+providerARN+" does not exist")
+
+	// Fixing the provider clears the alert
+	provider.info, provider.err = &federation.ProviderInfo{
+		ProviderARN: providerARN,
+		IssuerURL:   "oidc.example.com/production",
+		Status:      federation.ProviderStatusActive,
+	}, nil
+	d.check(ctx)
+	assert.Equal(t, 0.0, testutil.ToFloat64(d.metrics.FederationIssuerMismatch))
+	assert.Equal(t, 1.0, testutil.ToFloat64(d.metrics.FederationHealthy))
+	assert.Len(t, events, 3)
+}
+
+func TestGroupAggregationPoller_MergesGroups(t *testing.T) {
+	ctx := context.Background()
+	bucket := memory.NewBucket()
+
+	// Two groups with two clusters each; "shared" is served by a cluster in both groups
+	groups := map[string]map[string][]string{
+		"prod":    {"prod-a": {"key-p1", "shared"}, "prod-b": {"key-p2"}},
+		"staging": {"staging-a": {"key-s1", "shared"}, "staging-b": {"key-s2"}},
+	}
+	var topLeader *memory.Publisher
+	for group, clusters := range groups {
+		var groupLeader *memory.Publisher
+		for clusterID, kids := range clusters {
+			pub, err := memory.New(memory.Config{
+				PublicURL:           "https://oidc.example.com/" + group,
+				Prefix:              group,
+				MultiClusterEnabled: true,
+				ClusterID:           clusterID,
+			}, bucket)
+			require.NoError(t, err)
+			require.NoError(t, pub.Publish(ctx, &bridge.DiscoveryDocument{}, makeJWKS(kids...)))
+			groupLeader = pub
+		}
+
+		// First level: each group's leader merges its clusters
+		(&aggregationPoller{
+			aggregator: groupLeader,
+			issuerURL:  "https://oidc.example.com/" + group,
+			clusterTTL: time.Hour,
+			logger:     slog.Default(),
+		}).aggregate(ctx)
+		if group == "prod" {
+			topLeader = groupLeader
+		}
+	}
+
+	// Second level: the prod leader merges every group's root JWKS
+	poller, err := newGroupAggregationPoller(topLeader, &config.Config{
+		Controller: config.ControllerConfig{ClusterGroup: "prod"},
+	}, time.Minute, slog.Default())
+	require.NoError(t, err)
+	assert.Equal(t, "https://oidc.example.com", poller.issuerURL)
+	poller.aggregate(ctx)
+
+	data, ok := bucket.Get("openid/v1/jwks")
+	require.True(t, ok, "top-level JWKS should be published at the storage root")
+	var jwks bridge.JWKS
+	require.NoError(t, json.Unmarshal(data, &jwks))
+	kids := make([]string, 0, len(jwks.Keys))
+	for _, key := range jwks.Keys {
+		kids = append(kids, key.Kid)
+	}
+	assert.ElementsMatch(t, []string{"key-p1", "key-p2", "key-s1", "key-s2", "shared"}, kids)
+
+	data, ok = bucket.Get(".well-known/openid-configuration")
+	require.True(t, ok, "top-level discovery document should be published")
+	var discovery bridge.DiscoveryDocument
+	require.NoError(t, json.Unmarshal(data, &discovery))
+	assert.Equal(t, "https://oidc.example.com", discovery.Issuer)
+	assert.Equal(t, "https://oidc.example.com/openid/v1/jwks", discovery.JWKSURI)
+}
+
+func TestGroupAggregationPoller_PrunesStaleGroups(t *testing.T) {
+	ctx := context.Background()
+	bucket := memory.NewBucket()
+	now := time.Now()
+
+	for group, age := range map[string]time.Duration{"prod": time.Hour, "legacy": 200 * time.Hour} {
+		pub, err := memory.New(memory.Config{PublicURL: "https://oidc.example.com/" + group, Prefix: group}, bucket)
+		require.NoError(t, err)
+		pub.SetTimeFunc(func() time.Time { return now.Add(-age) })
+		require.NoError(t, pub.PublishAggregatedJWKS(ctx, makeJWKS("key-"+group)))
+	}
+
+	leader, err := memory.New(memory.Config{PublicURL: "https://oidc.example.com/prod", Prefix: "prod"}, bucket)
+	require.NoError(t, err)
+	(&groupAggregationPoller{
+		aggregator: leader,
+		issuerURL:  "https://oidc.example.com",
+		groupTTL:   96 * time.Hour,
+		logger:     slog.Default(),
+	}).aggregate(ctx)
+
+	data, ok := bucket.Get("openid/v1/jwks")
+	require.True(t, ok)
+	var jwks bridge.JWKS
+	require.NoError(t, json.Unmarshal(data, &jwks))
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "key-prod", jwks.Keys[0].Kid)
+}
+
+func TestGroupAggregationPoller_DuplicateKidWinner(t *testing.T) {
+	ctx := context.Background()
+	bucket := memory.NewBucket()
+
+	// Both groups publish a key with the same kid; the first group by name wins
+	for _, group := range []string{"staging", "prod"} {
+		pub, err := memory.New(memory.Config{PublicURL: "https://oidc.example.com/" + group, Prefix: group}, bucket)
+		require.NoError(t, err)
+		require.NoError(t, pub.PublishAggregatedJWKS(ctx, &bridge.JWKS{Keys: []bridge.JWK{{Kid: "shared", Kty: "RSA", N: group}}}))
+	}
+
+	leader, err := memory.New(memory.Config{PublicURL: "https://oidc.example.com/prod", Prefix: "prod"}, bucket)
+	require.NoError(t, err)
+	poller := &groupAggregationPoller{
+		aggregator: leader,
+		issuerURL:  "https://oidc.example.com",
+		groupTTL:   96 * time.Hour,
+		logger:     slog.Default(),
+	}
+	for range 10 {
+		poller.aggregate(ctx)
+
+		data, ok := bucket.Get("openid/v1/jwks")
+		require.True(t, ok)
+		var jwks bridge.JWKS
+		require.NoError(t, json.Unmarshal(data, &jwks))
+		require.Len(t, jwks.Keys, 1)
+		assert.Equal(t, "prod", jwks.Keys[0].N)
+	}
+}
+
+// validatingPublisher fails Validate until failures have been used up.
+type validatingPublisher struct {
+	iface.Publisher
+	failures int
+	calls    int
+}
+
+func (p *validatingPublisher) Validate(context.Context) error {
+	p.calls++
+	if p.calls <= p.failures {
+		return errors.New("bucket not reachable")
+	}
+	return nil
+}
+
+func TestStartupValidation(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int
+		strict       bool
+		wantErr      bool
+		wantDegraded bool
+		wantCalls    int
+	}{
+		{name: "succeeds after retries", failures: 2, wantCalls: 3},
+		{name: "give up strict", failures: 10, strict: true, wantErr: true, wantCalls: 3},
+		{name: "give up degraded", failures: 10, wantDegraded: true, wantCalls: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := newStartupValidation(config.StartupValidationConfig{
+				MaxAttempts:    3,
+				InitialBackoff: "1ms",
+				MaxBackoff:     "2ms",
+				Strict:         tt.strict,
+			}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			require.NoError(t, err)
+
+			pub := &validatingPublisher{failures: tt.failures}
+			degraded, err := v.validate(t.Context(), pub)
+			if tt.wantErr {
+				// initializeComponents returns the error and main exits non-zero
+				assert.ErrorContains(t, err, "gave up after 3 attempts")
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantDegraded, degraded)
+			assert.Equal(t, tt.wantCalls, pub.calls)
+		})
+	}
+}
+
+func TestStartupValidation_TimeoutStopsRetries(t *testing.T) {
+	v, err := newStartupValidation(config.StartupValidationConfig{
+		MaxAttempts:    100,
+		InitialBackoff: "20ms",
+		Timeout:        "30ms",
+		Strict:         true,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+
+	pub := &validatingPublisher{failures: 100}
+	_, err = v.validate(t.Context(), pub)
+	require.Error(t, err)
+	assert.Less(t, pub.calls, 100)
+}
+
+// closingPublisher records Close calls.
+type closingPublisher struct {
+	iface.Publisher
+	closes   int
+	closeErr error
+}
+
+func (p *closingPublisher) Close() error {
+	p.closes++
+	return p.closeErr
+}
+
+func (p *closingPublisher) Type() iface.PublisherType { return iface.PublisherTypeGCS }
+
+func TestClosePublisher(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	pub := &closingPublisher{}
+	closePublisher(pub, logger)
+	assert.Equal(t, 1, pub.closes)
+
+	// A failing Close is logged, not fatal
+	failing := &closingPublisher{closeErr: errors.New("connection reset")}
+	closePublisher(failing, logger)
+	assert.Equal(t, 1, failing.closes)
+}
+
+func TestInitializeFaultInjection(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	path := filepath.Join(t.TempDir(), "faults.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"faults":[{"operation":"publish","mode":"error","count":1}]}`), 0o600))
+
+	faults, err := initializeFaultInjection(false, path, logger)
+	require.NoError(t, err)
+	assert.Nil(t, faults, "the config alone must not enable fault injection")
+
+	_, err = initializeFaultInjection(true, "", logger)
+	assert.Error(t, err)
+
+	faults, err = initializeFaultInjection(true, path, logger)
+	require.NoError(t, err)
+	pub := faults.WrapPublisher(&closingPublisher{})
+	assert.ErrorIs(t, pub.Publish(context.Background(), &bridge.DiscoveryDocument{}, &bridge.JWKS{}), faultinject.ErrInjected)
+}
+
+func TestParseMetricsServing(t *testing.T) {
+	tests := []struct {
+		name        string
+		bindAddress string
+		certDir     string
+		wantAddress string
+		wantSecure  bool
+		wantSocket  string
+		wantErr     bool
+	}{
+		{name: "plain address", bindAddress: ":8080", wantAddress: ":8080"},
+		{name: "http scheme", bindAddress: "http://:8080", wantAddress: ":8080"},
+		{name: "https scheme", bindAddress: "https://:8443", wantAddress: ":8443", wantSecure: true},
+		{name: "cert dir selects secure serving", bindAddress: ":8443", certDir: "/etc/metrics-certs", wantAddress: ":8443", wantSecure: true},
+		{name: "unix socket", bindAddress: "unix:///var/run/kubeassume/metrics.sock", wantAddress: "0", wantSocket: "/var/run/kubeassume/metrics.sock"},
+		{name: "unix socket without path", bindAddress: "unix://", wantErr: true},
+		{name: "unix socket with TLS", bindAddress: "unix:///tmp/metrics.sock", certDir: "/etc/metrics-certs", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serving, err := parseMetricsServing(tt.bindAddress, tt.certDir)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAddress, serving.options.BindAddress)
+			assert.Equal(t, tt.wantSecure, serving.options.SecureServing)
+			assert.Equal(t, tt.certDir, serving.options.CertDir)
+			assert.Equal(t, tt.wantSocket, serving.socketPath)
+			assert.Equal(t, tt.wantSecure, serving.options.FilterProvider != nil, "secure serving requires authorization")
+		})
+	}
+}
+
+func TestUnixMetricsServer(t *testing.T) {
+	// Unix socket paths are limited to about 100 bytes, which t.TempDir can exceed
+	dir, err := os.MkdirTemp("", "metrics")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+	path := filepath.Join(dir, "metrics.sock")
+
+	server := &unixMetricsServer{
+		path: path,
+		handlers: map[string]http.Handler{"/events": http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = io.WriteString(w, "events")
+		})},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Start(ctx) }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	get := func(url string) (int, string) {
+		resp, err := client.Get(url)
+		if err != nil {
+			return 0, ""
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	require.Eventually(t, func() bool {
+		status, _ := get("http://metrics/metrics")
+		return status == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+	status, body := get("http://metrics/events")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "events", body)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"github.com/hixichen/kube-iam-assume/api/v1alpha1"
+)
+
+// EventReasonInvalidIssuerConfig is the event reason for an IssuerConfig whose spec cannot be applied.
+const EventReasonInvalidIssuerConfig = "InvalidIssuerConfig"
+
+// RuntimeConfig holds the settings an IssuerConfig resource overrides while the
+// controller runs. It is safe for concurrent use; a nil RuntimeConfig overrides nothing.
+type RuntimeConfig struct {
+	mu        sync.RWMutex
+	issuerURL string
+	audiences []string
+}
+
+// IssuerURL returns the overriding issuer URL, or fallback when none is set.
+func (c *RuntimeConfig) IssuerURL(fallback string) string {
+	if c == nil {
+		return fallback
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.issuerURL == "" {
+		return fallback
+	}
+	return c.issuerURL
+}
+
+// Audiences returns the overriding federation audiences, or fallback when none are set.
+func (c *RuntimeConfig) Audiences(fallback []string) []string {
+	if c == nil {
+		return fallback
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if len(c.audiences) == 0 {
+		return fallback
+	}
+	return c.audiences
+}
+
+// set replaces the overrides and reports whether they changed.
+func (c *RuntimeConfig) set(issuerURL string, audiences []string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.issuerURL == issuerURL && slices.Equal(c.audiences, audiences) {
+		return false
+	}
+	c.issuerURL, c.audiences = issuerURL, slices.Clone(audiences)
+	return true
+}
+
+// IssuerConfigReconciler applies the IssuerConfig resource named Name in Namespace
+// to RuntimeConfig. Other IssuerConfig resources are ignored. An invalid spec is
+// reported on the resource and leaves the previous settings in place; deleting the
+// resource restores the file configuration.
+type IssuerConfigReconciler struct {
+	client.Client
+	Recorder record.EventRecorder
+
+	Name      string
+	Namespace string
+
+	RuntimeConfig *RuntimeConfig
+	// OnChange is called after the effective settings changed, e.g. to republish (nil disables)
+	OnChange func()
+
+	Logger *slog.Logger
+}
+
+// Reconcile applies the IssuerConfig to RuntimeConfig and records the outcome in its status.
+func (r *IssuerConfigReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var ic v1alpha1.IssuerConfig
+	if err := r.Get(ctx, req.NamespacedName, &ic); err != nil {
+		if !errors.IsNotFound(err) {
+			return ctrl.Result{}, fmt.Errorf("failed to get IssuerConfig: %w", err)
+		}
+		if r.RuntimeConfig.set("", nil) {
+			r.Logger.Info("IssuerConfig deleted, using the file configuration", "name", req.Name)
+			r.changed()
+		}
+		return ctrl.Result{}, nil
+	}
+
+	condition := metav1.Condition{
+		Type:               v1alpha1.IssuerConfigConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Applied",
+		Message:            "IssuerConfig is applied",
+		ObservedGeneration: ic.Generation,
+	}
+	if err := validateIssuerConfigSpec(&ic.Spec); err != nil {
+		r.Logger.Error("invalid IssuerConfig, keeping the previous settings", "name", ic.Name, "error", err)
+		r.Recorder.Event(&ic, corev1.EventTypeWarning, EventReasonInvalidIssuerConfig, err.Error())
+		condition.Status, condition.Reason, condition.Message = metav1.ConditionFalse, "Invalid", err.Error()
+	} else if r.RuntimeConfig.set(ic.Spec.IssuerURL, ic.Spec.Audiences) {
+		r.Logger.Info("applied IssuerConfig", "name", ic.Name, "issuer_url", ic.Spec.IssuerURL, "audiences", ic.Spec.Audiences)
+		r.changed()
+	}
+
+	statusChanged := meta.SetStatusCondition(&ic.Status.Conditions, condition)
+	if !statusChanged && ic.Status.ObservedGeneration == ic.Generation {
+		return ctrl.Result{}, nil
+	}
+	ic.Status.ObservedGeneration = ic.Generation
+	if err := r.Status().Update(ctx, &ic); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update IssuerConfig status: %w", err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// changed calls OnChange when it is set.
+func (r *IssuerConfigReconciler) changed() {
+	if r.OnChange != nil {
+		r.OnChange()
+	}
+}
+
+// validateIssuerConfigSpec checks that spec can be applied.
+func validateIssuerConfigSpec(spec *v1alpha1.IssuerConfigSpec) error {
+	if spec.IssuerURL != "" {
+		u, err := url.Parse(spec.IssuerURL)
+		if err != nil {
+			return fmt.Errorf("invalid issuerURL: %w", err)
+		}
+		if u.Scheme != "https" || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+			return fmt.Errorf("issuerURL %q must be an https URL without query or fragment", spec.IssuerURL)
+		}
+	}
+	for i, audience := range spec.Audiences {
+		if strings.TrimSpace(audience) == "" {
+			return fmt.Errorf("audiences[%d] must not be empty", i)
+		}
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *IssuerConfigReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("issuerconfig").
+		For(&v1alpha1.IssuerConfig{}, builder.WithPredicates(
+			predicate.GenerationChangedPredicate{},
+			predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetName() == r.Name && obj.GetNamespace() == r.Namespace
+			}),
+		)).
+		Complete(r)
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/hixichen/kube-iam-assume/api/v1alpha1"
+)
+
+func TestIssuerConfigReconciler_UpdatesEffectiveConfig(t *testing.T) {
+	ctx := t.Context()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	ic := &v1alpha1.IssuerConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "kube-iam-assume", Namespace: testNamespace, Generation: 1},
+		Spec: v1alpha1.IssuerConfigSpec{
+			IssuerURL: "https://oidc.cdn.example.com",
+			Audiences: []string{"sts.amazonaws.com"},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ic).WithStatusSubresource(ic).Build()
+
+	pub := &fakePublisher{}
+	bridgeRec := newTestReconciler(t, pub, metadataConfigMap(testDiscoveryJSON(), testJWKSJSON()))
+	bridgeRec.RuntimeConfig = &RuntimeConfig{}
+	changes := 0
+	rec := &IssuerConfigReconciler{
+		Client:        c,
+		Recorder:      record.NewFakeRecorder(10),
+		Name:          "kube-iam-assume",
+		Namespace:     testNamespace,
+		RuntimeConfig: bridgeRec.RuntimeConfig,
+		OnChange:      func() { changes++ },
+		Logger:        testLogger(),
+	}
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: "kube-iam-assume", Namespace: testNamespace}}
+	fileAudiences := []string{"file-audience"}
+
+	// The resource overrides the file configuration
+	_, err := rec.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changes)
+	assert.Equal(t, "https://oidc.cdn.example.com", bridgeRec.publicIssuerURL())
+	assert.Equal(t, []string{"sts.amazonaws.com"}, rec.RuntimeConfig.Audiences(fileAudiences))
+	assertIssuerConfigReady(t, c, req, metav1.ConditionTrue, 1)
+
+	_, err = bridgeRec.Reconcile(ctx, metadataRequest())
+	require.NoError(t, err)
+	require.NotNil(t, pub.discovery)
+	assert.Equal(t, "https://oidc.cdn.example.com", pub.discovery.Issuer)
+
+	// A spec change updates the effective configuration
+	require.NoError(t, c.Get(ctx, req.NamespacedName, ic))
+	ic.Spec.IssuerURL = "https://oidc2.cdn.example.com"
+	ic.Spec.Audiences = []string{"sts.amazonaws.com", "vault"}
+	ic.Generation = 2
+	require.NoError(t, c.Update(ctx, ic))
+	_, err = rec.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 2, changes)
+	assert.Equal(t, "https://oidc2.cdn.example.com", bridgeRec.publicIssuerURL())
+	assert.Equal(t, []string{"sts.amazonaws.com", "vault"}, rec.RuntimeConfig.Audiences(fileAudiences))
+	assertIssuerConfigReady(t, c, req, metav1.ConditionTrue, 2)
+
+	// An invalid spec is reported and keeps the previous settings
+	require.NoError(t, c.Get(ctx, req.NamespacedName, ic))
+	ic.Spec.IssuerURL = "http://oidc.example.com"
+	ic.Generation = 3
+	require.NoError(t, c.Update(ctx, ic))
+	_, err = rec.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 2, changes)
+	assert.Equal(t, "https://oidc2.cdn.example.com", bridgeRec.publicIssuerURL())
+	assertIssuerConfigReady(t, c, req, metav1.ConditionFalse, 3)
+
+	// Deleting the resource restores the file configuration
+	require.NoError(t, c.Delete(ctx, ic))
+	_, err = rec.Reconcile(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, 3, changes)
+	assert.Equal(t, "https://oidc.example.com", bridgeRec.publicIssuerURL())
+	assert.Equal(t, fileAudiences, rec.RuntimeConfig.Audiences(fileAudiences))
+}
+
+// assertIssuerConfigReady checks the Ready condition of the IssuerConfig named by req.
+func assertIssuerConfigReady(t *testing.T, c client.Client, req ctrl.Request, status metav1.ConditionStatus, generation int64) {
+	t.Helper()
+	var ic v1alpha1.IssuerConfig
+	require.NoError(t, c.Get(t.Context(), req.NamespacedName, &ic))
+	ready := meta.FindStatusCondition(ic.Status.Conditions, v1alpha1.IssuerConfigConditionReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, status, ready.Status)
+	assert.Equal(t, generation, ic.Status.ObservedGeneration)
+}
+
+func TestRuntimeConfig_NilUsesFallback(t *testing.T) {
+	var c *RuntimeConfig
+	assert.Equal(t, "https://oidc.example.com", c.IssuerURL("https://oidc.example.com"))
+	assert.Equal(t, []string{"aud"}, c.Audiences([]string{"aud"}))
+}
@@ -2,26 +2,40 @@
 package controller
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"maps"
+	"net/http"
 	"os"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	crcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/hixichen/kube-iam-assume/pkg/bridge"
 	"github.com/hixichen/kube-iam-assume/pkg/constants"
+	"github.com/hixichen/kube-iam-assume/pkg/eventstream"
 	"github.com/hixichen/kube-iam-assume/pkg/health"
 	"github.com/hixichen/kube-iam-assume/pkg/metrics"
 	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
@@ -37,6 +51,14 @@ const (
 	EventReasonSyncFailed = "SyncFailed"
 	// EventReasonKeyRotation is the event reason for key rotation.
 	EventReasonKeyRotation = "KeyRotation"
+	// EventReasonPublicReadFailed is the event reason for published metadata that is not publicly readable.
+	EventReasonPublicReadFailed = "PublicReadFailed"
+	// EventReasonActiveKeysChanged is the event reason for a change in the set of published key IDs.
+	EventReasonActiveKeysChanged = "ActiveKeysChanged"
+	// EventReasonFederationDrift is the event reason for a federation provider that is missing or no longer matches the issuer.
+	EventReasonFederationDrift = "FederationDrift"
+	// EventReasonFederationIssuerMismatch is the event reason for a pinned federation provider that trusts a different issuer than the published one.
+	EventReasonFederationIssuerMismatch = "FederationIssuerMismatch"
 )
 
 // Config holds configuration for the controller.
@@ -49,6 +71,47 @@ type Config struct {
 	PublicIssuerURL string
 	// MultiClusterEnabled indicates that multi-cluster shared issuer mode is active
 	MultiClusterEnabled bool
+	// SigningKeysOnly drops keys whose use is set and is not "sig"
+	SigningKeysOnly bool
+	// RequireKeyAlg drops keys that have no alg
+	RequireKeyAlg bool
+	// RejectDuplicateKeyIDs skips publishing a source JWKS that has different keys
+	// with the same kid; otherwise the conflict is only logged
+	RejectDuplicateKeyIDs bool
+	// MaxConcurrentReconciles is the number of reconcile workers (values below 1 mean 1).
+	// Reconciles only run on the elected leader, and controller-runtime never reconciles
+	// the same ConfigMap concurrently, so extra workers only help when the event filter
+	// admits more than one object.
+	MaxConcurrentReconciles int
+	// RepublishInterval re-uploads the current metadata this often even when it is
+	// unchanged, to refresh CDN caches that ignore max-age (0 disables)
+	RepublishInterval time.Duration
+	// FailClosedOnPrivate probes the published discovery document anonymously after
+	// each publish and reports not ready while it is not publicly readable
+	FailClosedOnPrivate bool
+	// PublishOnChange skips uploads whose content matches the last publish. The content
+	// hash is persisted in the rotation state, so it also survives controller restarts.
+	PublishOnChange bool
+	// Claims customizes claims_supported in the published discovery document
+	Claims bridge.ClaimsCustomization
+	// DiscoveryExtraFields are extra top-level fields added to the published discovery document
+	DiscoveryExtraFields map[string]interface{}
+	// MetadataWaitInterval is how often a missing OIDC metadata ConfigMap is
+	// re-checked while waiting for the OIDC poller to write it (0 disables waiting)
+	MetadataWaitInterval time.Duration
+	// MetadataWaitAttempts bounds how many times a missing ConfigMap is re-checked
+	// before waiting for the watch instead
+	MetadataWaitAttempts int
+	// MetadataMaxAge forces a bridge refetch and republish when the OIDC poller has not
+	// refreshed the metadata ConfigMap for this long, e.g. because it is stalled (0 disables)
+	MetadataMaxAge time.Duration
+	// MinifyJWKS and MinifyDiscovery mirror the publisher settings of the same name,
+	// so the published size metrics measure the uploaded bytes
+	MinifyJWKS      bool
+	MinifyDiscovery bool
+	// PublishFormat identifies publisher settings that change the uploaded objects
+	// (key layout, minification) so that changing them forces a re-upload
+	PublishFormat string
 }
 
 // DefaultConfig returns a Config with sensible defaults.
@@ -71,17 +134,49 @@ type OIDCBridgeReconciler struct {
 	RotationManager rotation.Manager
 	Health          *health.Health
 	Metrics         *metrics.Metrics
+	// Events streams rotation, publish and sync events to operators (nil disables)
+	Events *eventstream.Broker
+	// RuntimeConfig overrides the public issuer URL from an IssuerConfig resource (nil disables)
+	RuntimeConfig *RuntimeConfig
 
 	// Configuration
 	Config Config
 	Logger *slog.Logger
 
+	// HTTPClient is used for the public-read probe (nil uses http.DefaultClient)
+	HTTPClient *http.Client
+
 	// Internal state
 	kubeClient kubernetes.Interface
-	lastSync   time.Time
+	nowFunc    func() time.Time // For testing
+	// lastSync is the time of the last successful sync; reconciles may run concurrently
+	lastSync atomic.Pointer[time.Time]
+	// firstPublishDone is set once the first publish has succeeded
+	firstPublishDone atomic.Bool
+	// publicReadFailed is set while the fail-closed public-read probe is failing
+	publicReadFailed atomic.Bool
+	// metadataWaits counts consecutive reconciles that found no metadata ConfigMap
+	metadataWaits atomic.Int32
+	// resync enqueues a sync of the OIDC metadata ConfigMap; set by SetupWithManager
+	resync chan event.GenericEvent
+
+	// publishGate serializes and coalesces uploads within this replica
+	publishGate publishGate
+
+	// publishMu guards the PublishOnChange state below
+	publishMu           sync.Mutex
+	publishedHash       string
+	publishedHashLoaded bool
+	lastUpload          time.Time
+
+	// keyIDsMu guards the key IDs published by the last successful sync
+	keyIDsMu       sync.Mutex
+	activeKeyIDs   []string
+	keyIDsRecorded bool
 }
 
-// NewOIDCBridgeReconciler creates a new reconciler.
+// NewOIDCBridgeReconciler creates a new reconciler. Its metrics are registered
+// with the controller-runtime registry, which the metrics servers expose.
 func NewOIDCBridgeReconciler(
 	c client.Client,
 	scheme *runtime.Scheme,
@@ -102,7 +197,8 @@ func NewOIDCBridgeReconciler(
 		Config:          cfg,
 		Logger:          logger,
 		Health:          health.New(logger),
-		Metrics:         metrics.New(),
+		Metrics:         metrics.New(ctrlmetrics.Registry),
+		nowFunc:         time.Now,
 	}
 }
 
@@ -114,11 +210,11 @@ func (r *OIDCBridgeReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	var cm corev1.ConfigMap
 	if err := r.Get(ctx, req.NamespacedName, &cm); err != nil {
 		if errors.IsNotFound(err) {
-			r.Logger.Info("OIDC metadata ConfigMap not found, skipping reconciliation", "name", req.Name, "namespace", req.Namespace)
-			return ctrl.Result{}, nil
+			return r.waitForMetadata(req), nil
 		}
 		return ctrl.Result{}, fmt.Errorf("failed to get OIDC metadata ConfigMap: %w", err)
 	}
+	r.metadataWaits.Store(0)
 
 	// Unmarshal discovery document
 	discoveryData, ok := cm.Data["discovery.json"]
@@ -140,26 +236,64 @@ func (r *OIDCBridgeReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, fmt.Errorf("failed to unmarshal jwks.json from ConfigMap: %w", err)
 	}
 
+	// Refetch instead of republishing metadata the OIDC poller stopped refreshing
+	lastFetch, refetched := r.refetchStaleMetadata(ctx, &cm)
+	if refetched != nil {
+		discovery, jwks = *refetched.Discovery, *refetched.JWKS
+	}
+
+	// Rotation merges keys by kid, which would hide a conflicting duplicate;
+	// later keys with a repeated kid are dropped
+	if err := bridge.ValidateUniqueKeyIDs(&jwks); err != nil {
+		if r.Config.RejectDuplicateKeyIDs {
+			r.Logger.Error("refusing to publish source JWKS", "error", err)
+			if pod, podErr := r.getControllerPod(ctx); podErr == nil && pod != nil {
+				r.Recorder.Eventf(pod, corev1.EventTypeWarning, EventReasonSyncFailed, "Refusing to publish source JWKS: %v", err)
+			}
+			r.Metrics.RecordSync("error")
+			r.streamSyncResult(err)
+			return ctrl.Result{}, nil
+		}
+		r.Logger.Warn("source JWKS has conflicting keys; only the first key per kid is published", "error", err)
+	}
+	bridge.DedupeKeyIDs(&jwks)
+
+	// Drop keys relying parties should not see before they enter rotation state
+	filtered := r.filterKeys(&jwks)
+
 	// 1. Process rotation (detect key changes, merge overlap keys)
-	mergedJWKS, events, err := r.processRotation(ctx, &jwks)
+	mergedJWKS, events, err := r.processRotation(ctx, filtered)
 	if err != nil {
 		r.Logger.Error("failed to process rotation", "error", err)
+		r.streamSyncResult(err)
 		return ctrl.Result{Requeue: true}, nil
 	}
 
+	// Relying parties skip keys whose algorithm the discovery document does not list
+	if err := bridge.ValidateSigningAlgs(&discovery, mergedJWKS); err != nil {
+		r.Logger.Warn("published JWKS has keys relying parties will not use", "error", err)
+	}
+
 	// 2. Emit K8s events for each rotation event
 	for _, event := range events {
 		r.emitRotationEvent(event)
+		r.Events.Publish(eventstream.FromRotation(event))
 	}
 
 	// 3. Transform discovery document and publish
 	if err := r.publish(ctx, &discovery, mergedJWKS); err != nil {
 		r.Logger.Error("failed to publish OIDC metadata", "error", err)
 		r.Metrics.RecordPublishError(string(r.Publisher.Type()))
+		r.streamSyncResult(err)
 		return ctrl.Result{Requeue: true}, nil
 	}
 
-	// 4. Update active keys metric
+	// 4. Fail closed when the published metadata is not publicly readable
+	if err := r.checkPublicRead(ctx); err != nil {
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	// 5. Update active keys metric
 	r.Metrics.SetActiveKeys(len(mergedJWKS.Keys))
 
 	r.Logger.Info("Sync completed successfully",
@@ -168,12 +302,68 @@ func (r *OIDCBridgeReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 	)
 
 	r.Metrics.RecordSync("success")
-	r.lastSync = time.Now()
+	r.streamSyncResult(nil)
+	syncedAt := r.now()
+	r.lastSync.Store(&syncedAt)
+	r.firstPublishDone.Store(true)
 	if pod, podErr := r.getControllerPod(ctx); podErr == nil && pod != nil {
 		r.Recorder.Eventf(pod, corev1.EventTypeNormal, EventReasonSynced, "OIDC metadata synced successfully")
 	}
+	r.recordActiveKeyIDs(ctx, mergedJWKS)
 
-	return ctrl.Result{}, nil
+	// Requeue to republish unchanged metadata when the CDN refresh timer is enabled,
+	// and to check the metadata again when it turns stale
+	return ctrl.Result{RequeueAfter: r.requeueAfter(lastFetch)}, nil
+}
+
+// refetchStaleMetadata fetches the OIDC metadata from the API server when the
+// ConfigMap was last fetched more than MetadataMaxAge ago, or has no fetch time.
+// The fetch also rewrites the ConfigMap. It returns the time of the latest fetch
+// and the refetched metadata, which is nil when the ConfigMap is fresh or the
+// refetch failed.
+func (r *OIDCBridgeReconciler) refetchStaleMetadata(ctx context.Context, cm *corev1.ConfigMap) (time.Time, *bridge.FetchResult) {
+	lastFetch, _ := bridge.LastFetchTime(cm)
+	if r.Config.MetadataMaxAge <= 0 {
+		return lastFetch, nil
+	}
+	age := r.now().Sub(lastFetch)
+	if age <= r.Config.MetadataMaxAge {
+		return lastFetch, nil
+	}
+
+	r.Logger.Warn("OIDC metadata ConfigMap is stale, refetching from the API server",
+		"lastFetch", lastFetch,
+		"maxAge", r.Config.MetadataMaxAge,
+	)
+	// A stale ConfigMap must be replaced by what the API server serves now
+	if cache, ok := r.Bridge.(bridge.CacheInvalidator); ok {
+		cache.InvalidateCache()
+	}
+	result, err := r.Bridge.Fetch(ctx)
+	if err != nil {
+		r.Logger.Error("failed to refetch stale OIDC metadata, publishing the ConfigMap content", "error", err)
+		return lastFetch, nil
+	}
+	return r.now(), result
+}
+
+// requeueAfter returns the delay before the next timer-driven reconcile: the
+// republish interval, or the time until metadata fetched at lastFetch turns stale
+// when that comes first. Metadata that is still stale after a failed refetch is
+// checked again after a sync period.
+func (r *OIDCBridgeReconciler) requeueAfter(lastFetch time.Time) time.Duration {
+	delay := r.Config.RepublishInterval
+	if r.Config.MetadataMaxAge <= 0 {
+		return delay
+	}
+	staleIn := r.Config.MetadataMaxAge - r.now().Sub(lastFetch)
+	if staleIn <= 0 {
+		staleIn = cmp.Or(r.Config.SyncPeriod, DefaultSyncPeriod)
+	}
+	if delay <= 0 || staleIn < delay {
+		delay = staleIn
+	}
+	return delay
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -193,21 +383,37 @@ func (r *OIDCBridgeReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		Named("oidcbridge").
 		For(&corev1.ConfigMap{}).
+		WatchesRawSource(r.initialSyncSource()).
 		WithEventFilter(r.oidcMetadataConfigMapFilter()).
+		WithOptions(r.controllerOptions()).
 		Complete(r)
 }
 
+// controllerOptions returns the controller-runtime options derived from Config.
+func (r *OIDCBridgeReconciler) controllerOptions() crcontroller.Options {
+	return crcontroller.Options{
+		MaxConcurrentReconciles: max(r.Config.MaxConcurrentReconciles, 1),
+	}
+}
+
 func (r *OIDCBridgeReconciler) oidcMetadataConfigMapFilter() predicate.Predicate {
 	return predicate.Funcs{
 		CreateFunc: func(e event.CreateEvent) bool {
 			return r.isOIDCConfigMap(e.Object)
 		},
+		// The OIDC poller records every fetch, so updates that only change the fetch
+		// time are skipped to avoid republishing on every poll.
 		UpdateFunc: func(e event.UpdateEvent) bool {
-			return r.isOIDCConfigMap(e.ObjectNew)
+			return r.isOIDCConfigMap(e.ObjectNew) && !onlyLastFetchChanged(e.ObjectOld, e.ObjectNew)
 		},
 		DeleteFunc: func(e event.DeleteEvent) bool {
 			return r.isOIDCConfigMap(e.Object)
 		},
+		// Generic events come from initialSyncSource, which applies this filter
+		// itself: WithEventFilter does not reach raw sources.
+		GenericFunc: func(e event.GenericEvent) bool {
+			return r.isOIDCConfigMap(e.Object)
+		},
 	}
 }
 
@@ -215,6 +421,51 @@ func (r *OIDCBridgeReconciler) isOIDCConfigMap(obj client.Object) bool {
 	return obj.GetName() == constants.DefaultOIDCConfigMapName && obj.GetNamespace() == r.Config.Namespace
 }
 
+// onlyLastFetchChanged reports whether two versions of a ConfigMap differ only in
+// the last-fetch annotation.
+func onlyLastFetchChanged(oldObj, newObj client.Object) bool {
+	oldCM, ok := oldObj.(*corev1.ConfigMap)
+	if !ok {
+		return false
+	}
+	newCM, ok := newObj.(*corev1.ConfigMap)
+	if !ok {
+		return false
+	}
+	if oldCM.Annotations[constants.OIDCLastFetchAnnotation] == newCM.Annotations[constants.OIDCLastFetchAnnotation] {
+		return false
+	}
+	if !maps.Equal(oldCM.Data, newCM.Data) || !maps.Equal(oldCM.Labels, newCM.Labels) {
+		return false
+	}
+	oldAnnotations := maps.Clone(oldCM.Annotations)
+	newAnnotations := maps.Clone(newCM.Annotations)
+	delete(oldAnnotations, constants.OIDCLastFetchAnnotation)
+	delete(newAnnotations, constants.OIDCLastFetchAnnotation)
+	return maps.Equal(oldAnnotations, newAnnotations)
+}
+
+// filterKeys removes keys rejected by the SigningKeysOnly and RequireKeyAlg options.
+func (r *OIDCBridgeReconciler) filterKeys(jwks *bridge.JWKS) *bridge.JWKS {
+	if !r.Config.SigningKeysOnly && !r.Config.RequireKeyAlg {
+		return jwks
+	}
+
+	filtered := &bridge.JWKS{Keys: make([]bridge.JWK, 0, len(jwks.Keys))}
+	for _, key := range jwks.Keys {
+		if r.Config.SigningKeysOnly && key.Use != "" && key.Use != "sig" {
+			r.Logger.Info("Dropping non-signing key", "kid", key.Kid, "use", key.Use)
+			continue
+		}
+		if r.Config.RequireKeyAlg && key.Alg == "" {
+			r.Logger.Info("Dropping key without alg", "kid", key.Kid)
+			continue
+		}
+		filtered.Keys = append(filtered.Keys, key)
+	}
+	return filtered
+}
+
 // processRotation handles key rotation detection and merging.
 func (r *OIDCBridgeReconciler) processRotation(ctx context.Context, jwks *bridge.JWKS) (*bridge.JWKS, []rotation.Event, error) {
 	// Process JWKS through rotation manager
@@ -238,34 +489,95 @@ func (r *OIDCBridgeReconciler) processRotation(ctx context.Context, jwks *bridge
 
 // publish publishes the OIDC metadata to the configured backend.
 func (r *OIDCBridgeReconciler) publish(ctx context.Context, discovery *bridge.DiscoveryDocument, jwks *bridge.JWKS) error {
-	publishStart := time.Now()
-
 	// Transform discovery document with public issuer URL
-	transformed, err := bridge.TransformDiscoveryDocument(discovery, r.Config.PublicIssuerURL)
+	transformed, err := bridge.TransformDiscoveryDocument(discovery, r.publicIssuerURL())
 	if err != nil {
 		return fmt.Errorf("failed to transform discovery document: %w", err)
 	}
+	transformed.ClaimsSupported = r.Config.Claims.Apply(transformed.ClaimsSupported)
+	transformed.Extra = r.Config.DiscoveryExtraFields
+
+	// Record document sizes so bloat (too many or duplicated keys) can be alerted on
+	r.recordPublishedSizes(transformed, jwks)
+
+	// Concurrent reconciles must not race uploads to the same objects
+	return r.publishGate.do(ctx, transformed, jwks, r.upload)
+}
+
+// upload writes the transformed documents to the configured backend.
+// Calls are serialized by publishGate.
+func (r *OIDCBridgeReconciler) upload(ctx context.Context, transformed *bridge.DiscoveryDocument, jwks *bridge.JWKS) error {
+	publishStart := r.now()
+
+	// Skip the upload when the content matches the last publish
+	var hash string
+	var err error
+	if r.Config.PublishOnChange {
+		hash, err = r.contentHash(transformed, jwks)
+		if err != nil {
+			return err
+		}
+		if r.unchangedSincePublish(ctx, hash) {
+			r.Logger.Debug("OIDC metadata unchanged since last publish, skipping upload", "hash", hash)
+			return nil
+		}
+	}
 
 	// Publish to configured backend
 	if err := r.Publisher.Publish(ctx, transformed, jwks); err != nil {
 		r.Metrics.RecordPublishError(string(r.Publisher.Type()))
 		return fmt.Errorf("failed to publish: %w", err)
 	}
+	if r.Config.PublishOnChange {
+		r.recordPublishedHash(ctx, hash)
+	}
 
 	// Record publish duration and timestamp
-	publishDuration := time.Since(publishStart).Seconds()
+	publishDuration := r.now().Sub(publishStart).Seconds()
 	r.Metrics.RecordSyncDuration("publish", publishDuration)
-	r.Metrics.RecordPublish(float64(time.Now().Unix()))
+	r.Metrics.RecordPublish(float64(r.now().Unix()))
+
+	r.Events.Publish(eventstream.Event{
+		Type:      eventstream.TypePublish,
+		Timestamp: r.now(),
+		Message:   fmt.Sprintf("published %d keys to %s", len(jwks.Keys), r.Publisher.Type()),
+	})
 
 	r.Logger.Debug("Published OIDC metadata",
 		"publisher", r.Publisher.Type(),
-		"public_url", r.Config.PublicIssuerURL,
+		"public_url", r.publicIssuerURL(),
 		"duration_s", publishDuration,
 	)
 
 	return nil
 }
 
+// recordPublishedSizes sets the published size gauges from the documents as the
+// publishers marshal them for upload.
+func (r *OIDCBridgeReconciler) recordPublishedSizes(discovery *bridge.DiscoveryDocument, jwks *bridge.JWKS) {
+	discoveryData, err := iface.MarshalDocument(discovery, r.Config.MinifyDiscovery)
+	if err != nil {
+		r.Logger.Debug("Failed to marshal discovery document for size metric", "error", err)
+		return
+	}
+	jwksData, err := iface.MarshalDocument(jwks, r.Config.MinifyJWKS)
+	if err != nil {
+		r.Logger.Debug("Failed to marshal JWKS for size metric", "error", err)
+		return
+	}
+	r.Metrics.SetPublishedSizes(len(discoveryData), len(jwksData))
+}
+
+// streamSyncResult reports the result of a sync on the event stream.
+func (r *OIDCBridgeReconciler) streamSyncResult(err error) {
+	event := eventstream.Event{Type: eventstream.TypeSync, Timestamp: r.now(), Status: eventstream.StatusSuccess}
+	if err != nil {
+		event.Status = eventstream.StatusError
+		event.Message = err.Error()
+	}
+	r.Events.Publish(event)
+}
+
 // emitRotationEvent emits a Kubernetes event for key rotation.
 func (r *OIDCBridgeReconciler) emitRotationEvent(event rotation.Event) {
 	pod, err := r.getControllerPod(context.Background())
@@ -284,6 +596,27 @@ func (r *OIDCBridgeReconciler) emitRotationEvent(event rotation.Event) {
 	}
 }
 
+// recordActiveKeyIDs emits an event listing the published key IDs when they differ
+// from the previous successful sync, so the event history shows how the key set
+// evolved without an event per no-op sync. The first sync after a start always
+// records the set.
+func (r *OIDCBridgeReconciler) recordActiveKeyIDs(ctx context.Context, jwks *bridge.JWKS) {
+	keyIDs := bridge.GetKeyIDs(jwks)
+	slices.Sort(keyIDs)
+	keyIDs = slices.Compact(keyIDs)
+
+	r.keyIDsMu.Lock()
+	changed := !r.keyIDsRecorded || !slices.Equal(keyIDs, r.activeKeyIDs)
+	r.activeKeyIDs, r.keyIDsRecorded = keyIDs, true
+	r.keyIDsMu.Unlock()
+	if !changed {
+		return
+	}
+
+	r.RecordControllerEvent(ctx, corev1.EventTypeNormal, EventReasonActiveKeysChanged,
+		fmt.Sprintf("Active signing keys (%d): %s", len(keyIDs), strings.Join(keyIDs, ", ")))
+}
+
 // registerHealthChecks registers health checks with the health manager.
 func (r *OIDCBridgeReconciler) registerHealthChecks() {
 	r.Health.Register("bridge", func(ctx context.Context) error {
@@ -295,6 +628,22 @@ func (r *OIDCBridgeReconciler) registerHealthChecks() {
 	r.Health.Register("publisher", func(ctx context.Context) error {
 		return r.Publisher.HealthCheck(ctx)
 	})
+
+	r.Health.Register("rotation", func(ctx context.Context) error {
+		// Verify the rotation state ConfigMap is readable and writable (RBAC)
+		return r.RotationManager.HealthCheck(ctx)
+	})
+}
+
+// RecordControllerEvent emits an event on the controller pod, for components that
+// run outside the reconcile loop. It is a no-op when the pod cannot be found.
+func (r *OIDCBridgeReconciler) RecordControllerEvent(ctx context.Context, eventType, reason, message string) {
+	pod, err := r.getControllerPod(ctx)
+	if err != nil || pod == nil {
+		r.Logger.Debug("Cannot emit K8s event, controller pod not found", "error", err)
+		return
+	}
+	r.Recorder.Event(pod, eventType, reason, message)
 }
 
 // getControllerPod retrieves the controller pod for event emission.
@@ -319,23 +668,128 @@ func (r *OIDCBridgeReconciler) getControllerPod(ctx context.Context) (*corev1.Po
 	return pod, nil
 }
 
-// InitialRequest returns a reconcile request to kick off the first sync.
-// Call this when setting up the controller to enqueue the initial run.
+// PublishReadyzCheck returns a readiness checker that fails until the first
+// successful publish, so the issuer is usable before the controller reports ready.
+// With FailClosedOnPrivate it also fails while the public-read probe is failing.
+// Only the leader publishes, so the check passes until elected is closed; a
+// standby replica waiting for the lease must not hold up a rolling update.
+func (r *OIDCBridgeReconciler) PublishReadyzCheck(elected <-chan struct{}) healthz.Checker {
+	return func(_ *http.Request) error {
+		select {
+		case <-elected:
+		default:
+			return nil
+		}
+		if !r.firstPublishDone.Load() {
+			return fmt.Errorf("OIDC metadata has not been published yet")
+		}
+		if r.publicReadFailed.Load() {
+			return fmt.Errorf("published OIDC metadata is not publicly readable")
+		}
+		return nil
+	}
+}
+
+// HealthReadyzCheck returns a readiness checker that fails while a registered health
+// check, such as RBAC on the rotation state ConfigMap, is failing. It reads the
+// results of the last run, so a health.Monitor must run the checks. The failing
+// checks are named in the error, which the manager serves at /readyz/health; the
+// aggregated /readyz withholds reasons.
+func (r *OIDCBridgeReconciler) HealthReadyzCheck() healthz.Checker {
+	readiness := r.Health.ReadinessCheck()
+	return func(req *http.Request) error {
+		return readiness(req.Context())
+	}
+}
+
+// waitForMetadata handles a missing OIDC metadata ConfigMap, which is expected
+// when the controller starts before the OIDC poller has written it. It requeues
+// up to MetadataWaitAttempts times; after that the watch triggers a reconcile
+// once the ConfigMap appears.
+func (r *OIDCBridgeReconciler) waitForMetadata(req ctrl.Request) ctrl.Result {
+	attempt := int(r.metadataWaits.Add(1))
+	if r.Config.MetadataWaitInterval <= 0 || attempt > r.Config.MetadataWaitAttempts {
+		r.Logger.Info("OIDC metadata ConfigMap not found, skipping reconciliation", "name", req.Name, "namespace", req.Namespace)
+		return ctrl.Result{}
+	}
+
+	r.Logger.Info("OIDC metadata ConfigMap not found yet, waiting for the OIDC poller",
+		"name", req.Name,
+		"namespace", req.Namespace,
+		"attempt", attempt,
+		"maxAttempts", r.Config.MetadataWaitAttempts,
+		"retryIn", r.Config.MetadataWaitInterval,
+	)
+	return ctrl.Result{RequeueAfter: r.Config.MetadataWaitInterval}
+}
+
+// InitialRequest returns a reconcile request to kick off the first sync: the
+// OIDC metadata ConfigMap in namespace. SetupWithManager enqueues it at startup
+// so the first sync does not depend on a ConfigMap event.
 func InitialRequest(namespace string) ctrl.Request {
 	return ctrl.Request{
 		NamespacedName: types.NamespacedName{
-			Name:      "kubeassume-sync",
+			Name:      constants.DefaultOIDCConfigMapName,
 			Namespace: namespace,
 		},
 	}
 }
 
+// initialSyncSource returns a source that enqueues InitialRequest once when
+// the controller starts, and again on every RequestResync. The controller starts
+// its workers only after the caches have synced, so the first reconcile sees a
+// populated cache.
+func (r *OIDCBridgeReconciler) initialSyncSource() source.Source {
+	r.resync = make(chan event.GenericEvent, 1)
+	r.RequestResync()
+	return source.Channel(r.resync, &handler.EnqueueRequestForObject{},
+		source.WithPredicates[client.Object, ctrl.Request](r.oidcMetadataConfigMapFilter()))
+}
+
+// RequestResync enqueues a sync, e.g. after the IssuerConfig changed the public
+// issuer URL. Requests made while one is pending are coalesced. It is a no-op
+// before SetupWithManager.
+func (r *OIDCBridgeReconciler) RequestResync() {
+	if r.resync == nil {
+		return
+	}
+	req := InitialRequest(r.Config.Namespace)
+	select {
+	case r.resync <- event.GenericEvent{Object: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      req.Name,
+		Namespace: req.Namespace,
+	}}}:
+	default:
+	}
+}
+
+// publicIssuerURL returns the public issuer URL, as overridden by the IssuerConfig.
+func (r *OIDCBridgeReconciler) publicIssuerURL() string {
+	return r.RuntimeConfig.IssuerURL(r.Config.PublicIssuerURL)
+}
+
 // SetKubeClient sets the kubernetes clientset (for use in tests).
 func (r *OIDCBridgeReconciler) SetKubeClient(clientset kubernetes.Interface) {
 	r.kubeClient = clientset
 }
 
+// SetTimeFunc sets the time function (for testing).
+func (r *OIDCBridgeReconciler) SetTimeFunc(f func() time.Time) {
+	r.nowFunc = f
+}
+
+// now returns the current time from nowFunc, defaulting to time.Now.
+func (r *OIDCBridgeReconciler) now() time.Time {
+	if r.nowFunc == nil {
+		return time.Now()
+	}
+	return r.nowFunc()
+}
+
 // GetLastSync returns the time of the last successful sync.
 func (r *OIDCBridgeReconciler) GetLastSync() time.Time {
-	return r.lastSync
+	if t := r.lastSync.Load(); t != nil {
+		return *t
+	}
+	return time.Time{}
 }
@@ -0,0 +1,954 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
+	"github.com/hixichen/kube-iam-assume/pkg/constants"
+	"github.com/hixichen/kube-iam-assume/pkg/eventstream"
+	"github.com/hixichen/kube-iam-assume/pkg/health"
+	"github.com/hixichen/kube-iam-assume/pkg/metrics"
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/memory"
+	"github.com/hixichen/kube-iam-assume/pkg/rotation"
+)
+
+const testNamespace = "kube-iam-assume-system"
+
+// sharedMetrics is created once; metrics.New(nil) registers with the global registry.
+var (
+	sharedMetrics     *metrics.Metrics
+	sharedMetricsOnce sync.Once
+)
+
+func testMetrics() *metrics.Metrics {
+	sharedMetricsOnce.Do(func() { sharedMetrics = metrics.New(nil) })
+	return sharedMetrics
+}
+
+// fakePublisher records published documents and optionally fails.
+type fakePublisher struct {
+	mu          sync.Mutex
+	publishErr  error
+	publishes   int
+	discovery   *bridge.DiscoveryDocument
+	jwks        *bridge.JWKS
+	healthErr   error
+	validateErr error
+}
+
+func (f *fakePublisher) Publish(_ context.Context, discovery *bridge.DiscoveryDocument, jwks *bridge.JWKS) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.publishErr != nil {
+		return f.publishErr
+	}
+	f.publishes++
+	f.discovery = discovery
+	f.jwks = jwks
+	return nil
+}
+
+func (f *fakePublisher) Validate(context.Context) error    { return f.validateErr }
+func (f *fakePublisher) GetPublicURL() string              { return "https://oidc.example.com" }
+func (f *fakePublisher) HealthCheck(context.Context) error { return f.healthErr }
+func (f *fakePublisher) Type() iface.PublisherType         { return iface.PublisherTypeS3 }
+func (f *fakePublisher) Close() error                      { return nil }
+
+// fakeBridge serves a fixed discovery document.
+type fakeBridge struct {
+	discovery *bridge.DiscoveryDocument
+	jwks      *bridge.JWKS
+	err       error
+	fetches   atomic.Int32
+}
+
+func (f *fakeBridge) FetchDiscoveryDocument(context.Context) (*bridge.DiscoveryDocument, error) {
+	return f.discovery, f.err
+}
+func (f *fakeBridge) FetchJWKS(context.Context) (*bridge.JWKS, error) { return f.jwks, f.err }
+func (f *fakeBridge) GetIssuer() string                               { return "https://kubernetes.default.svc" }
+func (f *fakeBridge) Fetch(context.Context) (*bridge.FetchResult, error) {
+	f.fetches.Add(1)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &bridge.FetchResult{Discovery: f.discovery, JWKS: f.jwks}, nil
+}
+
+// memStore is an in-memory rotation.Store. Like the ConfigMap store it keeps the
+// state serialized, so concurrent callers never share a *rotation.State.
+type memStore struct {
+	mu    sync.Mutex
+	state []byte
+	err   error
+}
+
+func (m *memStore) Load(context.Context) (*rotation.State, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	state := &rotation.State{Keys: map[string]*rotation.KeyState{}}
+	if m.state == nil {
+		return state, nil
+	}
+	if err := json.Unmarshal(m.state, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (m *memStore) Save(_ context.Context, state *rotation.State) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	m.state = data
+	return nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, nil))
+}
+
+func testDiscoveryJSON() string {
+	return `{"issuer":"https://kubernetes.default.svc","jwks_uri":"https://kubernetes.default.svc/openid/v1/jwks",` +
+		`"response_types_supported":["id_token"],"subject_types_supported":["public"],` +
+		`"id_token_signing_alg_values_supported":["RS256"]}`
+}
+
+func testJWKSJSON() string {
+	return `{"keys":[{"kty":"RSA","kid":"key-1","alg":"RS256","use":"sig","n":"AQAB","e":"AQAB"}]}`
+}
+
+func metadataConfigMap(discovery, jwks string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      constants.DefaultOIDCConfigMapName,
+			Namespace: testNamespace,
+		},
+		Data: map[string]string{
+			"discovery.json": discovery,
+			"jwks.json":      jwks,
+		},
+	}
+}
+
+func newTestReconciler(t *testing.T, pub iface.Publisher, objs ...runtime.Object) *OIDCBridgeReconciler {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build()
+
+	logger := testLogger()
+	store := &memStore{}
+	return &OIDCBridgeReconciler{
+		Client:          c,
+		Scheme:          scheme,
+		Recorder:        record.NewFakeRecorder(100),
+		Bridge:          &fakeBridge{},
+		Publisher:       pub,
+		RotationManager: rotation.NewManager(store, rotation.DefaultConfig(), logger),
+		Health:          health.New(logger),
+		Metrics:         testMetrics(),
+		Config: Config{
+			SyncPeriod:      DefaultSyncPeriod,
+			Namespace:       testNamespace,
+			PublicIssuerURL: "https://oidc.example.com",
+		},
+		Logger: logger,
+	}
+}
+
+func metadataRequest() ctrl.Request {
+	return ctrl.Request{NamespacedName: types.NamespacedName{
+		Name:      constants.DefaultOIDCConfigMapName,
+		Namespace: testNamespace,
+	}}
+}
+
+func TestNewOIDCBridgeReconciler_RegistersControllerRuntimeMetrics(t *testing.T) {
+	r := NewOIDCBridgeReconciler(nil, nil, nil, nil, &fakePublisher{}, nil, Config{}, slog.New(slog.NewTextHandler(os.Stderr, nil)))
+	r.Metrics.RecordSync("success")
+
+	families, err := ctrlmetrics.Registry.Gather()
+	require.NoError(t, err)
+	var names []string
+	for _, family := range families {
+		names = append(names, family.GetName())
+	}
+	assert.Contains(t, names, "kubeassume_sync_total")
+}
+
+func TestPublishReadyzCheck_FlipsAfterFirstPublish(t *testing.T) {
+	pub := &fakePublisher{}
+	r := newTestReconciler(t, pub, metadataConfigMap(testDiscoveryJSON(), testJWKSJSON()))
+	check := r.PublishReadyzCheck(electedChannel())
+
+	require.Error(t, check(nil), "should not be ready before any publish")
+
+	_, err := r.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+	assert.Equal(t, 1, pub.publishes)
+	assert.NoError(t, check(nil), "should be ready after a successful publish")
+}
+
+func TestPublishReadyzCheck_StaysNotReadyOnPublishFailure(t *testing.T) {
+	pub := &fakePublisher{publishErr: errors.New("access denied")}
+	r := newTestReconciler(t, pub, metadataConfigMap(testDiscoveryJSON(), testJWKSJSON()))
+	check := r.PublishReadyzCheck(electedChannel())
+
+	result, err := r.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.Error(t, check(nil), "should stay not-ready when publish fails")
+}
+
+func TestPublishReadyzCheck_ReadyWhileNotLeader(t *testing.T) {
+	r := newTestReconciler(t, &fakePublisher{}, metadataConfigMap(testDiscoveryJSON(), testJWKSJSON()))
+	elected := make(chan struct{})
+	check := r.PublishReadyzCheck(elected)
+
+	assert.NoError(t, check(nil), "a replica waiting for the lease should be ready")
+
+	close(elected)
+	assert.Error(t, check(nil), "the leader should not be ready before its first publish")
+}
+
+// electedChannel returns a closed channel, as the manager's Elected is once
+// this replica leads or when leader election is disabled.
+func electedChannel() <-chan struct{} {
+	elected := make(chan struct{})
+	close(elected)
+	return elected
+}
+
+func TestReconcile_StreamsEvents(t *testing.T) {
+	r := newTestReconciler(t, &fakePublisher{}, metadataConfigMap(testDiscoveryJSON(), testJWKSJSON()))
+	r.Events = eventstream.NewBroker(1, 10)
+	events, unsubscribe, err := r.Events.Subscribe()
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	_, err = r.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+
+	var got []eventstream.Type
+	for len(events) > 0 {
+		event := <-events
+		got = append(got, event.Type)
+		if event.Type == eventstream.TypeRotation {
+			assert.Equal(t, "key-1", event.KeyID)
+		}
+		if event.Type == eventstream.TypeSync {
+			assert.Equal(t, eventstream.StatusSuccess, event.Status)
+		}
+	}
+	assert.Equal(t, []eventstream.Type{eventstream.TypeRotation, eventstream.TypePublish, eventstream.TypeSync}, got)
+}
+
+func TestReconcile_KeyFilter(t *testing.T) {
+	jwks := `{"keys":[` +
+		`{"kty":"RSA","kid":"sig-key","alg":"RS256","use":"sig","n":"AQAB","e":"AQAB"},` +
+		`{"kty":"RSA","kid":"enc-key","alg":"RSA-OAEP","use":"enc","n":"AQAB","e":"AQAB"},` +
+		`{"kty":"RSA","kid":"no-alg-key","n":"AQAB","e":"AQAB"}]}`
+
+	tests := []struct {
+		name            string
+		signingKeysOnly bool
+		requireKeyAlg   bool
+		wantKids        []string
+	}{
+		{
+			name:     "filter disabled publishes every key",
+			wantKids: []string{"sig-key", "enc-key", "no-alg-key"},
+		},
+		{
+			name:            "signing keys only drops encryption key",
+			signingKeysOnly: true,
+			wantKids:        []string{"sig-key", "no-alg-key"},
+		},
+		{
+			name:            "signing keys with alg",
+			signingKeysOnly: true,
+			requireKeyAlg:   true,
+			wantKids:        []string{"sig-key"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pub := &fakePublisher{}
+			r := newTestReconciler(t, pub, metadataConfigMap(testDiscoveryJSON(), jwks))
+			r.Config.SigningKeysOnly = tt.signingKeysOnly
+			r.Config.RequireKeyAlg = tt.requireKeyAlg
+
+			_, err := r.Reconcile(t.Context(), metadataRequest())
+			require.NoError(t, err)
+			require.NotNil(t, pub.jwks)
+
+			kids := make([]string, 0, len(pub.jwks.Keys))
+			for _, key := range pub.jwks.Keys {
+				kids = append(kids, key.Kid)
+			}
+			assert.ElementsMatch(t, tt.wantKids, kids)
+		})
+	}
+}
+
+func TestReconcile_DuplicateKeyIDs(t *testing.T) {
+	sameMaterial := `{"keys":[` +
+		`{"kty":"RSA","kid":"key-1","n":"AQAB","e":"AQAB"},` +
+		`{"kty":"RSA","kid":"key-1","alg":"RS256","n":"AQAB","e":"AQAB"}]}`
+	differentMaterial := `{"keys":[` +
+		`{"kty":"RSA","kid":"key-1","n":"AQAB","e":"AQAB"},` +
+		`{"kty":"RSA","kid":"key-1","n":"AQAC","e":"AQAB"}]}`
+
+	tests := []struct {
+		name        string
+		jwks        string
+		reject      bool
+		wantPublish bool
+	}{
+		{name: "same material published when rejecting", jwks: sameMaterial, reject: true, wantPublish: true},
+		{name: "different material rejected", jwks: differentMaterial, reject: true, wantPublish: false},
+		{name: "different material only warned by default", jwks: differentMaterial, wantPublish: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pub := &fakePublisher{}
+			r := newTestReconciler(t, pub, metadataConfigMap(testDiscoveryJSON(), tt.jwks))
+			r.Config.RejectDuplicateKeyIDs = tt.reject
+
+			_, err := r.Reconcile(t.Context(), metadataRequest())
+			require.NoError(t, err)
+			if tt.wantPublish {
+				assert.Equal(t, 1, pub.publishes)
+				require.Len(t, pub.jwks.Keys, 1)
+				assert.Equal(t, "AQAB", pub.jwks.Keys[0].N)
+			} else {
+				assert.Zero(t, pub.publishes)
+				assert.Error(t, r.PublishReadyzCheck(electedChannel())(nil))
+				assert.Equal(t, float64(1), testutil.ToFloat64(r.Metrics.SyncTotal.WithLabelValues("error")))
+			}
+		})
+	}
+}
+
+func TestInitialSyncSource_EnqueuesInitialRequest(t *testing.T) {
+	r := newTestReconciler(t, &fakePublisher{})
+	queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer queue.ShutDown()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	require.NoError(t, r.initialSyncSource().Start(ctx, queue))
+
+	require.Eventually(t, func() bool { return queue.Len() == 1 }, 5*time.Second, 10*time.Millisecond)
+	req, _ := queue.Get()
+	assert.Equal(t, InitialRequest(testNamespace), req)
+	assert.Equal(t, metadataRequest(), req, "the initial request must name the OIDC metadata ConfigMap")
+}
+
+func TestInitialSyncSource_FiltersOtherObjects(t *testing.T) {
+	r := newTestReconciler(t, &fakePublisher{})
+	queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer queue.ShutDown()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	require.NoError(t, r.initialSyncSource().Start(ctx, queue))
+	require.Eventually(t, func() bool { return queue.Len() == 1 }, 5*time.Second, 10*time.Millisecond)
+	req, _ := queue.Get()
+	queue.Done(req)
+
+	// Events are handled in order, so the unrelated object has been filtered
+	// by the time the resync is enqueued
+	r.resync <- event.GenericEvent{Object: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: testNamespace}}}
+	r.resync <- event.GenericEvent{Object: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: req.Name, Namespace: req.Namespace}}}
+	require.Eventually(t, func() bool { return queue.Len() == 1 }, 5*time.Second, 10*time.Millisecond)
+	req, _ = queue.Get()
+	assert.Equal(t, InitialRequest(testNamespace), req)
+	assert.Zero(t, queue.Len())
+}
+
+func TestReconcile_WaitsForMissingMetadata(t *testing.T) {
+	pub := &fakePublisher{}
+	r := newTestReconciler(t, pub)
+	r.Config.MetadataWaitInterval = 10 * time.Second
+	r.Config.MetadataWaitAttempts = 2
+
+	for i := 0; i < 2; i++ {
+		result, err := r.Reconcile(t.Context(), metadataRequest())
+		require.NoError(t, err)
+		assert.Equal(t, 10*time.Second, result.RequeueAfter, "attempt %d should requeue", i+1)
+	}
+
+	result, err := r.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result, "gives up after the configured attempts")
+
+	// Once the poller writes the ConfigMap, the next reconcile publishes
+	require.NoError(t, r.Create(t.Context(), metadataConfigMap(testDiscoveryJSON(), testJWKSJSON())))
+	_, err = r.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+	assert.Equal(t, 1, pub.publishes)
+}
+
+func TestPublish_RecordsPublishedSizes(t *testing.T) {
+	for _, minify := range []bool{false, true} {
+		t.Run(fmt.Sprintf("minify=%t", minify), func(t *testing.T) {
+			memCfg := memory.Config{PublicURL: "https://oidc.example.com", MinifyJWKS: minify, MinifyDiscovery: minify}
+			pub, err := memory.New(memCfg, nil)
+			require.NoError(t, err)
+			r := newTestReconciler(t, pub, metadataConfigMap(testDiscoveryJSON(), testJWKSJSON()))
+			r.Config.MinifyJWKS = minify
+			r.Config.MinifyDiscovery = minify
+
+			_, err = r.Reconcile(t.Context(), metadataRequest())
+			require.NoError(t, err)
+
+			// The gauges match the stored objects
+			discoveryKeys, err := memCfg.GetDiscoveryObjectKeys()
+			require.NoError(t, err)
+			discoveryData, ok := pub.Bucket().Get(discoveryKeys[0])
+			require.True(t, ok)
+			jwksData, ok := pub.Bucket().Get(memCfg.GetJWKSPath())
+			require.True(t, ok)
+			assert.Equal(t, float64(len(discoveryData)), testutil.ToFloat64(r.Metrics.PublishedDiscoveryBytes))
+			assert.Equal(t, float64(len(jwksData)), testutil.ToFloat64(r.Metrics.PublishedJWKSBytes))
+		})
+	}
+}
+
+func TestPublish_CustomizesClaimsSupported(t *testing.T) {
+	discovery := `{"issuer":"https://kubernetes.default.svc","jwks_uri":"https://kubernetes.default.svc/openid/v1/jwks",` +
+		`"response_types_supported":["id_token"],"subject_types_supported":["public"],` +
+		`"id_token_signing_alg_values_supported":["RS256"],"claims_supported":["sub","iss","aud"]}`
+
+	tests := []struct {
+		name     string
+		claims   bridge.ClaimsCustomization
+		expected []string
+	}{
+		{
+			name:     "source claims by default",
+			expected: []string{"sub", "iss", "aud"},
+		},
+		{
+			name:     "append merges without duplicates",
+			claims:   bridge.ClaimsCustomization{Claims: []string{"aud", "kubernetes.io"}},
+			expected: []string{"sub", "iss", "aud", "kubernetes.io"},
+		},
+		{
+			name:     "override replaces",
+			claims:   bridge.ClaimsCustomization{Claims: []string{"sub", "kubernetes.io"}, Override: true},
+			expected: []string{"sub", "kubernetes.io"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pub := &fakePublisher{}
+			r := newTestReconciler(t, pub, metadataConfigMap(discovery, testJWKSJSON()))
+			r.Config.Claims = tt.claims
+
+			_, err := r.Reconcile(t.Context(), metadataRequest())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, pub.discovery.ClaimsSupported)
+		})
+	}
+}
+
+func TestPublish_AddsDiscoveryExtraFields(t *testing.T) {
+	pub := &fakePublisher{}
+	r := newTestReconciler(t, pub, metadataConfigMap(testDiscoveryJSON(), testJWKSJSON()))
+	r.Config.DiscoveryExtraFields = map[string]interface{}{
+		"code_challenge_methods_supported": []interface{}{"S256"},
+		"vendor_tenant":                    "platform",
+	}
+
+	_, err := r.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+
+	// The extra fields are merged into the published JSON next to the standard fields
+	data, err := iface.MarshalDocument(pub.discovery, false)
+	require.NoError(t, err)
+	var published map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &published))
+	assert.Equal(t, []interface{}{"S256"}, published["code_challenge_methods_supported"])
+	assert.Equal(t, "platform", published["vendor_tenant"])
+	assert.Equal(t, "https://oidc.example.com", published["issuer"])
+}
+
+func TestRotationHealthCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		storeErr   error
+		wantStatus health.Status
+	}{
+		{name: "readable store", wantStatus: health.StatusHealthy},
+		{name: "store error", storeErr: errors.New("configmaps is forbidden"), wantStatus: health.StatusUnhealthy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestReconciler(t, &fakePublisher{})
+			r.RotationManager = rotation.NewManager(&memStore{err: tt.storeErr}, rotation.DefaultConfig(), r.Logger)
+			r.registerHealthChecks()
+
+			check, err := r.Health.Run(t.Context(), "rotation")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, check.Status)
+		})
+	}
+}
+
+func TestHealthReadyzCheck_FailsOnRotationStoreError(t *testing.T) {
+	r := newTestReconciler(t, &fakePublisher{})
+	r.RotationManager = rotation.NewManager(&memStore{err: errors.New("configmaps is forbidden")}, rotation.DefaultConfig(), r.Logger)
+	r.registerHealthChecks()
+	check := r.HealthReadyzCheck()
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+
+	r.Health.RunAll(t.Context())
+	err := check(req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rotation: failed to load rotation state: configmaps is forbidden")
+
+	r.RotationManager = rotation.NewManager(&memStore{}, rotation.DefaultConfig(), r.Logger)
+	r.Health.RunAll(t.Context())
+	assert.NoError(t, check(req))
+}
+
+func TestHealthReadyzCheck_ServesFailingChecks(t *testing.T) {
+	r := newTestReconciler(t, &fakePublisher{healthErr: errors.New("bucket not reachable")})
+	r.RotationManager = rotation.NewManager(&memStore{err: errors.New("configmaps is forbidden")}, rotation.DefaultConfig(), r.Logger)
+	r.registerHealthChecks()
+	r.Health.RunAll(t.Context())
+	// The manager serves each readyz check below /readyz/<name> with its error
+	handler := &healthz.Handler{Checks: map[string]healthz.Checker{"health": r.HealthReadyzCheck()}}
+
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Contains(t, resp.Body.String(), "publisher: bucket not reachable\nrotation: failed to load rotation state: configmaps is forbidden")
+}
+
+func TestControllerOptions_MaxConcurrentReconciles(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured int
+		want       int
+	}{
+		{name: "unset defaults to one worker", want: 1},
+		{name: "configured value is used", configured: 4, want: 4},
+		{name: "negative value is clamped", configured: -2, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestReconciler(t, &fakePublisher{})
+			r.Config.MaxConcurrentReconciles = tt.configured
+			assert.Equal(t, tt.want, r.controllerOptions().MaxConcurrentReconciles)
+		})
+	}
+}
+
+func TestReconcile_RepublishInterval(t *testing.T) {
+	pub := &fakePublisher{}
+	r := newTestReconciler(t, pub, metadataConfigMap(testDiscoveryJSON(), testJWKSJSON()))
+
+	// Off by default: no timer-driven requeue
+	result, err := r.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+
+	// Enabled: every successful sync schedules a republish, which uploads again
+	// even though the ConfigMap content did not change
+	r.Config.RepublishInterval = 30 * time.Minute
+	for i := 2; i <= 3; i++ {
+		result, err = r.Reconcile(t.Context(), metadataRequest())
+		require.NoError(t, err)
+		assert.Equal(t, 30*time.Minute, result.RequeueAfter)
+		assert.Equal(t, i, pub.publishes)
+	}
+}
+
+func TestReconcile_MetadataMaxAge(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	refetchedJWKS := &bridge.JWKS{Keys: []bridge.JWK{{Kty: "RSA", Kid: "key-2", Alg: "RS256", Use: "sig", N: "AQAB", E: "AQAB"}}}
+
+	tests := []struct {
+		name          string
+		lastFetch     string
+		refetchErr    error
+		wantFetches   int32
+		wantKid       string
+		wantRequeueIn time.Duration
+	}{
+		{name: "fresh metadata is published as is", lastFetch: now.Add(-4 * time.Minute).Format(time.RFC3339), wantKid: "key-1", wantRequeueIn: 6 * time.Minute},
+		{name: "stale metadata is refetched", lastFetch: now.Add(-11 * time.Minute).Format(time.RFC3339), wantFetches: 1, wantKid: "key-2", wantRequeueIn: 10 * time.Minute},
+		{name: "missing fetch time counts as stale", wantFetches: 1, wantKid: "key-2", wantRequeueIn: 10 * time.Minute},
+		{
+			name:          "failed refetch publishes the ConfigMap and retries after a sync period",
+			lastFetch:     now.Add(-time.Hour).Format(time.RFC3339),
+			refetchErr:    errors.New("api server unavailable"),
+			wantFetches:   1,
+			wantKid:       "key-1",
+			wantRequeueIn: DefaultSyncPeriod,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := metadataConfigMap(testDiscoveryJSON(), testJWKSJSON())
+			if tt.lastFetch != "" {
+				cm.Annotations = map[string]string{constants.OIDCLastFetchAnnotation: tt.lastFetch}
+			}
+			pub := &fakePublisher{}
+			r := newTestReconciler(t, pub, cm)
+			r.SetTimeFunc(func() time.Time { return now })
+			r.Config.MetadataMaxAge = 10 * time.Minute
+			var discovery bridge.DiscoveryDocument
+			require.NoError(t, json.Unmarshal([]byte(testDiscoveryJSON()), &discovery))
+			fb := &fakeBridge{discovery: &discovery, jwks: refetchedJWKS, err: tt.refetchErr}
+			r.Bridge = fb
+
+			result, err := r.Reconcile(t.Context(), metadataRequest())
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFetches, fb.fetches.Load())
+			require.Equal(t, 1, pub.publishes)
+			assert.Equal(t, tt.wantKid, pub.jwks.Keys[0].Kid)
+			assert.Equal(t, tt.wantRequeueIn, result.RequeueAfter)
+		})
+	}
+}
+
+func TestReconcile_MetadataMaxAgeOffByDefault(t *testing.T) {
+	fb := &fakeBridge{}
+	r := newTestReconciler(t, &fakePublisher{}, metadataConfigMap(testDiscoveryJSON(), testJWKSJSON()))
+	r.Bridge = fb
+
+	result, err := r.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+	assert.Zero(t, fb.fetches.Load())
+	assert.Zero(t, result.RequeueAfter)
+
+	// The republish interval wins when it comes first
+	r.Config.RepublishInterval = time.Minute
+	r.Config.MetadataMaxAge = time.Hour
+	assert.Equal(t, time.Minute, r.requeueAfter(time.Now()))
+}
+
+func TestOIDCMetadataConfigMapFilter_SkipsFetchTimeOnlyUpdates(t *testing.T) {
+	r := newTestReconciler(t, &fakePublisher{})
+	filter := r.oidcMetadataConfigMapFilter()
+
+	withFetch := func(jwks, lastFetch string) *corev1.ConfigMap {
+		cm := metadataConfigMap(testDiscoveryJSON(), jwks)
+		cm.Annotations = map[string]string{constants.OIDCLastFetchAnnotation: lastFetch}
+		return cm
+	}
+	old := withFetch(testJWKSJSON(), "2026-05-01T12:00:00Z")
+
+	assert.False(t, filter.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: withFetch(testJWKSJSON(), "2026-05-01T12:01:00Z")}),
+		"a poll that only records the fetch time")
+	assert.True(t, filter.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: withFetch(`{"keys":[]}`, "2026-05-01T12:01:00Z")}),
+		"a poll that changes the metadata")
+	assert.True(t, filter.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: old.DeepCopy()}),
+		"a resync of an unchanged ConfigMap")
+}
+
+func TestReconcile_ActiveKeysChangedEvent(t *testing.T) {
+	t.Setenv("POD_NAME", "controller-0")
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "controller-0", Namespace: testNamespace}}
+	cm := metadataConfigMap(testDiscoveryJSON(), testJWKSJSON())
+	r := newTestReconciler(t, &fakePublisher{}, cm, pod)
+	recorder := r.Recorder.(*record.FakeRecorder)
+
+	keySetEvents := func() []string {
+		var events []string
+		for len(recorder.Events) > 0 {
+			if event := <-recorder.Events; strings.Contains(event, EventReasonActiveKeysChanged) {
+				events = append(events, event)
+			}
+		}
+		return events
+	}
+
+	// The first sync records the key set
+	_, err := r.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+	events := keySetEvents()
+	require.Len(t, events, 1)
+	assert.Equal(t, "Normal ActiveKeysChanged Active signing keys (1): key-1", events[0])
+
+	// A sync with the same keys does not repeat it
+	_, err = r.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+	assert.Empty(t, keySetEvents())
+
+	// A new key changes the set
+	require.NoError(t, r.Get(t.Context(), metadataRequest().NamespacedName, cm))
+	cm.Data["jwks.json"] = `{"keys":[` +
+		`{"kty":"RSA","kid":"key-2","alg":"RS256","use":"sig","n":"AQAB","e":"AQAB"},` +
+		`{"kty":"RSA","kid":"key-1","alg":"RS256","use":"sig","n":"AQAB","e":"AQAB"}]}`
+	require.NoError(t, r.Update(t.Context(), cm))
+	_, err = r.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+	events = keySetEvents()
+	require.Len(t, events, 1)
+	assert.Equal(t, "Normal ActiveKeysChanged Active signing keys (2): key-1, key-2", events[0])
+}
+
+func TestReconcile_FailClosedOnPrivate(t *testing.T) {
+	status := http.StatusForbidden
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, "/.well-known/openid-configuration", req.URL.Path)
+		assert.Empty(t, req.Header.Get("Authorization"), "probe must be anonymous")
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	t.Setenv("POD_NAME", "controller-0")
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "controller-0", Namespace: testNamespace}}
+	pub := &fakePublisher{}
+	r := newTestReconciler(t, pub, metadataConfigMap(testDiscoveryJSON(), testJWKSJSON()), pod)
+	r.Config.PublicIssuerURL = server.URL
+	r.Config.FailClosedOnPrivate = true
+	r.HTTPClient = server.Client()
+	check := r.PublishReadyzCheck(electedChannel())
+
+	// Bucket returns 403: published, but not ready and a warning event is emitted
+	result, err := r.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+	assert.True(t, result.Requeue)
+	assert.Equal(t, 1, pub.publishes)
+	assert.Error(t, check(nil))
+
+	recorder := r.Recorder.(*record.FakeRecorder)
+	var warnings []string
+	for len(recorder.Events) > 0 {
+		if event := <-recorder.Events; strings.HasPrefix(event, "Warning ") {
+			warnings = append(warnings, event)
+		}
+	}
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], EventReasonPublicReadFailed)
+
+	// Bucket becomes public: ready again
+	status = http.StatusOK
+	_, err = r.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+	assert.NoError(t, check(nil))
+
+	// A later regression flips readiness back to not ready
+	status = http.StatusForbidden
+	_, err = r.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+	assert.Error(t, check(nil))
+}
+
+func TestReconcile_PublicReadProbeOffByDefault(t *testing.T) {
+	probed := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		probed = true
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	r := newTestReconciler(t, &fakePublisher{}, metadataConfigMap(testDiscoveryJSON(), testJWKSJSON()))
+	r.Config.PublicIssuerURL = server.URL
+	r.HTTPClient = server.Client()
+
+	_, err := r.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+	assert.False(t, probed)
+	assert.NoError(t, r.PublishReadyzCheck(electedChannel())(nil))
+}
+
+func TestReconcile_PublishOnChangeSurvivesRestart(t *testing.T) {
+	cm := metadataConfigMap(testDiscoveryJSON(), testJWKSJSON())
+
+	first := &fakePublisher{}
+	r := newTestReconciler(t, first, cm.DeepCopy())
+	r.Config.PublishOnChange = true
+	_, err := r.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+	_, err = r.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+	assert.Equal(t, 1, first.publishes, "unchanged content should be uploaded once")
+
+	// A restarted controller shares only the persisted rotation state
+	restarted := &fakePublisher{}
+	r2 := newTestReconciler(t, restarted, cm.DeepCopy())
+	r2.Config.PublishOnChange = true
+	r2.RotationManager = r.RotationManager
+	_, err = r2.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+	assert.Equal(t, 0, restarted.publishes, "matching persisted hash should skip the initial publish")
+
+	// A publisher setting change alters the hash and forces an upload
+	r2.Config.PublishFormat = "layout=flat"
+	_, err = r2.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+	assert.Equal(t, 1, restarted.publishes)
+}
+
+func TestReconcile_PublishOnChangeHonorsRepublishInterval(t *testing.T) {
+	pub := &fakePublisher{}
+	r := newTestReconciler(t, pub, metadataConfigMap(testDiscoveryJSON(), testJWKSJSON()))
+	r.Config.PublishOnChange = true
+	r.Config.RepublishInterval = time.Hour
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.SetTimeFunc(func() time.Time { return now })
+
+	_, err := r.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+	_, err = r.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+	assert.Equal(t, 1, pub.publishes)
+
+	// Once the interval has elapsed, unchanged content is uploaded again
+	now = now.Add(2 * time.Hour)
+	_, err = r.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+	assert.Equal(t, 2, pub.publishes)
+}
+
+func TestReconcile_UsesInjectedClock(t *testing.T) {
+	pub := &fakePublisher{}
+	r := newTestReconciler(t, pub, metadataConfigMap(testDiscoveryJSON(), testJWKSJSON()))
+	fixed := time.Date(2026, 3, 14, 15, 9, 26, 0, time.UTC)
+	r.SetTimeFunc(func() time.Time { return fixed })
+
+	_, err := r.Reconcile(t.Context(), metadataRequest())
+	require.NoError(t, err)
+	assert.Equal(t, fixed, r.GetLastSync())
+}
+
+// overlapPublisher holds each upload until released and records how many overlap.
+type overlapPublisher struct {
+	fakePublisher
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+	release     chan struct{}
+}
+
+func (p *overlapPublisher) Publish(ctx context.Context, discovery *bridge.DiscoveryDocument, jwks *bridge.JWKS) error {
+	n := p.inFlight.Add(1)
+	defer p.inFlight.Add(-1)
+	for {
+		prev := p.maxInFlight.Load()
+		if n <= prev || p.maxInFlight.CompareAndSwap(prev, n) {
+			break
+		}
+	}
+	<-p.release
+	return p.fakePublisher.Publish(ctx, discovery, jwks)
+}
+
+func TestReconcile_ConcurrentPublishesDoNotOverlap(t *testing.T) {
+	pub := &overlapPublisher{release: make(chan struct{})}
+	r := newTestReconciler(t, pub, metadataConfigMap(testDiscoveryJSON(), testJWKSJSON()))
+
+	const reconciles = 8
+	var wg sync.WaitGroup
+	for range reconciles {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := r.Reconcile(t.Context(), metadataRequest())
+			assert.NoError(t, err)
+		}()
+	}
+	require.Eventually(t, func() bool { return pub.inFlight.Load() == 1 }, time.Second, time.Millisecond)
+	close(pub.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), pub.maxInFlight.Load())
+	assert.LessOrEqual(t, pub.publishes, reconciles)
+}
+
+func TestPublishGate_CoalescesQueuedCallers(t *testing.T) {
+	var gate publishGate
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var uploaded []string
+	upload := func(_ context.Context, discovery *bridge.DiscoveryDocument, _ *bridge.JWKS) error {
+		uploaded = append(uploaded, discovery.Issuer)
+		if len(uploaded) == 1 {
+			close(started)
+			<-release
+		}
+		return nil
+	}
+	queuedIssuer := func() string {
+		gate.mu.Lock()
+		defer gate.mu.Unlock()
+		if gate.queued == nil {
+			return ""
+		}
+		return gate.queued.discovery.Issuer
+	}
+
+	var wg sync.WaitGroup
+	submit := func(issuer string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, gate.do(t.Context(), &bridge.DiscoveryDocument{Issuer: issuer}, &bridge.JWKS{}, upload))
+		}()
+	}
+
+	submit("first")
+	<-started
+	// Every caller arriving during the in-flight upload joins the same queued call
+	for _, issuer := range []string{"second", "third", "fourth"} {
+		submit(issuer)
+		require.Eventually(t, func() bool { return queuedIssuer() == issuer }, time.Second, time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, []string{"first", "fourth"}, uploaded)
+	assert.Empty(t, queuedIssuer())
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
+)
+
+// publicReadTimeout bounds the anonymous public-read probe.
+const publicReadTimeout = 10 * time.Second
+
+// verifyPublicRead fetches the published discovery document anonymously from the
+// public issuer URL and fails unless it is served with 200 OK.
+func (r *OIDCBridgeReconciler) verifyPublicRead(ctx context.Context) error {
+	url := strings.TrimSuffix(r.publicIssuerURL(), "/") + "/.well-known/openid-configuration"
+
+	ctx, cancel := context.WithTimeout(ctx, publicReadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build public read request: %w", err)
+	}
+
+	httpClient := r.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("public read of %s failed: %w", url, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, bridge.DefaultMaxResponseBytes))
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("public read of %s returned HTTP %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+// checkPublicRead runs the public-read probe when FailClosedOnPrivate is set and
+// records the outcome for the readiness check. It returns the probe error, if any.
+func (r *OIDCBridgeReconciler) checkPublicRead(ctx context.Context) error {
+	if !r.Config.FailClosedOnPrivate {
+		return nil
+	}
+
+	if err := r.verifyPublicRead(ctx); err != nil {
+		r.publicReadFailed.Store(true)
+		r.Logger.Error("published OIDC metadata is not publicly readable, reporting not ready", "error", err)
+		if pod, podErr := r.getControllerPod(ctx); podErr == nil && pod != nil {
+			r.Recorder.Eventf(pod, corev1.EventTypeWarning, EventReasonPublicReadFailed,
+				"Published OIDC metadata is not publicly readable; cloud federation will fail: %v", err)
+		}
+		return err
+	}
+
+	r.publicReadFailed.Store(false)
+	return nil
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
+)
+
+// publishGate runs at most one upload at a time within a replica. Callers that
+// arrive while an upload is in flight are coalesced: they share one queued call
+// that uploads the most recently submitted documents once the in-flight upload
+// finishes, so a burst of triggers costs at most two uploads.
+// The zero value is ready to use.
+type publishGate struct {
+	mu      sync.Mutex
+	running bool
+	queued  *publishCall
+}
+
+// publishCall is one upload, shared by every caller coalesced into it.
+type publishCall struct {
+	discovery *bridge.DiscoveryDocument
+	jwks      *bridge.JWKS
+	done      chan struct{}
+	err       error
+}
+
+// do uploads the documents through upload, or queues them behind the in-flight
+// upload, and returns the result of the upload that carried them. A queued
+// caller whose ctx ends stops waiting; its documents are still uploaded.
+func (g *publishGate) do(
+	ctx context.Context,
+	discovery *bridge.DiscoveryDocument,
+	jwks *bridge.JWKS,
+	upload func(context.Context, *bridge.DiscoveryDocument, *bridge.JWKS) error,
+) error {
+	g.mu.Lock()
+	if g.running {
+		call := g.queued
+		if call == nil {
+			call = &publishCall{done: make(chan struct{})}
+			g.queued = call
+		}
+		// Later callers read the ConfigMap later, so their documents win
+		call.discovery, call.jwks = discovery, jwks
+		g.mu.Unlock()
+
+		select {
+		case <-call.done:
+			return call.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	g.running = true
+	g.mu.Unlock()
+
+	err := upload(ctx, discovery, jwks)
+
+	// Drain the queue before releasing the gate so queued callers are not stranded
+	for {
+		g.mu.Lock()
+		call := g.queued
+		g.queued = nil
+		if call == nil {
+			g.running = false
+			g.mu.Unlock()
+			return err
+		}
+		g.mu.Unlock()
+
+		call.err = upload(ctx, call.discovery, call.jwks)
+		close(call.done)
+	}
+}
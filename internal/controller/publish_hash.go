@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
+)
+
+// contentHash returns a hash over everything that determines the uploaded objects:
+// the documents, the destination, and the publisher settings in PublishFormat.
+func (r *OIDCBridgeReconciler) contentHash(discovery *bridge.DiscoveryDocument, jwks *bridge.JWKS) (string, error) {
+	discoveryData, err := json.Marshal(discovery)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal discovery document: %w", err)
+	}
+	jwksData, err := json.Marshal(jwks)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JWKS: %w", err)
+	}
+
+	h := sha256.New()
+	for _, part := range [][]byte{
+		[]byte(r.Publisher.Type()),
+		[]byte(r.publicIssuerURL()),
+		[]byte(r.Config.PublishFormat),
+		discoveryData,
+		jwksData,
+	} {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// unchangedSincePublish reports whether hash matches the last published content.
+// On the first call the hash persisted in the rotation state is loaded, so a restart
+// with unchanged content does not re-upload. A due RepublishInterval always reports changed.
+func (r *OIDCBridgeReconciler) unchangedSincePublish(ctx context.Context, hash string) bool {
+	r.publishMu.Lock()
+	defer r.publishMu.Unlock()
+
+	if !r.publishedHashLoaded {
+		persisted, err := r.RotationManager.GetPublishedHash(ctx)
+		if err != nil {
+			r.Logger.Warn("Failed to load persisted publish hash, publishing", "error", err)
+			return false
+		}
+		r.publishedHash = persisted
+		r.publishedHashLoaded = true
+	}
+
+	if r.publishedHash != hash {
+		return false
+	}
+	if r.Config.RepublishInterval > 0 && r.now().Sub(r.lastUpload) >= r.Config.RepublishInterval {
+		return false
+	}
+	return true
+}
+
+// recordPublishedHash remembers hash as the last published content and persists it.
+// A failure to persist only costs one redundant upload after the next restart.
+func (r *OIDCBridgeReconciler) recordPublishedHash(ctx context.Context, hash string) {
+	r.publishMu.Lock()
+	defer r.publishMu.Unlock()
+
+	r.lastUpload = r.now()
+	if r.publishedHash == hash && r.publishedHashLoaded {
+		return
+	}
+	if err := r.RotationManager.SetPublishedHash(ctx, hash); err != nil {
+		r.Logger.Warn("Failed to persist publish hash", "error", err)
+		return
+	}
+	r.publishedHash = hash
+	r.publishedHashLoaded = true
+}
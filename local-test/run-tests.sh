@@ -1,11 +1,12 @@
 #!/usr/bin/env bash
-# Run integration tests against local MinIO (starts and tears down automatically)
+# Run integration tests against local MinIO and the GCS and Azure emulators
+# (starts and tears down automatically)
 set -euo pipefail
 
 SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
 REPO_ROOT="$(cd "$SCRIPT_DIR/.." && pwd)"
 
-echo "Starting MinIO..."
+echo "Starting MinIO and the storage emulators..."
 docker compose -f "$SCRIPT_DIR/docker-compose.yml" up -d --wait
 
 echo "Running integration tests..."
@@ -14,7 +15,7 @@ go test -v -tags=integration -count=1 "$REPO_ROOT/test/integration/..."
 EXIT_CODE=$?
 set -e
 
-echo "Stopping MinIO..."
+echo "Stopping MinIO and the storage emulators..."
 docker compose -f "$SCRIPT_DIR/docker-compose.yml" down
 
 exit $EXIT_CODE
@@ -1,5 +1,5 @@
 #!/usr/bin/env bash
-# Start local MinIO (keep running for manual testing)
+# Start local MinIO and the GCS and Azure emulators (keep running for manual testing)
 set -euo pipefail
 
 SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
@@ -8,3 +8,6 @@ docker compose -f "$SCRIPT_DIR/docker-compose.yml" up -d --wait
 
 echo "MinIO UI: http://localhost:9001 (minioadmin/minioadmin)"
 echo "S3 endpoint: http://localhost:9000"
+echo "Secondary S3 endpoint: http://localhost:9100"
+echo "GCS endpoint (fake-gcs-server): http://localhost:4443"
+echo "Azure Blob endpoint (Azurite): http://localhost:10000/devstoreaccount1"
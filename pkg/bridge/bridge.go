@@ -3,16 +3,20 @@ package bridge
 import (
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"time"
 
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/hixichen/kube-iam-assume/pkg/constants"
 )
@@ -41,6 +45,8 @@ type Bridge struct {
 	issuer     string
 	logger     *slog.Logger
 	config     Config
+	// cache serves repeated fetches within Config.CacheTTL (nil when caching is off)
+	cache *responseCache
 }
 
 // Config holds configuration for creating a Bridge.
@@ -58,6 +64,24 @@ type Config struct {
 	PublicIssuerURL string
 	// SyncPeriod is the interval between OIDC metadata syncs
 	SyncPeriod time.Duration
+	// MaxResponseBytes caps the size of a fetched discovery document or JWKS
+	// (0 uses DefaultMaxResponseBytes)
+	MaxResponseBytes int64
+
+	// APIServerHost sends the metadata fetches to this https API server URL instead of
+	// RESTConfig.Host, e.g. a dedicated discovery endpoint (empty uses RESTConfig.Host)
+	APIServerHost string
+	// APIServerTLSServerName is the name the APIServerHost certificate is verified
+	// against (empty uses the host name)
+	APIServerTLSServerName string
+	// APIServerCAFile is a PEM bundle that verifies the APIServerHost certificate
+	// (empty uses the RESTConfig CA)
+	APIServerCAFile string
+
+	// CacheTTL serves the discovery document and JWKS from memory for this long after
+	// they were fetched, so health checks and pollers share API server requests
+	// (0 disables caching)
+	CacheTTL time.Duration
 }
 
 // Validate checks that the Config has the required fields for operation.
@@ -89,11 +113,16 @@ func New(cfg Config, logger *slog.Logger) (*Bridge, error) {
 		issuer:    "",
 		logger:    logger,
 		config:    cfg,
+		cache:     newResponseCache(cfg.CacheTTL),
 	}
 
 	// Only create the REST client when a config is provided
 	if cfg.RESTConfig != nil {
-		restClient, err := rest.RESTClientFor(cfg.RESTConfig)
+		restConfig, err := fetchRESTConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		restClient, err := rest.RESTClientFor(restConfig)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create REST client: %w", err)
 		}
@@ -103,20 +132,68 @@ func New(cfg Config, logger *slog.Logger) (*Bridge, error) {
 	return b, nil
 }
 
+// fetchRESTConfig returns the REST config the metadata is fetched with: a copy of
+// cfg.RESTConfig pointed at cfg.APIServerHost when one is set. The credentials are
+// kept, so the endpoint must present a certificate that verifies against the CA.
+func fetchRESTConfig(cfg Config) (*rest.Config, error) {
+	restConfig := restClientConfig(cfg.RESTConfig)
+	if cfg.APIServerHost == "" {
+		return restConfig, nil
+	}
+
+	if err := ValidateAPIServerHost(cfg.APIServerHost); err != nil {
+		return nil, err
+	}
+	if restConfig.Insecure {
+		return nil, fmt.Errorf("API server host %s requires TLS verification, but the REST config skips it", cfg.APIServerHost)
+	}
+	restConfig.Host = cfg.APIServerHost
+	if cfg.APIServerTLSServerName != "" {
+		restConfig.ServerName = cfg.APIServerTLSServerName
+	}
+	if cfg.APIServerCAFile != "" {
+		restConfig.CAFile = cfg.APIServerCAFile
+		restConfig.CAData = nil
+	}
+	return restConfig, nil
+}
+
+// restClientConfig returns a copy of restConfig that rest.RESTClientFor accepts.
+// A manager's config, as returned by mgr.GetConfig(), sets neither GroupVersion
+// nor NegotiatedSerializer, which RESTClientFor requires. The metadata paths are
+// requested with AbsPath, outside any API group, so empty defaults suffice.
+func restClientConfig(restConfig *rest.Config) *rest.Config {
+	restConfig = rest.CopyConfig(restConfig)
+	if restConfig.GroupVersion == nil {
+		restConfig.GroupVersion = &schema.GroupVersion{}
+	}
+	if restConfig.NegotiatedSerializer == nil {
+		restConfig.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+	}
+	return restConfig
+}
+
+// ValidateAPIServerHost checks that host is an https URL the bridge can fetch from.
+func ValidateAPIServerHost(host string) error {
+	u, err := url.Parse(host)
+	if err != nil {
+		return fmt.Errorf("invalid API server host %q: %w", host, err)
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("API server host %q must be an https URL", host)
+	}
+	return nil
+}
+
 // FetchDiscoveryDocument retrieves the OIDC discovery document.
 func (b *Bridge) FetchDiscoveryDocument(ctx context.Context) (*DiscoveryDocument, error) {
 	b.logger.Debug("Fetching discovery document")
 
-	// Make GET request to /.well-known/openid-configuration
-	result := b.restClient.Get().AbsPath("/.well-known/openid-configuration").Do(ctx)
-	if result.Error() != nil {
-		return nil, fmt.Errorf("failed to fetch discovery document: %w", result.Error())
-	}
-
-	// Read raw bytes
-	data, err := result.Raw()
+	// Make GET request to /.well-known/openid-configuration.
+	// Retries are disabled so throttling reaches the poller, which backs off instead.
+	data, err := b.get(ctx, "/.well-known/openid-configuration")
 	if err != nil {
-		return nil, fmt.Errorf("failed to read discovery document: %w", err)
+		return nil, fmt.Errorf("failed to fetch discovery document: %w", err)
 	}
 
 	// Parse JSON response into DiscoveryDocument
@@ -141,15 +218,9 @@ func (b *Bridge) FetchJWKS(ctx context.Context) (*JWKS, error) {
 	b.logger.Debug("Fetching JWKS")
 
 	// Make GET request to /openid/v1/jwks
-	result := b.restClient.Get().AbsPath("/openid/v1/jwks").Do(ctx)
-	if result.Error() != nil {
-		return nil, fmt.Errorf("failed to fetch JWKS: %w", result.Error())
-	}
-
-	// Read raw bytes
-	data, err := result.Raw()
+	data, err := b.get(ctx, "/openid/v1/jwks")
 	if err != nil {
-		return nil, fmt.Errorf("failed to read JWKS: %w", err)
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
 	}
 
 	// Parse JSON response into JWKS
@@ -170,6 +241,37 @@ func (b *Bridge) FetchJWKS(ctx context.Context) (*JWKS, error) {
 	return jwks, nil
 }
 
+// get reads the response body for path, bounded by Config.MaxResponseBytes.
+// Responses fetched within Config.CacheTTL are served from the cache.
+func (b *Bridge) get(ctx context.Context, path string) ([]byte, error) {
+	if data, ok := b.cache.get(path); ok {
+		return data, nil
+	}
+
+	body, err := b.restClient.Get().AbsPath(path).MaxRetries(0).Stream(ctx)
+	if err != nil {
+		return nil, wrapThrottled(err)
+	}
+	defer func() { _ = body.Close() }()
+
+	data, err := ReadLimited(body, b.config.MaxResponseBytes)
+	if err != nil {
+		return nil, err
+	}
+	b.cache.put(path, data)
+	return data, nil
+}
+
+// InvalidateCache drops the cached responses, so the next fetch reaches the API server.
+func (b *Bridge) InvalidateCache() {
+	b.cache.invalidate()
+}
+
+// SetRESTClient sets the REST client used to reach the API server (for use in tests).
+func (b *Bridge) SetRESTClient(restClient rest.Interface) {
+	b.restClient = restClient
+}
+
 // GetIssuer returns the original issuer URL.
 func (b *Bridge) GetIssuer() string {
 	return b.issuer
@@ -199,7 +301,9 @@ func (b *Bridge) Fetch(ctx context.Context) (*FetchResult, error) {
 		return nil, fmt.Errorf("failed to marshal JWKS to JSON: %w", err)
 	}
 
-	// Store in ConfigMap
+	// Store in ConfigMap, recording the fetch time even when the content is unchanged
+	fetchedAt := time.Now().UTC()
+	lastFetch := fetchedAt.Format(time.RFC3339)
 	cmClient := b.k8sClient.CoreV1().ConfigMaps(b.namespace)
 	configMapName := constants.DefaultOIDCConfigMapName
 
@@ -217,6 +321,9 @@ func (b *Bridge) Fetch(ctx context.Context) (*FetchResult, error) {
 							"app.kubernetes.io/name":      constants.ControllerName,
 							"app.kubernetes.io/component": "oidc-metadata",
 						},
+						Annotations: map[string]string{
+							constants.OIDCLastFetchAnnotation: lastFetch,
+						},
 					},
 					Data: map[string]string{
 						"discovery.json": string(discoveryJSON),
@@ -236,6 +343,7 @@ func (b *Bridge) Fetch(ctx context.Context) (*FetchResult, error) {
 		// Update existing ConfigMap
 		cm.Data["discovery.json"] = string(discoveryJSON)
 		cm.Data["jwks.json"] = string(jwksJSON)
+		metav1.SetMetaDataAnnotation(&cm.ObjectMeta, constants.OIDCLastFetchAnnotation, lastFetch)
 		_, err = cmClient.Update(ctx, cm, metav1.UpdateOptions{})
 		if err != nil {
 			if errors.IsConflict(err) {
@@ -251,11 +359,64 @@ func (b *Bridge) Fetch(ctx context.Context) (*FetchResult, error) {
 	return &FetchResult{
 		Discovery:    discovery,
 		JWKS:         jwks,
-		FetchedAt:    time.Now(),
+		FetchedAt:    fetchedAt,
 		SourceIssuer: b.issuer,
 	}, nil
 }
 
+// LastFetchTime returns when the OIDC poller last fetched the metadata stored in cm,
+// from the OIDCLastFetchAnnotation written by Fetch.
+func LastFetchTime(cm *corev1.ConfigMap) (time.Time, bool) {
+	value, ok := cm.Annotations[constants.OIDCLastFetchAnnotation]
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// ThrottledError is returned when the API server rate-limits a fetch (HTTP 429).
+type ThrottledError struct {
+	// RetryAfter is the delay requested by the API server; zero if none was given
+	RetryAfter time.Duration
+	Err        error
+}
+
+// Error implements error.
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("API server is throttling requests: %v", e.Err)
+}
+
+// Unwrap returns the underlying API error.
+func (e *ThrottledError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter reports whether err was caused by API server throttling
+// and the delay the API server asked for.
+func RetryAfter(err error) (time.Duration, bool) {
+	var throttled *ThrottledError
+	if !stderrors.As(err, &throttled) {
+		return 0, false
+	}
+	return throttled.RetryAfter, true
+}
+
+// wrapThrottled wraps a 429 response error in a ThrottledError.
+func wrapThrottled(err error) error {
+	if !errors.IsTooManyRequests(err) {
+		return err
+	}
+	throttled := &ThrottledError{Err: err}
+	if seconds, ok := errors.SuggestsClientDelay(err); ok {
+		throttled.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return throttled
+}
+
 // parseDiscoveryDocument parses JSON bytes into DiscoveryDocument.
 func parseDiscoveryDocument(data []byte) (*DiscoveryDocument, error) {
 	var doc DiscoveryDocument
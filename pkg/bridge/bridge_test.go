@@ -1,14 +1,31 @@
 package bridge
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
+	"io"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	restfake "k8s.io/client-go/rest/fake"
+
+	"github.com/hixichen/kube-iam-assume/pkg/constants"
+	kaerrors "github.com/hixichen/kube-iam-assume/pkg/errors"
 )
 
 func TestDiscoveryDocument_ToJSON(t *testing.T) {
@@ -78,6 +95,101 @@ func TestDiscoveryDocument_Validate(t *testing.T) {
 	}
 }
 
+func TestDiscoveryDocument_MarshalExtraFields(t *testing.T) {
+	doc := DiscoveryDocument{
+		Issuer:  "https://oidc.example.com",
+		JWKSURI: "https://oidc.example.com/openid/v1/jwks",
+		Extra: map[string]interface{}{
+			"code_challenge_methods_supported": []string{"S256"},
+			// Standard fields are never replaced
+			"issuer": "https://attacker.example.com",
+		},
+	}
+
+	data, err := json.Marshal(&doc)
+	require.NoError(t, err)
+	var published map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &published))
+	assert.Equal(t, []interface{}{"S256"}, published["code_challenge_methods_supported"])
+	assert.Equal(t, "https://oidc.example.com", published["issuer"])
+
+	// Without extra fields the encoding is unchanged
+	doc.Extra = nil
+	data, err = json.Marshal(doc)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), `{"issuer":`))
+}
+
+func TestValidateExtraFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		extra   map[string]interface{}
+		wantErr string
+	}{
+		{name: "none"},
+		{name: "vendor fields", extra: map[string]interface{}{"code_challenge_methods_supported": []interface{}{"S256"}, "x_tenant": map[string]interface{}{"id": 1}}},
+		{name: "empty name", extra: map[string]interface{}{" ": true}, wantErr: "must not be empty"},
+		{name: "standard field", extra: map[string]interface{}{"jwks_uri": "https://example.com"}, wantErr: "standard discovery field"},
+		{name: "not encodable", extra: map[string]interface{}{"x_channel": make(chan int)}, wantErr: "not JSON-encodable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateExtraFields(tt.extra)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestClaimsCustomization_Apply(t *testing.T) {
+	source := []string{"aud", "exp", "iat", "iss", "sub"}
+	tests := []struct {
+		name          string
+		customization ClaimsCustomization
+		source        []string
+		expected      []string
+	}{
+		{
+			name:     "no customization keeps source",
+			source:   source,
+			expected: source,
+		},
+		{
+			name:          "append merges and dedups",
+			customization: ClaimsCustomization{Claims: []string{"kubernetes.io", "sub", "team", "team"}},
+			source:        source,
+			expected:      []string{"aud", "exp", "iat", "iss", "sub", "kubernetes.io", "team"},
+		},
+		{
+			name:          "append to empty source",
+			customization: ClaimsCustomization{Claims: []string{"sub", "aud"}},
+			expected:      []string{"sub", "aud"},
+		},
+		{
+			name:          "override replaces source",
+			customization: ClaimsCustomization{Claims: []string{"sub", "aud", "sub"}, Override: true},
+			source:        source,
+			expected:      []string{"sub", "aud"},
+		},
+		{
+			name:          "override with no claims drops claims_supported",
+			customization: ClaimsCustomization{Override: true},
+			source:        source,
+			expected:      nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.customization.Apply(tt.source))
+		})
+	}
+}
+
 func TestJWKS_ToJSON(t *testing.T) {
 	jwk := JWK{
 		Kty: "RSA",
@@ -105,6 +217,30 @@ func TestJWKS_ToJSON(t *testing.T) {
 	assert.Equal(t, "test-key-1", result.Keys[0].Kid)
 }
 
+func TestJWK_CertificateChainJSON(t *testing.T) {
+	withChain := `{"kty":"RSA","kid":"key-1","n":"AQAB","e":"AQAB","x5c":["MIIBszCCAVmgAwIBAgIU","MIIBrjCCAVSgAwIBAgIU"],"x5t":"dGh1bWJwcmludA"}`
+	var jwk JWK
+	require.NoError(t, json.Unmarshal([]byte(withChain), &jwk))
+	assert.Equal(t, []string{"MIIBszCCAVmgAwIBAgIU", "MIIBrjCCAVSgAwIBAgIU"}, jwk.X5c)
+	assert.Equal(t, "dGh1bWJwcmludA", jwk.X5t)
+
+	data, err := json.Marshal(jwk)
+	require.NoError(t, err)
+	assert.JSONEq(t, withChain, string(data))
+
+	// Absent certificate material is omitted
+	data, err = json.Marshal(JWK{Kty: "RSA", Kid: "key-2", N: "AQAB", E: "AQAB"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "x5c")
+	assert.NotContains(t, string(data), "x5t")
+
+	// Clones do not share the chain
+	jwks := &JWKS{Keys: []JWK{jwk}}
+	cloned := CloneJWKS(jwks)
+	cloned.Keys[0].X5c[0] = "changed"
+	assert.Equal(t, "MIIBszCCAVmgAwIBAgIU", jwks.Keys[0].X5c[0])
+}
+
 func TestJWKS_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -157,6 +293,90 @@ func TestJWKS_Validate(t *testing.T) {
 	}
 }
 
+func TestValidateUniqueKeyIDs(t *testing.T) {
+	keyA := JWK{Kty: "RSA", Kid: "key-1", N: "modulus-a", E: "AQAB"}
+	keyB := JWK{Kty: "RSA", Kid: "key-1", N: "modulus-b", E: "AQAB"}
+	other := JWK{Kty: "RSA", Kid: "key-2", N: "modulus-c", E: "AQAB"}
+
+	tests := []struct {
+		name    string
+		jwks    *JWKS
+		wantErr string
+	}{
+		{name: "nil JWKS", jwks: nil},
+		{name: "unique kids", jwks: &JWKS{Keys: []JWK{keyA, other}}},
+		{
+			name: "duplicate kid with same material",
+			jwks: &JWKS{Keys: []JWK{keyA, {Kty: "RSA", Kid: "key-1", Alg: "RS256", N: "modulus-a", E: "AQAB"}}},
+		},
+		{
+			name:    "duplicate kid with different material",
+			jwks:    &JWKS{Keys: []JWK{keyA, other, keyB, keyB}},
+			wantErr: "same kid: key-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUniqueKeyIDs(tt.jwks)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, "JWKS contains different keys with the "+tt.wantErr)
+		})
+	}
+}
+
+func TestDedupeKeyIDs(t *testing.T) {
+	keyA := JWK{Kty: "RSA", Kid: "key-1", N: "modulus-a", E: "AQAB"}
+	keyB := JWK{Kty: "RSA", Kid: "key-1", N: "modulus-b", E: "AQAB"}
+	other := JWK{Kty: "RSA", Kid: "key-2", N: "modulus-c", E: "AQAB"}
+
+	jwks := &JWKS{Keys: []JWK{keyA, other, keyB}}
+	DedupeKeyIDs(jwks)
+	assert.Equal(t, []JWK{keyA, other}, jwks.Keys)
+
+	DedupeKeyIDs(nil)
+}
+
+func TestValidateSigningAlgs(t *testing.T) {
+	rsa := JWK{Kty: "RSA", Kid: "rsa-key", N: "modulus", E: "AQAB"}
+	ec := JWK{Kty: "EC", Kid: "ec-key", Crv: "P-256", X: "x", Y: "y"}
+
+	tests := []struct {
+		name    string
+		algs    []string
+		keys    []JWK
+		wantErr string
+	}{
+		{name: "RSA key covered by the default", algs: []string{"RS256"}, keys: []JWK{rsa}},
+		{name: "mixed keys covered", algs: []string{"RS256", "ES256"}, keys: []JWK{rsa, ec}},
+		{name: "explicit alg covered", algs: []string{"PS256"}, keys: []JWK{{Kty: "RSA", Kid: "pss-key", Alg: "PS256"}}},
+		{name: "unknown curve skipped", algs: []string{"RS256"}, keys: []JWK{{Kty: "EC", Kid: "odd-key", Crv: "secp256k1"}}},
+		{name: "EC key not advertised", algs: []string{"RS256"}, keys: []JWK{rsa, ec}, wantErr: "keys ec-key (ES256)"},
+		{
+			name:    "every uncovered key is named",
+			algs:    []string{"ES256"},
+			keys:    []JWK{rsa, ec, {Kty: "EC", Kid: "p384-key", Crv: "P-384"}},
+			wantErr: "keys rsa-key (RS256), p384-key (ES384)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSigningAlgs(&DiscoveryDocument{IDTokenSigningAlgValues: tt.algs}, &JWKS{Keys: tt.keys})
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	assert.NoError(t, ValidateSigningAlgs(nil, &JWKS{Keys: []JWK{rsa}}))
+}
+
 func TestJWK_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -202,6 +422,76 @@ func TestJWK_Validate(t *testing.T) {
 	}
 }
 
+// rfc7638Modulus is the RSA modulus from the RFC 7638 section 3.1 example.
+const rfc7638Modulus = "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw"
+
+func TestJWKThumbprint(t *testing.T) {
+	tests := []struct {
+		name    string
+		jwk     JWK
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "RFC 7638 RSA example",
+			jwk:  JWK{Kty: "RSA", Kid: "2011-04-29", Alg: "RS256", N: rfc7638Modulus, E: "AQAB"},
+			want: "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs",
+		},
+		{
+			name: "optional members do not change the thumbprint",
+			jwk:  JWK{Kty: "RSA", Kid: "other", Use: "sig", N: rfc7638Modulus, E: "AQAB"},
+			want: "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs",
+		},
+		{
+			name: "RFC 7517 EC example",
+			jwk: JWK{
+				Kty: "EC",
+				Kid: "1",
+				Crv: "P-256",
+				X:   "MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4",
+				Y:   "4Etl6SRW2YiLUrN5vfvVHuhp7x8PxltmWWlbbM4IFyM",
+			},
+			want: "cn-I_WNMClehiVp51i_0VpOENW1upEerA8sEam5hn-s",
+		},
+		{
+			name:    "RSA key missing exponent",
+			jwk:     JWK{Kty: "RSA", N: rfc7638Modulus},
+			wantErr: true,
+		},
+		{
+			name:    "EC key missing y",
+			jwk:     JWK{Kty: "EC", Crv: "P-256", X: "MKBCTNIcKUSDii11ySs3526iDZ8AiTo7Tu6KPAqv7D4"},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported key type",
+			jwk:     JWK{Kty: "oct"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := JWKThumbprint(tt.jwk)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestJWKSThumbprints_SkipsUnsupportedKeys(t *testing.T) {
+	jwks := &JWKS{Keys: []JWK{
+		{Kty: "RSA", Kid: "rsa", N: rfc7638Modulus, E: "AQAB"},
+		{Kty: "oct", Kid: "symmetric"},
+	}}
+
+	assert.Equal(t, map[string]string{"rsa": "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"}, JWKSThumbprints(jwks))
+}
+
 func TestOIDCBridge_New(t *testing.T) {
 	br, err := New(Config{
 		PublicIssuerURL: "https://example.com",
@@ -255,3 +545,242 @@ func TestOIDCBridge_ValidateConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestOIDCBridge_NewAcceptsManagerConfig(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/openid/v1/jwks", r.URL.Path)
+		_, _ = w.Write([]byte(`{"keys":[{"kty":"RSA","kid":"key-1","n":"AQAB","e":"AQAB"}]}`))
+	}))
+	defer srv.Close()
+
+	// Like mgr.GetConfig(), the config sets no GroupVersion or NegotiatedSerializer
+	restConfig := &rest.Config{Host: srv.URL}
+	br, err := New(Config{RESTConfig: restConfig}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, restConfig.GroupVersion, "the shared REST config must not be modified")
+
+	jwks, err := br.FetchJWKS(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", jwks.Keys[0].Kid)
+}
+
+func TestOIDCBridge_NewUsesAPIServerHost(t *testing.T) {
+	inCluster := &rest.Config{Host: "https://10.96.0.1:443"}
+
+	// Without an override the in-cluster endpoint is used
+	br, err := New(Config{RESTConfig: inCluster}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "https://10.96.0.1:443/openid/v1/jwks", br.restClient.Get().AbsPath("/openid/v1/jwks").URL().String())
+
+	br, err = New(Config{
+		RESTConfig:             inCluster,
+		APIServerHost:          "https://kube-oidc.internal:6443",
+		APIServerTLSServerName: "kubernetes",
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "https://kube-oidc.internal:6443/openid/v1/jwks", br.restClient.Get().AbsPath("/openid/v1/jwks").URL().String())
+	assert.Equal(t, "https://10.96.0.1:443", inCluster.Host, "the shared REST config must not be modified")
+
+	_, err = New(Config{RESTConfig: inCluster, APIServerHost: "http://kube-oidc.internal:6443"}, nil)
+	assert.ErrorContains(t, err, "must be an https URL")
+
+	insecure := &rest.Config{Host: "https://10.96.0.1:443", TLSClientConfig: rest.TLSClientConfig{Insecure: true}}
+	_, err = New(Config{RESTConfig: insecure, APIServerHost: "https://kube-oidc.internal:6443"}, nil)
+	assert.ErrorContains(t, err, "requires TLS verification")
+}
+
+func TestOIDCBridge_FetchVerifiesAPIServerCertificate(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"issuer":"https://kubernetes.default.svc","keys":[{"kty":"RSA","kid":"key-1","n":"AQAB","e":"AQAB"}]}`))
+	}))
+	defer srv.Close()
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600))
+	inCluster := &rest.Config{Host: "https://10.96.0.1:443"}
+
+	// The endpoint certificate is not signed by the in-cluster CA
+	br, err := New(Config{RESTConfig: inCluster, APIServerHost: srv.URL}, nil)
+	require.NoError(t, err)
+	_, err = br.FetchJWKS(context.Background())
+	assert.ErrorContains(t, err, "certificate")
+
+	// httptest certificates are issued for example.com
+	br, err = New(Config{RESTConfig: inCluster, APIServerHost: srv.URL, APIServerCAFile: caFile, APIServerTLSServerName: "example.com"}, nil)
+	require.NoError(t, err)
+	jwks, err := br.FetchJWKS(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", jwks.Keys[0].Kid)
+}
+
+// newFakeRESTClient returns a REST client that answers every request with the given status and headers.
+func newFakeRESTClient(status int, header http.Header, body string) *restfake.RESTClient {
+	return &restfake.RESTClient{
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: restfake.CreateHTTPClient(func(_ *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: status,
+				Header:     header,
+				Body:       io.NopCloser(strings.NewReader(body)),
+			}, nil
+		}),
+	}
+}
+
+func TestOIDCBridge_FetchThrottled(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         int
+		header         http.Header
+		wantThrottled  bool
+		wantRetryAfter time.Duration
+	}{
+		{
+			name:           "429 with Retry-After",
+			status:         http.StatusTooManyRequests,
+			header:         http.Header{"Retry-After": []string{"30"}},
+			wantThrottled:  true,
+			wantRetryAfter: 30 * time.Second,
+		},
+		{
+			name:          "429 without Retry-After",
+			status:        http.StatusTooManyRequests,
+			header:        http.Header{},
+			wantThrottled: true,
+		},
+		{
+			name:   "500 is not throttling",
+			status: http.StatusInternalServerError,
+			header: http.Header{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			br, err := New(Config{}, nil)
+			require.NoError(t, err)
+			br.SetRESTClient(newFakeRESTClient(tt.status, tt.header, "slow down"))
+
+			_, err = br.Fetch(context.Background())
+			require.Error(t, err)
+
+			retryAfter, throttled := RetryAfter(err)
+			assert.Equal(t, tt.wantThrottled, throttled)
+			assert.Equal(t, tt.wantRetryAfter, retryAfter)
+		})
+	}
+}
+
+func TestOIDCBridge_FetchRecordsLastFetch(t *testing.T) {
+	clientset := k8sfake.NewClientset()
+	br, err := New(Config{K8sClient: clientset, Namespace: "kube-iam-assume-system"}, nil)
+	require.NoError(t, err)
+	// One body that decodes as both the discovery document and the JWKS
+	body := `{"issuer":"https://kubernetes.default.svc","keys":[{"kty":"RSA","kid":"key-1","n":"AQAB","e":"AQAB"}]}`
+	br.SetRESTClient(newFakeRESTClient(http.StatusOK, http.Header{}, body))
+
+	// The first fetch creates the ConfigMap, the second updates it
+	for i := 0; i < 2; i++ {
+		result, err := br.Fetch(context.Background())
+		require.NoError(t, err)
+
+		cm, err := clientset.CoreV1().ConfigMaps("kube-iam-assume-system").Get(context.Background(), constants.DefaultOIDCConfigMapName, metav1.GetOptions{})
+		require.NoError(t, err)
+		lastFetch, ok := LastFetchTime(cm)
+		require.True(t, ok, "fetch %d", i+1)
+		assert.Equal(t, result.FetchedAt.Truncate(time.Second), lastFetch)
+	}
+
+	_, ok := LastFetchTime(&corev1.ConfigMap{})
+	assert.False(t, ok)
+	_, ok = LastFetchTime(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{constants.OIDCLastFetchAnnotation: "yesterday"}}})
+	assert.False(t, ok)
+}
+
+func TestOIDCBridge_CachesWithinTTL(t *testing.T) {
+	br, err := New(Config{CacheTTL: time.Minute}, nil)
+	require.NoError(t, err)
+	now := time.Now()
+	br.cache.now = func() time.Time { return now }
+
+	requests := map[string]int{}
+	br.SetRESTClient(&restfake.RESTClient{
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: restfake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			requests[req.URL.Path]++
+			body := `{"issuer":"https://kubernetes.default.svc","keys":[{"kty":"RSA","kid":"key-1","n":"AQAB","e":"AQAB"}]}`
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}, nil
+		}),
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err := br.FetchDiscoveryDocument(ctx)
+		require.NoError(t, err)
+		_, err = br.FetchJWKS(ctx)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, map[string]int{"/.well-known/openid-configuration": 1, "/openid/v1/jwks": 1}, requests)
+
+	// Cached documents are parsed again, so callers cannot modify the cache
+	jwks, err := br.FetchJWKS(ctx)
+	require.NoError(t, err)
+	jwks.Keys[0].Kid = "modified"
+	jwks, err = br.FetchJWKS(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", jwks.Keys[0].Kid)
+
+	// Expired responses are fetched again
+	now = now.Add(time.Minute)
+	_, err = br.FetchJWKS(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests["/openid/v1/jwks"])
+
+	// Invalidating the cache forces the next fetch
+	br.InvalidateCache()
+	_, err = br.FetchDiscoveryDocument(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests["/.well-known/openid-configuration"])
+}
+
+func TestOIDCBridge_CacheDisabledByDefault(t *testing.T) {
+	br, err := New(Config{}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, br.cache)
+
+	requests := 0
+	br.SetRESTClient(&restfake.RESTClient{
+		NegotiatedSerializer: scheme.Codecs.WithoutConversion(),
+		Client: restfake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			requests++
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(`{"issuer":"https://kubernetes.default.svc"}`))}, nil
+		}),
+	})
+	for i := 0; i < 2; i++ {
+		_, err := br.FetchDiscoveryDocument(context.Background())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, requests)
+	br.InvalidateCache()
+}
+
+func TestReadLimited(t *testing.T) {
+	data, err := ReadLimited(strings.NewReader("12345"), 5)
+	require.NoError(t, err)
+	assert.Equal(t, "12345", string(data))
+
+	_, err = ReadLimited(strings.NewReader("123456"), 5)
+	require.Error(t, err)
+	assert.True(t, kaerrors.IsFetchError(err))
+}
+
+func TestOIDCBridge_FetchRejectsOversizedBody(t *testing.T) {
+	br, err := New(Config{MaxResponseBytes: 64}, nil)
+	require.NoError(t, err)
+	body := `{"keys":[` + strings.Repeat(`{"kty":"RSA","kid":"k"},`, 10) + `{"kty":"RSA","kid":"last"}]}`
+	br.SetRESTClient(newFakeRESTClient(http.StatusOK, http.Header{}, body))
+
+	_, err = br.FetchJWKS(context.Background())
+	require.Error(t, err)
+	assert.True(t, kaerrors.IsFetchError(err))
+	assert.Contains(t, err.Error(), "64 byte limit")
+}
@@ -0,0 +1,78 @@
+package bridge
+
+import (
+	"sync"
+	"time"
+)
+
+// CacheInvalidator is implemented by bridges that cache fetched metadata.
+type CacheInvalidator interface {
+	// InvalidateCache drops the cached metadata, so the next fetch reaches the API server
+	InvalidateCache()
+}
+
+// Ensure Bridge can drop its cache on demand.
+var _ CacheInvalidator = (*Bridge)(nil)
+
+// responseCache keeps API server responses by path for a short TTL, so that
+// health checks and pollers fetching within the TTL share one request.
+// Responses are cached as raw bytes, so every fetch parses its own copy.
+type responseCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// cacheEntry is a cached response and when it was fetched.
+type cacheEntry struct {
+	data      []byte
+	fetchedAt time.Time
+}
+
+// newResponseCache creates a responseCache, or returns nil when ttl is not positive.
+func newResponseCache(ttl time.Duration) *responseCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &responseCache{ttl: ttl, now: time.Now, entries: make(map[string]cacheEntry)}
+}
+
+// get returns the response cached for path when it is younger than the TTL.
+// A nil cache caches nothing.
+func (c *responseCache) get(path string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[path]
+	if !ok || c.now().Sub(entry.fetchedAt) >= c.ttl {
+		return nil, false
+	}
+	return entry.data, true
+}
+
+// put caches data as the response for path.
+func (c *responseCache) put(path string, data []byte) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[path] = cacheEntry{data: data, fetchedAt: c.now()}
+}
+
+// invalidate drops every cached response.
+func (c *responseCache) invalidate() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.entries)
+}
@@ -1,8 +1,11 @@
 package bridge
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
+	"reflect"
+	"slices"
 	"strings"
 )
 
@@ -45,6 +48,41 @@ func TransformDiscoveryDocument(doc *DiscoveryDocument, publicIssuerURL string)
 	return transformed, nil
 }
 
+// ClaimsCustomization adjusts claims_supported in the published discovery document.
+type ClaimsCustomization struct {
+	// Claims are advertised in addition to the source claims, or instead of them with Override
+	Claims []string
+	// Override replaces the source claims instead of appending to them
+	Override bool
+}
+
+// Apply returns the claims to advertise given the source document's claims.
+// Appended claims follow the source claims; duplicates are dropped, keeping the first.
+func (c ClaimsCustomization) Apply(source []string) []string {
+	if len(c.Claims) == 0 && !c.Override {
+		return source
+	}
+	var claims []string
+	if !c.Override {
+		claims = append(claims, source...)
+	}
+	claims = append(claims, c.Claims...)
+
+	seen := make(map[string]struct{}, len(claims))
+	deduped := make([]string, 0, len(claims))
+	for _, claim := range claims {
+		if _, ok := seen[claim]; ok {
+			continue
+		}
+		seen[claim] = struct{}{}
+		deduped = append(deduped, claim)
+	}
+	if len(deduped) == 0 {
+		return nil
+	}
+	return deduped
+}
+
 // ValidateDiscoveryDocument validates a discovery document has required fields.
 func ValidateDiscoveryDocument(doc *DiscoveryDocument) error {
 	// Check issuer is not empty
@@ -75,6 +113,55 @@ func ValidateDiscoveryDocument(doc *DiscoveryDocument) error {
 	return nil
 }
 
+// ValidateSigningAlgs checks that the discovery document advertises the signing
+// algorithm of every key in the JWKS. Relying parties only try keys whose algorithm
+// is listed in id_token_signing_alg_values_supported, so an unlisted key is never
+// used to verify tokens. Keys whose algorithm cannot be determined are skipped.
+func ValidateSigningAlgs(doc *DiscoveryDocument, jwks *JWKS) error {
+	if doc == nil || jwks == nil {
+		return nil
+	}
+
+	var uncovered []string
+	for _, key := range jwks.Keys {
+		alg := signingAlg(key)
+		if alg == "" || slices.Contains(doc.IDTokenSigningAlgValues, alg) {
+			continue
+		}
+		uncovered = append(uncovered, fmt.Sprintf("%s (%s)", key.Kid, alg))
+	}
+	if len(uncovered) > 0 {
+		return fmt.Errorf("id_token_signing_alg_values_supported %v does not cover the algorithms of keys %s",
+			doc.IDTokenSigningAlgValues, strings.Join(uncovered, ", "))
+	}
+	return nil
+}
+
+// signingAlg returns the algorithm a key signs with: its alg parameter when set,
+// otherwise the algorithm implied by its key type and curve. RSA keys without an
+// alg are assumed to be RS256, the only algorithm Kubernetes signs RSA tokens with.
+func signingAlg(key JWK) string {
+	if key.Alg != "" {
+		return key.Alg
+	}
+	switch key.Kty {
+	case "RSA":
+		return "RS256"
+	case "EC":
+		switch key.Crv {
+		case "P-256":
+			return "ES256"
+		case "P-384":
+			return "ES384"
+		case "P-521":
+			return "ES512"
+		}
+	case "OKP":
+		return "EdDSA"
+	}
+	return ""
+}
+
 // buildPublicJWKSURI constructs the public JWKS URI from the issuer URL.
 func buildPublicJWKSURI(issuerURL string) (string, error) {
 	parsed, err := url.Parse(issuerURL)
@@ -88,3 +175,34 @@ func buildPublicJWKSURI(issuerURL string) (string, error) {
 
 	return parsed.String(), nil
 }
+
+// ValidateExtraFields checks that extra discovery fields can be published: names
+// must be non-empty, must not shadow a standard field, and values must encode as JSON.
+func ValidateExtraFields(extra map[string]interface{}) error {
+	standard := discoveryFieldNames()
+	for name, value := range extra {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("field names must not be empty")
+		}
+		if _, ok := standard[name]; ok {
+			return fmt.Errorf("field %q is a standard discovery field and cannot be overridden", name)
+		}
+		if _, err := json.Marshal(value); err != nil {
+			return fmt.Errorf("field %q is not JSON-encodable: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// discoveryFieldNames returns the JSON names of the DiscoveryDocument fields.
+func discoveryFieldNames() map[string]struct{} {
+	names := make(map[string]struct{})
+	t := reflect.TypeOf(DiscoveryDocument{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = struct{}{}
+		}
+	}
+	return names
+}
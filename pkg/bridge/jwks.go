@@ -2,6 +2,8 @@ package bridge
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 )
 
 // ValidateJWKS validates that a JWKS has at least one valid key.
@@ -25,6 +27,56 @@ func ValidateJWKS(jwks *JWKS) error {
 	return nil
 }
 
+// ValidateUniqueKeyIDs returns an error naming every key ID that appears more
+// than once with different key material. Repeated identical keys are allowed;
+// merging by kid would otherwise silently publish only one of the conflicting keys.
+func ValidateUniqueKeyIDs(jwks *JWKS) error {
+	if jwks == nil {
+		return nil
+	}
+
+	seen := make(map[string]JWK, len(jwks.Keys))
+	var conflicts []string
+	for _, key := range jwks.Keys {
+		first, ok := seen[key.Kid]
+		if !ok {
+			seen[key.Kid] = key
+			continue
+		}
+		if !sameKeyMaterial(first, key) && !slices.Contains(conflicts, key.Kid) {
+			conflicts = append(conflicts, key.Kid)
+		}
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("JWKS contains different keys with the same kid: %s", strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+// DedupeKeyIDs removes every key whose kid appeared earlier in the JWKS, so
+// only the first key per kid is kept.
+func DedupeKeyIDs(jwks *JWKS) {
+	if jwks == nil {
+		return
+	}
+
+	seen := make(map[string]bool, len(jwks.Keys))
+	keys := jwks.Keys[:0]
+	for _, key := range jwks.Keys {
+		if seen[key.Kid] {
+			continue
+		}
+		seen[key.Kid] = true
+		keys = append(keys, key)
+	}
+	jwks.Keys = keys
+}
+
+// sameKeyMaterial reports whether two JWKs hold the same public key.
+func sameKeyMaterial(a, b JWK) bool {
+	return a.Kty == b.Kty && a.N == b.N && a.E == b.E && a.Crv == b.Crv && a.X == b.X && a.Y == b.Y
+}
+
 // GetKeyIDs returns all key IDs from a JWKS.
 func GetKeyIDs(jwks *JWKS) []string {
 	if jwks == nil {
@@ -121,6 +173,9 @@ func CloneJWKS(jwks *JWKS) *JWKS {
 		Keys: make([]JWK, len(jwks.Keys)),
 	}
 	copy(cloned.Keys, jwks.Keys)
+	for i := range cloned.Keys {
+		cloned.Keys[i].X5c = slices.Clone(cloned.Keys[i].X5c)
+	}
 	return cloned
 }
 
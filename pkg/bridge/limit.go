@@ -0,0 +1,29 @@
+package bridge
+
+import (
+	"fmt"
+	"io"
+
+	kaerrors "github.com/hixichen/kube-iam-assume/pkg/errors"
+)
+
+// DefaultMaxResponseBytes bounds how much of an OIDC metadata response is read.
+// Real discovery documents and JWKS are a few KB; the bound only stops a broken
+// or hostile endpoint from exhausting controller memory.
+const DefaultMaxResponseBytes int64 = 4 << 20
+
+// ReadLimited reads r until EOF, failing with a FetchError once more than limit
+// bytes have been read. A limit of zero or less uses DefaultMaxResponseBytes.
+func ReadLimited(r io.Reader, limit int64) ([]byte, error) {
+	if limit <= 0 {
+		limit = DefaultMaxResponseBytes
+	}
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, kaerrors.NewFetchError("bridge", fmt.Sprintf("response exceeds the %d byte limit", limit), nil)
+	}
+	return data, nil
+}
@@ -0,0 +1,67 @@
+package bridge
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// rsaThumbprintInput holds the RFC 7638 required members of an RSA key, in
+// lexicographic order so encoding/json emits the canonical form.
+type rsaThumbprintInput struct {
+	E   string `json:"e"`
+	Kty string `json:"kty"`
+	N   string `json:"n"`
+}
+
+// ecThumbprintInput holds the RFC 7638 required members of an EC key, in
+// lexicographic order so encoding/json emits the canonical form.
+type ecThumbprintInput struct {
+	Crv string `json:"crv"`
+	Kty string `json:"kty"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// JWKThumbprint returns the RFC 7638 SHA-256 thumbprint of an RSA or EC key,
+// base64url-encoded without padding.
+func JWKThumbprint(jwk JWK) (string, error) {
+	var input interface{}
+	switch jwk.Kty {
+	case "RSA":
+		if jwk.N == "" || jwk.E == "" {
+			return "", fmt.Errorf("RSA key requires n and e parameters")
+		}
+		input = rsaThumbprintInput{E: jwk.E, Kty: jwk.Kty, N: jwk.N}
+	case "EC":
+		if jwk.Crv == "" || jwk.X == "" || jwk.Y == "" {
+			return "", fmt.Errorf("EC key requires crv, x and y parameters")
+		}
+		input = ecThumbprintInput{Crv: jwk.Crv, Kty: jwk.Kty, X: jwk.X, Y: jwk.Y}
+	default:
+		return "", fmt.Errorf("unsupported key type for thumbprint: %q", jwk.Kty)
+	}
+
+	canonical, err := json.Marshal(input)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode thumbprint input: %w", err)
+	}
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// JWKSThumbprints returns the thumbprint of every key in the JWKS by key ID,
+// skipping keys whose thumbprint cannot be computed.
+func JWKSThumbprints(jwks *JWKS) map[string]string {
+	if jwks == nil {
+		return nil
+	}
+	thumbprints := make(map[string]string, len(jwks.Keys))
+	for _, key := range jwks.Keys {
+		if thumbprint, err := JWKThumbprint(key); err == nil {
+			thumbprints[key.Kid] = thumbprint
+		}
+	}
+	return thumbprints
+}
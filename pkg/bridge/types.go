@@ -23,6 +23,36 @@ type DiscoveryDocument struct {
 	IDTokenSigningAlgValues []string `json:"id_token_signing_alg_values_supported"`
 	ClaimsSupported         []string `json:"claims_supported,omitempty"`
 	ScopesSupported         []string `json:"scopes_supported,omitempty"`
+
+	// Extra holds additional top-level fields, such as vendor extensions, that are
+	// merged into the JSON encoding. Use ValidateExtraFields before setting it.
+	Extra map[string]interface{} `json:"-"`
+}
+
+// MarshalJSON encodes the document with its Extra fields merged in at the top
+// level. Extra fields never replace standard fields.
+func (d DiscoveryDocument) MarshalJSON() ([]byte, error) {
+	type plain DiscoveryDocument
+	data, err := json.Marshal(plain(d))
+	if err != nil || len(d.Extra) == 0 {
+		return data, err
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range d.Extra {
+		if _, ok := fields[name]; ok {
+			continue
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode extra field %s: %w", name, err)
+		}
+		fields[name] = raw
+	}
+	return json.Marshal(fields)
 }
 
 // ToJSON serializes the discovery document to indented JSON.
@@ -73,6 +103,12 @@ type JWK struct {
 	Use string `json:"use,omitempty"` // Key use (e.g., "sig")
 	N   string `json:"n,omitempty"`   // RSA modulus (base64url)
 	E   string `json:"e,omitempty"`   // RSA exponent (base64url)
+	Crv string `json:"crv,omitempty"` // EC curve (e.g., "P-256")
+	X   string `json:"x,omitempty"`   // EC x coordinate (base64url)
+	Y   string `json:"y,omitempty"`   // EC y coordinate (base64url)
+	// X5c is the X.509 certificate chain of the key, leaf first (standard base64 DER)
+	X5c []string `json:"x5c,omitempty"`
+	X5t string   `json:"x5t,omitempty"` // SHA-1 thumbprint of the leaf certificate (base64url)
 }
 
 // Validate checks that the JWK has the required fields and is a supported key type.
@@ -7,6 +7,8 @@ import (
 	"strings"
 
 	"github.com/spf13/viper"
+
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
 )
 
 // dnsLabelRe validates that a string is safe for use as a path component and DNS label.
@@ -16,6 +18,10 @@ var dnsLabelRe = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*[a-z0-9]$`)
 type Config struct {
 	Controller ControllerConfig `mapstructure:"controller"`
 	Publisher  PublisherConfig  `mapstructure:"publisher"`
+
+	// setKeys holds the lower-cased dotted paths of the settings present in the
+	// loaded config file, so Values can keep explicit false booleans
+	setKeys map[string]bool
 }
 
 // ControllerConfig holds controller-specific configuration.
@@ -24,6 +30,23 @@ type ControllerConfig struct {
 	RotationOverlap string               `mapstructure:"rotationOverlap"`
 	LeaderElection  LeaderElectionConfig `mapstructure:"leaderElection"`
 
+	// RotationCleanupMaxSourceAge skips removing expired keys while the source JWKS has
+	// not been synced for longer than this, since keys marked for removal while the
+	// source was unreachable may still be valid (default: "" = never skip)
+	RotationCleanupMaxSourceAge string `mapstructure:"rotationCleanupMaxSourceAge"`
+
+	// RotationMissingThreshold is how many consecutive syncs a key must be absent from
+	// the source JWKS before it is marked for removal (default: 0 = 2 syncs)
+	RotationMissingThreshold int `mapstructure:"rotationMissingThreshold"`
+
+	// RotationMissingGracePeriod is how long a key must have been absent before it is
+	// marked for removal, in addition to RotationMissingThreshold (default: "" = none)
+	RotationMissingGracePeriod string `mapstructure:"rotationMissingGracePeriod"`
+
+	// KeyOrder is the order of keys in the published JWKS: "kid", or "newestFirst" to
+	// list the most recently rotated-in keys first (default: "kid")
+	KeyOrder string `mapstructure:"keyOrder"`
+
 	// ClusterGroup enables multi-cluster shared issuer mode.
 	// All clusters with the same clusterGroup share one issuer URL and one aggregated JWKS endpoint.
 	// When set, this value is used as the storage prefix. Empty = single-cluster mode (default).
@@ -38,6 +61,190 @@ type ControllerConfig struct {
 
 	// ClusterTTL is how long to keep a cluster's keys after its last update (default: "48h")
 	ClusterTTL string `mapstructure:"clusterTTL"`
+
+	// ClusterFreshness is how recently a cluster must have written its JWKS to be reported
+	// healthy; lagging clusters are reported before ClusterTTL prunes them (default: half of ClusterTTL)
+	ClusterFreshness string `mapstructure:"clusterFreshness"`
+
+	// ExcludedClusters lists cluster IDs whose keys are left out of the aggregated JWKS,
+	// e.g. to stop trusting a compromised cluster without deleting its sub-path
+	ExcludedClusters []string `mapstructure:"excludedClusters"`
+
+	// GroupAggregation merges the root JWKS of every cluster group in the storage into one
+	// top-level JWKS. Requires ClusterGroup; enable it in one group only.
+	GroupAggregation GroupAggregationConfig `mapstructure:"groupAggregation"`
+
+	// SigningKeysOnly drops keys whose "use" is set to anything other than "sig" before publishing (default: false)
+	SigningKeysOnly bool `mapstructure:"signingKeysOnly"`
+
+	// RequireKeyAlg drops keys without an "alg" before publishing (default: false)
+	RequireKeyAlg bool `mapstructure:"requireKeyAlg"`
+
+	// RejectDuplicateKeyIDs refuses to publish a source JWKS that has different keys
+	// with the same kid, instead of warning and publishing one of them (default: false)
+	RejectDuplicateKeyIDs bool `mapstructure:"rejectDuplicateKeyIDs"`
+
+	// ClaimsSupported customizes claims_supported in the published discovery document
+	ClaimsSupported ClaimsSupportedConfig `mapstructure:"claimsSupported"`
+
+	// DiscoveryExtraFields are extra top-level fields, such as vendor extensions, added
+	// to the published discovery document. Names cannot shadow standard fields and are
+	// read case-insensitively, so they are published in lower case (default: none)
+	DiscoveryExtraFields map[string]interface{} `mapstructure:"discoveryExtraFields,omitempty"`
+
+	// RepublishInterval re-uploads unchanged metadata on this interval to refresh CDN caches
+	// (default: "" = off). Cannot be combined with publisher.skipUnchangedUploads.
+	RepublishInterval string `mapstructure:"republishInterval"`
+
+	// MetadataMaxAge forces a refetch and republish when the OIDC poller has not refreshed
+	// the metadata ConfigMap for this long; must exceed syncPeriod (default: "" = off)
+	MetadataMaxAge string `mapstructure:"metadataMaxAge"`
+
+	// PublishOnChange only uploads metadata whose content differs from the last publish,
+	// including across restarts (default: false)
+	PublishOnChange bool `mapstructure:"publishOnChange"`
+
+	// FailClosedOnPrivate reports not ready when the published metadata is not anonymously readable (default: false)
+	FailClosedOnPrivate bool `mapstructure:"failClosedOnPrivate"`
+
+	// IssuerCertExpiryWarning checks the issuer certificate pinned by the AWS thumbprint
+	// hourly and logs a warning when it expires within this window (default: "" =
+	// checked with a "720h" window only when federationDriftCheck targets aws)
+	IssuerCertExpiryWarning string `mapstructure:"issuerCertExpiryWarning"`
+
+	// MaxFetchBytes caps the size of fetched OIDC metadata and of cluster JWKS read
+	// during aggregation (default: 0 = 4 MiB)
+	MaxFetchBytes int64 `mapstructure:"maxFetchBytes"`
+
+	// APIServer points the OIDC metadata fetches at a specific API server endpoint,
+	// e.g. a dedicated discovery endpoint (default: the in-cluster endpoint)
+	APIServer APIServerConfig `mapstructure:"apiServer"`
+
+	// BridgeCacheTTL serves repeated OIDC metadata fetches, such as readiness checks on
+	// every replica, from memory for this long; must be shorter than syncPeriod (default: "" = off)
+	BridgeCacheTTL string `mapstructure:"bridgeCacheTTL"`
+
+	// MaxConcurrentReconciles is the number of reconcile workers (default: 1)
+	MaxConcurrentReconciles int `mapstructure:"maxConcurrentReconciles"`
+
+	// Heartbeat configures the optional Lease renewed by the active controller on every sync
+	Heartbeat HeartbeatConfig `mapstructure:"heartbeat"`
+
+	// MetadataWait configures how long the reconciler waits for the OIDC poller to
+	// write the metadata ConfigMap after startup
+	MetadataWait MetadataWaitConfig `mapstructure:"metadataWait"`
+
+	// StartupValidation configures how the publisher is validated before the controller starts
+	StartupValidation StartupValidationConfig `mapstructure:"startupValidation"`
+
+	// EventStream serves rotation, publish and sync events as server-sent events on the
+	// metrics endpoint at /events
+	EventStream EventStreamConfig `mapstructure:"eventStream"`
+
+	// FederationDriftCheck periodically verifies that the cloud federation provider
+	// still exists and trusts the issuer
+	FederationDriftCheck FederationDriftCheckConfig `mapstructure:"federationDriftCheck"`
+
+	// IssuerConfig reads issuer URL and federation audience overrides from an
+	// IssuerConfig custom resource in the controller namespace
+	IssuerConfig IssuerConfigResourceConfig `mapstructure:"issuerConfig"`
+}
+
+// IssuerConfigResourceConfig holds the IssuerConfig custom resource configuration.
+type IssuerConfigResourceConfig struct {
+	// Enabled watches the IssuerConfig resource; its CRD must be installed.
+	// Not supported together with clusterGroup.
+	Enabled bool `mapstructure:"enabled"`
+	// Name of the IssuerConfig resource (default: "kube-iam-assume")
+	Name string `mapstructure:"name,omitempty"`
+}
+
+// FederationDriftCheckConfig holds the federation provider drift check configuration.
+type FederationDriftCheckConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Provider is the federation provider type: aws or gcp
+	Provider string `mapstructure:"provider,omitempty"`
+	// Region is the AWS region of the IAM OIDC provider (aws only; default: the AWS SDK default)
+	Region string `mapstructure:"region,omitempty"`
+	// Project is the GCP project of the Workload Identity Pool provider (required for gcp)
+	Project string `mapstructure:"project,omitempty"`
+	// Audiences the provider must accept (default: audiences are not checked)
+	Audiences []string `mapstructure:"audiences,omitempty"`
+	// ProviderID pins the provider to check: the IAM OIDC provider ARN (aws) or the
+	// Workload Identity Pool provider resource name (gcp). When set, the check alerts
+	// if that provider trusts a different issuer than the one published (default: the
+	// provider is looked up by the published issuer)
+	ProviderID string `mapstructure:"providerID,omitempty"`
+	// Interval between checks (default: "1h")
+	Interval string `mapstructure:"interval,omitempty"`
+}
+
+// APIServerConfig holds the API server endpoint the OIDC metadata is fetched from.
+type APIServerConfig struct {
+	// Host is the https URL of the API server (default: "" = the in-cluster endpoint)
+	Host string `mapstructure:"host,omitempty"`
+	// TLSServerName is the name the endpoint certificate is verified against (default: the host name)
+	TLSServerName string `mapstructure:"tlsServerName,omitempty"`
+	// CAFile is a PEM bundle that verifies the endpoint certificate (default: the in-cluster CA)
+	CAFile string `mapstructure:"caFile,omitempty"`
+}
+
+// EventStreamConfig holds the server-sent events stream configuration.
+type EventStreamConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxSubscribers bounds the number of concurrent stream clients (default: 8)
+	MaxSubscribers int `mapstructure:"maxSubscribers,omitempty"`
+	// BufferSize is how many events a slow client may fall behind by before events are dropped for it (default: 32)
+	BufferSize int `mapstructure:"bufferSize,omitempty"`
+}
+
+// HeartbeatConfig holds heartbeat Lease configuration.
+type HeartbeatConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Name is the Lease name (default: "kube-iam-assume-heartbeat")
+	Name string `mapstructure:"name,omitempty"`
+	// Namespace is the Lease namespace (default: the controller namespace)
+	Namespace string `mapstructure:"namespace,omitempty"`
+}
+
+// MetadataWaitConfig holds the retry configuration for a missing OIDC metadata ConfigMap.
+type MetadataWaitConfig struct {
+	// Interval between checks for the ConfigMap (default: "10s", "0s" disables waiting)
+	Interval string `mapstructure:"interval,omitempty"`
+	// MaxAttempts bounds the checks before relying on the ConfigMap watch alone (default: 30)
+	MaxAttempts int `mapstructure:"maxAttempts,omitempty"`
+}
+
+// StartupValidationConfig holds publisher startup validation retry configuration.
+type StartupValidationConfig struct {
+	// MaxAttempts is the number of validation attempts before giving up (default: 1)
+	MaxAttempts int `mapstructure:"maxAttempts,omitempty"`
+	// InitialBackoff is the delay after the first failed attempt, doubled after each retry (default: "2s")
+	InitialBackoff string `mapstructure:"initialBackoff,omitempty"`
+	// MaxBackoff caps the delay between attempts (default: "30s")
+	MaxBackoff string `mapstructure:"maxBackoff,omitempty"`
+	// Timeout bounds the total time spent validating (empty = bounded by maxAttempts only)
+	Timeout string `mapstructure:"timeout,omitempty"`
+	// Strict fails startup after giving up. Otherwise the controller starts degraded and
+	// reports not-ready once elected leader until it publishes successfully (default: false)
+	Strict bool `mapstructure:"strict,omitempty"`
+}
+
+// ClaimsSupportedConfig holds claims_supported customization.
+type ClaimsSupportedConfig struct {
+	// Claims are appended to the claims advertised by the API server (duplicates dropped)
+	Claims []string `mapstructure:"claims,omitempty"`
+	// Override advertises only Claims instead of appending them (default: false)
+	Override bool `mapstructure:"override,omitempty"`
+}
+
+// GroupAggregationConfig holds group-of-groups aggregation configuration.
+type GroupAggregationConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Interval is how often the group leader aggregates group JWKS (default: the aggregation interval)
+	Interval string `mapstructure:"interval,omitempty"`
+	// GroupTTL is how long to keep a group's keys after its root JWKS was last updated (default: "96h")
+	GroupTTL string `mapstructure:"groupTTL,omitempty"`
 }
 
 // LeaderElectionConfig holds leader election configuration.
@@ -48,11 +255,38 @@ type LeaderElectionConfig struct {
 
 // PublisherConfig holds publisher configuration.
 type PublisherConfig struct {
-	Type  string       `mapstructure:"type"`
-	S3    *S3Config    `mapstructure:"s3,omitempty"`
-	GCS   *GCSConfig   `mapstructure:"gcs,omitempty"`
-	Azure *AzureConfig `mapstructure:"azure,omitempty"`
-	OCI   *OCIConfig   `mapstructure:"oci,omitempty"`
+	Type string `mapstructure:"type"`
+	// KeyLayout selects the object key layout: "wellKnown" (default) or "flat".
+	// The flat layout also writes the discovery document at a path without a dot segment.
+	KeyLayout string `mapstructure:"keyLayout,omitempty"`
+	// MinifyJWKS publishes the JWKS as compact JSON; MinifyDiscovery does the same
+	// for the discovery document. Both default to indented JSON, which changed the
+	// GCS output: GCS wrote compact JSON before these options existed.
+	MinifyJWKS      bool `mapstructure:"minifyJWKS,omitempty"`
+	MinifyDiscovery bool `mapstructure:"minifyDiscovery,omitempty"`
+	// ObjectTags are extra tags applied to every published object, on top of the
+	// standard managed-by, cluster and group tags. S3 stores them as object tags;
+	// GCS, Azure and OCI store them as object metadata.
+	ObjectTags map[string]string `mapstructure:"objectTags,omitempty"`
+	// DisableObjectTags skips tagging published objects entirely, for buckets
+	// where the publisher lacks s3:PutObjectTagging.
+	DisableObjectTags bool `mapstructure:"disableObjectTags,omitempty"`
+	// KeyThumbprintTag also tags JWKS objects with the RFC 7638 thumbprints of
+	// the published keys. Ignored when DisableObjectTags is set.
+	KeyThumbprintTag bool `mapstructure:"keyThumbprintTag,omitempty"`
+	// SkipUnchangedUploads stores a content hash in the metadata of published
+	// objects and skips an upload when the stored object already has the hash
+	// of the intended content, e.g. when several replicas publish at once.
+	// Cannot be combined with controller.republishInterval.
+	SkipUnchangedUploads bool `mapstructure:"skipUnchangedUploads,omitempty"`
+	// JWKSHistory additionally writes each distinct published JWKS to
+	// history/<unix seconds>/jwks.json and keeps this many of the newest
+	// snapshots, for forensics (default: 0, disabled).
+	JWKSHistory int          `mapstructure:"jwksHistory,omitempty"`
+	S3          *S3Config    `mapstructure:"s3,omitempty"`
+	GCS         *GCSConfig   `mapstructure:"gcs,omitempty"`
+	Azure       *AzureConfig `mapstructure:"azure,omitempty"`
+	OCI         *OCIConfig   `mapstructure:"oci,omitempty"`
 }
 
 // AzureConfig holds Azure Blob Storage publisher configuration.
@@ -66,6 +300,8 @@ type AzureConfig struct {
 	ClientSecret       string `mapstructure:"clientSecret,omitempty"`
 	CacheControl       string `mapstructure:"cacheControl,omitempty"`
 	ContentType        string `mapstructure:"contentType,omitempty"`
+	ServiceURL         string `mapstructure:"serviceURL,omitempty"`
+	AccountKey         string `mapstructure:"accountKey,omitempty"`
 }
 
 // OCIConfig holds OCI Object Storage publisher configuration.
@@ -93,6 +329,17 @@ type S3Config struct {
 	UseIRSA        bool   `mapstructure:"useIRSA,omitempty"`
 	CacheControl   string `mapstructure:"cacheControl,omitempty"`
 	ContentType    string `mapstructure:"contentType,omitempty"`
+	// Secondary is an optional second bucket, usually in another region, that every
+	// object is also written to. Writes are skipped while the primary bucket
+	// replicates to it.
+	Secondary *S3SecondaryConfig `mapstructure:"secondary,omitempty"`
+}
+
+// S3SecondaryConfig holds the secondary S3 bucket configuration.
+type S3SecondaryConfig struct {
+	Bucket   string `mapstructure:"bucket"`
+	Region   string `mapstructure:"region"`
+	Endpoint string `mapstructure:"endpoint,omitempty"`
 }
 
 // GCSConfig holds GCS publisher configuration.
@@ -100,11 +347,50 @@ type GCSConfig struct {
 	Bucket              string `mapstructure:"bucket"`
 	Project             string `mapstructure:"project"`
 	Prefix              string `mapstructure:"prefix,omitempty"`
+	Endpoint            string `mapstructure:"endpoint,omitempty"`
 	UseWorkloadIdentity bool   `mapstructure:"useWorkloadIdentity,omitempty"`
 	CacheControl        string `mapstructure:"cacheControl,omitempty"`
 	ContentType         string `mapstructure:"contentType,omitempty"`
 }
 
+// Prefix returns the prefix configured for the selected publisher type, or "" if none is set.
+func (p *PublisherConfig) Prefix() string {
+	switch p.Type {
+	case "s3":
+		if p.S3 != nil {
+			return p.S3.Prefix
+		}
+	case "gcs":
+		if p.GCS != nil {
+			return p.GCS.Prefix
+		}
+	case "azure":
+		if p.Azure != nil {
+			return p.Azure.Prefix
+		}
+	case "oci":
+		if p.OCI != nil {
+			return p.OCI.Prefix
+		}
+	}
+	return ""
+}
+
+// PrefixOverridden reports whether an explicitly configured publisher prefix will be
+// replaced by clusterGroup. In multi-cluster mode the group name is always used as the
+// storage prefix, so a differing prefix is silently discarded.
+// Returns the discarded prefix and true when that happens.
+func (c *Config) PrefixOverridden() (string, bool) {
+	if c.Controller.ClusterGroup == "" {
+		return "", false
+	}
+	prefix := c.Publisher.Prefix()
+	if prefix == "" || prefix == c.Controller.ClusterGroup {
+		return "", false
+	}
+	return prefix, true
+}
+
 // LoadConfig loads the configuration from a file.
 func LoadConfig(configPath string) (*Config, error) {
 	viper.SetConfigFile(configPath)
@@ -120,19 +406,129 @@ func LoadConfig(configPath string) (*Config, error) {
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	config.setKeys = make(map[string]bool)
+	for _, key := range viper.AllKeys() {
+		config.setKeys[key] = true
+	}
 
-	if err := config.Controller.validate(); err != nil {
-		return nil, fmt.Errorf("invalid controller config: %w", err)
+	if err := config.validate(); err != nil {
+		return nil, err
 	}
 
 	return &config, nil
 }
 
+// validate validates each section and the settings that span sections.
+func (c *Config) validate() error {
+	if err := c.Controller.validate(); err != nil {
+		return fmt.Errorf("invalid controller config: %w", err)
+	}
+	if err := c.Publisher.validate(); err != nil {
+		return fmt.Errorf("invalid publisher config: %w", err)
+	}
+	if c.Publisher.SkipUnchangedUploads && c.Controller.RepublishInterval != "" {
+		// The publisher would skip the re-upload meant to refresh CDN caches
+		return fmt.Errorf("publisher.skipUnchangedUploads cannot be combined with controller.republishInterval")
+	}
+	return nil
+}
+
+// validate validates PublisherConfig fields.
+func (c *PublisherConfig) validate() error {
+	switch c.KeyLayout {
+	case "", "wellKnown", "flat":
+	default:
+		return fmt.Errorf("keyLayout %q must be one of wellKnown, flat", c.KeyLayout)
+	}
+	if c.JWKSHistory < 0 {
+		return fmt.Errorf("jwksHistory must not be negative, got %d", c.JWKSHistory)
+	}
+	return nil
+}
+
+// validate validates FederationDriftCheckConfig fields.
+func (c *FederationDriftCheckConfig) validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	switch c.Provider {
+	case "aws":
+	case "gcp":
+		if c.Project == "" {
+			return fmt.Errorf("federationDriftCheck.project is required for provider gcp")
+		}
+	default:
+		return fmt.Errorf("federationDriftCheck.provider %q must be one of aws, gcp", c.Provider)
+	}
+	return nil
+}
+
+// validate validates APIServerConfig fields.
+func (c *APIServerConfig) validate() error {
+	if c.Host == "" {
+		if c.TLSServerName != "" || c.CAFile != "" {
+			return fmt.Errorf("apiServer.tlsServerName and apiServer.caFile require apiServer.host to be set")
+		}
+		return nil
+	}
+	if err := bridge.ValidateAPIServerHost(c.Host); err != nil {
+		return fmt.Errorf("apiServer.host: %w", err)
+	}
+	return nil
+}
+
 // validate validates ControllerConfig fields.
 func (c *ControllerConfig) validate() error {
+	if c.MaxConcurrentReconciles < 0 {
+		return fmt.Errorf("maxConcurrentReconciles must not be negative, got %d", c.MaxConcurrentReconciles)
+	}
+	if c.RotationMissingThreshold < 0 {
+		return fmt.Errorf("rotationMissingThreshold must not be negative, got %d", c.RotationMissingThreshold)
+	}
+	if c.MetadataWait.MaxAttempts < 0 {
+		return fmt.Errorf("metadataWait.maxAttempts must not be negative, got %d", c.MetadataWait.MaxAttempts)
+	}
+	if c.StartupValidation.MaxAttempts < 0 {
+		return fmt.Errorf("startupValidation.maxAttempts must not be negative, got %d", c.StartupValidation.MaxAttempts)
+	}
+	switch c.KeyOrder {
+	case "", "kid", "newestFirst":
+	default:
+		return fmt.Errorf("keyOrder %q must be one of kid, newestFirst", c.KeyOrder)
+	}
+	if c.EventStream.MaxSubscribers < 0 {
+		return fmt.Errorf("eventStream.maxSubscribers must not be negative, got %d", c.EventStream.MaxSubscribers)
+	}
+	if c.EventStream.BufferSize < 0 {
+		return fmt.Errorf("eventStream.bufferSize must not be negative, got %d", c.EventStream.BufferSize)
+	}
+	if err := c.FederationDriftCheck.validate(); err != nil {
+		return err
+	}
+	if err := c.APIServer.validate(); err != nil {
+		return err
+	}
+	for i, claim := range c.ClaimsSupported.Claims {
+		if strings.TrimSpace(claim) == "" {
+			return fmt.Errorf("claimsSupported.claims[%d] must not be empty", i)
+		}
+	}
+	if err := bridge.ValidateExtraFields(c.DiscoveryExtraFields); err != nil {
+		return fmt.Errorf("discoveryExtraFields: %w", err)
+	}
 	if c.ClusterGroup == "" {
+		if c.GroupAggregation.Enabled {
+			return fmt.Errorf("groupAggregation requires clusterGroup to be set")
+		}
+		if len(c.ExcludedClusters) > 0 {
+			return fmt.Errorf("excludedClusters requires clusterGroup to be set")
+		}
 		return nil // single-cluster mode, no further checks needed
 	}
+	if c.IssuerConfig.Enabled {
+		// The aggregation leaders publish the group's issuer from the bucket URL
+		return fmt.Errorf("issuerConfig cannot be enabled when clusterGroup is set")
+	}
 	if !dnsLabelRe.MatchString(c.ClusterGroup) {
 		return fmt.Errorf("clusterGroup %q must match ^[a-z0-9][a-z0-9-]*[a-z0-9]$", c.ClusterGroup)
 	}
@@ -142,5 +538,10 @@ func (c *ControllerConfig) validate() error {
 	if !dnsLabelRe.MatchString(c.ClusterID) {
 		return fmt.Errorf("clusterID %q must match ^[a-z0-9][a-z0-9-]*[a-z0-9]$", c.ClusterID)
 	}
+	for i, clusterID := range c.ExcludedClusters {
+		if !dnsLabelRe.MatchString(clusterID) {
+			return fmt.Errorf("excludedClusters[%d] %q must match ^[a-z0-9][a-z0-9-]*[a-z0-9]$", i, clusterID)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,275 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_PrefixOverridden(t *testing.T) {
+	tests := []struct {
+		name         string
+		clusterGroup string
+		publisher    PublisherConfig
+		wantPrefix   string
+		wantOverride bool
+	}{
+		{
+			name:      "single-cluster mode keeps prefix",
+			publisher: PublisherConfig{Type: "s3", S3: &S3Config{Prefix: "oidc"}},
+		},
+		{
+			name:         "group without prefix",
+			clusterGroup: "prod",
+			publisher:    PublisherConfig{Type: "s3", S3: &S3Config{}},
+		},
+		{
+			name:         "group equal to prefix",
+			clusterGroup: "prod",
+			publisher:    PublisherConfig{Type: "s3", S3: &S3Config{Prefix: "prod"}},
+		},
+		{
+			name:         "s3 prefix differs from group",
+			clusterGroup: "prod",
+			publisher:    PublisherConfig{Type: "s3", S3: &S3Config{Prefix: "oidc"}},
+			wantPrefix:   "oidc",
+			wantOverride: true,
+		},
+		{
+			name:         "gcs prefix differs from group",
+			clusterGroup: "prod",
+			publisher:    PublisherConfig{Type: "gcs", GCS: &GCSConfig{Prefix: "oidc"}},
+			wantPrefix:   "oidc",
+			wantOverride: true,
+		},
+		{
+			name:         "azure prefix differs from group",
+			clusterGroup: "prod",
+			publisher:    PublisherConfig{Type: "azure", Azure: &AzureConfig{Prefix: "oidc"}},
+			wantPrefix:   "oidc",
+			wantOverride: true,
+		},
+		{
+			name:         "oci prefix differs from group",
+			clusterGroup: "prod",
+			publisher:    PublisherConfig{Type: "oci", OCI: &OCIConfig{Prefix: "oidc"}},
+			wantPrefix:   "oidc",
+			wantOverride: true,
+		},
+		{
+			name:         "prefix on a publisher that is not selected",
+			clusterGroup: "prod",
+			publisher:    PublisherConfig{Type: "gcs", GCS: &GCSConfig{}, S3: &S3Config{Prefix: "oidc"}},
+		},
+		{
+			name:         "missing publisher section",
+			clusterGroup: "prod",
+			publisher:    PublisherConfig{Type: "azure"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Controller: ControllerConfig{ClusterGroup: tt.clusterGroup, ClusterID: "cluster-a"},
+				Publisher:  tt.publisher,
+			}
+			prefix, overridden := cfg.PrefixOverridden()
+			assert.Equal(t, tt.wantOverride, overridden)
+			assert.Equal(t, tt.wantPrefix, prefix)
+		})
+	}
+}
+
+func TestPublisherConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		keyLayout string
+		wantErr   bool
+	}{
+		{name: "default layout"},
+		{name: "well-known layout", keyLayout: "wellKnown"},
+		{name: "flat layout", keyLayout: "flat"},
+		{name: "unknown layout", keyLayout: "nested", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &PublisherConfig{Type: "s3", KeyLayout: tt.keyLayout}
+			err := cfg.validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPublisherConfig_ValidateJWKSHistory(t *testing.T) {
+	assert.NoError(t, (&PublisherConfig{Type: "s3"}).validate())
+	assert.NoError(t, (&PublisherConfig{Type: "s3", JWKSHistory: 10}).validate())
+	assert.Error(t, (&PublisherConfig{Type: "s3", JWKSHistory: -1}).validate())
+}
+
+func TestConfig_ValidateSkipUnchangedUploadsWithRepublish(t *testing.T) {
+	cfg := &Config{Publisher: PublisherConfig{Type: "s3", SkipUnchangedUploads: true}}
+	assert.NoError(t, cfg.validate())
+
+	cfg.Controller.RepublishInterval = "1h"
+	assert.ErrorContains(t, cfg.validate(), "skipUnchangedUploads cannot be combined with controller.republishInterval")
+
+	cfg.Publisher.SkipUnchangedUploads = false
+	assert.NoError(t, cfg.validate())
+}
+
+func TestControllerConfig_ValidateMaxConcurrentReconciles(t *testing.T) {
+	assert.NoError(t, (&ControllerConfig{}).validate())
+	assert.NoError(t, (&ControllerConfig{MaxConcurrentReconciles: 3}).validate())
+	assert.Error(t, (&ControllerConfig{MaxConcurrentReconciles: -1}).validate())
+}
+
+func TestControllerConfig_ValidateRotationMissingThreshold(t *testing.T) {
+	assert.NoError(t, (&ControllerConfig{RotationMissingThreshold: 1}).validate())
+	assert.ErrorContains(t, (&ControllerConfig{RotationMissingThreshold: -1}).validate(), "rotationMissingThreshold")
+}
+
+func TestControllerConfig_ValidateGroupAggregation(t *testing.T) {
+	assert.Error(t, (&ControllerConfig{GroupAggregation: GroupAggregationConfig{Enabled: true}}).validate())
+	assert.NoError(t, (&ControllerConfig{
+		ClusterGroup:     "prod",
+		ClusterID:        "prod-a",
+		GroupAggregation: GroupAggregationConfig{Enabled: true},
+	}).validate())
+}
+
+func TestControllerConfig_ValidateExcludedClusters(t *testing.T) {
+	assert.NoError(t, (&ControllerConfig{ClusterGroup: "prod", ClusterID: "prod-a", ExcludedClusters: []string{"prod-b"}}).validate())
+	assert.ErrorContains(t, (&ControllerConfig{ExcludedClusters: []string{"prod-b"}}).validate(), "requires clusterGroup")
+	assert.ErrorContains(t,
+		(&ControllerConfig{ClusterGroup: "prod", ClusterID: "prod-a", ExcludedClusters: []string{"Prod_B"}}).validate(),
+		"excludedClusters[0]")
+}
+
+func TestControllerConfig_ValidateIssuerConfigWithClusterGroup(t *testing.T) {
+	assert.NoError(t, (&ControllerConfig{IssuerConfig: IssuerConfigResourceConfig{Enabled: true}}).validate())
+	assert.ErrorContains(t,
+		(&ControllerConfig{ClusterGroup: "prod", ClusterID: "prod-a", IssuerConfig: IssuerConfigResourceConfig{Enabled: true}}).validate(),
+		"issuerConfig cannot be enabled when clusterGroup is set")
+}
+
+func TestControllerConfig_ValidateClaimsSupported(t *testing.T) {
+	assert.NoError(t, (&ControllerConfig{ClaimsSupported: ClaimsSupportedConfig{Claims: []string{"sub", "team"}}}).validate())
+	assert.NoError(t, (&ControllerConfig{ClaimsSupported: ClaimsSupportedConfig{Override: true}}).validate())
+	assert.ErrorContains(t,
+		(&ControllerConfig{ClaimsSupported: ClaimsSupportedConfig{Claims: []string{"sub", ""}}}).validate(),
+		"claimsSupported.claims[1]")
+}
+
+func TestControllerConfig_ValidateDiscoveryExtraFields(t *testing.T) {
+	assert.NoError(t, (&ControllerConfig{DiscoveryExtraFields: map[string]interface{}{"code_challenge_methods_supported": []interface{}{"S256"}}}).validate())
+	assert.ErrorContains(t, (&ControllerConfig{DiscoveryExtraFields: map[string]interface{}{"issuer": "https://example.com"}}).validate(), "discoveryExtraFields")
+}
+
+func TestControllerConfig_ValidateMetadataWait(t *testing.T) {
+	assert.NoError(t, (&ControllerConfig{MetadataWait: MetadataWaitConfig{Interval: "5s", MaxAttempts: 3}}).validate())
+	assert.ErrorContains(t,
+		(&ControllerConfig{MetadataWait: MetadataWaitConfig{MaxAttempts: -1}}).validate(),
+		"metadataWait.maxAttempts")
+}
+
+func TestControllerConfig_ValidateKeyOrder(t *testing.T) {
+	for _, order := range []string{"", "kid", "newestFirst"} {
+		assert.NoError(t, (&ControllerConfig{KeyOrder: order}).validate(), order)
+	}
+	assert.ErrorContains(t, (&ControllerConfig{KeyOrder: "oldestFirst"}).validate(), "keyOrder")
+}
+
+func TestControllerConfig_ValidateFederationDriftCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		check   FederationDriftCheckConfig
+		wantErr string
+	}{
+		{name: "disabled", check: FederationDriftCheckConfig{Provider: "azure"}},
+		{name: "aws", check: FederationDriftCheckConfig{Enabled: true, Provider: "aws", Region: "us-west-2"}},
+		{name: "gcp", check: FederationDriftCheckConfig{Enabled: true, Provider: "gcp", Project: "my-project"}},
+		{name: "gcp without project", check: FederationDriftCheckConfig{Enabled: true, Provider: "gcp"}, wantErr: "federationDriftCheck.project"},
+		{name: "unsupported provider", check: FederationDriftCheckConfig{Enabled: true, Provider: "azure"}, wantErr: "federationDriftCheck.provider"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&ControllerConfig{FederationDriftCheck: tt.check}).validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestControllerConfig_ValidateAPIServer(t *testing.T) {
+	assert.NoError(t, (&ControllerConfig{APIServer: APIServerConfig{Host: "https://kube-oidc.internal:6443", TLSServerName: "kubernetes"}}).validate())
+	assert.ErrorContains(t,
+		(&ControllerConfig{APIServer: APIServerConfig{Host: "http://kube-oidc.internal:6443"}}).validate(),
+		"apiServer.host")
+	assert.ErrorContains(t,
+		(&ControllerConfig{APIServer: APIServerConfig{CAFile: "/etc/kube-oidc/ca.crt"}}).validate(),
+		"require apiServer.host")
+}
+
+func TestControllerConfig_ValidateEventStream(t *testing.T) {
+	assert.NoError(t, (&ControllerConfig{EventStream: EventStreamConfig{Enabled: true, MaxSubscribers: 2, BufferSize: 16}}).validate())
+	assert.ErrorContains(t,
+		(&ControllerConfig{EventStream: EventStreamConfig{MaxSubscribers: -1}}).validate(),
+		"eventStream.maxSubscribers")
+	assert.ErrorContains(t,
+		(&ControllerConfig{EventStream: EventStreamConfig{BufferSize: -1}}).validate(),
+		"eventStream.bufferSize")
+}
+
+func TestControllerConfig_ValidateStartupValidation(t *testing.T) {
+	assert.NoError(t, (&ControllerConfig{StartupValidation: StartupValidationConfig{MaxAttempts: 5, Strict: true}}).validate())
+	assert.ErrorContains(t,
+		(&ControllerConfig{StartupValidation: StartupValidationConfig{MaxAttempts: -1}}).validate(),
+		"startupValidation.maxAttempts")
+}
+
+func TestConfig_ValuesOmitsZeroValues(t *testing.T) {
+	cfg := &Config{
+		Controller: ControllerConfig{SyncPeriod: "60s"},
+		Publisher:  PublisherConfig{Type: "s3", S3: &S3Config{Bucket: "oidc-bucket", UseIRSA: true}},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"controller": map[string]interface{}{"syncPeriod": "60s"},
+		"publisher": map[string]interface{}{
+			"type": "s3",
+			"s3":   map[string]interface{}{"bucket": "oidc-bucket", "useIRSA": true},
+		},
+	}, cfg.Values())
+}
+
+func TestConfig_ValuesKeepsExplicitFalse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`controller:
+  leaderElection:
+    enabled: false
+publisher:
+  type: s3
+  s3:
+    bucket: oidc-bucket
+`), 0o600))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	values := cfg.Values()
+	assert.Equal(t, map[string]interface{}{"leaderElection": map[string]interface{}{"enabled": false}}, values["controller"])
+	// Unset booleans stay omitted
+	assert.NotContains(t, values["publisher"], "minifyJWKS")
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Values returns the configuration as a nested map keyed by the mapstructure
+// field names used in config files. Zero values are omitted so the result only
+// carries settings that were explicitly configured; a false boolean set in the
+// loaded config file is kept, since it overrides defaults that are true.
+func (c *Config) Values() map[string]interface{} {
+	values, _ := structValues(reflect.ValueOf(*c), "", c.setKeys).(map[string]interface{})
+	return values
+}
+
+// structValues converts a struct (or pointer to one) into a map, recursing into
+// nested structs. path is the dotted path of v, checked against setKeys for
+// booleans. It returns nil when every field is an unset zero value.
+func structValues(v reflect.Value, path string, setKeys map[string]bool) interface{} {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		if v.IsZero() && !(v.Kind() == reflect.Bool && setKeys[strings.ToLower(path)]) {
+			return nil
+		}
+		return v.Interface()
+	}
+
+	out := make(map[string]interface{})
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		fieldPath := name
+		if path != "" {
+			fieldPath = path + "." + name
+		}
+		if value := structValues(v.Field(i), fieldPath, setKeys); value != nil {
+			out[name] = value
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
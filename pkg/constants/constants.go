@@ -11,12 +11,25 @@ const (
 	// DefaultLeaderElectionID is the default leader election lock name.
 	DefaultLeaderElectionID = "kube-iam-assume-controller-leader-election"
 
+	// DefaultHeartbeatLeaseName is the default name for the heartbeat lease.
+	DefaultHeartbeatLeaseName = "kube-iam-assume-heartbeat"
+
 	// DefaultRotationConfigMapName is the default name for the rotation state configmap.
 	DefaultRotationConfigMapName = "kube-iam-assume-rotation-state"
 
 	// DefaultOIDCConfigMapName is the default name for the OIDC metadata configmap.
 	DefaultOIDCConfigMapName = "kube-iam-assume-oidc-metadata"
 
+	// OIDCLastFetchAnnotation records on the OIDC metadata configmap when the OIDC
+	// poller last fetched the metadata from the API server (RFC 3339).
+	OIDCLastFetchAnnotation = "kube-iam-assume.io/last-fetch"
+
+	// DefaultIssuerConfigName is the default name of the IssuerConfig resource.
+	DefaultIssuerConfigName = "kube-iam-assume"
+
+	// DefaultClusterHealthConfigMapName is the default name for the multi-cluster health configmap.
+	DefaultClusterHealthConfigMapName = "kube-iam-assume-cluster-health"
+
 	// DefaultGCPWorkloadIdentityPoolID is the default ID for GCP Workload Identity Pool.
 	DefaultGCPWorkloadIdentityPoolID = "kube-iam-assume-pool"
 
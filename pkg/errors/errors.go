@@ -27,6 +27,12 @@ const (
 	CodePermission ErrorCode = "PermissionError"
 	// CodeInternal indicates an unexpected internal error (retryable).
 	CodeInternal ErrorCode = "InternalError"
+	// CodeIssuerMismatch indicates published metadata names a different issuer (not retryable).
+	CodeIssuerMismatch ErrorCode = "IssuerMismatchError"
+	// CodeInvalidJWKS indicates a published JWKS that relying parties cannot use (not retryable).
+	CodeInvalidJWKS ErrorCode = "InvalidJWKSError"
+	// CodeDrift indicates published metadata differs from the cluster's (not retryable).
+	CodeDrift ErrorCode = "DriftError"
 )
 
 // retryableCodes contains the set of error codes that indicate retryable errors.
@@ -108,6 +114,21 @@ func NewInternalError(component, message string, err error) *KubeAssumeError {
 	return New(CodeInternal, component, message, err)
 }
 
+// NewIssuerMismatchError creates an issuer mismatch error.
+func NewIssuerMismatchError(component, message string, err error) *KubeAssumeError {
+	return New(CodeIssuerMismatch, component, message, err)
+}
+
+// NewInvalidJWKSError creates an invalid JWKS error.
+func NewInvalidJWKSError(component, message string, err error) *KubeAssumeError {
+	return New(CodeInvalidJWKS, component, message, err)
+}
+
+// NewDriftError creates a drift error.
+func NewDriftError(component, message string, err error) *KubeAssumeError {
+	return New(CodeDrift, component, message, err)
+}
+
 // --- Type checking helpers ---
 
 // AsKubeAssumeError extracts a KubeAssumeError from the error chain.
@@ -0,0 +1,202 @@
+// Package eventstream broadcasts controller activity (key rotations, publishes and
+// sync results) to operators over server-sent events, so dashboards can follow the
+// controller without polling.
+package eventstream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hixichen/kube-iam-assume/pkg/rotation"
+)
+
+const (
+	// DefaultMaxSubscribers bounds the number of concurrent stream clients.
+	DefaultMaxSubscribers = 8
+	// DefaultBufferSize is how many events a slow subscriber may fall behind by
+	// before further events are dropped for it.
+	DefaultBufferSize = 32
+)
+
+// Type identifies what an Event reports.
+type Type string
+
+const (
+	// TypeRotation reports a key rotation event.
+	TypeRotation Type = "rotation"
+	// TypePublish reports an upload of the OIDC metadata to the backend.
+	TypePublish Type = "publish"
+	// TypeSync reports the result of a sync.
+	TypeSync Type = "sync"
+)
+
+// Sync results reported in Event.Status.
+const (
+	StatusSuccess = "success"
+	StatusError   = "error"
+)
+
+// Event is one entry of the stream.
+type Event struct {
+	Type      Type      `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	// Rotation is the rotation event type (NewKey, KeyExpired) for TypeRotation
+	Rotation rotation.EventType `json:"rotation,omitempty"`
+	KeyID    string             `json:"keyId,omitempty"`
+	// Status is the sync result (success, error) for TypeSync
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// FromRotation converts a rotation event into a stream event.
+func FromRotation(event rotation.Event) Event {
+	return Event{
+		Type:      TypeRotation,
+		Timestamp: event.Timestamp,
+		Rotation:  event.Type,
+		KeyID:     event.KeyID,
+		Message:   event.Message,
+	}
+}
+
+// ErrTooManySubscribers is returned by Subscribe when the subscriber limit is reached.
+var ErrTooManySubscribers = errors.New("too many event stream subscribers")
+
+// ErrClosed is returned by Subscribe once the Broker is closed.
+var ErrClosed = errors.New("event stream closed")
+
+// Broker fans events out to subscribers. Publishing never blocks: a subscriber
+// whose buffer is full misses events until it catches up.
+type Broker struct {
+	maxSubscribers int
+	bufferSize     int
+
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+
+	// done is closed by Close to end every stream
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewBroker creates a Broker. Values below 1 select the defaults.
+func NewBroker(maxSubscribers, bufferSize int) *Broker {
+	if maxSubscribers < 1 {
+		maxSubscribers = DefaultMaxSubscribers
+	}
+	if bufferSize < 1 {
+		bufferSize = DefaultBufferSize
+	}
+	return &Broker{
+		maxSubscribers: maxSubscribers,
+		bufferSize:     bufferSize,
+		subscribers:    make(map[chan Event]struct{}),
+		done:           make(chan struct{}),
+	}
+}
+
+// Start closes the Broker when ctx is cancelled, so open streams end and the
+// server serving them can shut down. It lets a manager run the Broker.
+func (b *Broker) Start(ctx context.Context) error {
+	<-ctx.Done()
+	b.Close()
+	return nil
+}
+
+// NeedLeaderElection lets every replica serve its event stream.
+func (b *Broker) NeedLeaderElection() bool { return false }
+
+// Close ends every open stream and rejects new subscribers.
+func (b *Broker) Close() {
+	b.closeOnce.Do(func() { close(b.done) })
+}
+
+// Publish delivers event to every subscriber. It is safe to call on a nil Broker.
+func (b *Broker) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe registers a subscriber. The returned function unsubscribes and must be called.
+func (b *Broker) Subscribe() (<-chan Event, func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	select {
+	case <-b.done:
+		return nil, nil, ErrClosed
+	default:
+	}
+	if len(b.subscribers) >= b.maxSubscribers {
+		return nil, nil, ErrTooManySubscribers
+	}
+
+	ch := make(chan Event, b.bufferSize)
+	b.subscribers[ch] = struct{}{}
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.subscribers, ch)
+		})
+	}, nil
+}
+
+// ServeHTTP streams events to the client as server-sent events until it
+// disconnects or the Broker is closed.
+func (b *Broker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	events, unsubscribe, err := b.Subscribe()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-b.done:
+			return
+		case event := <-events:
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package eventstream
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hixichen/kube-iam-assume/pkg/rotation"
+)
+
+func TestBroker_ServeHTTPDeliversEvents(t *testing.T) {
+	broker := NewBroker(1, 4)
+	srv := httptest.NewServer(broker)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// The handler subscribes before sending the headers
+	broker.Publish(FromRotation(rotation.Event{
+		Type:      rotation.EventNewKey,
+		KeyID:     "key-2",
+		Timestamp: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}))
+
+	reader := bufio.NewReader(resp.Body)
+	eventLine, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "event: rotation\n", eventLine)
+	dataLine, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(dataLine, "data: "))
+
+	var got Event
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(dataLine, "data: ")), &got))
+	assert.Equal(t, TypeRotation, got.Type)
+	assert.Equal(t, rotation.EventNewKey, got.Rotation)
+	assert.Equal(t, "key-2", got.KeyID)
+
+	// The subscriber limit is enforced
+	second, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer func() { _ = second.Body.Close() }()
+	assert.Equal(t, http.StatusServiceUnavailable, second.StatusCode)
+}
+
+func TestBroker_PublishDoesNotBlockOnSlowSubscribers(t *testing.T) {
+	broker := NewBroker(2, 1)
+	events, unsubscribe, err := broker.Subscribe()
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	broker.Publish(Event{Type: TypeSync, Status: StatusSuccess})
+	broker.Publish(Event{Type: TypeSync, Status: StatusError})
+
+	got := <-events
+	assert.Equal(t, StatusSuccess, got.Status)
+	assert.False(t, got.Timestamp.IsZero())
+	select {
+	case extra := <-events:
+		t.Fatalf("expected the second event to be dropped, got %+v", extra)
+	default:
+	}
+}
+
+func TestBroker_Unsubscribe(t *testing.T) {
+	broker := NewBroker(1, 1)
+	_, unsubscribe, err := broker.Subscribe()
+	require.NoError(t, err)
+	_, _, err = broker.Subscribe()
+	assert.ErrorIs(t, err, ErrTooManySubscribers)
+
+	unsubscribe()
+	unsubscribe()
+	_, unsubscribe, err = broker.Subscribe()
+	require.NoError(t, err)
+	unsubscribe()
+
+	var nilBroker *Broker
+	nilBroker.Publish(Event{Type: TypePublish})
+}
+
+func TestBroker_StartClosesStreamsOnShutdown(t *testing.T) {
+	broker := NewBroker(1, 1)
+	srv := httptest.NewServer(broker)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	started := make(chan error, 1)
+	go func() { started <- broker.Start(ctx) }()
+	cancel()
+	require.NoError(t, <-started)
+
+	// The open stream ends, so the server shuts down without waiting for the client
+	shutdownCtx, shutdownCancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer shutdownCancel()
+	require.NoError(t, srv.Config.Shutdown(shutdownCtx))
+	_, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	_, _, err = broker.Subscribe()
+	assert.ErrorIs(t, err, ErrClosed)
+}
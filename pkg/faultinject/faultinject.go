@@ -0,0 +1,242 @@
+// Package faultinject makes the publisher and the OIDC bridge fail in controlled
+// ways, so the controller's retry, requeue and fail-closed paths can be exercised
+// end to end. It is only active when the controller runs with --enable-fault-injection.
+package faultinject
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
+	kaerrors "github.com/hixichen/kube-iam-assume/pkg/errors"
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
+)
+
+// Operation is a call that faults can be injected into.
+type Operation string
+
+const (
+	// OperationPublish is Publisher.Publish.
+	OperationPublish Operation = "publish"
+	// OperationValidate is Publisher.Validate.
+	OperationValidate Operation = "validate"
+	// OperationHealthCheck is Publisher.HealthCheck.
+	OperationHealthCheck Operation = "healthCheck"
+	// OperationFetch is every OIDC bridge fetch from the API server.
+	OperationFetch Operation = "fetch"
+)
+
+// Mode is how an injected fault fails.
+type Mode string
+
+const (
+	// ModeError fails with a generic error.
+	ModeError Mode = "error"
+	// ModePermission fails with a permission error.
+	ModePermission Mode = "permission"
+	// ModeTimeout blocks for the fault's delay, or until the context is done, then
+	// fails with context.DeadlineExceeded.
+	ModeTimeout Mode = "timeout"
+)
+
+// ErrInjected is wrapped by every injected failure.
+var ErrInjected = errors.New("injected fault")
+
+// Fault configures failures for one operation.
+type Fault struct {
+	Operation Operation `json:"operation"`
+	Mode      Mode      `json:"mode"`
+	// Count is the number of calls that fail before calls succeed again (0 fails every call)
+	Count int `json:"count,omitempty"`
+	// Delay is how long a timeout blocks (default: until the context is done)
+	Delay string `json:"delay,omitempty"`
+}
+
+// Config is the fault injection configuration file.
+type Config struct {
+	Faults []Fault `json:"faults"`
+}
+
+// LoadConfig reads a JSON fault injection configuration file.
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read fault injection config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("failed to parse fault injection config: %w", err)
+	}
+	return cfg, nil
+}
+
+// fault is a Fault with its parsed delay and remaining failures.
+type fault struct {
+	mode      Mode
+	delay     time.Duration
+	unlimited bool
+	remaining int
+}
+
+// Injector decides which calls fail. A nil Injector injects nothing.
+type Injector struct {
+	mu     sync.Mutex
+	faults map[Operation]*fault
+}
+
+// New creates an Injector from cfg.
+func New(cfg Config) (*Injector, error) {
+	inj := &Injector{faults: make(map[Operation]*fault, len(cfg.Faults))}
+	for i, f := range cfg.Faults {
+		switch f.Operation {
+		case OperationPublish, OperationValidate, OperationHealthCheck, OperationFetch:
+		default:
+			return nil, fmt.Errorf("faults[%d]: unknown operation %q", i, f.Operation)
+		}
+		switch f.Mode {
+		case ModeError, ModePermission, ModeTimeout:
+		default:
+			return nil, fmt.Errorf("faults[%d]: unknown mode %q", i, f.Mode)
+		}
+		if f.Count < 0 {
+			return nil, fmt.Errorf("faults[%d]: count must not be negative, got %d", i, f.Count)
+		}
+		if _, ok := inj.faults[f.Operation]; ok {
+			return nil, fmt.Errorf("faults[%d]: duplicate fault for operation %q", i, f.Operation)
+		}
+
+		parsed := &fault{mode: f.Mode, unlimited: f.Count == 0, remaining: f.Count}
+		if f.Delay != "" {
+			delay, err := time.ParseDuration(f.Delay)
+			if err != nil {
+				return nil, fmt.Errorf("faults[%d]: invalid delay: %w", i, err)
+			}
+			parsed.delay = delay
+		}
+		inj.faults[f.Operation] = parsed
+	}
+	return inj, nil
+}
+
+// Inject returns the injected failure for one call of op, or nil when the call
+// should go through.
+func (i *Injector) Inject(ctx context.Context, op Operation) error {
+	if i == nil {
+		return nil
+	}
+
+	i.mu.Lock()
+	f, ok := i.faults[op]
+	if !ok || (!f.unlimited && f.remaining == 0) {
+		i.mu.Unlock()
+		return nil
+	}
+	if !f.unlimited {
+		f.remaining--
+	}
+	mode, delay := f.mode, f.delay
+	i.mu.Unlock()
+
+	switch mode {
+	case ModePermission:
+		return kaerrors.NewPermissionError("fault-injection", fmt.Sprintf("%s denied", op), ErrInjected)
+	case ModeTimeout:
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+			}
+		} else {
+			<-ctx.Done()
+		}
+		return fmt.Errorf("%s: %w: %w", op, ErrInjected, context.DeadlineExceeded)
+	default:
+		return fmt.Errorf("%s: %w", op, ErrInjected)
+	}
+}
+
+// WrapPublisher returns pub with faults injected into Publish, Validate and
+// HealthCheck. The wrapper only implements iface.Publisher, so type assertions for
+// optional interfaces must use the unwrapped publisher.
+func (i *Injector) WrapPublisher(pub iface.Publisher) iface.Publisher {
+	if i == nil {
+		return pub
+	}
+	return &publisher{Publisher: pub, injector: i}
+}
+
+// WrapBridge returns b with faults injected into every fetch.
+func (i *Injector) WrapBridge(b bridge.OIDCBridge) bridge.OIDCBridge {
+	if i == nil {
+		return b
+	}
+	return &oidcBridge{OIDCBridge: b, injector: i}
+}
+
+// publisher injects faults into an iface.Publisher.
+type publisher struct {
+	iface.Publisher
+	injector *Injector
+}
+
+func (p *publisher) Publish(ctx context.Context, discovery *bridge.DiscoveryDocument, jwks *bridge.JWKS) error {
+	if err := p.injector.Inject(ctx, OperationPublish); err != nil {
+		return err
+	}
+	return p.Publisher.Publish(ctx, discovery, jwks)
+}
+
+func (p *publisher) Validate(ctx context.Context) error {
+	if err := p.injector.Inject(ctx, OperationValidate); err != nil {
+		return err
+	}
+	return p.Publisher.Validate(ctx)
+}
+
+func (p *publisher) HealthCheck(ctx context.Context) error {
+	if err := p.injector.Inject(ctx, OperationHealthCheck); err != nil {
+		return err
+	}
+	return p.Publisher.HealthCheck(ctx)
+}
+
+// oidcBridge injects faults into a bridge.OIDCBridge.
+type oidcBridge struct {
+	bridge.OIDCBridge
+	injector *Injector
+}
+
+func (b *oidcBridge) FetchDiscoveryDocument(ctx context.Context) (*bridge.DiscoveryDocument, error) {
+	if err := b.injector.Inject(ctx, OperationFetch); err != nil {
+		return nil, err
+	}
+	return b.OIDCBridge.FetchDiscoveryDocument(ctx)
+}
+
+func (b *oidcBridge) FetchJWKS(ctx context.Context) (*bridge.JWKS, error) {
+	if err := b.injector.Inject(ctx, OperationFetch); err != nil {
+		return nil, err
+	}
+	return b.OIDCBridge.FetchJWKS(ctx)
+}
+
+func (b *oidcBridge) Fetch(ctx context.Context) (*bridge.FetchResult, error) {
+	if err := b.injector.Inject(ctx, OperationFetch); err != nil {
+		return nil, err
+	}
+	return b.OIDCBridge.Fetch(ctx)
+}
+
+// InvalidateCache forwards to the wrapped bridge when it caches fetched metadata.
+func (b *oidcBridge) InvalidateCache() {
+	if cache, ok := b.OIDCBridge.(bridge.CacheInvalidator); ok {
+		cache.InvalidateCache()
+	}
+}
@@ -0,0 +1,112 @@
+package faultinject
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
+	kaerrors "github.com/hixichen/kube-iam-assume/pkg/errors"
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/memory"
+)
+
+func TestInjector_FailsCountTimesThenSucceeds(t *testing.T) {
+	inj, err := New(Config{Faults: []Fault{{Operation: OperationPublish, Mode: ModeError, Count: 2}}})
+	require.NoError(t, err)
+
+	pub, err := memory.New(memory.Config{PublicURL: "https://oidc.example.com"}, memory.NewBucket())
+	require.NoError(t, err)
+	wrapped := inj.WrapPublisher(pub)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		assert.ErrorIs(t, wrapped.Publish(ctx, &bridge.DiscoveryDocument{}, &bridge.JWKS{}), ErrInjected)
+	}
+	assert.NoError(t, wrapped.Publish(ctx, &bridge.DiscoveryDocument{}, &bridge.JWKS{}))
+
+	// Operations without a fault are untouched
+	assert.NoError(t, wrapped.Validate(ctx))
+	assert.NoError(t, wrapped.HealthCheck(ctx))
+}
+
+func TestInjector_Modes(t *testing.T) {
+	inj, err := New(Config{Faults: []Fault{
+		{Operation: OperationValidate, Mode: ModePermission},
+		{Operation: OperationHealthCheck, Mode: ModeTimeout, Delay: "10ms"},
+		{Operation: OperationFetch, Mode: ModeTimeout},
+	}})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Count 0 fails every call
+	for i := 0; i < 3; i++ {
+		err := inj.Inject(ctx, OperationValidate)
+		require.ErrorIs(t, err, ErrInjected)
+		assert.Equal(t, kaerrors.CodePermission, kaerrors.GetCode(err))
+	}
+
+	start := time.Now()
+	err = inj.Inject(ctx, OperationHealthCheck)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.GreaterOrEqual(t, time.Since(start), 10*time.Millisecond)
+
+	// Without a delay a timeout lasts until the caller gives up
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = inj.WrapBridge(nil).Fetch(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.NoError(t, inj.Inject(ctx, OperationPublish))
+}
+
+func TestInjector_NilInjectsNothing(t *testing.T) {
+	var inj *Injector
+	pub, err := memory.New(memory.Config{PublicURL: "https://oidc.example.com"}, memory.NewBucket())
+	require.NoError(t, err)
+
+	assert.Same(t, pub, inj.WrapPublisher(pub))
+	assert.NoError(t, inj.Inject(context.Background(), OperationPublish))
+}
+
+func TestNew_RejectsInvalidFaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		fault Fault
+		want  string
+	}{
+		{name: "unknown operation", fault: Fault{Operation: "delete", Mode: ModeError}, want: "unknown operation"},
+		{name: "unknown mode", fault: Fault{Operation: OperationPublish, Mode: "panic"}, want: "unknown mode"},
+		{name: "negative count", fault: Fault{Operation: OperationPublish, Mode: ModeError, Count: -1}, want: "count"},
+		{name: "invalid delay", fault: Fault{Operation: OperationPublish, Mode: ModeTimeout, Delay: "soon"}, want: "invalid delay"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(Config{Faults: []Fault{tt.fault}})
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+
+	_, err := New(Config{Faults: []Fault{
+		{Operation: OperationPublish, Mode: ModeError},
+		{Operation: OperationPublish, Mode: ModeTimeout},
+	}})
+	assert.ErrorContains(t, err, "duplicate")
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "faults.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"faults":[{"operation":"publish","mode":"permission","count":3}]}`), 0o600))
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Fault{{Operation: OperationPublish, Mode: ModePermission, Count: 3}}, cfg.Faults)
+
+	_, err = LoadConfig(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,145 @@
+package federation
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// audienceProbeTTL is the lifetime requested for the throwaway token used to read audiences.
+// 10 minutes is the shortest expiration the API server accepts.
+const audienceProbeTTL int64 = 600
+
+// ClusterAudiences returns the audiences the API server puts into service account
+// tokens by default (its --api-audiences). It requests a short-lived token for the
+// given service account without audiences and reads the "aud" claim back.
+func ClusterAudiences(ctx context.Context, client kubernetes.Interface, namespace, serviceAccount string) ([]string, error) {
+	ttl := audienceProbeTTL
+	tr, err := client.CoreV1().ServiceAccounts(namespace).CreateToken(ctx, serviceAccount, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &ttl},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to request token for service account %s/%s: %w", namespace, serviceAccount, err)
+	}
+
+	audiences, err := TokenAudiences(tr.Status.Token)
+	if err != nil {
+		return nil, err
+	}
+	if len(audiences) == 0 {
+		return nil, fmt.Errorf("service account token for %s/%s has no audiences", namespace, serviceAccount)
+	}
+	return audiences, nil
+}
+
+// TokenAudiences returns the "aud" claim of a JWT without verifying its signature.
+// The claim may be a single string or an array of strings.
+func TokenAudiences(token string) ([]string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid token: expected 3 segments, got %d", len(parts))
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token payload: %w", err)
+	}
+
+	var claims struct {
+		Aud json.RawMessage `json:"aud"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("invalid token claims: %w", err)
+	}
+	if len(claims.Aud) == 0 {
+		return nil, nil
+	}
+
+	var single string
+	if err := json.Unmarshal(claims.Aud, &single); err == nil {
+		return []string{single}, nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(claims.Aud, &multiple); err != nil {
+		return nil, fmt.Errorf("invalid aud claim: %w", err)
+	}
+	return multiple, nil
+}
+
+// MergeAudiences combines explicitly configured audiences with derived ones.
+// Empty entries and duplicates are dropped; explicit audiences keep their order and come first.
+func MergeAudiences(explicit, derived []string) []string {
+	merged := make([]string, 0, len(explicit)+len(derived))
+	seen := make(map[string]struct{}, len(explicit)+len(derived))
+	for _, list := range [][]string{explicit, derived} {
+		for _, aud := range list {
+			if aud == "" {
+				continue
+			}
+			if _, ok := seen[aud]; ok {
+				continue
+			}
+			seen[aud] = struct{}{}
+			merged = append(merged, aud)
+		}
+	}
+	return merged
+}
+
+// Documented default audiences of the cloud token exchanges.
+const (
+	// AWSDefaultAudience is the audience AWS SDKs and the EKS pod identity webhook
+	// request for AssumeRoleWithWebIdentity.
+	AWSDefaultAudience = "sts.amazonaws.com"
+	// AzureDefaultAudience is the audience Azure AD expects for workload identity
+	// federated credentials.
+	AzureDefaultAudience = "api://AzureADTokenExchange"
+)
+
+// gcpMaxAudiences is the maximum number of allowed audiences of a GCP workload
+// identity pool OIDC provider.
+const gcpMaxAudiences = 10
+
+// DefaultAudiences returns the audiences to configure for a provider type when
+// none are given. GCP has none: a provider without allowed audiences accepts its
+// own full resource name, which is what the Google client libraries request.
+func DefaultAudiences(providerType ProviderType) []string {
+	switch providerType {
+	case ProviderTypeAWS:
+		return []string{AWSDefaultAudience}
+	case ProviderTypeAzure:
+		return []string{AzureDefaultAudience}
+	default:
+		return nil
+	}
+}
+
+// AudienceWarnings returns human-readable warnings for audiences that the cloud
+// will reject or that its standard clients will not request. It returns nil when
+// the audiences look usable.
+func AudienceWarnings(providerType ProviderType, audiences []string) []string {
+	set := NewAudienceSet(audiences...)
+	var warnings []string
+	switch providerType {
+	case ProviderTypeAWS:
+		if len(set) == 0 {
+			warnings = append(warnings, "no audiences: AWS requires at least one client ID on the OIDC provider")
+		} else if !set.Contains(AWSDefaultAudience) {
+			warnings = append(warnings, fmt.Sprintf("audiences do not include %s: AWS SDKs and the EKS pod identity webhook request it by default", AWSDefaultAudience))
+		}
+	case ProviderTypeGCP:
+		if len(set) > gcpMaxAudiences {
+			warnings = append(warnings, fmt.Sprintf("%d audiences: GCP accepts at most %d allowed audiences per provider", len(set), gcpMaxAudiences))
+		}
+	case ProviderTypeAzure:
+		if !set.Contains(AzureDefaultAudience) {
+			warnings = append(warnings, fmt.Sprintf("audiences do not include %s: Azure AD only accepts it for workload identity federation", AzureDefaultAudience))
+		}
+	}
+	return warnings
+}
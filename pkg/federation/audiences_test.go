@@ -0,0 +1,155 @@
+package federation
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// unsignedToken builds a JWT with the given payload and a dummy signature.
+func unsignedToken(payload string) string {
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(`{"alg":"RS256"}`)) + "." + enc.EncodeToString([]byte(payload)) + ".sig"
+}
+
+func TestTokenAudiences(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "single audience string",
+			token: unsignedToken(`{"aud":"https://kubernetes.default.svc"}`),
+			want:  []string{"https://kubernetes.default.svc"},
+		},
+		{
+			name:  "audience array",
+			token: unsignedToken(`{"aud":["https://kubernetes.default.svc","sts.amazonaws.com"]}`),
+			want:  []string{"https://kubernetes.default.svc", "sts.amazonaws.com"},
+		},
+		{
+			name:  "no audience claim",
+			token: unsignedToken(`{"sub":"system:serviceaccount:default:default"}`),
+		},
+		{
+			name:    "not a JWT",
+			token:   "opaque-token",
+			wantErr: true,
+		},
+		{
+			name:    "malformed aud claim",
+			token:   unsignedToken(`{"aud":42}`),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TokenAudiences(tt.token)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMergeAudiences(t *testing.T) {
+	assert.Equal(t,
+		[]string{"sts.amazonaws.com", "https://kubernetes.default.svc"},
+		MergeAudiences(
+			[]string{"sts.amazonaws.com", ""},
+			[]string{"https://kubernetes.default.svc", "sts.amazonaws.com"},
+		),
+	)
+	assert.Empty(t, MergeAudiences(nil, nil))
+}
+
+func TestClusterAudiences(t *testing.T) {
+	client := fake.NewClientset()
+	var requested *authenticationv1.TokenRequest
+	client.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		create := action.(k8stesting.CreateAction)
+		if create.GetSubresource() != "token" {
+			return false, nil, nil
+		}
+		requested = create.GetObject().(*authenticationv1.TokenRequest)
+		return true, &authenticationv1.TokenRequest{
+			Status: authenticationv1.TokenRequestStatus{
+				Token: unsignedToken(`{"aud":["https://oidc.example.com"]}`),
+			},
+		}, nil
+	})
+
+	audiences, err := ClusterAudiences(context.Background(), client, "default", "default")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://oidc.example.com"}, audiences)
+	require.NotNil(t, requested)
+	assert.Empty(t, requested.Spec.Audiences, "the probe must not request audiences so the defaults are returned")
+}
+
+func TestDefaultAudiences(t *testing.T) {
+	tests := []struct {
+		providerType ProviderType
+		want         []string
+	}{
+		{providerType: ProviderTypeAWS, want: []string{"sts.amazonaws.com"}},
+		{providerType: ProviderTypeGCP},
+		{providerType: ProviderTypeAzure, want: []string{"api://AzureADTokenExchange"}},
+		{providerType: ProviderTypeOCI},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.providerType), func(t *testing.T) {
+			got := DefaultAudiences(tt.providerType)
+			assert.Equal(t, tt.want, got)
+			// The defaults never warn
+			assert.Empty(t, AudienceWarnings(tt.providerType, got))
+		})
+	}
+}
+
+func TestAudienceWarnings(t *testing.T) {
+	tooMany := make([]string, 11)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("aud-%d", i)
+	}
+
+	tests := []struct {
+		name         string
+		providerType ProviderType
+		audiences    []string
+		want         string
+	}{
+		{name: "aws without audiences", providerType: ProviderTypeAWS, want: "at least one client ID"},
+		{name: "aws without sts", providerType: ProviderTypeAWS, audiences: []string{"my-app"}, want: "do not include sts.amazonaws.com"},
+		{name: "aws with sts and extras", providerType: ProviderTypeAWS, audiences: []string{"my-app", "sts.amazonaws.com"}},
+		{name: "gcp custom audiences", providerType: ProviderTypeGCP, audiences: []string{"my-app"}},
+		{name: "gcp too many audiences", providerType: ProviderTypeGCP, audiences: tooMany, want: "at most 10"},
+		{name: "azure custom audience", providerType: ProviderTypeAzure, audiences: []string{"my-app"}, want: "do not include api://AzureADTokenExchange"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AudienceWarnings(tt.providerType, tt.audiences)
+			if tt.want == "" {
+				assert.Empty(t, got)
+				return
+			}
+			require.Len(t, got, 1)
+			assert.Contains(t, got[0], tt.want)
+		})
+	}
+}
@@ -3,12 +3,9 @@ package aws
 
 import (
 	"context"
-	"crypto/sha1"
-	"crypto/tls"
+	"errors"
 	"fmt"
 	"log/slog"
-	"net"
-	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -16,14 +13,19 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 
+	kaerrors "github.com/hixichen/kube-iam-assume/pkg/errors"
 	"github.com/hixichen/kube-iam-assume/pkg/federation"
 )
 
 // Ensure awsProvider implements federation.Provider interface.
 var _ federation.Provider = (*awsProvider)(nil)
 
+// Ensure awsProvider can look up providers by ARN.
+var _ federation.ProviderLookup = (*awsProvider)(nil)
+
 // awsProvider implements federation.Provider for AWS IAM OIDC Provider.
 type awsProvider struct {
 	iamClient *iam.Client
@@ -54,6 +56,12 @@ func (a *awsProvider) Setup(ctx context.Context, cfg federation.SetupConfig) (*f
 		"issuer_url", cfg.IssuerURL,
 		"audiences", cfg.Audiences)
 
+	// Compile the trust-policy condition up front so a bad subject or template fails before any API call
+	trustCondition, err := trustConditionJSON(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if provider already exists
 	providerInfo, err := a.GetProviderInfo(ctx, cfg.IssuerURL)
 	if err != nil && !strings.Contains(err.Error(), "no OIDC provider found") { // Ignore "not found" errors
@@ -68,17 +76,33 @@ func (a *awsProvider) Setup(ctx context.Context, cfg federation.SetupConfig) (*f
 		// AWS does not allow updating thumbprints directly. If thumbprint is wrong, user needs to delete and re-create.
 		// For now, we'll just re-use the existing thumbprint or fetch a new one if it's nil
 		thumbprint = providerInfo.Thumbprint
+
+		existing := federation.NewAudienceSet(providerInfo.Audiences...)
+		if wanted := federation.NewAudienceSet(cfg.Audiences...); !existing.Equal(wanted) {
+			a.logger.Warn("Existing OIDC provider has different client IDs",
+				"arn", providerInfo.ProviderARN,
+				"missing", wanted.Missing(existing),
+				"extra", existing.Missing(wanted))
+		}
 	}
 
 	// Fetch thumbprint
-	if thumbprint == "" {
-		a.logger.Info("Fetching OIDC issuer thumbprint", "issuer_url", cfg.IssuerURL)
-		t, err := getThumbprint(ctx, cfg.IssuerURL)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get OIDC issuer thumbprint: %w", err)
+	a.logger.Info("Fetching OIDC issuer thumbprint", "issuer_url", cfg.IssuerURL)
+	info, err := FetchThumbprint(ctx, cfg.IssuerURL)
+	switch {
+	case err != nil && thumbprint == "":
+		return nil, fmt.Errorf("failed to get OIDC issuer thumbprint: %w", err)
+	case err != nil:
+		// The existing provider keeps its thumbprint; only the expiry report is lost
+		a.logger.Warn("Failed to fetch OIDC issuer certificate for expiry reporting", "issuer_url", cfg.IssuerURL, "error", err)
+	default:
+		if thumbprint == "" {
+			thumbprint = info.Thumbprint
 		}
-		thumbprint = t
-		a.logger.Info("Fetched OIDC issuer thumbprint", "thumbprint", thumbprint)
+		a.logger.Info("Fetched OIDC issuer thumbprint",
+			"thumbprint", info.Thumbprint,
+			"not_after", info.NotAfter,
+			"chain_length", info.ChainLength)
 	}
 
 	if providerInfo != nil {
@@ -106,11 +130,17 @@ func (a *awsProvider) Setup(ctx context.Context, cfg federation.SetupConfig) (*f
 		a.logger.Info("Successfully created IAM OIDC Provider", "arn", providerARN)
 	}
 
-	return &federation.SetupResult{
-		ProviderARN: providerARN,
-		Audiences:   cfg.Audiences,
-		Thumbprint:  thumbprint,
-	}, nil
+	result := &federation.SetupResult{
+		ProviderARN:    providerARN,
+		Audiences:      cfg.Audiences,
+		Thumbprint:     thumbprint,
+		TrustCondition: trustCondition,
+	}
+	if info != nil {
+		result.CertNotAfter = info.NotAfter
+		result.CertChainLength = info.ChainLength
+	}
+	return result, nil
 }
 
 // Validate checks if setup is valid.
@@ -146,10 +176,12 @@ func (a *awsProvider) GetProviderInfo(ctx context.Context, issuerURL string) (*f
 		return nil, fmt.Errorf("failed to list OIDC providers: %w", err)
 	}
 
+	// OIDC provider ARNs end with the issuer host and path; IAM lower-cases neither,
+	// so the ARN is only a case-insensitive prefilter
+	arnSuffix := strings.ToLower(strings.TrimPrefix(federation.NormalizeIssuerURL(issuerURL), "https://"))
 	for _, p := range listOutput.OpenIDConnectProviderList {
 		arn := aws.ToString(p.Arn)
-		// OIDC provider ARN contains the URL
-		if strings.Contains(arn, strings.ReplaceAll(issuerURL, "https://", "")) {
+		if strings.HasSuffix(strings.ToLower(arn), "/"+arnSuffix) {
 			getOutput, err := a.iamClient.GetOpenIDConnectProvider(ctx, &iam.GetOpenIDConnectProviderInput{
 				OpenIDConnectProviderArn: aws.String(arn),
 			})
@@ -157,41 +189,82 @@ func (a *awsProvider) GetProviderInfo(ctx context.Context, issuerURL string) (*f
 				return nil, fmt.Errorf("failed to get details for OIDC provider ARN '%s': %w", arn, err)
 			}
 
-			// Re-verify the URL explicitly as the ARN might be a partial match
-			if aws.ToString(getOutput.Url) == issuerURL {
-				return &federation.ProviderInfo{
-					ProviderARN:   arn,
-					IssuerURL:     aws.ToString(getOutput.Url),
-					Audiences:     getOutput.ClientIDList,
-					Thumbprint:    getOutput.ThumbprintList[0], // Assuming only one thumbprint
-					Status:        "Active",                    // AWS does not provide explicit status
-					CreatedAt:     getOutput.CreateDate.Format(time.RFC3339),
-					CloudProvider: string(federation.ProviderTypeAWS),
-				}, nil
+			// Re-verify the URL explicitly; IAM stores it without the scheme
+			if federation.IssuerURLsEqual(aws.ToString(getOutput.Url), issuerURL) {
+				return newProviderInfo(arn, getOutput), nil
 			}
 		}
 	}
 
-	return nil, fmt.Errorf("no OIDC provider found for issuer: %s", issuerURL)
+	return nil, kaerrors.NewNotFoundError("aws-federation", "no OIDC provider found for issuer: "+issuerURL, nil)
+}
+
+// GetProviderInfoByID returns info about the OIDC provider with the given ARN,
+// whatever issuer it trusts.
+func (a *awsProvider) GetProviderInfoByID(ctx context.Context, providerID string) (*federation.ProviderInfo, error) {
+	getOutput, err := a.iamClient.GetOpenIDConnectProvider(ctx, &iam.GetOpenIDConnectProviderInput{
+		OpenIDConnectProviderArn: aws.String(providerID),
+	})
+	if err != nil {
+		var notFound *types.NoSuchEntityException
+		if errors.As(err, &notFound) {
+			return nil, kaerrors.NewNotFoundError("aws-federation", "no OIDC provider found with ARN: "+providerID, err)
+		}
+		return nil, fmt.Errorf("failed to get details for OIDC provider ARN '%s': %w", providerID, err)
+	}
+	return newProviderInfo(providerID, getOutput), nil
+}
+
+// newProviderInfo converts the details of the OIDC provider arn to a ProviderInfo.
+func newProviderInfo(arn string, getOutput *iam.GetOpenIDConnectProviderOutput) *federation.ProviderInfo {
+	info := &federation.ProviderInfo{
+		ProviderARN:   arn,
+		IssuerURL:     aws.ToString(getOutput.Url),
+		Audiences:     getOutput.ClientIDList,
+		Status:        providerStatus(""), // AWS does not provide explicit status
+		CloudProvider: string(federation.ProviderTypeAWS),
+	}
+	if len(getOutput.ThumbprintList) > 0 {
+		info.Thumbprint = getOutput.ThumbprintList[0] // Assuming only one thumbprint
+	}
+	if getOutput.CreateDate != nil {
+		info.CreatedAt = getOutput.CreateDate.Format(time.RFC3339)
+	}
+	return info
+}
+
+// providerStatus normalizes an IAM OIDC provider state. IAM reports no state: a
+// provider that exists accepts tokens.
+func providerStatus(raw string) federation.ProviderStatus {
+	if raw == "" {
+		return federation.ProviderStatusActive
+	}
+	return federation.ProviderStatusUnknown
 }
 
-// Delete removes the OIDC provider.
+// Delete removes the OIDC provider. Deleting a provider that does not exist, or
+// was deleted concurrently, is not an error.
 func (a *awsProvider) Delete(ctx context.Context, issuerURL string) error {
 	a.logger.Info("Deleting AWS IAM OIDC Provider", "issuer_url", issuerURL)
 
 	providerInfo, err := a.GetProviderInfo(ctx, issuerURL)
-	if err != nil {
-		return fmt.Errorf("failed to get OIDC provider info for deletion: %w", err)
-	}
-	if providerInfo == nil {
+	if kaerrors.IsNotFoundError(err) {
 		a.logger.Info("OIDC provider not found, skipping deletion", "issuer_url", issuerURL)
 		return nil
 	}
+	if err != nil {
+		return fmt.Errorf("failed to get OIDC provider info for deletion: %w", err)
+	}
 
 	_, err = a.iamClient.DeleteOpenIDConnectProvider(ctx, &iam.DeleteOpenIDConnectProviderInput{
 		OpenIDConnectProviderArn: aws.String(providerInfo.ProviderARN),
 	})
 	if err != nil {
+		var notFound *types.NoSuchEntityException
+		if errors.As(err, &notFound) {
+			a.logger.Info("OIDC provider already deleted", "arn", providerInfo.ProviderARN)
+			return nil
+		}
 		return fmt.Errorf("failed to delete OIDC provider '%s': %w", providerInfo.ProviderARN, err)
 	}
 
@@ -236,48 +309,3 @@ func loadAWSConfig(ctx context.Context, region string) (aws.Config, error) {
 
 	return cfg, nil
 }
-
-// getThumbprint fetches the OIDC issuer's root CA certificate thumbprint.
-func getThumbprint(ctx context.Context, issuerURL string) (string, error) {
-	parsedURL, err := url.Parse(issuerURL)
-	if err != nil {
-		return "", fmt.Errorf("invalid issuer URL: %w", err)
-	}
-
-	// Create a custom HTTP client that does not verify TLS certificates for fetching discovery doc
-	// This might be necessary if the issuer uses a self-signed CA or is not publicly trusted.
-	// However, for fetching thumbprints for AWS, it's generally recommended to fetch the root CA's thumbprint
-	// of the actual issuer URL, not necessarily the JWKS URI.
-	// We connect directly to the issuerURL host to get the certificate chain.
-
-	hostPort := parsedURL.Hostname()
-	if parsedURL.Port() == "" {
-		hostPort += ":443" // Default HTTPS port
-	} else {
-		hostPort = net.JoinHostPort(parsedURL.Hostname(), parsedURL.Port())
-	}
-
-	// Connect to the issuer URL's host to get its certificate chain.
-	// InsecureSkipVerify is intentional: we want the raw certificate chain to compute
-	// the thumbprint, not to validate it — the thumbprint IS the validation mechanism.
-	dialer := &tls.Dialer{Config: &tls.Config{InsecureSkipVerify: true}} //nolint:gosec
-	netConn, err := dialer.DialContext(ctx, "tcp", hostPort)
-	if err != nil {
-		return "", fmt.Errorf("failed to connect to issuer host %s: %w", hostPort, err)
-	}
-	defer func() { _ = netConn.Close() }()
-
-	conn := netConn.(*tls.Conn)
-	certs := conn.ConnectionState().PeerCertificates
-	if len(certs) == 0 {
-		return "", fmt.Errorf("no certificates found for issuer host %s", hostPort)
-	}
-
-	// AWS requires the thumbprint of the root CA.
-	// The last certificate in the chain is usually the root.
-	// If the chain is incomplete or self-signed, it might be the only cert.
-	rootCert := certs[len(certs)-1]
-	thumbprint := fmt.Sprintf("%x", sha1.Sum(rootCert.Raw))
-
-	return thumbprint, nil
-}
@@ -0,0 +1,14 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hixichen/kube-iam-assume/pkg/federation"
+)
+
+func TestProviderStatus(t *testing.T) {
+	assert.Equal(t, federation.ProviderStatusActive, providerStatus(""), "IAM providers that exist are active")
+	assert.Equal(t, federation.ProviderStatusUnknown, providerStatus("Pending"))
+}
@@ -0,0 +1,52 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hixichen/kube-iam-assume/pkg/federation"
+)
+
+// TrustPolicyCondition is the Condition block of an IAM role trust policy,
+// keyed by operator and then by condition key.
+type TrustPolicyCondition map[string]map[string][]string
+
+// TrustedSubjectsCondition compiles trusted subjects ("namespace/serviceaccount")
+// into a trust-policy StringEquals condition on the issuer's "sub" claim, building
+// each claim value with subjectTemplate (empty selects the Kubernetes format).
+// It returns nil when no subjects are given.
+func TrustedSubjectsCondition(issuerURL string, trustedSubjects []string, subjectTemplate string) (TrustPolicyCondition, error) {
+	subjects, err := federation.ServiceAccountSubjects(trustedSubjects, subjectTemplate)
+	if err != nil {
+		return nil, err
+	}
+	if len(subjects) == 0 {
+		return nil, nil
+	}
+
+	return TrustPolicyCondition{
+		"StringEquals": {
+			issuerConditionKey(issuerURL) + ":sub": subjects,
+		},
+	}, nil
+}
+
+// trustConditionJSON renders the trust-policy condition for cfg's trusted subjects as
+// indented JSON, or "" when no subjects are trusted.
+func trustConditionJSON(cfg federation.SetupConfig) (string, error) {
+	condition, err := TrustedSubjectsCondition(cfg.IssuerURL, cfg.TrustedSubjects, cfg.SubjectTemplate)
+	if err != nil || condition == nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(condition, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to render trust policy condition: %w", err)
+	}
+	return string(data), nil
+}
+
+// issuerConditionKey returns the issuer as IAM expects it in condition keys: without scheme or trailing slash.
+func issuerConditionKey(issuerURL string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(issuerURL, "https://"), "/")
+}
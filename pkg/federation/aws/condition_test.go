@@ -0,0 +1,92 @@
+package aws
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hixichen/kube-iam-assume/pkg/federation"
+)
+
+func TestTrustedSubjectsCondition(t *testing.T) {
+	tests := []struct {
+		name      string
+		issuerURL string
+		subjects  []string
+		template  string
+		want      TrustPolicyCondition
+		wantErr   bool
+	}{
+		{
+			name:      "no subjects",
+			issuerURL: "https://oidc.example.com",
+		},
+		{
+			name:      "subjects compile to StringEquals on sub",
+			issuerURL: "https://my-bucket.s3.us-west-2.amazonaws.com/prod/",
+			subjects:  []string{"payments/api", "batch:worker"},
+			want: TrustPolicyCondition{
+				"StringEquals": {
+					"my-bucket.s3.us-west-2.amazonaws.com/prod:sub": {
+						"system:serviceaccount:payments:api",
+						"system:serviceaccount:batch:worker",
+					},
+				},
+			},
+		},
+		{
+			name:      "custom subject template",
+			issuerURL: "https://oidc.example.com",
+			subjects:  []string{"payments/api", "batch/worker"},
+			template:  "cluster-a:{{.Namespace}}:{{.ServiceAccount}}",
+			want: TrustPolicyCondition{
+				"StringEquals": {
+					"oidc.example.com:sub": {
+						"cluster-a:payments:api",
+						"cluster-a:batch:worker",
+					},
+				},
+			},
+		},
+		{
+			name:      "template rendering an empty subject",
+			issuerURL: "https://oidc.example.com",
+			subjects:  []string{"payments/api"},
+			template:  "{{if false}}{{.Namespace}}{{end}}",
+			wantErr:   true,
+		},
+		{
+			name:      "invalid entry",
+			issuerURL: "https://oidc.example.com",
+			subjects:  []string{"payments"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TrustedSubjectsCondition(tt.issuerURL, tt.subjects, tt.template)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTrustConditionJSON(t *testing.T) {
+	got, err := trustConditionJSON(federation.SetupConfig{IssuerURL: "https://oidc.example.com"})
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	got, err = trustConditionJSON(federation.SetupConfig{
+		IssuerURL:       "https://oidc.example.com",
+		TrustedSubjects: []string{"payments/api"},
+		SubjectTemplate: "{{.Namespace}}.{{.ServiceAccount}}",
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"StringEquals": {"oidc.example.com:sub": ["payments.api"]}}`, got)
+}
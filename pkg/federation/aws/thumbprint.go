@@ -0,0 +1,81 @@
+package aws
+
+import (
+	"context"
+	"crypto/sha1" //nolint:gosec // AWS IAM thumbprints are defined as SHA-1 fingerprints
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+)
+
+// ThumbprintInfo describes the certificate AWS pins for an OIDC issuer.
+type ThumbprintInfo struct {
+	// Thumbprint is the hex SHA-1 fingerprint of the last certificate in the chain
+	Thumbprint string
+	// NotAfter is when the thumbprinted certificate expires; the thumbprint must be
+	// updated before then
+	NotAfter time.Time
+	// ChainLength is the number of certificates the issuer host presented
+	ChainLength int
+}
+
+// ExpiresWithin reports whether the thumbprinted certificate expires within window of now.
+func (t *ThumbprintInfo) ExpiresWithin(window time.Duration, now time.Time) bool {
+	return t.NotAfter.Sub(now) <= window
+}
+
+// FetchThumbprint connects to the OIDC issuer host and returns the thumbprint,
+// expiry and chain length of its root CA certificate.
+func FetchThumbprint(ctx context.Context, issuerURL string) (*ThumbprintInfo, error) {
+	parsedURL, err := url.Parse(issuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issuer URL: %w", err)
+	}
+
+	// For fetching thumbprints for AWS, it's recommended to fetch the root CA's thumbprint
+	// of the actual issuer URL, not necessarily the JWKS URI.
+	// We connect directly to the issuerURL host to get the certificate chain.
+	hostPort := parsedURL.Hostname()
+	if parsedURL.Port() == "" {
+		hostPort += ":443" // Default HTTPS port
+	} else {
+		hostPort = net.JoinHostPort(parsedURL.Hostname(), parsedURL.Port())
+	}
+
+	// Connect to the issuer URL's host to get its certificate chain.
+	// InsecureSkipVerify is intentional: we want the raw certificate chain to compute
+	// the thumbprint, not to validate it — the thumbprint IS the validation mechanism.
+	dialer := &tls.Dialer{Config: &tls.Config{InsecureSkipVerify: true}} //nolint:gosec
+	netConn, err := dialer.DialContext(ctx, "tcp", hostPort)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to issuer host %s: %w", hostPort, err)
+	}
+	defer func() { _ = netConn.Close() }()
+
+	conn := netConn.(*tls.Conn)
+	info, err := thumbprintFromChain(conn.ConnectionState().PeerCertificates)
+	if err != nil {
+		return nil, fmt.Errorf("issuer host %s: %w", hostPort, err)
+	}
+	return info, nil
+}
+
+// thumbprintFromChain selects the certificate AWS pins from a presented chain.
+func thumbprintFromChain(certs []*x509.Certificate) (*ThumbprintInfo, error) {
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificates found")
+	}
+
+	// AWS requires the thumbprint of the root CA.
+	// The last certificate in the chain is usually the root.
+	// If the chain is incomplete or self-signed, it might be the only cert.
+	rootCert := certs[len(certs)-1]
+	return &ThumbprintInfo{
+		Thumbprint:  fmt.Sprintf("%x", sha1.Sum(rootCert.Raw)), //nolint:gosec
+		NotAfter:    rootCert.NotAfter,
+		ChainLength: len(certs),
+	}, nil
+}
@@ -0,0 +1,75 @@
+package aws
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // matches the thumbprint algorithm under test
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCert creates a self-signed certificate that expires at notAfter.
+func newTestCert(t *testing.T, cn string, notAfter time.Time) *x509.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+func TestThumbprintFromChain(t *testing.T) {
+	leafExpiry := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	rootExpiry := time.Date(2035, 6, 1, 0, 0, 0, 0, time.UTC)
+	leaf := newTestCert(t, "leaf", leafExpiry)
+	root := newTestCert(t, "root", rootExpiry)
+
+	info, err := thumbprintFromChain([]*x509.Certificate{leaf, root})
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%x", sha1.Sum(root.Raw)), info.Thumbprint) //nolint:gosec
+	assert.Equal(t, rootExpiry, info.NotAfter)
+	assert.Equal(t, 2, info.ChainLength)
+
+	_, err = thumbprintFromChain(nil)
+	assert.Error(t, err)
+}
+
+func TestThumbprintInfo_ExpiresWithin(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	info := &ThumbprintInfo{NotAfter: now.Add(10 * 24 * time.Hour)}
+
+	assert.True(t, info.ExpiresWithin(30*24*time.Hour, now))
+	assert.False(t, info.ExpiresWithin(24*time.Hour, now))
+}
+
+func TestFetchThumbprint(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	cert := srv.Certificate()
+	info, err := FetchThumbprint(context.Background(), srv.URL)
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("%x", sha1.Sum(cert.Raw)), info.Thumbprint) //nolint:gosec
+	assert.Equal(t, cert.NotAfter, info.NotAfter)
+	assert.Equal(t, 1, info.ChainLength)
+}
@@ -2,9 +2,14 @@
 // with various cloud providers (AWS, GCP, Azure, OCI).
 package federation
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Provider defines the interface for cloud identity federation providers.
+// Implementations create their cloud client once, when the provider is created,
+// and reuse it for every call; all methods are safe for concurrent use.
 type Provider interface {
 	// Setup creates the OIDC identity provider/federation
 	Setup(ctx context.Context, cfg SetupConfig) (*SetupResult, error)
@@ -22,10 +27,24 @@ type Provider interface {
 	Type() string
 }
 
+// ProviderLookup is implemented by providers that can look up a provider by its
+// ID (AWS: the IAM OIDC provider ARN, GCP: the Workload Identity Pool provider
+// resource name) instead of by the issuer it trusts.
+type ProviderLookup interface {
+	// GetProviderInfoByID returns info about the provider with the given ID
+	GetProviderInfoByID(ctx context.Context, providerID string) (*ProviderInfo, error)
+}
+
 // SetupConfig contains configuration for setting up OIDC federation.
 type SetupConfig struct {
 	IssuerURL string
 	Audiences []string
+	// TrustedSubjects restricts federation to these service accounts ("namespace/serviceaccount").
+	// Empty means every service account in the cluster is trusted.
+	TrustedSubjects []string
+	// SubjectTemplate builds the "sub" claim value of each trusted subject, as a Go template
+	// over federation.SubjectTemplateData (default: DefaultSubjectTemplate)
+	SubjectTemplate string
 	// Provider-specific options
 	Options map[string]interface{}
 }
@@ -35,19 +54,40 @@ type SetupResult struct {
 	ProviderARN string // AWS: arn:aws:iam::..., GCP: projects/..., etc.
 	Audiences   []string
 	Thumbprint  string
+	// CertNotAfter is when the thumbprinted certificate expires (zero if unknown)
+	CertNotAfter time.Time
+	// CertChainLength is the number of certificates presented by the issuer host
+	CertChainLength int
+	// TrustCondition is the condition compiled from the trusted subjects: a trust-policy
+	// Condition block as JSON for AWS, a CEL attribute condition for GCP (empty if none)
+	TrustCondition string
 }
 
 // ProviderInfo contains information about an existing OIDC provider.
 type ProviderInfo struct {
-	ProviderARN   string
-	IssuerURL     string
-	Audiences     []string
-	Thumbprint    string
-	Status        string
+	ProviderARN string
+	IssuerURL   string
+	Audiences   []string
+	Thumbprint  string
+	Status      ProviderStatus
+	// RawStatus is the provider state as reported by the cloud (empty when it reports none)
+	RawStatus     string
 	CreatedAt     string
 	CloudProvider string
 }
 
+// ProviderStatus is the cloud-independent state of an OIDC provider.
+type ProviderStatus string
+
+const (
+	// ProviderStatusActive means the provider accepts tokens.
+	ProviderStatusActive ProviderStatus = "active"
+	// ProviderStatusDisabled means the provider exists but rejects tokens (disabled or pending deletion).
+	ProviderStatusDisabled ProviderStatus = "disabled"
+	// ProviderStatusUnknown means the cloud reported a state that is not recognized.
+	ProviderStatusUnknown ProviderStatus = "unknown"
+)
+
 // ProviderType represents the type of cloud provider.
 type ProviderType string
 
@@ -0,0 +1,28 @@
+package gcp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hixichen/kube-iam-assume/pkg/federation"
+)
+
+// TrustedSubjectsCondition compiles trusted subjects ("namespace/serviceaccount")
+// into a CEL attribute condition on google.subject, which is mapped from assertion.sub,
+// building each claim value with subjectTemplate (empty selects the Kubernetes format).
+// It returns an empty string when no subjects are given.
+func TrustedSubjectsCondition(trustedSubjects []string, subjectTemplate string) (string, error) {
+	subjects, err := federation.ServiceAccountSubjects(trustedSubjects, subjectTemplate)
+	if err != nil {
+		return "", err
+	}
+	if len(subjects) == 0 {
+		return "", nil
+	}
+
+	quoted := make([]string, len(subjects))
+	for i, sub := range subjects {
+		quoted[i] = fmt.Sprintf("'%s'", sub)
+	}
+	return fmt.Sprintf("google.subject in [%s]", strings.Join(quoted, ", ")), nil
+}
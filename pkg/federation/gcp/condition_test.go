@@ -0,0 +1,94 @@
+package gcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrustedSubjectsCondition(t *testing.T) {
+	tests := []struct {
+		name     string
+		subjects []string
+		template string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name: "no subjects",
+			want: "",
+		},
+		{
+			name:     "single subject with slash",
+			subjects: []string{"payments/api"},
+			want:     "google.subject in ['system:serviceaccount:payments:api']",
+		},
+		{
+			name:     "colon separator",
+			subjects: []string{"payments:api"},
+			want:     "google.subject in ['system:serviceaccount:payments:api']",
+		},
+		{
+			name:     "multiple subjects keep order and drop duplicates",
+			subjects: []string{"payments/api", "batch:worker", "payments:api"},
+			want:     "google.subject in ['system:serviceaccount:payments:api', 'system:serviceaccount:batch:worker']",
+		},
+		{
+			name:     "missing service account",
+			subjects: []string{"payments/"},
+			wantErr:  true,
+		},
+		{
+			name:     "missing namespace",
+			subjects: []string{"/api"},
+			wantErr:  true,
+		},
+		{
+			name:     "no separator",
+			subjects: []string{"api"},
+			wantErr:  true,
+		},
+		{
+			name:     "quote would break the expression",
+			subjects: []string{"payments/api' || true || '"},
+			wantErr:  true,
+		},
+		{
+			name:     "custom subject template",
+			subjects: []string{"payments/api"},
+			template: "prod/{{.Namespace}}/{{.ServiceAccount}}",
+			want:     "google.subject in ['prod/payments/api']",
+		},
+		{
+			name:     "template with a quote would break the expression",
+			subjects: []string{"payments/api"},
+			template: "{{.Namespace}}' || true || '{{.ServiceAccount}}",
+			wantErr:  true,
+		},
+		{
+			name:     "template with an unknown field",
+			subjects: []string{"payments/api"},
+			template: "{{.Cluster}}:{{.ServiceAccount}}",
+			wantErr:  true,
+		},
+		{
+			name:     "unparsable template",
+			subjects: []string{"payments/api"},
+			template: "{{.Namespace",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TrustedSubjectsCondition(tt.subjects, tt.template)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -14,12 +14,16 @@ import (
 	"golang.org/x/oauth2/google"
 
 	"github.com/hixichen/kube-iam-assume/pkg/constants"
+	kaerrors "github.com/hixichen/kube-iam-assume/pkg/errors"
 	"github.com/hixichen/kube-iam-assume/pkg/federation"
 )
 
 // Ensure gcpProvider implements federation.Provider interface.
 var _ federation.Provider = (*gcpProvider)(nil)
 
+// Ensure gcpProvider can look up providers by resource name.
+var _ federation.ProviderLookup = (*gcpProvider)(nil)
+
 // gcpProvider implements federation.Provider for GCP Workload Identity Federation.
 type gcpProvider struct {
 	httpClient *http.Client
@@ -40,14 +44,15 @@ type WorkloadIdentityPool struct {
 
 // WorkloadIdentityPoolProvider represents a GCP Workload Identity Pool Provider.
 type WorkloadIdentityPoolProvider struct {
-	Name             string            `json:"name"`
-	DisplayName      string            `json:"displayName"`
-	Description      string            `json:"description"`
-	State            string            `json:"state"`
-	Disabled         bool              `json:"disabled"`
-	Oidc             *OidcConfig       `json:"oidc,omitempty"`
-	AttributeMapping map[string]string `json:"attributeMapping,omitempty"`
-	CreateTime       string            `json:"createTime"`
+	Name               string            `json:"name"`
+	DisplayName        string            `json:"displayName"`
+	Description        string            `json:"description"`
+	State              string            `json:"state"`
+	Disabled           bool              `json:"disabled"`
+	Oidc               *OidcConfig       `json:"oidc,omitempty"`
+	AttributeMapping   map[string]string `json:"attributeMapping,omitempty"`
+	AttributeCondition string            `json:"attributeCondition,omitempty"`
+	CreateTime         string            `json:"createTime"`
 }
 
 // OidcConfig contains OIDC configuration.
@@ -57,7 +62,13 @@ type OidcConfig struct {
 }
 
 // NewProvider creates a new GCP Provider.
+// The provider's HTTP client is created once and reused by every call; it is safe
+// for concurrent use and outlives ctx, which is only used to find the credentials.
 func NewProvider(ctx context.Context, projectID string, logger *slog.Logger) (federation.Provider, error) {
+	// The token source refreshes with the context it is created with, so it must not
+	// be cancelled with the caller's context
+	ctx = context.WithoutCancel(ctx)
+
 	// Create HTTP client with default credentials (Workload Identity if configured)
 	credentials, err := google.FindDefaultCredentials(ctx, "https://www.googleapis.com/auth/cloud-platform")
 	if err != nil {
@@ -80,6 +91,12 @@ func (g *gcpProvider) Setup(ctx context.Context, cfg federation.SetupConfig) (*f
 		"issuer_url", cfg.IssuerURL,
 		"audiences", cfg.Audiences)
 
+	// Compile the attribute condition up front so a bad subject or template fails before any API call
+	condition, err := TrustedSubjectsCondition(cfg.TrustedSubjects, cfg.SubjectTemplate)
+	if err != nil {
+		return nil, err
+	}
+
 	poolID := g.getOptionString(cfg.Options, "pool_id", constants.DefaultGCPWorkloadIdentityPoolID)
 	poolName := g.getOptionString(cfg.Options, "pool_name", "KubeAssume Workload Identity Pool")
 	providerID := constants.DefaultGCPWorkloadIdentityPoolProviderID
@@ -128,9 +145,10 @@ func (g *gcpProvider) Setup(ctx context.Context, cfg federation.SetupConfig) (*f
 	}
 
 	return &federation.SetupResult{
-		ProviderARN: provider.Name,
-		Audiences:   cfg.Audiences,
-		Thumbprint:  "", // GCP does not use thumbprints
+		ProviderARN:    provider.Name,
+		Audiences:      cfg.Audiences,
+		Thumbprint:     "", // GCP does not use thumbprints
+		TrustCondition: condition,
 	}, nil
 }
 
@@ -170,33 +188,75 @@ func (g *gcpProvider) GetProviderInfo(ctx context.Context, issuerURL string) (*f
 		}
 
 		for _, provider := range providers {
-			if provider.Oidc != nil && provider.Oidc.IssuerURI == issuerURL {
-				return &federation.ProviderInfo{
-					ProviderARN:   provider.Name,
-					IssuerURL:     provider.Oidc.IssuerURI,
-					Audiences:     provider.Oidc.AllowedAudiences,
-					Thumbprint:    "",
-					Status:        provider.State,
-					CreatedAt:     provider.CreateTime,
-					CloudProvider: string(federation.ProviderTypeGCP),
-				}, nil
+			if provider.Oidc != nil && federation.IssuerURLsEqual(provider.Oidc.IssuerURI, issuerURL) {
+				return newProviderInfo(provider), nil
 			}
 		}
 	}
 
-	return nil, fmt.Errorf("no GCP Workload Identity Pool Provider found for issuer: %s", issuerURL)
+	return nil, kaerrors.NewNotFoundError("gcp-federation", "no GCP Workload Identity Pool Provider found for issuer: "+issuerURL, nil)
 }
 
-// Delete removes the OIDC provider.
+// GetProviderInfoByID returns info about the Workload Identity Pool Provider with
+// the given resource name, whatever issuer it trusts.
+func (g *gcpProvider) GetProviderInfoByID(ctx context.Context, providerID string) (*federation.ProviderInfo, error) {
+	provider, err := g.getWorkloadIdentityPoolProvider(ctx, providerID)
+	if err != nil {
+		if strings.Contains(err.Error(), "NotFound") {
+			return nil, kaerrors.NewNotFoundError("gcp-federation", "no GCP Workload Identity Pool Provider found with name: "+providerID, err)
+		}
+		return nil, fmt.Errorf("failed to get Workload Identity Pool Provider %s: %w", providerID, err)
+	}
+	return newProviderInfo(provider), nil
+}
+
+// newProviderInfo converts a Workload Identity Pool Provider to a ProviderInfo.
+func newProviderInfo(provider *WorkloadIdentityPoolProvider) *federation.ProviderInfo {
+	info := &federation.ProviderInfo{
+		ProviderARN:   provider.Name,
+		Status:        providerStatus(provider.State, provider.Disabled),
+		RawStatus:     provider.State,
+		CreatedAt:     provider.CreateTime,
+		CloudProvider: string(federation.ProviderTypeGCP),
+	}
+	if provider.Oidc != nil {
+		info.IssuerURL = provider.Oidc.IssuerURI
+		info.Audiences = provider.Oidc.AllowedAudiences
+	}
+	return info
+}
+
+// providerStatus normalizes a Workload Identity Pool Provider state. Disabled
+// providers and providers pending deletion (DELETED) reject tokens.
+func providerStatus(state string, disabled bool) federation.ProviderStatus {
+	if disabled {
+		return federation.ProviderStatusDisabled
+	}
+	switch state {
+	case "ACTIVE":
+		return federation.ProviderStatusActive
+	case "DELETED":
+		return federation.ProviderStatusDisabled
+	default:
+		return federation.ProviderStatusUnknown
+	}
+}
+
+// Delete removes the OIDC provider, and its pool once the pool is empty. Deleting
+// a provider that does not exist is not an error.
 func (g *gcpProvider) Delete(ctx context.Context, issuerURL string) error {
 	g.logger.Info("Deleting GCP Workload Identity Pool Provider", "issuer_url", issuerURL)
 
 	providerInfo, err := g.GetProviderInfo(ctx, issuerURL)
+	if kaerrors.IsNotFoundError(err) {
+		g.logger.Info("GCP Workload Identity Pool Provider not found, skipping deletion", "issuer_url", issuerURL)
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to get OIDC provider info for deletion: %w", err)
 	}
-	if providerInfo == nil {
-		g.logger.Info("GCP Workload Identity Pool Provider not found, skipping deletion", "issuer_url", issuerURL)
+	if providerInfo.RawStatus == "DELETED" {
+		g.logger.Info("GCP Workload Identity Pool Provider already deleted", "provider_resource_name", providerInfo.ProviderARN)
 		return nil
 	}
 
@@ -332,6 +392,11 @@ func (g *gcpProvider) getWorkloadIdentityPoolProvider(ctx context.Context, name
 func (g *gcpProvider) createWorkloadIdentityPoolProvider(ctx context.Context, parent, providerID string, cfg federation.SetupConfig) (*WorkloadIdentityPoolProvider, error) {
 	url := fmt.Sprintf("https://iam.googleapis.com/v1/%s/providers?workloadIdentityPoolProviderId=%s", parent, providerID)
 
+	condition, err := TrustedSubjectsCondition(cfg.TrustedSubjects, cfg.SubjectTemplate)
+	if err != nil {
+		return nil, err
+	}
+
 	provider := &WorkloadIdentityPoolProvider{
 		DisplayName: "KubeAssume OIDC Provider",
 		Description: "OIDC Provider for Kubernetes OIDC federation managed by KubeAssume",
@@ -345,6 +410,7 @@ func (g *gcpProvider) createWorkloadIdentityPoolProvider(ctx context.Context, pa
 			"attribute.service_account_name": "assertion['kubernetes.io']['serviceaccount']['name']",
 			"attribute.original_claims":      "assertion",
 		},
+		AttributeCondition: condition,
 		Oidc: &OidcConfig{
 			IssuerURI:        cfg.IssuerURL,
 			AllowedAudiences: cfg.Audiences,
@@ -382,11 +448,17 @@ func (g *gcpProvider) createWorkloadIdentityPoolProvider(ctx context.Context, pa
 func (g *gcpProvider) updateWorkloadIdentityPoolProvider(ctx context.Context, name string, cfg federation.SetupConfig) (*WorkloadIdentityPoolProvider, error) {
 	url := fmt.Sprintf("https://iam.googleapis.com/v1/%s", name)
 
+	condition, err := TrustedSubjectsCondition(cfg.TrustedSubjects, cfg.SubjectTemplate)
+	if err != nil {
+		return nil, err
+	}
+
 	provider := &WorkloadIdentityPoolProvider{
 		Name: name,
 		Oidc: &OidcConfig{
 			AllowedAudiences: cfg.Audiences,
 		},
+		AttributeCondition: condition,
 	}
 
 	jsonData, err := json.Marshal(provider)
@@ -0,0 +1,29 @@
+package gcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hixichen/kube-iam-assume/pkg/federation"
+)
+
+func TestProviderStatus(t *testing.T) {
+	tests := []struct {
+		state    string
+		disabled bool
+		want     federation.ProviderStatus
+	}{
+		{state: "ACTIVE", want: federation.ProviderStatusActive},
+		{state: "ACTIVE", disabled: true, want: federation.ProviderStatusDisabled},
+		{state: "DELETED", want: federation.ProviderStatusDisabled},
+		{state: "STATE_UNSPECIFIED", want: federation.ProviderStatusUnknown},
+		{state: "", want: federation.ProviderStatusUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			assert.Equal(t, tt.want, providerStatus(tt.state, tt.disabled))
+		})
+	}
+}
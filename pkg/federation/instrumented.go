@@ -0,0 +1,108 @@
+package federation
+
+import (
+	"context"
+	"time"
+)
+
+// Operation names recorded by an instrumented provider.
+const (
+	OperationSetup           = "setup"
+	OperationValidate        = "validate"
+	OperationDelete          = "delete"
+	OperationGetProviderInfo = "get_provider_info"
+)
+
+// Operation results recorded by an instrumented provider.
+const (
+	OperationResultSuccess = "success"
+	OperationResultError   = "error"
+)
+
+// OperationRecorder records the outcome of a federation provider call.
+type OperationRecorder interface {
+	// RecordFederationOperation records one call of operation on a provider of the given type
+	RecordFederationOperation(provider, operation, result string, duration time.Duration)
+}
+
+// Instrument wraps p so that Setup, Validate, Delete, GetProviderInfo and, when p
+// implements ProviderLookup, GetProviderInfoByID are recorded on rec.
+// It returns p unchanged when rec is nil.
+func Instrument(p Provider, rec OperationRecorder) Provider {
+	if rec == nil {
+		return p
+	}
+	instrumented := &instrumentedProvider{provider: p, rec: rec}
+	if lookup, ok := p.(ProviderLookup); ok {
+		return &instrumentedLookupProvider{instrumentedProvider: instrumented, lookup: lookup}
+	}
+	return instrumented
+}
+
+// instrumentedProvider records every call of the wrapped provider.
+type instrumentedProvider struct {
+	provider Provider
+	rec      OperationRecorder
+}
+
+// record records operation as started at start and ending now with err.
+func (p *instrumentedProvider) record(operation string, start time.Time, err error) {
+	result := OperationResultSuccess
+	if err != nil {
+		result = OperationResultError
+	}
+	p.rec.RecordFederationOperation(p.provider.Type(), operation, result, time.Since(start))
+}
+
+// Setup calls Setup on the wrapped provider and records it.
+func (p *instrumentedProvider) Setup(ctx context.Context, cfg SetupConfig) (*SetupResult, error) {
+	start := time.Now()
+	result, err := p.provider.Setup(ctx, cfg)
+	p.record(OperationSetup, start, err)
+	return result, err
+}
+
+// Validate calls Validate on the wrapped provider and records it.
+func (p *instrumentedProvider) Validate(ctx context.Context, issuerURL string) error {
+	start := time.Now()
+	err := p.provider.Validate(ctx, issuerURL)
+	p.record(OperationValidate, start, err)
+	return err
+}
+
+// GetProviderInfo calls GetProviderInfo on the wrapped provider and records it.
+func (p *instrumentedProvider) GetProviderInfo(ctx context.Context, issuerURL string) (*ProviderInfo, error) {
+	start := time.Now()
+	info, err := p.provider.GetProviderInfo(ctx, issuerURL)
+	p.record(OperationGetProviderInfo, start, err)
+	return info, err
+}
+
+// Delete calls Delete on the wrapped provider and records it.
+func (p *instrumentedProvider) Delete(ctx context.Context, issuerURL string) error {
+	start := time.Now()
+	err := p.provider.Delete(ctx, issuerURL)
+	p.record(OperationDelete, start, err)
+	return err
+}
+
+// Type returns the type of the wrapped provider.
+func (p *instrumentedProvider) Type() string {
+	return p.provider.Type()
+}
+
+// instrumentedLookupProvider is an instrumentedProvider whose wrapped provider
+// can also look providers up by ID.
+type instrumentedLookupProvider struct {
+	*instrumentedProvider
+	lookup ProviderLookup
+}
+
+// GetProviderInfoByID calls GetProviderInfoByID on the wrapped provider and records
+// it as a GetProviderInfo call.
+func (p *instrumentedLookupProvider) GetProviderInfoByID(ctx context.Context, providerID string) (*ProviderInfo, error) {
+	start := time.Now()
+	info, err := p.lookup.GetProviderInfoByID(ctx, providerID)
+	p.record(OperationGetProviderInfo, start, err)
+	return info, err
+}
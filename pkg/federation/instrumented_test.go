@@ -0,0 +1,82 @@
+package federation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubProvider is a Provider whose Validate returns validateErr.
+type stubProvider struct {
+	Provider
+	validateErr error
+}
+
+func (p *stubProvider) Validate(ctx context.Context, issuerURL string) error { return p.validateErr }
+func (p *stubProvider) Type() string                                         { return "aws" }
+
+// stubLookupProvider is a stubProvider that can look providers up by ID.
+type stubLookupProvider struct {
+	stubProvider
+}
+
+func (p *stubLookupProvider) GetProviderInfoByID(ctx context.Context, providerID string) (*ProviderInfo, error) {
+	return &ProviderInfo{ProviderARN: providerID}, nil
+}
+
+// operation is one call recorded by recordingRecorder.
+type operation struct {
+	provider, operation, result string
+}
+
+// recordingRecorder keeps every recorded operation.
+type recordingRecorder struct {
+	operations []operation
+}
+
+func (r *recordingRecorder) RecordFederationOperation(provider, op, result string, duration time.Duration) {
+	r.operations = append(r.operations, operation{provider: provider, operation: op, result: result})
+}
+
+func TestInstrument_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantResult string
+	}{
+		{name: "success", wantResult: OperationResultSuccess},
+		{name: "failure", err: errors.New("provider not found"), wantResult: OperationResultError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recordingRecorder{}
+			provider := Instrument(&stubProvider{validateErr: tt.err}, rec)
+
+			err := provider.Validate(t.Context(), "https://issuer.example.com")
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, []operation{{provider: "aws", operation: OperationValidate, result: tt.wantResult}}, rec.operations)
+		})
+	}
+}
+
+func TestInstrument_ForwardsProviderLookup(t *testing.T) {
+	rec := &recordingRecorder{}
+
+	_, ok := Instrument(&stubProvider{}, rec).(ProviderLookup)
+	assert.False(t, ok, "a provider without lookup must not gain one")
+
+	lookup, ok := Instrument(&stubLookupProvider{}, rec).(ProviderLookup)
+	require.True(t, ok)
+	info, err := lookup.GetProviderInfoByID(t.Context(), "arn:aws:iam::123456789012:oidc-provider/issuer.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "arn:aws:iam::123456789012:oidc-provider/issuer.example.com", info.ProviderARN)
+	assert.Equal(t, []operation{{provider: "aws", operation: OperationGetProviderInfo, result: OperationResultSuccess}}, rec.operations)
+
+	p := &stubProvider{}
+	assert.Same(t, p, Instrument(p, nil))
+}
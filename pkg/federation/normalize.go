@@ -0,0 +1,86 @@
+package federation
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// NormalizeIssuerURL returns the canonical form of an issuer URL so that
+// cosmetically different spellings compare equal: the scheme and host are
+// lower-cased, a default :443 port and trailing slashes are dropped, and a
+// missing scheme is assumed to be https (AWS stores provider URLs without one).
+// The path keeps its case because issuers are case-sensitive there.
+func NormalizeIssuerURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return strings.TrimRight(raw, "/")
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Host)
+	if scheme == "https" {
+		host = strings.TrimSuffix(host, ":443")
+	}
+	return scheme + "://" + host + strings.TrimRight(u.EscapedPath(), "/")
+}
+
+// IssuerURLsEqual reports whether two issuer URLs name the same issuer.
+func IssuerURLsEqual(a, b string) bool {
+	return NormalizeIssuerURL(a) == NormalizeIssuerURL(b)
+}
+
+// AudienceSet is an unordered set of audiences. Surrounding whitespace and
+// empty entries are ignored; audiences are otherwise compared exactly.
+type AudienceSet map[string]struct{}
+
+// NewAudienceSet builds an AudienceSet from a list of audiences.
+func NewAudienceSet(audiences ...string) AudienceSet {
+	set := make(AudienceSet, len(audiences))
+	for _, aud := range audiences {
+		if aud = strings.TrimSpace(aud); aud != "" {
+			set[aud] = struct{}{}
+		}
+	}
+	return set
+}
+
+// Contains reports whether aud is in the set.
+func (s AudienceSet) Contains(aud string) bool {
+	_, ok := s[strings.TrimSpace(aud)]
+	return ok
+}
+
+// Equal reports whether both sets hold the same audiences, regardless of order
+// or duplicates in the lists they were built from.
+func (s AudienceSet) Equal(other AudienceSet) bool {
+	if len(s) != len(other) {
+		return false
+	}
+	for aud := range s {
+		if _, ok := other[aud]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Missing returns the audiences of s that are not in other, sorted.
+func (s AudienceSet) Missing(other AudienceSet) []string {
+	var missing []string
+	for aud := range s {
+		if _, ok := other[aud]; !ok {
+			missing = append(missing, aud)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
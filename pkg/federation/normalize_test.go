@@ -0,0 +1,54 @@
+package federation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeIssuerURL(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{name: "canonical", raw: "https://oidc.example.com/prod", want: "https://oidc.example.com/prod"},
+		{name: "trailing slash", raw: "https://oidc.example.com/prod/", want: "https://oidc.example.com/prod"},
+		{name: "host case", raw: "HTTPS://OIDC.Example.com/prod", want: "https://oidc.example.com/prod"},
+		{name: "path case preserved", raw: "https://oidc.example.com/Prod", want: "https://oidc.example.com/Prod"},
+		{name: "default port", raw: "https://oidc.example.com:443/prod", want: "https://oidc.example.com/prod"},
+		{name: "non-default port kept", raw: "https://oidc.example.com:8443/prod", want: "https://oidc.example.com:8443/prod"},
+		{name: "missing scheme", raw: "oidc.example.com/prod", want: "https://oidc.example.com/prod"},
+		{name: "bare host", raw: "https://oidc.example.com/", want: "https://oidc.example.com"},
+		{name: "surrounding whitespace", raw: " https://oidc.example.com/prod ", want: "https://oidc.example.com/prod"},
+		{name: "empty", raw: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NormalizeIssuerURL(tt.raw))
+		})
+	}
+}
+
+func TestIssuerURLsEqual(t *testing.T) {
+	assert.True(t, IssuerURLsEqual("https://OIDC.example.com/prod/", "https://oidc.example.com/prod"))
+	assert.True(t, IssuerURLsEqual("oidc.example.com/prod", "https://oidc.example.com/prod"))
+	assert.False(t, IssuerURLsEqual("https://oidc.example.com/prod", "https://oidc.example.com/staging"))
+	assert.False(t, IssuerURLsEqual("https://oidc.example.com/prod", "https://oidc.example.com/Prod"))
+}
+
+func TestAudienceSet(t *testing.T) {
+	a := NewAudienceSet("sts.amazonaws.com", "https://kubernetes.default.svc")
+	b := NewAudienceSet("https://kubernetes.default.svc", " sts.amazonaws.com", "sts.amazonaws.com", "")
+
+	assert.True(t, a.Equal(b), "order, duplicates and blanks must not matter")
+	assert.True(t, a.Contains("sts.amazonaws.com"))
+	assert.False(t, a.Contains("api://AzureADTokenExchange"))
+
+	c := NewAudienceSet("sts.amazonaws.com", "api://AzureADTokenExchange")
+	assert.False(t, a.Equal(c))
+	assert.Equal(t, []string{"https://kubernetes.default.svc"}, a.Missing(c))
+	assert.Equal(t, []string{"api://AzureADTokenExchange"}, c.Missing(a))
+	assert.Empty(t, a.Missing(b))
+}
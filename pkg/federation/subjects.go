@@ -0,0 +1,89 @@
+package federation
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// DefaultSubjectTemplate renders the "sub" claim Kubernetes puts into service account tokens.
+const DefaultSubjectTemplate = "system:serviceaccount:{{.Namespace}}:{{.ServiceAccount}}"
+
+// SubjectTemplateData is what a subject template is applied to for each trusted subject.
+type SubjectTemplateData struct {
+	Namespace      string
+	ServiceAccount string
+}
+
+// ServiceAccountSubjects converts trusted subject entries into the "sub" claim values
+// that service account tokens carry, by applying subjectTemplate (a Go template over
+// SubjectTemplateData) to each entry. An empty template selects DefaultSubjectTemplate.
+// Each entry is "namespace/serviceaccount" or "namespace:serviceaccount".
+// Duplicates are dropped and the input order is preserved.
+func ServiceAccountSubjects(trustedSubjects []string, subjectTemplate string) ([]string, error) {
+	tmpl, err := parseSubjectTemplate(subjectTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	subjects := make([]string, 0, len(trustedSubjects))
+	seen := make(map[string]struct{}, len(trustedSubjects))
+	for _, entry := range trustedSubjects {
+		data, err := parseTrustedSubject(entry)
+		if err != nil {
+			return nil, err
+		}
+		sub, err := renderSubject(tmpl, data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted subject %q: %w", entry, err)
+		}
+		if _, ok := seen[sub]; ok {
+			continue
+		}
+		seen[sub] = struct{}{}
+		subjects = append(subjects, sub)
+	}
+	return subjects, nil
+}
+
+// parseSubjectTemplate compiles a subject template, defaulting to DefaultSubjectTemplate.
+func parseSubjectTemplate(subjectTemplate string) (*template.Template, error) {
+	if subjectTemplate == "" {
+		subjectTemplate = DefaultSubjectTemplate
+	}
+	tmpl, err := template.New("subject").Option("missingkey=error").Parse(subjectTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subject template %q: %w", subjectTemplate, err)
+	}
+	return tmpl, nil
+}
+
+// renderSubject applies tmpl to data. The result is quoted into trust conditions, so
+// it must be non-empty and free of quotes and backslashes.
+func renderSubject(tmpl *template.Template, data SubjectTemplateData) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to apply subject template: %w", err)
+	}
+	sub := sb.String()
+	if strings.TrimSpace(sub) == "" {
+		return "", fmt.Errorf("subject template rendered an empty subject")
+	}
+	if strings.ContainsAny(sub, "'\"\\") {
+		return "", fmt.Errorf("subject template rendered %q, which contains quotes or backslashes", sub)
+	}
+	return sub, nil
+}
+
+func parseTrustedSubject(entry string) (SubjectTemplateData, error) {
+	trimmed := strings.TrimSpace(entry)
+	sep := strings.IndexAny(trimmed, "/:")
+	if sep <= 0 || sep == len(trimmed)-1 {
+		return SubjectTemplateData{}, fmt.Errorf("invalid trusted subject %q: expected namespace/serviceaccount", entry)
+	}
+	namespace, name := trimmed[:sep], trimmed[sep+1:]
+	if strings.ContainsAny(name, "/:'\"\\ ") || strings.ContainsAny(namespace, "'\"\\ ") {
+		return SubjectTemplateData{}, fmt.Errorf("invalid trusted subject %q: expected namespace/serviceaccount", entry)
+	}
+	return SubjectTemplateData{Namespace: namespace, ServiceAccount: name}, nil
+}
@@ -0,0 +1,91 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// clusterHealthKey is the ConfigMap data key holding the cluster statuses.
+const clusterHealthKey = "clusters.json"
+
+// ClusterStatus is the freshness of one cluster's JWKS within a cluster group.
+type ClusterStatus struct {
+	ClusterID    string    `json:"clusterID"`
+	LastModified time.Time `json:"lastModified"`
+	// Healthy is false once the cluster's JWKS is older than the freshness threshold
+	Healthy bool `json:"healthy"`
+}
+
+// ClusterHealth derives each cluster's status from when its JWKS was last written.
+// A cluster is healthy while its JWKS is no older than threshold. Results are sorted by cluster ID.
+func ClusterHealth(lastModified map[string]time.Time, threshold time.Duration, now time.Time) []ClusterStatus {
+	statuses := make([]ClusterStatus, 0, len(lastModified))
+	for clusterID, t := range lastModified {
+		statuses = append(statuses, ClusterStatus{
+			ClusterID:    clusterID,
+			LastModified: t,
+			Healthy:      now.Sub(t) <= threshold,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].ClusterID < statuses[j].ClusterID })
+	return statuses
+}
+
+// SaveClusterHealth writes the cluster statuses to the named ConfigMap, creating it if needed.
+func SaveClusterHealth(ctx context.Context, client kubernetes.Interface, namespace, name string, statuses []ClusterStatus) error {
+	data, err := json.Marshal(statuses)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cluster health: %w", err)
+	}
+
+	configMaps := client.CoreV1().ConfigMaps(namespace)
+	cm, err := configMaps.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get cluster health ConfigMap: %w", err)
+		}
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Data:       map[string]string{clusterHealthKey: string(data)},
+		}
+		if _, err := configMaps.Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create cluster health ConfigMap: %w", err)
+		}
+		return nil
+	}
+
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[clusterHealthKey] = string(data)
+	if _, err := configMaps.Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update cluster health ConfigMap: %w", err)
+	}
+	return nil
+}
+
+// LoadClusterHealth reads the cluster statuses from the named ConfigMap.
+// It returns nil without error when the ConfigMap does not exist (single-cluster mode).
+func LoadClusterHealth(ctx context.Context, client kubernetes.Interface, namespace, name string) ([]ClusterStatus, error) {
+	cm, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get cluster health ConfigMap: %w", err)
+	}
+
+	var statuses []ClusterStatus
+	if err := json.Unmarshal([]byte(cm.Data[clusterHealthKey]), &statuses); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cluster health: %w", err)
+	}
+	return statuses, nil
+}
@@ -3,8 +3,10 @@ package health
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
 	"sync"
 	"time"
 )
@@ -36,6 +38,27 @@ type Result struct {
 	Version string            `json:"version,omitempty"`
 }
 
+// Err returns the unhealthy checks joined into a single error, ordered by check
+// name, or nil if no check is unhealthy.
+func (r *Result) Err() error {
+	names := make([]string, 0, len(r.Checks))
+	for name, check := range r.Checks {
+		if check.Status == StatusUnhealthy {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	errs := make([]error, 0, len(names))
+	for _, name := range names {
+		errs = append(errs, fmt.Errorf("%s: %s", name, r.Checks[name].Message))
+	}
+	return errors.Join(errs...)
+}
+
+// DefaultCheckInterval is how often a Monitor runs the health checks by default.
+const DefaultCheckInterval = time.Minute
+
 // Checker defines a function that performs a health check.
 type Checker func(ctx context.Context) error
 
@@ -62,8 +85,9 @@ func (h *Health) Register(name string, checker Checker) {
 	defer h.mu.Unlock()
 	h.checkers[name] = checker
 	h.results[name] = &Check{
-		Name:   name,
-		Status: StatusUnhealthy,
+		Name:    name,
+		Status:  StatusUnhealthy,
+		Message: "not run yet",
 	}
 }
 
@@ -189,12 +213,43 @@ func (h *Health) ReadinessCheck() Checker {
 		// Check all components are ready to serve
 		result := h.GetResult()
 		if result.Status == string(StatusUnhealthy) {
-			return fmt.Errorf("health check failed")
+			return fmt.Errorf("health check failed: %w", result.Err())
 		}
 		return nil
 	}
 }
 
+// Monitor is a manager runnable that runs every check of a Health on an interval,
+// so readiness probes read cached results instead of running checks, some of which
+// write to the API server, on every request. It runs on every replica.
+type Monitor struct {
+	Health   *Health
+	Interval time.Duration
+}
+
+// NeedLeaderElection lets every replica run its checks, since each reports readiness.
+func (m *Monitor) NeedLeaderElection() bool { return false }
+
+// Start runs the checks right away and then on every interval until ctx is done.
+func (m *Monitor) Start(ctx context.Context) error {
+	interval := m.Interval
+	if interval <= 0 {
+		interval = DefaultCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	m.Health.RunAll(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			m.Health.RunAll(ctx)
+		}
+	}
+}
+
 // computeOverallStatus computes the overall status from individual checks.
 func computeOverallStatus(checks map[string]*Check) Status {
 	status := StatusHealthy
@@ -0,0 +1,121 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testHealth() *Health {
+	return New(slog.New(slog.NewTextHandler(os.Stdout, nil)))
+}
+
+func TestResult_Err(t *testing.T) {
+	h := testHealth()
+	h.Register("bridge", func(context.Context) error { return errors.New("api server unreachable") })
+	h.Register("publisher", func(context.Context) error { return nil })
+	h.Register("rotation", func(context.Context) error { return errors.New("configmaps is forbidden") })
+
+	result := h.RunAll(context.Background())
+	assert.Equal(t, string(StatusUnhealthy), result.Status)
+
+	err := result.Err()
+	require.Error(t, err)
+	assert.Equal(t, "bridge: api server unreachable\nrotation: configmaps is forbidden", err.Error())
+	assert.NotContains(t, err.Error(), "publisher")
+}
+
+func TestResult_ErrHealthy(t *testing.T) {
+	h := testHealth()
+	h.Register("publisher", func(context.Context) error { return nil })
+
+	assert.NoError(t, h.RunAll(context.Background()).Err())
+}
+
+func TestReadinessCheck_NamesFailingChecks(t *testing.T) {
+	h := testHealth()
+	h.Register("bridge", func(context.Context) error { return errors.New("api server unreachable") })
+	h.Register("rotation", func(context.Context) error { return errors.New("configmaps is forbidden") })
+	h.RunAll(context.Background())
+
+	err := h.ReadinessCheck()(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bridge: api server unreachable")
+	assert.Contains(t, err.Error(), "rotation: configmaps is forbidden")
+}
+
+func TestMonitor_RunsChecks(t *testing.T) {
+	h := testHealth()
+	var forbidden atomic.Bool
+	forbidden.Store(true)
+	h.Register("rotation", func(context.Context) error {
+		if forbidden.Load() {
+			return errors.New("configmaps is forbidden")
+		}
+		return nil
+	})
+	assert.ErrorContains(t, h.ReadinessCheck()(context.Background()), "rotation: not run yet")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	monitor := &Monitor{Health: h, Interval: 10 * time.Millisecond}
+	assert.False(t, monitor.NeedLeaderElection())
+	go func() { done <- monitor.Start(ctx) }()
+
+	assert.Eventually(t, func() bool {
+		err := h.ReadinessCheck()(context.Background())
+		return err != nil && strings.Contains(err.Error(), "rotation: configmaps is forbidden")
+	}, time.Second, 5*time.Millisecond)
+
+	forbidden.Store(false)
+	assert.Eventually(t, func() bool {
+		return h.ReadinessCheck()(context.Background()) == nil
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestClusterHealth(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	statuses := ClusterHealth(map[string]time.Time{
+		"cluster-c": now.Add(-25 * time.Hour),
+		"cluster-a": now.Add(-time.Minute),
+		"cluster-b": now.Add(-24 * time.Hour),
+	}, 24*time.Hour, now)
+
+	assert.Equal(t, []ClusterStatus{
+		{ClusterID: "cluster-a", LastModified: now.Add(-time.Minute), Healthy: true},
+		{ClusterID: "cluster-b", LastModified: now.Add(-24 * time.Hour), Healthy: true},
+		{ClusterID: "cluster-c", LastModified: now.Add(-25 * time.Hour), Healthy: false},
+	}, statuses)
+}
+
+func TestClusterHealth_SaveLoadRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewClientset()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// Missing ConfigMap means single-cluster mode
+	statuses, err := LoadClusterHealth(ctx, client, "kube-iam-assume-system", "cluster-health")
+	require.NoError(t, err)
+	assert.Nil(t, statuses)
+
+	first := []ClusterStatus{{ClusterID: "cluster-a", LastModified: now, Healthy: true}}
+	require.NoError(t, SaveClusterHealth(ctx, client, "kube-iam-assume-system", "cluster-health", first))
+	second := []ClusterStatus{{ClusterID: "cluster-a", LastModified: now, Healthy: false}}
+	require.NoError(t, SaveClusterHealth(ctx, client, "kube-iam-assume-system", "cluster-health", second))
+
+	statuses, err = LoadClusterHealth(ctx, client, "kube-iam-assume-system", "cluster-health")
+	require.NoError(t, err)
+	assert.Equal(t, second, statuses)
+}
@@ -0,0 +1,94 @@
+// Package heartbeat renews a coordination.k8s.io Lease so external tooling can
+// detect a controller that is running but no longer making progress.
+package heartbeat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Heartbeat renews a Lease on every sync tick of the active controller.
+type Heartbeat struct {
+	client        kubernetes.Interface
+	namespace     string
+	name          string
+	identity      string
+	leaseDuration time.Duration
+	logger        *slog.Logger
+	nowFunc       func() time.Time // For testing
+}
+
+// New creates a Heartbeat for the Lease namespace/name. identity is recorded as the
+// Lease holder and leaseDuration tells monitors how long a renewal stays valid.
+func New(client kubernetes.Interface, namespace, name, identity string, leaseDuration time.Duration, logger *slog.Logger) *Heartbeat {
+	return &Heartbeat{
+		client:        client,
+		namespace:     namespace,
+		name:          name,
+		identity:      identity,
+		leaseDuration: leaseDuration,
+		logger:        logger,
+		nowFunc:       time.Now,
+	}
+}
+
+// Renew sets the Lease's renewTime to now, creating the Lease if it does not exist.
+func (h *Heartbeat) Renew(ctx context.Context) error {
+	now := metav1.NewMicroTime(h.nowFunc())
+	leaseDurationSeconds := int32(h.leaseDuration.Seconds())
+	leases := h.client.CoordinationV1().Leases(h.namespace)
+
+	lease, err := leases.Get(ctx, h.name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get heartbeat lease: %w", err)
+		}
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      h.name,
+				Namespace: h.namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/name":      "kube-iam-assume",
+					"app.kubernetes.io/component": "heartbeat",
+				},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &h.identity,
+				LeaseDurationSeconds: &leaseDurationSeconds,
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		if _, err := leases.Create(ctx, lease, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create heartbeat lease: %w", err)
+		}
+		h.logger.Debug("Created heartbeat lease", "name", h.name, "namespace", h.namespace)
+		return nil
+	}
+
+	// A new holder (e.g. after failover) resets the acquire time
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != h.identity {
+		lease.Spec.HolderIdentity = &h.identity
+		lease.Spec.AcquireTime = &now
+	}
+	lease.Spec.LeaseDurationSeconds = &leaseDurationSeconds
+	lease.Spec.RenewTime = &now
+
+	if _, err := leases.Update(ctx, lease, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to renew heartbeat lease: %w", err)
+	}
+	h.logger.Debug("Renewed heartbeat lease", "name", h.name, "namespace", h.namespace)
+	return nil
+}
+
+// SetTimeFunc sets the time function (for testing).
+func (h *Heartbeat) SetTimeFunc(f func() time.Time) {
+	h.nowFunc = f
+}
@@ -0,0 +1,51 @@
+package heartbeat
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestHeartbeat_Renew(t *testing.T) {
+	ctx := context.Background()
+	clientset := fake.NewClientset()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	hb := New(clientset, "kube-iam-assume-system", "kube-iam-assume-heartbeat", "controller-a", 3*time.Minute, logger)
+	hb.SetTimeFunc(func() time.Time { return now })
+
+	// First renewal creates the lease
+	require.NoError(t, hb.Renew(ctx))
+	lease, err := clientset.CoordinationV1().Leases("kube-iam-assume-system").Get(ctx, "kube-iam-assume-heartbeat", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "controller-a", *lease.Spec.HolderIdentity)
+	assert.Equal(t, int32(180), *lease.Spec.LeaseDurationSeconds)
+	assert.True(t, lease.Spec.RenewTime.Time.Equal(now))
+	firstAcquire := lease.Spec.AcquireTime.Time
+
+	// Later renewals advance renewTime and keep the acquire time
+	now = now.Add(time.Minute)
+	require.NoError(t, hb.Renew(ctx))
+	lease, err = clientset.CoordinationV1().Leases("kube-iam-assume-system").Get(ctx, "kube-iam-assume-heartbeat", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, lease.Spec.RenewTime.Time.Equal(now))
+	assert.True(t, lease.Spec.AcquireTime.Time.Equal(firstAcquire))
+
+	// A new holder takes over the lease
+	now = now.Add(time.Minute)
+	other := New(clientset, "kube-iam-assume-system", "kube-iam-assume-heartbeat", "controller-b", 3*time.Minute, logger)
+	other.SetTimeFunc(func() time.Time { return now })
+	require.NoError(t, other.Renew(ctx))
+	lease, err = clientset.CoordinationV1().Leases("kube-iam-assume-system").Get(ctx, "kube-iam-assume-heartbeat", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "controller-b", *lease.Spec.HolderIdentity)
+	assert.True(t, lease.Spec.AcquireTime.Time.Equal(now))
+}
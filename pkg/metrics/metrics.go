@@ -2,8 +2,10 @@
 package metrics
 
 import (
+	"errors"
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
 const (
@@ -28,20 +30,46 @@ type Metrics struct {
 	FetchErrorsTotal prometheus.Counter
 	// HealthStatus tracks overall health status
 	HealthStatus *prometheus.GaugeVec
+	// PublishedJWKSBytes tracks the size of the last published JWKS
+	PublishedJWKSBytes prometheus.Gauge
+	// PublishedDiscoveryBytes tracks the size of the last published discovery document
+	PublishedDiscoveryBytes prometheus.Gauge
+	// IssuerCertExpiryTimestamp tracks when the issuer's thumbprinted certificate expires
+	IssuerCertExpiryTimestamp prometheus.Gauge
+	// ClusterHealthy tracks the JWKS freshness of each cluster in a cluster group
+	ClusterHealthy *prometheus.GaugeVec
+	// ClusterJWKSAge tracks the age of each cluster's last JWKS update in a cluster group
+	ClusterJWKSAge *prometheus.GaugeVec
+	// ClusterExcluded tracks the clusters left out of the aggregated JWKS by the denylist
+	ClusterExcluded *prometheus.GaugeVec
+	// FederationHealthy tracks whether the cloud federation provider still trusts the issuer
+	FederationHealthy prometheus.Gauge
+	// FederationIssuerMismatch tracks whether the pinned federation provider trusts a different issuer than the published one
+	FederationIssuerMismatch prometheus.Gauge
+	// FederationOperationsTotal counts calls to the cloud federation provider
+	FederationOperationsTotal *prometheus.CounterVec
+	// FederationOperationDuration measures calls to the cloud federation provider
+	FederationOperationDuration *prometheus.HistogramVec
 }
 
-// New creates and registers all metrics.
-func New() *Metrics {
+// New creates all metrics and registers them with reg, or with the global
+// registry when reg is nil. Metrics already registered by an earlier call are
+// reused, so New can be called more than once per registry without panicking;
+// all Metrics created for one registry then share the same series.
+func New(reg prometheus.Registerer) *Metrics {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
 	return &Metrics{
-		SyncTotal: promauto.NewCounterVec(
+		SyncTotal: register(reg, prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Name:      "sync_total",
 				Help:      "Total number of sync operations",
 			},
 			[]string{"status"}, // success, error
-		),
-		SyncDuration: promauto.NewHistogramVec(
+		)),
+		SyncDuration: register(reg, prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
 				Namespace: namespace,
 				Name:      "sync_duration_seconds",
@@ -49,53 +77,144 @@ func New() *Metrics {
 				Buckets:   prometheus.DefBuckets,
 			},
 			[]string{"phase"}, // fetch, publish
-		),
-		RotationTotal: promauto.NewCounterVec(
+		)),
+		RotationTotal: register(reg, prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Name:      "rotation_total",
 				Help:      "Total number of key rotation events",
 			},
 			[]string{"type"}, // new_key, key_expired
-		),
-		ActiveKeys: promauto.NewGauge(
+		)),
+		ActiveKeys: register(reg, prometheus.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
 				Name:      "active_keys",
 				Help:      "Number of active keys in the JWKS",
 			},
-		),
-		PublishErrorsTotal: promauto.NewCounterVec(
+		)),
+		PublishErrorsTotal: register(reg, prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Name:      "publish_errors_total",
 				Help:      "Total number of publish errors",
 			},
 			[]string{"publisher"}, // s3, gcs, etc.
-		),
-		LastPublishTimestamp: promauto.NewGauge(
+		)),
+		LastPublishTimestamp: register(reg, prometheus.NewGauge(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
 				Name:      "last_publish_timestamp",
 				Help:      "Unix timestamp of last successful publish",
 			},
-		),
-		FetchErrorsTotal: promauto.NewCounter(
+		)),
+		FetchErrorsTotal: register(reg, prometheus.NewCounter(
 			prometheus.CounterOpts{
 				Namespace: namespace,
 				Name:      "fetch_errors_total",
 				Help:      "Total number of OIDC fetch errors",
 			},
-		),
-		HealthStatus: promauto.NewGaugeVec(
+		)),
+		HealthStatus: register(reg, prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
 				Namespace: namespace,
 				Name:      "health_status",
 				Help:      "Health status of components (1=healthy, 0=unhealthy)",
 			},
 			[]string{"component"},
-		),
+		)),
+		PublishedJWKSBytes: register(reg, prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "published_jwks_bytes",
+				Help:      "Size in bytes of the last published JWKS",
+			},
+		)),
+		PublishedDiscoveryBytes: register(reg, prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "published_discovery_bytes",
+				Help:      "Size in bytes of the last published discovery document",
+			},
+		)),
+		IssuerCertExpiryTimestamp: register(reg, prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "issuer_cert_expiry_timestamp",
+				Help:      "Unix timestamp when the issuer certificate used for the AWS thumbprint expires",
+			},
+		)),
+		ClusterHealthy: register(reg, prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "cluster_healthy",
+				Help:      "Whether each cluster in the group published its JWKS within the freshness threshold (1=healthy, 0=lagging)",
+			},
+			[]string{"cluster"},
+		)),
+		ClusterJWKSAge: register(reg, prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "cluster_jwks_age_seconds",
+				Help:      "Seconds since each cluster in the group last updated its JWKS",
+			},
+			[]string{"cluster"},
+		)),
+		ClusterExcluded: register(reg, prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "cluster_excluded",
+				Help:      "Clusters that published a JWKS but were left out of the aggregated JWKS by excludedClusters (1=excluded)",
+			},
+			[]string{"cluster"},
+		)),
+		FederationHealthy: register(reg, prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "federation_healthy",
+				Help:      "Whether the cloud federation provider exists and trusts the issuer, audiences and thumbprint (1=healthy, 0=missing or drifted)",
+			},
+		)),
+		FederationIssuerMismatch: register(reg, prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "federation_issuer_mismatch",
+				Help:      "Whether the pinned federation provider trusts a different issuer than the one published (1=mismatch, 0=match or not checked)",
+			},
+		)),
+		FederationOperationsTotal: register(reg, prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Name:      "federation_operations_total",
+				Help:      "Total number of cloud federation provider operations",
+			},
+			[]string{"provider", "operation", "result"}, // result: success, error
+		)),
+		FederationOperationDuration: register(reg, prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Name:      "federation_operation_duration_seconds",
+				Help:      "Duration of cloud federation provider operations in seconds",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"provider", "operation"},
+		)),
+	}
+}
+
+// register registers c with reg and returns it, or returns the collector that is
+// already registered under the same name. A collector that cannot be registered
+// for another reason, e.g. a conflicting help text, is returned unregistered: it
+// still records, but is not exported.
+func register[T prometheus.Collector](reg prometheus.Registerer, c T) T {
+	err := reg.Register(c)
+	var registered prometheus.AlreadyRegisteredError
+	if errors.As(err, &registered) {
+		if existing, ok := registered.ExistingCollector.(T); ok {
+			return existing
+		}
 	}
+	return c
 }
 
 // RecordSync records a sync operation.
@@ -128,6 +247,74 @@ func (m *Metrics) RecordPublish(timestamp float64) {
 	m.LastPublishTimestamp.Set(timestamp)
 }
 
+// SetPublishedSizes records the marshaled sizes of the published documents.
+func (m *Metrics) SetPublishedSizes(discoveryBytes, jwksBytes int) {
+	m.PublishedDiscoveryBytes.Set(float64(discoveryBytes))
+	m.PublishedJWKSBytes.Set(float64(jwksBytes))
+}
+
+// SetIssuerCertExpiry records when the issuer's thumbprinted certificate expires.
+func (m *Metrics) SetIssuerCertExpiry(notAfter time.Time) {
+	m.IssuerCertExpiryTimestamp.Set(float64(notAfter.Unix()))
+}
+
+// SetClusterHealth replaces the per-cluster health series with healthy, keyed by
+// cluster ID, so clusters that left the group stop being reported.
+func (m *Metrics) SetClusterHealth(healthy map[string]bool) {
+	m.ClusterHealthy.Reset()
+	for cluster, ok := range healthy {
+		value := 0.0
+		if ok {
+			value = 1.0
+		}
+		m.ClusterHealthy.WithLabelValues(cluster).Set(value)
+	}
+}
+
+// SetClusterJWKSAges replaces the per-cluster JWKS age series with ages, keyed by
+// cluster ID, so clusters that left the group stop being reported.
+func (m *Metrics) SetClusterJWKSAges(ages map[string]time.Duration) {
+	m.ClusterJWKSAge.Reset()
+	for cluster, age := range ages {
+		m.ClusterJWKSAge.WithLabelValues(cluster).Set(age.Seconds())
+	}
+}
+
+// SetClusterExcluded replaces the excluded cluster series with excluded, so clusters
+// removed from the denylist stop being reported.
+func (m *Metrics) SetClusterExcluded(excluded []string) {
+	m.ClusterExcluded.Reset()
+	for _, cluster := range excluded {
+		m.ClusterExcluded.WithLabelValues(cluster).Set(1)
+	}
+}
+
+// SetFederationHealthy records the result of the last federation provider drift check.
+func (m *Metrics) SetFederationHealthy(healthy bool) {
+	value := 0.0
+	if healthy {
+		value = 1.0
+	}
+	m.FederationHealthy.Set(value)
+}
+
+// SetFederationIssuerMismatch records whether the pinned federation provider
+// trusts a different issuer than the published one.
+func (m *Metrics) SetFederationIssuerMismatch(mismatch bool) {
+	value := 0.0
+	if mismatch {
+		value = 1.0
+	}
+	m.FederationIssuerMismatch.Set(value)
+}
+
+// RecordFederationOperation records one call to the cloud federation provider.
+// It implements federation.OperationRecorder.
+func (m *Metrics) RecordFederationOperation(provider, operation, result string, duration time.Duration) {
+	m.FederationOperationsTotal.WithLabelValues(provider, operation, result).Inc()
+	m.FederationOperationDuration.WithLabelValues(provider, operation).Observe(duration.Seconds())
+}
+
 // RecordFetchError records a fetch error.
 func (m *Metrics) RecordFetchError() {
 	m.FetchErrorsTotal.Inc()
@@ -154,6 +341,16 @@ func (m *Metrics) Register(reg prometheus.Registerer) error {
 		m.LastPublishTimestamp,
 		m.FetchErrorsTotal,
 		m.HealthStatus,
+		m.PublishedJWKSBytes,
+		m.PublishedDiscoveryBytes,
+		m.IssuerCertExpiryTimestamp,
+		m.ClusterHealthy,
+		m.ClusterJWKSAge,
+		m.ClusterExcluded,
+		m.FederationHealthy,
+		m.FederationIssuerMismatch,
+		m.FederationOperationsTotal,
+		m.FederationOperationDuration,
 	}
 
 	for _, c := range collectors {
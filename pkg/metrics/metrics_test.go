@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_TwiceDoesNotPanic(t *testing.T) {
+	// The global registry, as used by the controller and its tests
+	assert.NotPanics(t, func() {
+		New(nil)
+		New(nil)
+	})
+
+	reg := prometheus.NewRegistry()
+	var first, second *Metrics
+	require.NotPanics(t, func() {
+		first = New(reg)
+		second = New(reg)
+	})
+
+	// Both share the registered series
+	first.RecordSync("success")
+	assert.Equal(t, 1.0, testutil.ToFloat64(second.SyncTotal.WithLabelValues("success")))
+	second.SetActiveKeys(3)
+	assert.Equal(t, 3.0, testutil.ToFloat64(first.ActiveKeys))
+
+	count, err := testutil.GatherAndCount(reg, "kubeassume_sync_total")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestNew_SeparateRegistries(t *testing.T) {
+	first := New(prometheus.NewRegistry())
+	second := New(prometheus.NewRegistry())
+
+	first.RecordSync("success")
+	assert.Equal(t, 0.0, testutil.ToFloat64(second.SyncTotal.WithLabelValues("success")))
+}
@@ -7,13 +7,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"log/slog"
 	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
@@ -33,6 +33,8 @@ type azurePublisher struct {
 	container string
 	config    Config
 	logger    *slog.Logger
+	// history keeps past JWKS versions (nil when disabled)
+	history *iface.JWKSHistory
 }
 
 // New creates a new Azure Blob Storage publisher.
@@ -42,6 +44,39 @@ func New(ctx context.Context, config Config, logger *slog.Logger) (iface.Publish
 		return nil, fmt.Errorf("invalid Azure config: %w", err)
 	}
 
+	client, err := newClient(config, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	publisher := &azurePublisher{
+		client:    client,
+		container: config.Container,
+		config:    config,
+		logger:    logger,
+	}
+	publisher.history = iface.NewJWKSHistory(historyStore{a: publisher}, config.GetHistoryPrefix(), config.JWKSHistory)
+	return publisher, nil
+}
+
+// newClient creates a Blob client for the configured service URL, authenticating
+// with the account key when one is set and with Azure AD otherwise.
+func newClient(config Config, logger *slog.Logger) (*azblob.Client, error) {
+	serviceURL := config.GetServiceURL()
+
+	if config.AccountKey != "" {
+		logger.Info("Azure publisher: using shared key for authentication")
+		cred, err := azblob.NewSharedKeyCredential(config.StorageAccount, config.AccountKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Azure shared key credential: %w", err)
+		}
+		client, err := azblob.NewClientWithSharedKeyCredential(serviceURL, cred, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Azure Blob client: %w", err)
+		}
+		return client, nil
+	}
+
 	var cred *azidentity.DefaultAzureCredential
 	var err error
 
@@ -58,41 +93,35 @@ func New(ctx context.Context, config Config, logger *slog.Logger) (iface.Publish
 		return nil, fmt.Errorf("failed to create Azure credential: %w", err)
 	}
 
-	// Create service URL
-	serviceURL := fmt.Sprintf("https://%s.blob.core.windows.net/", config.StorageAccount)
 	client, err := azblob.NewClient(serviceURL, cred, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Azure Blob client: %w", err)
 	}
-
-	return &azurePublisher{
-		client:    client,
-		container: config.Container,
-		config:    config,
-		logger:    logger,
-	}, nil
+	return client, nil
 }
 
 // Publish uploads the discovery document and JWKS to Azure Blob Storage.
 func (a *azurePublisher) Publish(ctx context.Context, discovery *bridge.DiscoveryDocument, jwks *bridge.JWKS) error {
 	a.logger.Debug("Azure publisher: publishing discovery document and JWKS")
 
-	discoveryPath := a.config.GetDiscoveryPath()
 	jwksPath := a.config.GetJWKSPath()
 
-	// Publish discovery document
-	if err := a.uploadObject(ctx, discoveryPath, discovery); err != nil {
-		return fmt.Errorf("failed to upload discovery document to Azure: %w", err)
+	// Publish discovery document; in multi-cluster mode the aggregation leader owns it
+	if !a.config.MultiClusterEnabled {
+		if err := a.uploadDiscovery(ctx, discovery); err != nil {
+			return err
+		}
 	}
-	a.logger.Debug("Azure publisher: successfully uploaded discovery document",
-		"container", a.container,
-		"path", discoveryPath,
-	)
 
 	// Publish JWKS
-	if err := a.uploadObject(ctx, jwksPath, jwks); err != nil {
+	if err := a.uploadObject(ctx, jwksPath, jwks, a.config.MinifyJWKS); err != nil {
 		return fmt.Errorf("failed to upload JWKS to Azure: %w", err)
 	}
+
+	// Snapshot the JWKS; history is kept for forensics only, so a failure does not fail the publish
+	if err := a.history.Record(ctx, jwks, time.Now()); err != nil {
+		a.logger.Warn("failed to record JWKS history", "error", err)
+	}
 	a.logger.Debug("Azure publisher: successfully uploaded JWKS",
 		"container", a.container,
 		"path", jwksPath,
@@ -101,25 +130,33 @@ func (a *azurePublisher) Publish(ctx context.Context, discovery *bridge.Discover
 	return nil
 }
 
-// uploadObject marshals the object to JSON and uploads it to Azure Blob Storage with optimistic locking.
-func (a *azurePublisher) uploadObject(ctx context.Context, blobPath string, data interface{}) error {
-	jsonData, err := json.MarshalIndent(data, "", "  ")
+// uploadDiscovery uploads the discovery document to every path of the configured key layout.
+func (a *azurePublisher) uploadDiscovery(ctx context.Context, discovery *bridge.DiscoveryDocument) error {
+	keys, err := a.config.GetDiscoveryObjectKeys()
 	if err != nil {
-		return fmt.Errorf("failed to marshal data to JSON: %w", err)
+		return fmt.Errorf("failed to derive discovery object keys: %w", err)
 	}
+	for _, key := range keys {
+		if err := a.uploadObject(ctx, key, discovery, a.config.MinifyDiscovery); err != nil {
+			return fmt.Errorf("failed to upload discovery document to Azure: %w", err)
+		}
+		a.logger.Debug("Azure publisher: successfully uploaded discovery document",
+			"container", a.container,
+			"path", key,
+		)
+	}
+	return nil
+}
 
-	blobClient := a.client.ServiceClient().NewContainerClient(a.container).NewBlockBlobClient(blobPath)
-
-	// Get current ETag for optimistic locking
-	getResp, err := blobClient.GetProperties(ctx, nil)
-	var ifMatch *azcore.ETag
+// uploadObject marshals the object to JSON (compact when minify is set) and uploads it to Azure Blob Storage with optimistic locking.
+func (a *azurePublisher) uploadObject(ctx context.Context, blobPath string, data interface{}, minify bool) error {
+	jsonData, err := iface.MarshalDocument(data, minify)
 	if err != nil {
-		// Blob doesn't exist, we'll set If-Match to "*" for new blobs
-		ifMatch = nil
-	} else {
-		ifMatch = getResp.ETag
+		return fmt.Errorf("failed to marshal data to JSON: %w", err)
 	}
 
+	blobClient := a.client.ServiceClient().NewContainerClient(a.container).NewBlockBlobClient(blobPath)
+
 	contentType := a.config.ContentType
 	if contentType == "" {
 		contentType = "application/json"
@@ -130,6 +167,26 @@ func (a *azurePublisher) uploadObject(ctx context.Context, blobPath string, data
 		cacheControl = "max-age=300"
 	}
 
+	tags := iface.DocumentTags(a.config.ObjectTags, data, a.config.KeyThumbprintTag)
+	var hash string
+	if a.config.SkipUnchangedUploads {
+		hash = iface.ContentHash(jsonData, contentType, cacheControl, tags)
+	}
+
+	// Get current ETag for optimistic locking
+	getResp, err := blobClient.GetProperties(ctx, nil)
+	var ifMatch *azcore.ETag
+	if err != nil {
+		// Blob doesn't exist, we'll set If-Match to "*" for new blobs
+		ifMatch = nil
+	} else {
+		if hash != "" && iface.ContentHashMatches(stringMetadata(getResp.Metadata), hash) {
+			a.logger.Debug("Blob is already up to date, skipping upload", "key", blobPath)
+			return nil
+		}
+		ifMatch = getResp.ETag
+	}
+
 	// Set headers
 	headers := &blob.HTTPHeaders{
 		BlobContentType:  &contentType,
@@ -138,6 +195,13 @@ func (a *azurePublisher) uploadObject(ctx context.Context, blobPath string, data
 
 	uploadOptions := &blockblob.UploadOptions{
 		HTTPHeaders: headers,
+		Metadata:    blobMetadata(tags),
+	}
+	if hash != "" {
+		if uploadOptions.Metadata == nil {
+			uploadOptions.Metadata = make(map[string]*string, 1)
+		}
+		uploadOptions.Metadata[iface.MetadataContentHash] = to.Ptr(hash)
 	}
 
 	if ifMatch != nil {
@@ -165,6 +229,30 @@ func (a *azurePublisher) uploadObject(ctx context.Context, blobPath string, data
 	return nil
 }
 
+// blobMetadata converts object tags to blob metadata, rewriting keys into
+// valid metadata names. Returns nil when there are no tags.
+func blobMetadata(tags map[string]string) map[string]*string {
+	if len(tags) == 0 {
+		return nil
+	}
+	metadata := make(map[string]*string, len(tags))
+	for k, v := range tags {
+		metadata[iface.MetadataKey(k)] = to.Ptr(v)
+	}
+	return metadata
+}
+
+// stringMetadata converts blob metadata returned by Azure to plain strings.
+func stringMetadata(metadata map[string]*string) map[string]string {
+	values := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		if v != nil {
+			values[k] = *v
+		}
+	}
+	return values
+}
+
 // Validate checks configuration and permissions.
 func (a *azurePublisher) Validate(ctx context.Context) error {
 	a.logger.Debug("Azure publisher: validating configuration and permissions")
@@ -237,8 +325,17 @@ func (a *azurePublisher) Type() iface.PublisherType {
 	return iface.PublisherTypeAzure
 }
 
+// Close is a no-op: the Azure SDK client holds no resources that need releasing.
+func (a *azurePublisher) Close() error {
+	return nil
+}
+
 // Ensure azurePublisher implements iface.MultiClusterAggregator.
-var _ iface.MultiClusterAggregator = (*azurePublisher)(nil)
+var (
+	_ iface.MultiClusterAggregator = (*azurePublisher)(nil)
+	_ iface.ClusterDeleter         = (*azurePublisher)(nil)
+	_ iface.ManagedObjectStore     = (*azurePublisher)(nil)
+)
 
 // ListClusterJWKS lists all cluster sub-paths under "clusters/" and returns parsed JWKS per clusterID.
 func (a *azurePublisher) ListClusterJWKS(ctx context.Context) (map[string]*bridge.JWKS, error) {
@@ -281,7 +378,7 @@ func (a *azurePublisher) ListClusterJWKS(ctx context.Context) (map[string]*bridg
 				a.logger.Warn("failed to download cluster JWKS, skipping", "clusterID", clusterID, "error", err)
 				continue
 			}
-			data, err := io.ReadAll(resp.Body)
+			data, err := bridge.ReadLimited(resp.Body, a.config.MaxReadBytes)
 			_ = resp.Body.Close()
 			if err != nil {
 				a.logger.Warn("failed to read cluster JWKS body, skipping", "clusterID", clusterID, "error", err)
@@ -344,8 +441,50 @@ func (a *azurePublisher) GetClusterLastModified(ctx context.Context) (map[string
 	return lastModified, nil
 }
 
+// DeleteClusterJWKS deletes the cluster's JWKS history and JWKS blob.
+func (a *azurePublisher) DeleteClusterJWKS(ctx context.Context, clusterID string) error {
+	if err := iface.DeleteHistory(ctx, historyStore{a: a}, a.config.GetClusterHistoryPrefix(clusterID)); err != nil {
+		return err
+	}
+	blobPath := a.config.GetClusterJWKSPath(clusterID)
+	_, err := a.client.DeleteBlob(ctx, a.container, blobPath, nil)
+	if err != nil {
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == 404 {
+			return nil
+		}
+		return fmt.Errorf("failed to delete blob %s: %w", blobPath, err)
+	}
+	return nil
+}
+
+// ListManagedObjects returns the names of the discovery documents, JWKS and JWKS
+// history blobs still in the container.
+func (a *azurePublisher) ListManagedObjects(ctx context.Context) ([]string, error) {
+	var documents []string
+	if !a.config.MultiClusterEnabled {
+		keys, err := a.config.GetDiscoveryObjectKeys()
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive discovery object keys: %w", err)
+		}
+		documents = keys
+	}
+	documents = append(documents, a.config.GetJWKSPath())
+	return iface.ListExistingObjects(ctx, historyStore{a: a}, documents, a.config.GetHistoryPrefix())
+}
+
+// DeleteManagedObject deletes the blob key.
+func (a *azurePublisher) DeleteManagedObject(ctx context.Context, key string) error {
+	return historyStore{a: a}.DeleteObject(ctx, key)
+}
+
 // PublishAggregatedJWKS writes the merged JWKS to the root JWKS path using optimistic locking.
 func (a *azurePublisher) PublishAggregatedJWKS(ctx context.Context, merged *bridge.JWKS) error {
 	rootPath := a.config.GetRootJWKSPath()
-	return a.uploadObject(ctx, rootPath, merged)
+	return a.uploadObject(ctx, rootPath, merged, a.config.MinifyJWKS)
+}
+
+// PublishRootDiscovery writes the group's discovery document to the root discovery path.
+func (a *azurePublisher) PublishRootDiscovery(ctx context.Context, discovery *bridge.DiscoveryDocument) error {
+	return a.uploadDiscovery(ctx, discovery)
 }
@@ -4,6 +4,9 @@ package azure
 import (
 	"fmt"
 	"path"
+	"strings"
+
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
 )
 
 // Config holds Azure Blob Storage configuration.
@@ -17,11 +20,42 @@ type Config struct {
 	ClientSecret       string `mapstructure:"clientSecret,omitempty"`
 	CacheControl       string `mapstructure:"cacheControl,omitempty"`
 	ContentType        string `mapstructure:"contentType,omitempty"`
+	// ServiceURL is an optional custom Blob service URL (for testing with Azurite),
+	// including the account path, e.g. http://127.0.0.1:10000/devstoreaccount1
+	ServiceURL string `mapstructure:"serviceURL,omitempty"`
+	// AccountKey authenticates with a shared key instead of Azure AD
+	AccountKey string `mapstructure:"accountKey,omitempty"`
 
 	// MultiClusterEnabled enables multi-cluster shared issuer mode
 	MultiClusterEnabled bool
 	// ClusterID is the unique identifier for this cluster within the group
 	ClusterID string
+	// KeyLayout selects where the discovery document is written (default: well-known only)
+	KeyLayout iface.KeyLayout
+
+	// MinifyJWKS publishes the JWKS as compact JSON instead of indented JSON
+	MinifyJWKS bool
+
+	// MinifyDiscovery publishes the discovery document as compact JSON instead of indented JSON
+	MinifyDiscovery bool
+
+	// MaxReadBytes caps the size of a cluster JWKS read during aggregation
+	// (0 uses bridge.DefaultMaxResponseBytes)
+	MaxReadBytes int64
+
+	// ObjectTags are applied to every uploaded object (nil applies none)
+	ObjectTags map[string]string
+
+	// KeyThumbprintTag adds the RFC 7638 thumbprints of the published keys as
+	// a tag on JWKS objects
+	KeyThumbprintTag bool
+
+	// JWKSHistory is the number of past JWKS versions kept below history/ (0 disables)
+	JWKSHistory int
+
+	// SkipUnchangedUploads records a content hash in object metadata and skips
+	// uploads whose hash the stored object already has
+	SkipUnchangedUploads bool
 }
 
 // Validate validates the Azure configuration.
@@ -35,9 +69,11 @@ func (c Config) Validate() error {
 	return nil
 }
 
-// GetDiscoveryPath returns the path for the discovery document.
-func (c Config) GetDiscoveryPath() string {
-	return path.Join(c.Prefix, ".well-known", "openid-configuration")
+// GetDiscoveryObjectKeys returns every object key the discovery document is
+// written to, derived from the issuer URL so the well-known document is served
+// exactly below it.
+func (c Config) GetDiscoveryObjectKeys() ([]string, error) {
+	return iface.DiscoveryObjectKeys(c.GetPublicURL(), c.BucketLayout(), c.KeyLayout)
 }
 
 // GetJWKSPath returns the path for the JWKS
@@ -54,12 +90,40 @@ func (c Config) GetRootJWKSPath() string {
 	return path.Join(c.Prefix, "openid", "v1", "jwks")
 }
 
+// GetHistoryPrefix returns the directory JWKS snapshots are written below. In
+// multi-cluster mode each cluster keeps its own history, outside clusters/ so
+// pruned clusters are not listed again.
+func (c Config) GetHistoryPrefix() string {
+	if c.MultiClusterEnabled {
+		return c.GetClusterHistoryPrefix(c.ClusterID)
+	}
+	return path.Join(c.Prefix, iface.HistoryDir)
+}
+
+// GetClusterHistoryPrefix returns the directory the given cluster's JWKS snapshots are written below.
+func (c Config) GetClusterHistoryPrefix(clusterID string) string {
+	return path.Join(c.Prefix, iface.HistoryDir, clusterID)
+}
+
 // GetClusterJWKSPath returns the cluster-specific JWKS path for the given clusterID.
 func (c Config) GetClusterJWKSPath(clusterID string) string {
 	return path.Join(c.Prefix, "clusters", clusterID, "openid", "v1", "jwks")
 }
 
+// GetServiceURL returns the Blob service URL of the storage account.
+func (c Config) GetServiceURL() string {
+	if c.ServiceURL != "" {
+		return strings.TrimSuffix(c.ServiceURL, "/") + "/"
+	}
+	return fmt.Sprintf("https://%s.blob.core.windows.net/", c.StorageAccount)
+}
+
+// BucketLayout returns how the container is served: blobs live below the container URL.
+func (c Config) BucketLayout() iface.BucketLayout {
+	return iface.BucketLayout{BaseURL: c.GetServiceURL() + c.Container}
+}
+
 // GetPublicURL returns the public URL for the issuer.
 func (c Config) GetPublicURL() string {
-	return fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s", c.StorageAccount, c.Container, c.Prefix)
+	return fmt.Sprintf("%s/%s", c.BucketLayout().BaseURL, c.Prefix)
 }
@@ -1,10 +1,13 @@
 package azure
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
 )
 
 func TestConfig_Validate(t *testing.T) {
@@ -106,6 +109,16 @@ func TestConfig_GetPublicURL(t *testing.T) {
 			},
 			expected: "https://myaccount.blob.core.windows.net/mycontainer/v1/oidc",
 		},
+		{
+			name: "custom service URL",
+			config: Config{
+				StorageAccount: "devstoreaccount1",
+				Container:      "oidc",
+				Prefix:         "test",
+				ServiceURL:     "http://127.0.0.1:10000/devstoreaccount1",
+			},
+			expected: "http://127.0.0.1:10000/devstoreaccount1/oidc/test",
+		},
 	}
 
 	for _, tt := range tests {
@@ -116,7 +129,7 @@ func TestConfig_GetPublicURL(t *testing.T) {
 	}
 }
 
-func TestConfig_GetDiscoveryPath(t *testing.T) {
+func TestConfig_GetJWKSPath(t *testing.T) {
 	tests := []struct {
 		name     string
 		prefix   string
@@ -125,57 +138,75 @@ func TestConfig_GetDiscoveryPath(t *testing.T) {
 		{
 			name:     "with prefix",
 			prefix:   "oidc",
-			expected: "oidc/.well-known/openid-configuration",
+			expected: "oidc/openid/v1/jwks",
 		},
 		{
 			name:     "without prefix",
 			prefix:   "",
-			expected: ".well-known/openid-configuration",
+			expected: "openid/v1/jwks",
 		},
 		{
 			name:     "with nested prefix",
 			prefix:   "v1/oidc",
-			expected: "v1/oidc/.well-known/openid-configuration",
+			expected: "v1/oidc/openid/v1/jwks",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			config := Config{Prefix: tt.prefix}
-			result := config.GetDiscoveryPath()
+			result := config.GetJWKSPath()
 			assert.Equal(t, tt.expected, result)
 		})
 	}
 }
 
-func TestConfig_GetJWKSPath(t *testing.T) {
+func TestConfig_GetDiscoveryObjectKeys(t *testing.T) {
 	tests := []struct {
-		name     string
-		prefix   string
-		expected string
+		name      string
+		endpoint  string
+		prefix    string
+		keyLayout iface.KeyLayout
+		expected  []string
 	}{
+		{
+			name:     "without prefix",
+			expected: []string{".well-known/openid-configuration"},
+		},
 		{
 			name:     "with prefix",
 			prefix:   "oidc",
-			expected: "oidc/openid/v1/jwks",
+			expected: []string{"oidc/.well-known/openid-configuration"},
 		},
 		{
-			name:     "without prefix",
-			prefix:   "",
-			expected: "openid/v1/jwks",
+			name:     "with nested prefix",
+			prefix:   "prod/group-a",
+			expected: []string{"prod/group-a/.well-known/openid-configuration"},
 		},
 		{
-			name:     "with nested prefix",
-			prefix:   "v1/oidc",
-			expected: "v1/oidc/openid/v1/jwks",
+			name:     "custom endpoint",
+			endpoint: "http://127.0.0.1:10000/devstoreaccount1",
+			prefix:   "oidc",
+			expected: []string{"oidc/.well-known/openid-configuration"},
+		},
+		{
+			name:      "flat layout adds dot-free copy",
+			prefix:    "oidc",
+			keyLayout: iface.KeyLayoutFlat,
+			expected:  []string{"oidc/.well-known/openid-configuration", "oidc/openid-configuration"},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			config := Config{Prefix: tt.prefix}
-			result := config.GetJWKSPath()
-			assert.Equal(t, tt.expected, result)
+			config := Config{StorageAccount: "oidcaccount", Container: "oidc", ServiceURL: tt.endpoint, Prefix: tt.prefix, KeyLayout: tt.keyLayout}
+			keys, err := config.GetDiscoveryObjectKeys()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, keys)
+
+			// The well-known document is served exactly below the issuer
+			served := strings.TrimSuffix(config.BucketLayout().BaseURL, "/") + "/" + keys[0]
+			assert.Equal(t, strings.TrimSuffix(config.GetPublicURL(), "/")+"/.well-known/openid-configuration", served)
 		})
 	}
 }
@@ -0,0 +1,69 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
+)
+
+// historyStore keeps JWKS snapshots in the container.
+type historyStore struct {
+	a *azurePublisher
+}
+
+var _ iface.HistoryStore = historyStore{}
+
+func (s historyStore) PutObject(ctx context.Context, key string, data []byte) error {
+	contentType := s.a.config.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	blobClient := s.a.client.ServiceClient().NewContainerClient(s.a.container).NewBlockBlobClient(key)
+	_, err := blobClient.Upload(ctx, streaming.NopCloser(bytes.NewReader(data)), &blockblob.UploadOptions{
+		HTTPHeaders: &blob.HTTPHeaders{BlobContentType: &contentType},
+		Metadata:    blobMetadata(s.a.config.ObjectTags),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload blob %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s historyStore) ListObjects(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	pager := s.a.client.ServiceClient().NewContainerClient(s.a.container).NewListBlobsFlatPager(&container.ListBlobsFlatOptions{
+		Prefix: &prefix,
+	})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", prefix, err)
+		}
+		for _, item := range page.Segment.BlobItems {
+			if item.Name != nil {
+				keys = append(keys, *item.Name)
+			}
+		}
+	}
+	return keys, nil
+}
+
+func (s historyStore) DeleteObject(ctx context.Context, key string) error {
+	if _, err := s.a.client.DeleteBlob(ctx, s.a.container, key, nil); err != nil {
+		var respErr *azcore.ResponseError
+		if errors.As(err, &respErr) && respErr.StatusCode == 404 {
+			return nil
+		}
+		return fmt.Errorf("failed to delete blob %s: %w", key, err)
+	}
+	return nil
+}
@@ -30,35 +30,85 @@ func (f *Factory) Create(ctx context.Context, cfg *config.Config) (iface.Publish
 	if cfg == nil {
 		return nil, fmt.Errorf("nil config")
 	}
+	if prefix, ok := cfg.PrefixOverridden(); ok {
+		f.logger.Warn("publisher prefix is ignored because clusterGroup is set; the group name is used as the storage prefix",
+			"prefix", prefix,
+			"clusterGroup", cfg.Controller.ClusterGroup,
+		)
+	}
 	switch iface.PublisherType(cfg.Publisher.Type) {
 	case iface.PublisherTypeS3:
-		return f.createS3Publisher(ctx, cfg.Publisher.S3, cfg.Controller.ClusterGroup, cfg.Controller.ClusterID)
+		return f.createS3Publisher(ctx, cfg.Publisher.S3, cfg.Controller.ClusterGroup, cfg.Controller.ClusterID, newPublishOptions(cfg))
 	case iface.PublisherTypeGCS:
-		return f.createGCSPublisher(ctx, cfg.Publisher.GCS, cfg.Controller.ClusterGroup, cfg.Controller.ClusterID)
+		return f.createGCSPublisher(ctx, cfg.Publisher.GCS, cfg.Controller.ClusterGroup, cfg.Controller.ClusterID, newPublishOptions(cfg))
 	case iface.PublisherTypeAzure:
-		return f.createAzurePublisher(ctx, cfg.Publisher.Azure, cfg.Controller.ClusterGroup, cfg.Controller.ClusterID)
+		return f.createAzurePublisher(ctx, cfg.Publisher.Azure, cfg.Controller.ClusterGroup, cfg.Controller.ClusterID, newPublishOptions(cfg))
 	case iface.PublisherTypeOCI:
-		return f.createOCIPublisher(ctx, cfg.Publisher.OCI, cfg.Controller.ClusterGroup, cfg.Controller.ClusterID)
+		return f.createOCIPublisher(ctx, cfg.Publisher.OCI, cfg.Controller.ClusterGroup, cfg.Controller.ClusterID, newPublishOptions(cfg))
 	default:
 		return nil, fmt.Errorf("unsupported publisher type: %s", cfg.Publisher.Type)
 	}
 }
 
+// publishOptions holds the backend-independent publisher settings.
+type publishOptions struct {
+	keyLayout       iface.KeyLayout
+	minifyJWKS      bool
+	minifyDiscovery bool
+	maxReadBytes    int64
+	objectTags      map[string]string
+	keyThumbprints  bool
+	jwksHistory     int
+	skipUnchanged   bool
+}
+
+// newPublishOptions extracts the backend-independent settings from the config.
+func newPublishOptions(cfg *config.Config) publishOptions {
+	opts := publishOptions{
+		keyLayout:       iface.KeyLayout(cfg.Publisher.KeyLayout),
+		minifyJWKS:      cfg.Publisher.MinifyJWKS,
+		minifyDiscovery: cfg.Publisher.MinifyDiscovery,
+		maxReadBytes:    cfg.Controller.MaxFetchBytes,
+		jwksHistory:     cfg.Publisher.JWKSHistory,
+		skipUnchanged:   cfg.Publisher.SkipUnchangedUploads,
+	}
+	if !cfg.Publisher.DisableObjectTags {
+		opts.objectTags = iface.ObjectTags(cfg.Controller.ClusterID, cfg.Controller.ClusterGroup, cfg.Publisher.ObjectTags)
+		opts.keyThumbprints = cfg.Publisher.KeyThumbprintTag
+	}
+	return opts
+}
+
 // createS3Publisher creates an S3 publisher.
-func (f *Factory) createS3Publisher(ctx context.Context, cfg *config.S3Config, clusterGroup, clusterID string) (iface.Publisher, error) {
+func (f *Factory) createS3Publisher(ctx context.Context, cfg *config.S3Config, clusterGroup, clusterID string, opts publishOptions) (iface.Publisher, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("S3 configuration is required")
 	}
 
 	s3Cfg := s3.Config{
-		Bucket:         cfg.Bucket,
-		Region:         cfg.Region,
-		Endpoint:       cfg.Endpoint,
-		ForcePathStyle: cfg.ForcePathStyle,
-		Prefix:         cfg.Prefix,
-		UseIRSA:        cfg.UseIRSA,
-		CacheControl:   cfg.CacheControl,
-		ContentType:    cfg.ContentType,
+		Bucket:               cfg.Bucket,
+		Region:               cfg.Region,
+		Endpoint:             cfg.Endpoint,
+		ForcePathStyle:       cfg.ForcePathStyle,
+		Prefix:               cfg.Prefix,
+		UseIRSA:              cfg.UseIRSA,
+		CacheControl:         cfg.CacheControl,
+		ContentType:          cfg.ContentType,
+		KeyLayout:            opts.keyLayout,
+		MinifyJWKS:           opts.minifyJWKS,
+		MinifyDiscovery:      opts.minifyDiscovery,
+		MaxReadBytes:         opts.maxReadBytes,
+		ObjectTags:           opts.objectTags,
+		KeyThumbprintTag:     opts.keyThumbprints,
+		JWKSHistory:          opts.jwksHistory,
+		SkipUnchangedUploads: opts.skipUnchanged,
+	}
+	if cfg.Secondary != nil {
+		s3Cfg.Secondary = &s3.SecondaryConfig{
+			Bucket:   cfg.Secondary.Bucket,
+			Region:   cfg.Secondary.Region,
+			Endpoint: cfg.Secondary.Endpoint,
+		}
 	}
 
 	// When clusterGroup is set, override prefix with group name and enable multi-cluster mode
@@ -77,18 +127,27 @@ func (f *Factory) createS3Publisher(ctx context.Context, cfg *config.S3Config, c
 }
 
 // createGCSPublisher creates a GCS publisher.
-func (f *Factory) createGCSPublisher(ctx context.Context, cfg *config.GCSConfig, clusterGroup, clusterID string) (iface.Publisher, error) {
+func (f *Factory) createGCSPublisher(ctx context.Context, cfg *config.GCSConfig, clusterGroup, clusterID string, opts publishOptions) (iface.Publisher, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("GCS configuration is required")
 	}
 
 	gcsCfg := gcs.Config{
-		Bucket:              cfg.Bucket,
-		Project:             cfg.Project,
-		Prefix:              cfg.Prefix,
-		UseWorkloadIdentity: cfg.UseWorkloadIdentity,
-		CacheControl:        cfg.CacheControl,
-		ContentType:         cfg.ContentType,
+		Bucket:               cfg.Bucket,
+		Project:              cfg.Project,
+		Prefix:               cfg.Prefix,
+		Endpoint:             cfg.Endpoint,
+		UseWorkloadIdentity:  cfg.UseWorkloadIdentity,
+		CacheControl:         cfg.CacheControl,
+		ContentType:          cfg.ContentType,
+		KeyLayout:            opts.keyLayout,
+		MinifyJWKS:           opts.minifyJWKS,
+		MinifyDiscovery:      opts.minifyDiscovery,
+		MaxReadBytes:         opts.maxReadBytes,
+		ObjectTags:           opts.objectTags,
+		KeyThumbprintTag:     opts.keyThumbprints,
+		JWKSHistory:          opts.jwksHistory,
+		SkipUnchangedUploads: opts.skipUnchanged,
 	}
 
 	// When clusterGroup is set, override prefix with group name and enable multi-cluster mode
@@ -107,21 +166,31 @@ func (f *Factory) createGCSPublisher(ctx context.Context, cfg *config.GCSConfig,
 }
 
 // createAzurePublisher creates an Azure Blob Storage publisher.
-func (f *Factory) createAzurePublisher(ctx context.Context, cfg *config.AzureConfig, clusterGroup, clusterID string) (iface.Publisher, error) {
+func (f *Factory) createAzurePublisher(ctx context.Context, cfg *config.AzureConfig, clusterGroup, clusterID string, opts publishOptions) (iface.Publisher, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("azure configuration is required")
 	}
 
 	azureCfg := azure.Config{
-		StorageAccount:     cfg.StorageAccount,
-		Container:          cfg.Container,
-		Prefix:             cfg.Prefix,
-		UseManagedIdentity: cfg.UseManagedIdentity,
-		TenantID:           cfg.TenantID,
-		ClientID:           cfg.ClientID,
-		ClientSecret:       cfg.ClientSecret,
-		CacheControl:       cfg.CacheControl,
-		ContentType:        cfg.ContentType,
+		StorageAccount:       cfg.StorageAccount,
+		Container:            cfg.Container,
+		Prefix:               cfg.Prefix,
+		UseManagedIdentity:   cfg.UseManagedIdentity,
+		TenantID:             cfg.TenantID,
+		ClientID:             cfg.ClientID,
+		ClientSecret:         cfg.ClientSecret,
+		CacheControl:         cfg.CacheControl,
+		ContentType:          cfg.ContentType,
+		ServiceURL:           cfg.ServiceURL,
+		AccountKey:           cfg.AccountKey,
+		KeyLayout:            opts.keyLayout,
+		MinifyJWKS:           opts.minifyJWKS,
+		MinifyDiscovery:      opts.minifyDiscovery,
+		MaxReadBytes:         opts.maxReadBytes,
+		ObjectTags:           opts.objectTags,
+		KeyThumbprintTag:     opts.keyThumbprints,
+		JWKSHistory:          opts.jwksHistory,
+		SkipUnchangedUploads: opts.skipUnchanged,
 	}
 
 	// When clusterGroup is set, override prefix with group name and enable multi-cluster mode
@@ -140,7 +209,7 @@ func (f *Factory) createAzurePublisher(ctx context.Context, cfg *config.AzureCon
 }
 
 // createOCIPublisher creates an OCI Object Storage publisher.
-func (f *Factory) createOCIPublisher(ctx context.Context, cfg *config.OCIConfig, clusterGroup, clusterID string) (iface.Publisher, error) {
+func (f *Factory) createOCIPublisher(ctx context.Context, cfg *config.OCIConfig, clusterGroup, clusterID string, opts publishOptions) (iface.Publisher, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("OCI configuration is required")
 	}
@@ -157,6 +226,14 @@ func (f *Factory) createOCIPublisher(ctx context.Context, cfg *config.OCIConfig,
 		TenancyID:            cfg.TenancyID,
 		CacheControl:         cfg.CacheControl,
 		ContentType:          cfg.ContentType,
+		KeyLayout:            opts.keyLayout,
+		MinifyJWKS:           opts.minifyJWKS,
+		MinifyDiscovery:      opts.minifyDiscovery,
+		MaxReadBytes:         opts.maxReadBytes,
+		ObjectTags:           opts.objectTags,
+		KeyThumbprintTag:     opts.keyThumbprints,
+		JWKSHistory:          opts.jwksHistory,
+		SkipUnchangedUploads: opts.skipUnchanged,
 	}
 
 	// When clusterGroup is set, override prefix with group name and enable multi-cluster mode
@@ -157,7 +157,7 @@ func TestFactory_CreateS3Publisher_ConfigMapping(t *testing.T) {
 	}
 
 	ctx := t.Context()
-	pub, err := factory.createS3Publisher(ctx, s3Cfg, "", "")
+	pub, err := factory.createS3Publisher(ctx, s3Cfg, "", "", publishOptions{})
 	// Publisher creation may succeed; verify there's no panic and result is usable
 	if err == nil {
 		require.NotNil(t, pub)
@@ -168,7 +168,7 @@ func TestFactory_CreateS3Publisher_NilConfig(t *testing.T) {
 	factory := NewFactory(nil)
 	ctx := t.Context()
 
-	_, err := factory.createS3Publisher(ctx, nil, "", "")
+	_, err := factory.createS3Publisher(ctx, nil, "", "", publishOptions{})
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "S3 configuration is required")
 }
@@ -177,7 +177,7 @@ func TestFactory_CreateGCSPublisher_NilConfig(t *testing.T) {
 	factory := NewFactory(nil)
 	ctx := t.Context()
 
-	_, err := factory.createGCSPublisher(ctx, nil, "", "")
+	_, err := factory.createGCSPublisher(ctx, nil, "", "", publishOptions{})
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "GCS configuration is required")
 }
@@ -186,7 +186,7 @@ func TestFactory_CreateAzurePublisher_NilConfig(t *testing.T) {
 	factory := NewFactory(nil)
 	ctx := t.Context()
 
-	_, err := factory.createAzurePublisher(ctx, nil, "", "")
+	_, err := factory.createAzurePublisher(ctx, nil, "", "", publishOptions{})
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "azure configuration is required")
 }
@@ -195,7 +195,7 @@ func TestFactory_CreateOCIPublisher_NilConfig(t *testing.T) {
 	factory := NewFactory(nil)
 	ctx := t.Context()
 
-	_, err := factory.createOCIPublisher(ctx, nil, "", "")
+	_, err := factory.createOCIPublisher(ctx, nil, "", "", publishOptions{})
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "OCI configuration is required")
 }
@@ -4,6 +4,9 @@ package gcs
 import (
 	"fmt"
 	"regexp"
+	"strings"
+
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
 )
 
 // Config holds configuration for GCS publisher.
@@ -17,6 +20,10 @@ type Config struct {
 	// Prefix is an optional path prefix within the bucket
 	Prefix string
 
+	// Endpoint is an optional custom GCS endpoint (for testing with fake-gcs-server).
+	// Requests to it are sent without credentials.
+	Endpoint string
+
 	// UseWorkloadIdentity indicates whether to use Workload Identity for credentials (recommended)
 	UseWorkloadIdentity bool
 
@@ -31,6 +38,33 @@ type Config struct {
 
 	// ClusterID is the unique identifier for this cluster within the group
 	ClusterID string
+
+	// KeyLayout selects where the discovery document is written (default: well-known only)
+	KeyLayout iface.KeyLayout
+
+	// MinifyJWKS publishes the JWKS as compact JSON instead of indented JSON
+	MinifyJWKS bool
+
+	// MinifyDiscovery publishes the discovery document as compact JSON instead of indented JSON
+	MinifyDiscovery bool
+
+	// MaxReadBytes caps the size of a cluster JWKS read during aggregation
+	// (0 uses bridge.DefaultMaxResponseBytes)
+	MaxReadBytes int64
+
+	// ObjectTags are applied to every uploaded object (nil applies none)
+	ObjectTags map[string]string
+
+	// KeyThumbprintTag adds the RFC 7638 thumbprints of the published keys as
+	// a tag on JWKS objects
+	KeyThumbprintTag bool
+
+	// JWKSHistory is the number of past JWKS versions kept below history/ (0 disables)
+	JWKSHistory int
+
+	// SkipUnchangedUploads records a content hash in object metadata and skips
+	// uploads whose hash the stored object already has
+	SkipUnchangedUploads bool
 }
 
 // Validate validates the GCS configuration.
@@ -48,9 +82,17 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// BucketLayout returns how the bucket is served: objects live below the bucket URL.
+func (c *Config) BucketLayout() iface.BucketLayout {
+	if c.Endpoint != "" {
+		return iface.BucketLayout{BaseURL: fmt.Sprintf("%s/%s", strings.TrimSuffix(c.Endpoint, "/"), c.Bucket)}
+	}
+	return iface.BucketLayout{BaseURL: fmt.Sprintf("https://storage.googleapis.com/%s", c.Bucket)}
+}
+
 // GetPublicURL constructs the public URL for the bucket, including prefix if set.
 func (c *Config) GetPublicURL() string {
-	base := fmt.Sprintf("https://storage.googleapis.com/%s", c.Bucket)
+	base := c.BucketLayout().BaseURL
 	if c.Prefix != "" {
 		return base + "/" + c.Prefix
 	}
@@ -59,7 +101,14 @@ func (c *Config) GetPublicURL() string {
 
 // GetDiscoveryPath returns the path for the discovery document (no prefix — prefix is in GetPublicURL).
 func (c *Config) GetDiscoveryPath() string {
-	return ".well-known/openid-configuration"
+	return iface.WellKnownDiscoveryPath
+}
+
+// GetDiscoveryObjectKeys returns every object key the discovery document is
+// written to, derived from the issuer URL so the well-known document is served
+// exactly below it.
+func (c *Config) GetDiscoveryObjectKeys() ([]string, error) {
+	return iface.DiscoveryObjectKeys(c.GetPublicURL(), c.BucketLayout(), c.KeyLayout)
 }
 
 // GetJWKSPath returns the path for the JWKS
@@ -76,6 +125,21 @@ func (c *Config) GetRootJWKSPath() string {
 	return "openid/v1/jwks"
 }
 
+// GetHistoryPrefix returns the directory JWKS snapshots are written below. In
+// multi-cluster mode each cluster keeps its own history, outside clusters/ so
+// pruned clusters are not listed again.
+func (c *Config) GetHistoryPrefix() string {
+	if c.MultiClusterEnabled {
+		return c.GetClusterHistoryPrefix(c.ClusterID)
+	}
+	return iface.HistoryDir
+}
+
+// GetClusterHistoryPrefix returns the directory the given cluster's JWKS snapshots are written below.
+func (c *Config) GetClusterHistoryPrefix(clusterID string) string {
+	return iface.HistoryDir + "/" + clusterID
+}
+
 // GetClusterJWKSPath returns the cluster-specific JWKS path for the given clusterID.
 func (c *Config) GetClusterJWKSPath(clusterID string) string {
 	return "clusters/" + clusterID + "/openid/v1/jwks"
@@ -1,10 +1,13 @@
 package gcs
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
 )
 
 func TestConfig_Validate(t *testing.T) {
@@ -137,6 +140,15 @@ func TestConfig_GetPublicURL(t *testing.T) {
 			},
 			expected: "https://storage.googleapis.com/my.bucket",
 		},
+		{
+			name: "custom endpoint",
+			config: Config{
+				Bucket:   "my-bucket",
+				Endpoint: "http://localhost:4443/",
+				Prefix:   "oidc",
+			},
+			expected: "http://localhost:4443/my-bucket/oidc",
+		},
 	}
 
 	for _, tt := range tests {
@@ -343,3 +355,54 @@ func TestValidateBucketName(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_GetDiscoveryObjectKeys(t *testing.T) {
+	tests := []struct {
+		name      string
+		endpoint  string
+		prefix    string
+		keyLayout iface.KeyLayout
+		expected  []string
+	}{
+		{
+			name:     "without prefix",
+			expected: []string{".well-known/openid-configuration"},
+		},
+		{
+			name:     "with prefix",
+			prefix:   "oidc",
+			expected: []string{"oidc/.well-known/openid-configuration"},
+		},
+		{
+			name:     "with nested prefix",
+			prefix:   "prod/group-a",
+			expected: []string{"prod/group-a/.well-known/openid-configuration"},
+		},
+		{
+			name:     "custom endpoint",
+			endpoint: "http://localhost:4443/",
+			prefix:   "oidc",
+			expected: []string{"oidc/.well-known/openid-configuration"},
+		},
+		{
+			name:      "flat layout adds dot-free copy",
+			prefix:    "oidc",
+			keyLayout: iface.KeyLayoutFlat,
+			expected:  []string{"oidc/.well-known/openid-configuration", "oidc/openid-configuration"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{Bucket: "oidc-bucket", Endpoint: tt.endpoint, Prefix: tt.prefix, KeyLayout: tt.keyLayout}
+			keys, err := config.GetDiscoveryObjectKeys()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, keys)
+
+			// The well-known document is served exactly below the issuer
+			served := strings.TrimSuffix(config.BucketLayout().BaseURL, "/") + "/" + keys[0]
+			assert.Equal(t, strings.TrimSuffix(config.GetPublicURL(), "/")+"/.well-known/openid-configuration", served)
+			assert.Equal(t, config.GetFullDiscoveryURL(), served)
+		})
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"log/slog"
 	"strings"
 	"time"
@@ -29,6 +28,8 @@ type gcsPublisher struct {
 	config       Config
 	bucketHandle *storage.BucketHandle
 	logger       *slog.Logger
+	// history keeps past JWKS versions (nil when disabled)
+	history *iface.JWKSHistory
 }
 
 // New creates a new GCS publisher.
@@ -40,7 +41,13 @@ func New(ctx context.Context, config Config, logger *slog.Logger) (iface.Publish
 
 	var opts []option.ClientOption
 
-	if config.UseWorkloadIdentity {
+	if config.Endpoint != "" {
+		logger.Info("GCS publisher: using custom endpoint without authentication", "endpoint", config.Endpoint)
+		opts = append(opts,
+			option.WithEndpoint(strings.TrimSuffix(config.Endpoint, "/")+"/storage/v1/"),
+			option.WithoutAuthentication(),
+		)
+	} else if config.UseWorkloadIdentity {
 		logger.Info("GCS publisher: using workload identity for authentication")
 		// Default credential chain will use Workload Identity if configured
 		// No explicit options needed here for default behavior.
@@ -61,6 +68,7 @@ func New(ctx context.Context, config Config, logger *slog.Logger) (iface.Publish
 		bucketHandle: client.Bucket(config.Bucket),
 		logger:       logger,
 	}
+	publisher.history = iface.NewJWKSHistory(historyStore{g: publisher}, publisher.prefixedKey(config.GetHistoryPrefix()), config.JWKSHistory)
 
 	return publisher, nil
 }
@@ -77,22 +85,24 @@ func (g *gcsPublisher) prefixedKey(key string) string {
 func (g *gcsPublisher) Publish(ctx context.Context, discovery *bridge.DiscoveryDocument, jwks *bridge.JWKS) error {
 	g.logger.Debug("GCS publisher: publishing discovery document and JWKS")
 
-	discoveryPath := g.prefixedKey(g.config.GetDiscoveryPath())
 	jwksPath := g.prefixedKey(g.config.GetJWKSPath())
 
-	// Publish discovery document
-	if err := g.uploadObject(ctx, discoveryPath, discovery); err != nil {
-		return fmt.Errorf("failed to upload discovery document to GCS: %w", err)
+	// Publish discovery document; in multi-cluster mode the aggregation leader owns it
+	if !g.config.MultiClusterEnabled {
+		if err := g.uploadDiscovery(ctx, discovery); err != nil {
+			return err
+		}
 	}
-	g.logger.Debug("GCS publisher: successfully uploaded discovery document",
-		"bucket", g.config.Bucket,
-		"path", discoveryPath,
-	)
 
 	// Publish JWKS
-	if err := g.uploadObject(ctx, jwksPath, jwks); err != nil {
+	if err := g.uploadObject(ctx, jwksPath, jwks, g.config.MinifyJWKS); err != nil {
 		return fmt.Errorf("failed to upload JWKS to GCS: %w", err)
 	}
+
+	// Snapshot the JWKS; history is kept for forensics only, so a failure does not fail the publish
+	if err := g.history.Record(ctx, jwks, time.Now()); err != nil {
+		g.logger.Warn("failed to record JWKS history", "error", err)
+	}
 	g.logger.Debug("GCS publisher: successfully uploaded JWKS",
 		"bucket", g.config.Bucket,
 		"path", jwksPath,
@@ -101,14 +111,37 @@ func (g *gcsPublisher) Publish(ctx context.Context, discovery *bridge.DiscoveryD
 	return nil
 }
 
-// uploadObject marshals the object to JSON and uploads it to GCS with optimistic locking.
-func (g *gcsPublisher) uploadObject(ctx context.Context, path string, data interface{}) error {
-	jsonData, err := json.Marshal(data)
+// uploadDiscovery uploads the discovery document to every path of the configured key layout.
+func (g *gcsPublisher) uploadDiscovery(ctx context.Context, discovery *bridge.DiscoveryDocument) error {
+	keys, err := g.config.GetDiscoveryObjectKeys()
+	if err != nil {
+		return fmt.Errorf("failed to derive discovery object keys: %w", err)
+	}
+	for _, key := range keys {
+		if err := g.uploadObject(ctx, key, discovery, g.config.MinifyDiscovery); err != nil {
+			return fmt.Errorf("failed to upload discovery document to GCS: %w", err)
+		}
+		g.logger.Debug("GCS publisher: successfully uploaded discovery document",
+			"bucket", g.config.Bucket,
+			"path", key,
+		)
+	}
+	return nil
+}
+
+// uploadObject marshals the object to JSON (compact when minify is set) and uploads it to GCS with optimistic locking.
+func (g *gcsPublisher) uploadObject(ctx context.Context, path string, data interface{}, minify bool) error {
+	jsonData, err := iface.MarshalDocument(data, minify)
 	if err != nil {
 		return fmt.Errorf("failed to marshal data to JSON: %w", err)
 	}
 
 	obj := g.bucketHandle.Object(path)
+	tags := iface.DocumentTags(g.config.ObjectTags, data, g.config.KeyThumbprintTag)
+	var hash string
+	if g.config.SkipUnchangedUploads {
+		hash = iface.ContentHash(jsonData, g.config.ContentType, g.config.CacheControl, tags)
+	}
 
 	// Get current generation for optimistic locking
 	attrs, err := obj.Attrs(ctx)
@@ -120,6 +153,10 @@ func (g *gcsPublisher) uploadObject(ctx context.Context, path string, data inter
 		// Object doesn't exist, so we expect generation 0
 		generation = 0
 	} else {
+		if hash != "" && iface.ContentHashMatches(attrs.Metadata, hash) {
+			g.logger.Debug("Object is already up to date, skipping upload", "key", path)
+			return nil
+		}
 		generation = attrs.Generation
 	}
 
@@ -127,6 +164,15 @@ func (g *gcsPublisher) uploadObject(ctx context.Context, path string, data inter
 	wc := obj.If(storage.Conditions{GenerationMatch: generation}).NewWriter(ctx)
 	wc.ContentType = g.config.ContentType
 	wc.CacheControl = g.config.CacheControl
+	if len(tags) > 0 || hash != "" {
+		wc.Metadata = make(map[string]string, len(tags)+1)
+		for k, v := range tags {
+			wc.Metadata[k] = v
+		}
+		if hash != "" {
+			wc.Metadata[iface.MetadataContentHash] = hash
+		}
+	}
 
 	if _, err := wc.Write(jsonData); err != nil {
 		return fmt.Errorf("failed to write data to GCS object %s: %w", path, err)
@@ -229,8 +275,20 @@ func (g *gcsPublisher) Type() iface.PublisherType {
 	return iface.PublisherTypeGCS
 }
 
+// Close closes the GCS client and its idle connections.
+func (g *gcsPublisher) Close() error {
+	if err := g.client.Close(); err != nil {
+		return fmt.Errorf("failed to close GCS client: %w", err)
+	}
+	return nil
+}
+
 // Ensure gcsPublisher implements iface.MultiClusterAggregator.
-var _ iface.MultiClusterAggregator = (*gcsPublisher)(nil)
+var (
+	_ iface.MultiClusterAggregator = (*gcsPublisher)(nil)
+	_ iface.ClusterDeleter         = (*gcsPublisher)(nil)
+	_ iface.ManagedObjectStore     = (*gcsPublisher)(nil)
+)
 
 // ListClusterJWKS lists all cluster sub-paths under "clusters/" and returns parsed JWKS per clusterID.
 func (g *gcsPublisher) ListClusterJWKS(ctx context.Context) (map[string]*bridge.JWKS, error) {
@@ -251,7 +309,7 @@ func (g *gcsPublisher) ListClusterJWKS(ctx context.Context) (map[string]*bridge.
 			g.logger.Warn("failed to read cluster JWKS, skipping", "clusterID", clusterID, "error", err)
 			continue
 		}
-		data, err := io.ReadAll(r)
+		data, err := bridge.ReadLimited(r, g.config.MaxReadBytes)
 		_ = r.Close()
 		if err != nil {
 			g.logger.Warn("failed to read cluster JWKS body, skipping", "clusterID", clusterID, "error", err)
@@ -290,10 +348,47 @@ func (g *gcsPublisher) GetClusterLastModified(ctx context.Context) (map[string]t
 	return lastModified, nil
 }
 
+// DeleteClusterJWKS deletes the cluster's JWKS history and JWKS object.
+func (g *gcsPublisher) DeleteClusterJWKS(ctx context.Context, clusterID string) error {
+	if err := iface.DeleteHistory(ctx, historyStore{g: g}, g.prefixedKey(g.config.GetClusterHistoryPrefix(clusterID))); err != nil {
+		return err
+	}
+	key := g.prefixedKey(g.config.GetClusterJWKSPath(clusterID))
+	if err := g.bucketHandle.Object(key).Delete(ctx); err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// ListManagedObjects returns the keys of the discovery documents, JWKS and JWKS
+// history still in the bucket.
+func (g *gcsPublisher) ListManagedObjects(ctx context.Context) ([]string, error) {
+	var documents []string
+	if !g.config.MultiClusterEnabled {
+		keys, err := g.config.GetDiscoveryObjectKeys()
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive discovery object keys: %w", err)
+		}
+		documents = keys
+	}
+	documents = append(documents, g.prefixedKey(g.config.GetJWKSPath()))
+	return iface.ListExistingObjects(ctx, historyStore{g: g}, documents, g.prefixedKey(g.config.GetHistoryPrefix()))
+}
+
+// DeleteManagedObject deletes key.
+func (g *gcsPublisher) DeleteManagedObject(ctx context.Context, key string) error {
+	return historyStore{g: g}.DeleteObject(ctx, key)
+}
+
 // PublishAggregatedJWKS writes the merged JWKS to the root JWKS path using optimistic locking.
 func (g *gcsPublisher) PublishAggregatedJWKS(ctx context.Context, merged *bridge.JWKS) error {
 	rootKey := g.prefixedKey(g.config.GetRootJWKSPath())
-	return g.uploadObject(ctx, rootKey, merged)
+	return g.uploadObject(ctx, rootKey, merged, g.config.MinifyJWKS)
+}
+
+// PublishRootDiscovery writes the group's discovery document to the root discovery path.
+func (g *gcsPublisher) PublishRootDiscovery(ctx context.Context, discovery *bridge.DiscoveryDocument) error {
+	return g.uploadDiscovery(ctx, discovery)
 }
 
 // listClusterIDs lists cluster IDs from the "clusters/" prefix using delimiter listing.
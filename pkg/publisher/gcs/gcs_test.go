@@ -0,0 +1,35 @@
+package gcs
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/option"
+)
+
+// closeRecordingTransport counts CloseIdleConnections calls made through the HTTP client.
+type closeRecordingTransport struct {
+	http.RoundTripper
+	closes int
+}
+
+func (t *closeRecordingTransport) CloseIdleConnections() {
+	t.closes++
+}
+
+func TestClose_ClosesClient(t *testing.T) {
+	transport := &closeRecordingTransport{RoundTripper: http.DefaultTransport}
+	client, err := storage.NewClient(context.Background(),
+		option.WithHTTPClient(&http.Client{Transport: transport}),
+		option.WithoutAuthentication(),
+	)
+	require.NoError(t, err)
+
+	pub := &gcsPublisher{client: client, config: Config{Bucket: "oidc-bucket"}, bucketHandle: client.Bucket("oidc-bucket")}
+	require.NoError(t, pub.Close())
+	assert.Equal(t, 1, transport.closes, "Close must release the client's idle connections")
+}
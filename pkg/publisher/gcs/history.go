@@ -0,0 +1,59 @@
+package gcs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
+)
+
+// historyStore keeps JWKS snapshots in the bucket. Snapshots are not made
+// publicly readable: relying parties never fetch them.
+type historyStore struct {
+	g *gcsPublisher
+}
+
+var _ iface.HistoryStore = historyStore{}
+
+func (s historyStore) PutObject(ctx context.Context, key string, data []byte) error {
+	wc := s.g.bucketHandle.Object(key).NewWriter(ctx)
+	wc.ContentType = s.g.config.ContentType
+	if len(s.g.config.ObjectTags) > 0 {
+		wc.Metadata = s.g.config.ObjectTags
+	}
+	if _, err := wc.Write(data); err != nil {
+		_ = wc.Close()
+		return fmt.Errorf("failed to write GCS object %s: %w", key, err)
+	}
+	if err := wc.Close(); err != nil {
+		return fmt.Errorf("failed to close GCS object writer for %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s historyStore) ListObjects(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	it := s.g.bucketHandle.Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", prefix, err)
+		}
+		keys = append(keys, attrs.Name)
+	}
+	return keys, nil
+}
+
+func (s historyStore) DeleteObject(ctx context.Context, key string) error {
+	if err := s.g.bucketHandle.Object(key).Delete(ctx); err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
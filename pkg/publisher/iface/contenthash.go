@@ -0,0 +1,43 @@
+package iface
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// MetadataContentHash is the object metadata key holding the ContentHash of an
+// upload. It is a valid metadata name on every backend.
+const MetadataContentHash = "kube_iam_assume_content_sha256"
+
+// ContentHash returns a hash over an upload of data with the given headers and
+// tags, so a change to any of them is uploaded even when the data is unchanged.
+func ContentHash(data []byte, contentType, cacheControl string, tags map[string]string) string {
+	h := sha256.New()
+	for _, part := range []string{contentType, cacheControl} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	for _, k := range slices.Sorted(maps.Keys(tags)) {
+		h.Write([]byte(k))
+		h.Write([]byte{0})
+		h.Write([]byte(tags[k]))
+		h.Write([]byte{0})
+	}
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ContentHashMatches reports whether the metadata of a stored object records
+// hash as its ContentHash. Metadata names are compared case-insensitively, as
+// backends return them in canonical header case.
+func ContentHashMatches(metadata map[string]string, hash string) bool {
+	for k, v := range metadata {
+		if strings.EqualFold(k, MetadataContentHash) {
+			return v == hash
+		}
+	}
+	return false
+}
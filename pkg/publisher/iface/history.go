@@ -0,0 +1,142 @@
+package iface
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
+)
+
+// HistoryDir is the directory JWKS snapshots are written below, relative to the issuer.
+const HistoryDir = "history"
+
+// historyJWKSFile is the name of a JWKS snapshot inside its version directory.
+const historyJWKSFile = "jwks.json"
+
+// HistoryStore is the object storage a JWKSHistory keeps snapshots in.
+type HistoryStore interface {
+	// PutObject writes data to key
+	PutObject(ctx context.Context, key string, data []byte) error
+	// ListObjects returns the keys of every object below prefix
+	ListObjects(ctx context.Context, prefix string) ([]string, error)
+	// DeleteObject deletes key; deleting a missing key is not an error
+	DeleteObject(ctx context.Context, key string) error
+}
+
+// JWKSHistory writes each distinct published JWKS to a versioned key,
+// <prefix>/<unix seconds>/jwks.json, and deletes all but the newest snapshots.
+// Snapshots are kept for forensics only; they are never read back.
+// A nil JWKSHistory records nothing.
+type JWKSHistory struct {
+	store  HistoryStore
+	prefix string
+	retain int
+
+	mu sync.Mutex
+	// last is the most recently recorded JWKS, so unchanged republishes are skipped
+	last []byte
+}
+
+// NewJWKSHistory creates a JWKSHistory that keeps retain snapshots below prefix.
+// It returns nil when retain is not positive.
+func NewJWKSHistory(store HistoryStore, prefix string, retain int) *JWKSHistory {
+	if retain <= 0 {
+		return nil
+	}
+	return &JWKSHistory{store: store, prefix: strings.TrimSuffix(prefix, "/") + "/", retain: retain}
+}
+
+// HistoryJWKSKey returns the key of the JWKS snapshot taken at at below prefix.
+func HistoryJWKSKey(prefix string, at time.Time) string {
+	return path.Join(prefix, strconv.FormatInt(at.Unix(), 10), historyJWKSFile)
+}
+
+// Record writes jwks as the snapshot taken at at, unless it equals the previous
+// snapshot, then prunes the snapshots beyond the retention count. The first call
+// after a restart always writes a snapshot.
+func (h *JWKSHistory) Record(ctx context.Context, jwks *bridge.JWKS, at time.Time) error {
+	if h == nil {
+		return nil
+	}
+	data, err := MarshalDocument(jwks, false)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JWKS snapshot: %w", err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.last != nil && bytes.Equal(h.last, data) {
+		return nil
+	}
+	key := HistoryJWKSKey(h.prefix, at)
+	if err := h.store.PutObject(ctx, key, data); err != nil {
+		return fmt.Errorf("failed to write JWKS snapshot %s: %w", key, err)
+	}
+	h.last = bytes.Clone(data)
+	return h.prune(ctx)
+}
+
+// prune deletes every snapshot older than the newest h.retain.
+func (h *JWKSHistory) prune(ctx context.Context) error {
+	keys, err := h.store.ListObjects(ctx, h.prefix)
+	if err != nil {
+		return fmt.Errorf("failed to list JWKS snapshots: %w", err)
+	}
+
+	type snapshot struct {
+		key     string
+		version int64
+	}
+	var snapshots []snapshot
+	for _, key := range keys {
+		rest, ok := strings.CutPrefix(key, h.prefix)
+		if !ok {
+			continue
+		}
+		dir, file, _ := strings.Cut(rest, "/")
+		version, err := strconv.ParseInt(dir, 10, 64)
+		if err != nil || file != historyJWKSFile {
+			continue
+		}
+		snapshots = append(snapshots, snapshot{key: key, version: version})
+	}
+	if len(snapshots) <= h.retain {
+		return nil
+	}
+
+	// Newest first
+	slices.SortFunc(snapshots, func(a, b snapshot) int {
+		return cmp.Compare(b.version, a.version)
+	})
+	for _, s := range snapshots[h.retain:] {
+		if err := h.store.DeleteObject(ctx, s.key); err != nil {
+			return fmt.Errorf("failed to delete JWKS snapshot %s: %w", s.key, err)
+		}
+	}
+	return nil
+}
+
+// DeleteHistory deletes every JWKS snapshot below prefix, e.g. of a cluster that
+// left its group.
+func DeleteHistory(ctx context.Context, store HistoryStore, prefix string) error {
+	prefix = strings.TrimSuffix(prefix, "/") + "/"
+	keys, err := store.ListObjects(ctx, prefix)
+	if err != nil {
+		return fmt.Errorf("failed to list JWKS snapshots: %w", err)
+	}
+	for _, key := range keys {
+		if err := store.DeleteObject(ctx, key); err != nil {
+			return fmt.Errorf("failed to delete JWKS snapshot %s: %w", key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package iface
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
+)
+
+// mapStore is an in-memory HistoryStore.
+type mapStore map[string][]byte
+
+func (s mapStore) PutObject(_ context.Context, key string, data []byte) error {
+	s[key] = data
+	return nil
+}
+
+func (s mapStore) ListObjects(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for key := range s {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+func (s mapStore) DeleteObject(_ context.Context, key string) error {
+	delete(s, key)
+	return nil
+}
+
+func TestJWKSHistory_PrunesToRetention(t *testing.T) {
+	store := mapStore{
+		// Unrelated objects below the prefix are left alone
+		"prod/history/notes.txt": []byte("keep"),
+	}
+	history := NewJWKSHistory(store, "prod/history", 2)
+	ctx := context.Background()
+	start := time.Unix(1700000000, 0)
+
+	for i, kid := range []string{"key-1", "key-2", "key-3"} {
+		jwks := &bridge.JWKS{Keys: []bridge.JWK{{Kid: kid, Kty: "RSA"}}}
+		require.NoError(t, history.Record(ctx, jwks, start.Add(time.Duration(i)*time.Minute)))
+	}
+
+	keys, err := store.ListObjects(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"prod/history/1700000060/jwks.json",
+		"prod/history/1700000120/jwks.json",
+		"prod/history/notes.txt",
+	}, keys)
+	assert.Contains(t, string(store["prod/history/1700000120/jwks.json"]), "key-3")
+}
+
+func TestJWKSHistory_SkipsUnchangedJWKS(t *testing.T) {
+	store := mapStore{}
+	history := NewJWKSHistory(store, "history", 5)
+	ctx := context.Background()
+	jwks := &bridge.JWKS{Keys: []bridge.JWK{{Kid: "key-1", Kty: "RSA"}}}
+
+	require.NoError(t, history.Record(ctx, jwks, time.Unix(100, 0)))
+	require.NoError(t, history.Record(ctx, jwks, time.Unix(200, 0)))
+	assert.Len(t, store, 1)
+
+	jwks.Keys = append(jwks.Keys, bridge.JWK{Kid: "key-2", Kty: "RSA"})
+	require.NoError(t, history.Record(ctx, jwks, time.Unix(300, 0)))
+	assert.Len(t, store, 2)
+}
+
+func TestJWKSHistory_DisabledRecordsNothing(t *testing.T) {
+	store := mapStore{}
+	history := NewJWKSHistory(store, "history", 0)
+	assert.Nil(t, history)
+	require.NoError(t, history.Record(context.Background(), &bridge.JWKS{}, time.Now()))
+	assert.Empty(t, store)
+}
+
+func TestDeleteHistory(t *testing.T) {
+	store := mapStore{
+		"prod/history/cluster-a/1700000000/jwks.json":  []byte("a"),
+		"prod/history/cluster-ab/1700000000/jwks.json": []byte("ab"),
+		"prod/history/cluster-b/1700000000/jwks.json":  []byte("b"),
+	}
+	ctx := context.Background()
+
+	require.NoError(t, DeleteHistory(ctx, store, "prod/history/cluster-a"))
+	// Deleting an empty history is not an error
+	require.NoError(t, DeleteHistory(ctx, store, "prod/history/cluster-c"))
+
+	keys, err := store.ListObjects(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"prod/history/cluster-ab/1700000000/jwks.json",
+		"prod/history/cluster-b/1700000000/jwks.json",
+	}, keys)
+}
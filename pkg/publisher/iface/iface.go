@@ -2,7 +2,10 @@
 package iface
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/hixichen/kube-iam-assume/pkg/bridge"
@@ -20,9 +23,59 @@ const (
 	PublisherTypeAzure PublisherType = "azure"
 	// PublisherTypeOCI is Oracle Cloud Infrastructure Object Storage.
 	PublisherTypeOCI PublisherType = "oci"
+	// PublisherTypeMemory is the in-memory backend used for tests and local development.
+	PublisherTypeMemory PublisherType = "memory"
 )
 
+// KeyLayout selects where the discovery document is written.
+type KeyLayout string
+
+const (
+	// KeyLayoutWellKnown writes the discovery document only at .well-known/openid-configuration.
+	KeyLayoutWellKnown KeyLayout = "wellKnown"
+	// KeyLayoutFlat also writes a copy at FlatDiscoveryPath for hosts that cannot serve dot-prefixed paths.
+	KeyLayoutFlat KeyLayout = "flat"
+)
+
+// FlatDiscoveryPath is the dot-free discovery path used by KeyLayoutFlat, relative to the issuer.
+const FlatDiscoveryPath = "openid-configuration"
+
+// MarshalDocument encodes v as canonical JSON: object keys are sorted at every
+// level, so the same document always yields the same bytes regardless of struct
+// field order or map iteration. Documents are indented for readability unless
+// minify is set, in which case the compact form is used.
+func MarshalDocument(v interface{}, minify bool) ([]byte, error) {
+	canonical, err := canonicalize(v)
+	if err != nil {
+		return nil, err
+	}
+	if minify {
+		return json.Marshal(canonical)
+	}
+	return json.MarshalIndent(canonical, "", "  ")
+}
+
+// canonicalize round-trips v through a generic JSON value. encoding/json sorts
+// map keys when marshaling, which turns struct fields into sorted keys too;
+// numbers are kept verbatim.
+func canonicalize(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, fmt.Errorf("failed to canonicalize document: %w", err)
+	}
+	return generic, nil
+}
+
 // Publisher defines the interface for publishing OIDC metadata.
+// Implementations create their backend client once, in New, and reuse it for every
+// call until Close. All methods are safe for concurrent use: the controller shares
+// one publisher between startup validation, the reconciler and the aggregation pollers.
 type Publisher interface {
 	// Publish uploads the discovery document and JWKS to the backend
 	Publish(ctx context.Context, discovery *bridge.DiscoveryDocument, jwks *bridge.JWKS) error
@@ -39,6 +92,10 @@ type Publisher interface {
 
 	// Type returns the publisher type (s3, gcs, azure, oci)
 	Type() PublisherType
+
+	// Close releases the backend client's connections. The publisher must not be
+	// used afterwards.
+	Close() error
 }
 
 // MultiClusterAggregator is implemented by publishers when clusterGroup is set.
@@ -53,4 +110,50 @@ type MultiClusterAggregator interface {
 
 	// PublishAggregatedJWKS writes merged JWKS to root openid/v1/jwks with optimistic locking.
 	PublishAggregatedJWKS(ctx context.Context, merged *bridge.JWKS) error
+
+	// PublishRootDiscovery writes the group's discovery document to the root
+	// .well-known/openid-configuration. Clusters do not write it themselves in group mode.
+	PublishRootDiscovery(ctx context.Context, discovery *bridge.DiscoveryDocument) error
+}
+
+// GroupAggregator is implemented by publishers that can merge the root JWKS of every
+// cluster group sharing the storage into one top-level JWKS ("group of groups").
+// The top level is the storage location directly above the group prefixes.
+// Only the elected leader of the aggregating group calls these methods.
+type GroupAggregator interface {
+	// ListGroupJWKS lists the group prefixes and reads each group's root JWKS
+	// (<group>/openid/v1/jwks). Returns a map from group name to JWKS. Prefixes
+	// without a root JWKS are skipped; other read failures are returned.
+	ListGroupJWKS(ctx context.Context) (map[string]*bridge.JWKS, error)
+
+	// GetGroupLastModified returns last-modified time per group for TTL pruning.
+	GetGroupLastModified(ctx context.Context) (map[string]time.Time, error)
+
+	// PublishTopLevelJWKS writes the merged JWKS to openid/v1/jwks at the top level.
+	PublishTopLevelJWKS(ctx context.Context, merged *bridge.JWKS) error
+
+	// PublishTopLevelDiscovery writes the discovery document for the top-level issuer.
+	PublishTopLevelDiscovery(ctx context.Context, discovery *bridge.DiscoveryDocument) error
+}
+
+// ClusterDeleter is implemented by publishers that can remove a cluster's sub-path
+// in multi-cluster mode, so decommissioned clusters can be cleaned up in bulk.
+type ClusterDeleter interface {
+	// DeleteClusterJWKS deletes clusters/<clusterID>/openid/v1/jwks and the cluster's
+	// JWKS history. The history goes first, so a failed delete leaves the cluster
+	// listed for a retry. Deleting a JWKS that does not exist is not an error.
+	DeleteClusterJWKS(ctx context.Context, clusterID string) error
+}
+
+// ManagedObjectStore is implemented by publishers that can list and delete the
+// objects they wrote, so an issuer can be torn down.
+type ManagedObjectStore interface {
+	// ListManagedObjects returns the keys of the discovery documents, JWKS and JWKS
+	// history snapshots this publisher wrote that still exist. In multi-cluster mode
+	// only the cluster's JWKS and history are listed; the group's documents belong
+	// to the aggregation leader.
+	ListManagedObjects(ctx context.Context) ([]string, error)
+
+	// DeleteManagedObject deletes key. Deleting an object that does not exist is not an error.
+	DeleteManagedObject(ctx context.Context, key string) error
 }
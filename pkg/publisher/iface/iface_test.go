@@ -1,9 +1,14 @@
 package iface
 
 import (
+	"encoding/json"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
 )
 
 func TestPublisherType_String(t *testing.T) {
@@ -97,3 +102,195 @@ func TestPublisherTypeFromString(t *testing.T) {
 		})
 	}
 }
+
+func TestMarshalDocument(t *testing.T) {
+	jwks := &bridge.JWKS{Keys: []bridge.JWK{
+		{Kty: "RSA", Kid: "key-1", Alg: "RS256", Use: "sig", N: "AQAB", E: "AQAB"},
+		{Kty: "RSA", Kid: "key-2", Alg: "RS256", Use: "sig", N: "AQAB", E: "AQAB"},
+	}}
+
+	pretty, err := MarshalDocument(jwks, false)
+	require.NoError(t, err)
+	minified, err := MarshalDocument(jwks, true)
+	require.NoError(t, err)
+
+	assert.Less(t, len(minified), len(pretty))
+	assert.Contains(t, string(pretty), "\n  ")
+	assert.NotContains(t, string(minified), "\n")
+
+	// Both forms decode to the same document.
+	var fromPretty, fromMinified bridge.JWKS
+	require.NoError(t, json.Unmarshal(pretty, &fromPretty))
+	require.NoError(t, json.Unmarshal(minified, &fromMinified))
+	assert.Equal(t, *jwks, fromPretty)
+	assert.Equal(t, fromPretty, fromMinified)
+}
+
+func TestMarshalDocument_Canonical(t *testing.T) {
+	doc := map[string]interface{}{
+		"zeta":  1,
+		"alpha": map[string]string{"b": "2", "a": "1", "c": "3"},
+		"keys":  []bridge.JWK{{Kty: "RSA", Kid: "key-1", N: "AQAB", E: "AQAB"}},
+		"large": json.Number("12345678901234567890"),
+	}
+
+	for _, minify := range []bool{false, true} {
+		first, err := MarshalDocument(doc, minify)
+		require.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			again, err := MarshalDocument(doc, minify)
+			require.NoError(t, err)
+			assert.Equal(t, first, again, "marshalling must be byte-stable")
+		}
+	}
+
+	minified, err := MarshalDocument(doc, true)
+	require.NoError(t, err)
+	assert.Equal(t,
+		`{"alpha":{"a":"1","b":"2","c":"3"},"keys":[{"e":"AQAB","kid":"key-1","kty":"RSA","n":"AQAB"}],"large":12345678901234567890,"zeta":1}`,
+		string(minified))
+}
+
+func TestObjectTags(t *testing.T) {
+	tests := []struct {
+		name         string
+		clusterID    string
+		clusterGroup string
+		extra        map[string]string
+		expected     map[string]string
+	}{
+		{
+			name:     "single cluster without ID",
+			expected: map[string]string{TagManagedBy: "kube-iam-assume"},
+		},
+		{
+			name:         "group member",
+			clusterID:    "prod-a",
+			clusterGroup: "prod",
+			expected: map[string]string{
+				TagManagedBy: "kube-iam-assume",
+				TagCluster:   "prod-a",
+				TagGroup:     "prod",
+			},
+		},
+		{
+			name:      "extra tags overlay defaults",
+			clusterID: "prod-a",
+			extra:     map[string]string{"team": "platform", TagManagedBy: "terraform"},
+			expected: map[string]string{
+				TagManagedBy: "terraform",
+				TagCluster:   "prod-a",
+				"team":       "platform",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ObjectTags(tt.clusterID, tt.clusterGroup, tt.extra))
+		})
+	}
+}
+
+func TestMetadataKey(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{TagCluster, "kube_iam_assume_cluster"},
+		{"team", "team"},
+		{"cost.center", "cost_center"},
+		{"2024-budget", "_2024_budget"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MetadataKey(tt.input))
+		})
+	}
+}
+
+func TestDocumentTags(t *testing.T) {
+	rsaKey := func(kid string) bridge.JWK {
+		return bridge.JWK{Kty: "RSA", Kid: kid, N: "sXchDaQebHnPiGvyDOAT4saGEUetSyo9MKLOoWFsueri23bOdgWp4Dy1WlUzewbgBHod5pcM9H95GQRV3JDXboIRROSBigeC5yjU1hGzHHyXss8UDprecbAYxknTcQkhslANGRUZmdTOQ5qTRsLAt6BTYuyvVRdhS8exSZEy_c4gs_7svlJJQ4H9_NxsiIoLwAEk7-Q3UXERGYw_75IDrGA84-lA_-Ct4eTlXHBIY2EaV7t7LjJaynVJCpkv4LKjTTAumiGUIuQhrNhZLuF_RJLqHpM2kgWFLU7-VTdL1VbC2tejvcI2BlMkEpk1BzBZI0KQB0GaDWFLN-aEAw3vRw", E: "AQAB"}
+	}
+	base := map[string]string{TagManagedBy: "kube-iam-assume"}
+	jwks := &bridge.JWKS{Keys: []bridge.JWK{rsaKey("key-1"), {Kty: "oct", Kid: "skipped"}}}
+	thumbprint, err := bridge.JWKThumbprint(rsaKey("key-1"))
+	require.NoError(t, err)
+
+	assert.Equal(t, base, DocumentTags(base, jwks, false), "disabled")
+	assert.Equal(t, base, DocumentTags(base, &bridge.DiscoveryDocument{}, true), "not a JWKS")
+	assert.Equal(t, map[string]string{
+		TagManagedBy:      "kube-iam-assume",
+		TagKeyThumbprints: thumbprint,
+	}, DocumentTags(base, jwks, true))
+	assert.Len(t, base, 1, "base tags must not be modified")
+
+	many := &bridge.JWKS{}
+	for i := 0; i < 10; i++ {
+		many.Keys = append(many.Keys, rsaKey(fmt.Sprintf("key-%d", i)))
+	}
+	assert.LessOrEqual(t, len(DocumentTags(nil, many, true)[TagKeyThumbprints]), maxThumbprintTagLength)
+}
+
+func TestDiscoveryObjectKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		issuerURL string
+		baseURL   string
+		expected  string
+		wantErr   string
+	}{
+		{name: "bucket root", issuerURL: "https://b.s3.us-east-1.amazonaws.com", baseURL: "https://b.s3.us-east-1.amazonaws.com", expected: ".well-known/openid-configuration"},
+		{name: "prefix", issuerURL: "https://storage.googleapis.com/b/oidc", baseURL: "https://storage.googleapis.com/b", expected: "oidc/.well-known/openid-configuration"},
+		{name: "nested prefix", issuerURL: "https://storage.googleapis.com/b/prod/group-a", baseURL: "https://storage.googleapis.com/b", expected: "prod/group-a/.well-known/openid-configuration"},
+		{name: "issuer trailing slash", issuerURL: "https://acct.blob.core.windows.net/oidc/", baseURL: "https://acct.blob.core.windows.net/oidc", expected: ".well-known/openid-configuration"},
+		{name: "base trailing slash", issuerURL: "https://acct.blob.core.windows.net/oidc/prod", baseURL: "https://acct.blob.core.windows.net/oidc/", expected: "prod/.well-known/openid-configuration"},
+		{name: "other host", issuerURL: "https://oidc.example.com/prod", baseURL: "https://storage.googleapis.com/b", wantErr: "not served from bucket URL"},
+		{name: "sibling bucket", issuerURL: "https://storage.googleapis.com/b-other/oidc", baseURL: "https://storage.googleapis.com/b", wantErr: "not served from bucket URL"},
+		{name: "missing base", issuerURL: "https://oidc.example.com", wantErr: "base URL is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := DiscoveryObjectKey(tt.issuerURL, BucketLayout{BaseURL: tt.baseURL})
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, key)
+		})
+	}
+}
+
+func TestDiscoveryObjectKeys(t *testing.T) {
+	layout := BucketLayout{BaseURL: "https://storage.googleapis.com/b"}
+
+	keys, err := DiscoveryObjectKeys("https://storage.googleapis.com/b/oidc", layout, KeyLayoutWellKnown)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"oidc/.well-known/openid-configuration"}, keys)
+
+	keys, err = DiscoveryObjectKeys("https://storage.googleapis.com/b/oidc", layout, KeyLayoutFlat)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"oidc/.well-known/openid-configuration", "oidc/openid-configuration"}, keys)
+
+	_, err = DiscoveryObjectKeys("https://oidc.example.com", layout, KeyLayoutFlat)
+	assert.Error(t, err)
+}
+
+func TestContentHash(t *testing.T) {
+	data := []byte(`{"keys":[]}`)
+	tags := map[string]string{TagManagedBy: "kube-iam-assume", TagCluster: "prod"}
+	hash := ContentHash(data, "application/json", "max-age=300", tags)
+
+	assert.Equal(t, hash, ContentHash(data, "application/json", "max-age=300", map[string]string{TagCluster: "prod", TagManagedBy: "kube-iam-assume"}))
+	assert.NotEqual(t, hash, ContentHash([]byte(`{"keys":[{}]}`), "application/json", "max-age=300", tags))
+	assert.NotEqual(t, hash, ContentHash(data, "application/json", "max-age=60", tags))
+	assert.NotEqual(t, hash, ContentHash(data, "application/json", "max-age=300", nil))
+
+	assert.True(t, ContentHashMatches(map[string]string{"Kube_iam_assume_content_sha256": hash}, hash))
+	assert.False(t, ContentHashMatches(map[string]string{MetadataContentHash: "stale"}, hash))
+	assert.False(t, ContentHashMatches(nil, hash))
+}
@@ -0,0 +1,54 @@
+package iface
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WellKnownDiscoveryPath is the discovery path relative to the issuer.
+const WellKnownDiscoveryPath = ".well-known/openid-configuration"
+
+// BucketLayout describes how a backend serves its objects over HTTP.
+type BucketLayout struct {
+	// BaseURL is the public URL of the bucket root: the object with key K is served at BaseURL + "/" + K
+	BaseURL string
+}
+
+// DiscoveryObjectKey returns the object key that is served at
+// issuerURL + "/.well-known/openid-configuration". A trailing slash on the issuer
+// is dropped before the well-known path is appended, as OpenID Connect Discovery
+// requires. It fails when the issuer is not served from the bucket.
+func DiscoveryObjectKey(issuerURL string, layout BucketLayout) (string, error) {
+	return issuerObjectKey(issuerURL, layout, WellKnownDiscoveryPath)
+}
+
+// DiscoveryObjectKeys returns every object key the discovery document is written
+// to: the DiscoveryObjectKey, plus the dot-free copy in KeyLayoutFlat.
+func DiscoveryObjectKeys(issuerURL string, layout BucketLayout, keyLayout KeyLayout) ([]string, error) {
+	key, err := DiscoveryObjectKey(issuerURL, layout)
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{key}
+	if keyLayout == KeyLayoutFlat {
+		flat, err := issuerObjectKey(issuerURL, layout, FlatDiscoveryPath)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, flat)
+	}
+	return keys, nil
+}
+
+// issuerObjectKey returns the object key that is served at relPath below issuerURL.
+func issuerObjectKey(issuerURL string, layout BucketLayout, relPath string) (string, error) {
+	base := strings.TrimSuffix(layout.BaseURL, "/")
+	if base == "" {
+		return "", fmt.Errorf("bucket base URL is required")
+	}
+	issuerPath, ok := strings.CutPrefix(strings.TrimSuffix(issuerURL, "/"), base)
+	if !ok || (issuerPath != "" && !strings.HasPrefix(issuerPath, "/")) {
+		return "", fmt.Errorf("issuer %s is not served from bucket URL %s", issuerURL, layout.BaseURL)
+	}
+	return strings.TrimPrefix(issuerPath+"/"+relPath, "/"), nil
+}
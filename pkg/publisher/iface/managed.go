@@ -0,0 +1,29 @@
+package iface
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// ListExistingObjects returns the keys among documents that exist in store,
+// followed by the keys of every object below historyPrefix.
+func ListExistingObjects(ctx context.Context, store HistoryStore, documents []string, historyPrefix string) ([]string, error) {
+	var keys []string
+	for _, document := range documents {
+		// Listing by the key itself also returns longer keys it is a prefix of
+		found, err := store.ListObjects(ctx, document)
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(found, document) && !slices.Contains(keys, document) {
+			keys = append(keys, document)
+		}
+	}
+
+	history, err := store.ListObjects(ctx, strings.TrimSuffix(historyPrefix, "/")+"/")
+	if err != nil {
+		return nil, err
+	}
+	return append(keys, history...), nil
+}
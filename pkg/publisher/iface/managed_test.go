@@ -0,0 +1,29 @@
+package iface
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListExistingObjects(t *testing.T) {
+	store := mapStore{
+		"prod/.well-known/openid-configuration":    []byte("{}"),
+		"prod/openid/v1/jwks.bak":                  []byte("{}"),
+		"prod/history/1700000000/jwks.json":        []byte("{}"),
+		"prod/history-old/1600000000/jwks.json":    []byte("{}"),
+		"staging/.well-known/openid-configuration": []byte("{}"),
+	}
+
+	keys, err := ListExistingObjects(context.Background(), store, []string{
+		"prod/.well-known/openid-configuration",
+		"prod/openid/v1/jwks", // already deleted; the longer key is not ours
+	}, "prod/history")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"prod/.well-known/openid-configuration",
+		"prod/history/1700000000/jwks.json",
+	}, keys)
+}
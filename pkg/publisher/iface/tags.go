@@ -0,0 +1,96 @@
+package iface
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
+)
+
+// Standard object tag keys, matching the keys applied to buckets by generate-bucket-name.
+const (
+	TagManagedBy = "kube-iam-assume/managed-by"
+	TagCluster   = "kube-iam-assume/cluster"
+	TagGroup     = "kube-iam-assume/group"
+
+	// TagKeyThumbprints lists the RFC 7638 thumbprints of the keys in a published JWKS
+	TagKeyThumbprints = "kube-iam-assume/key-thumbprints"
+)
+
+// maxThumbprintTagLength keeps the thumbprint tag within the S3 tag value limit.
+const maxThumbprintTagLength = 256
+
+// ObjectTags returns the tags applied to published objects: the standard
+// management tags for clusterID and clusterGroup (omitted when empty),
+// overlaid with extra. Extra tags win on key collisions.
+func ObjectTags(clusterID, clusterGroup string, extra map[string]string) map[string]string {
+	tags := map[string]string{TagManagedBy: "kube-iam-assume"}
+	if clusterID != "" {
+		tags[TagCluster] = clusterID
+	}
+	if clusterGroup != "" {
+		tags[TagGroup] = clusterGroup
+	}
+	for k, v := range extra {
+		tags[k] = v
+	}
+	return tags
+}
+
+// MetadataKey rewrites a tag key into a form every backend accepts as a
+// user metadata name: letters, digits and underscores, not starting with a
+// digit. Azure metadata names must be C# identifiers and OCI sends them as
+// HTTP header names, so "kube-iam-assume/cluster" becomes "kube_iam_assume_cluster".
+func MetadataKey(key string) string {
+	key = strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_') {
+			return r
+		}
+		return '_'
+	}, key)
+	if key != "" && unicode.IsDigit(rune(key[0])) {
+		key = "_" + key
+	}
+	return key
+}
+
+// DocumentTags returns the tags for uploading doc: base, plus TagKeyThumbprints
+// when thumbprints is set and doc is a JWKS. base is never modified.
+func DocumentTags(base map[string]string, doc interface{}, thumbprints bool) map[string]string {
+	jwks, ok := doc.(*bridge.JWKS)
+	if !thumbprints || !ok {
+		return base
+	}
+	value := thumbprintTagValue(jwks)
+	if value == "" {
+		return base
+	}
+
+	tags := make(map[string]string, len(base)+1)
+	for k, v := range base {
+		tags[k] = v
+	}
+	tags[TagKeyThumbprints] = value
+	return tags
+}
+
+// thumbprintTagValue joins the key thumbprints in JWKS order with ":", which
+// every backend accepts in tag values, keeping as many as fit in
+// maxThumbprintTagLength.
+func thumbprintTagValue(jwks *bridge.JWKS) string {
+	var b strings.Builder
+	for _, key := range jwks.Keys {
+		thumbprint, err := bridge.JWKThumbprint(key)
+		if err != nil {
+			continue
+		}
+		if b.Len() > 0 {
+			if b.Len()+1+len(thumbprint) > maxThumbprintTagLength {
+				break
+			}
+			b.WriteByte(':')
+		}
+		b.WriteString(thumbprint)
+	}
+	return b.String()
+}
@@ -0,0 +1,133 @@
+// Package memory provides an in-memory implementation of the Publisher interface
+// for tests and local development.
+package memory
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
+)
+
+// Config holds configuration for the in-memory publisher.
+type Config struct {
+	// PublicURL is the issuer URL reported by GetPublicURL (required)
+	PublicURL string
+
+	// Prefix is an optional path prefix for stored objects; PublicURL must end with it
+	Prefix string
+
+	// MultiClusterEnabled enables multi-cluster shared issuer mode
+	MultiClusterEnabled bool
+
+	// ClusterID is the unique identifier for this cluster within the group
+	ClusterID string
+
+	// KeyLayout selects where the discovery document is written (default: well-known only)
+	KeyLayout iface.KeyLayout
+
+	// MinifyJWKS publishes the JWKS as compact JSON instead of indented JSON
+	MinifyJWKS bool
+
+	// MinifyDiscovery publishes the discovery document as compact JSON instead of indented JSON
+	MinifyDiscovery bool
+
+	// JWKSHistory is the number of past JWKS versions kept below history/ (0 disables)
+	JWKSHistory int
+}
+
+// Validate validates the in-memory configuration.
+func (c Config) Validate() error {
+	if c.PublicURL == "" {
+		return fmt.Errorf("public URL is required")
+	}
+	if c.Prefix != "" && !strings.HasSuffix(strings.TrimSuffix(c.PublicURL, "/"), "/"+c.Prefix) {
+		return fmt.Errorf("public URL %s must end with the prefix %s", c.PublicURL, c.Prefix)
+	}
+	return nil
+}
+
+// BucketLayout returns how the bucket is served: objects live below PublicURL
+// without the prefix.
+func (c Config) BucketLayout() iface.BucketLayout {
+	base := strings.TrimSuffix(c.PublicURL, "/")
+	if c.Prefix != "" {
+		base = strings.TrimSuffix(base, "/"+c.Prefix)
+	}
+	return iface.BucketLayout{BaseURL: base}
+}
+
+// GetPublicURL returns the issuer URL.
+func (c Config) GetPublicURL() string {
+	return c.PublicURL
+}
+
+// GetDiscoveryObjectKeys returns every object key the discovery document is
+// written to, derived from the issuer URL so the well-known document is served
+// exactly below it.
+func (c Config) GetDiscoveryObjectKeys() ([]string, error) {
+	return iface.DiscoveryObjectKeys(c.GetPublicURL(), c.BucketLayout(), c.KeyLayout)
+}
+
+// GetJWKSPath returns the path for the JWKS
+// In multi-cluster mode, writes to the cluster-specific sub-path.
+func (c Config) GetJWKSPath() string {
+	if c.MultiClusterEnabled {
+		return c.GetClusterJWKSPath(c.ClusterID)
+	}
+	return c.GetRootJWKSPath()
+}
+
+// rootJWKSPath is the JWKS path relative to an issuer prefix.
+const rootJWKSPath = "openid/v1/jwks"
+
+// GetRootJWKSPath returns the root JWKS path (for aggregated writes in multi-cluster mode).
+func (c Config) GetRootJWKSPath() string {
+	return path.Join(c.Prefix, rootJWKSPath)
+}
+
+// GetGroupJWKSPath returns the root JWKS path of a group stored next to this one.
+func (c Config) GetGroupJWKSPath(group string) string {
+	return path.Join(c.topLevel().Prefix, group, rootJWKSPath)
+}
+
+// GetHistoryPrefix returns the directory JWKS snapshots are written below. In
+// multi-cluster mode each cluster keeps its own history, outside clusters/ so
+// pruned clusters are not listed again.
+func (c Config) GetHistoryPrefix() string {
+	if c.MultiClusterEnabled {
+		return c.GetClusterHistoryPrefix(c.ClusterID)
+	}
+	return path.Join(c.Prefix, iface.HistoryDir)
+}
+
+// GetClusterHistoryPrefix returns the directory the given cluster's JWKS snapshots are written below.
+func (c Config) GetClusterHistoryPrefix(clusterID string) string {
+	return path.Join(c.Prefix, iface.HistoryDir, clusterID)
+}
+
+// GetClusterJWKSPath returns the cluster-specific JWKS path for the given clusterID.
+func (c Config) GetClusterJWKSPath(clusterID string) string {
+	return path.Join(c.Prefix, "clusters", clusterID, "openid", "v1", "jwks")
+}
+
+// getClustersPrefix returns the prefix under which cluster sub-paths live.
+func (c Config) getClustersPrefix() string {
+	return path.Join(c.Prefix, "clusters") + "/"
+}
+
+// topLevel returns the config for the location above the group prefix, where the
+// group-of-groups metadata is stored.
+func (c Config) topLevel() Config {
+	top := c
+	top.Prefix = path.Dir(c.Prefix)
+	if top.Prefix == "." || top.Prefix == "/" {
+		top.Prefix = ""
+	}
+	top.PublicURL = c.BucketLayout().BaseURL
+	if top.Prefix != "" {
+		top.PublicURL += "/" + top.Prefix
+	}
+	return top
+}
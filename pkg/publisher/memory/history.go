@@ -0,0 +1,35 @@
+package memory
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
+)
+
+// historyStore keeps JWKS snapshots in the publisher's bucket.
+type historyStore struct {
+	p *Publisher
+}
+
+var _ iface.HistoryStore = historyStore{}
+
+func (s historyStore) PutObject(_ context.Context, key string, data []byte) error {
+	s.p.bucket.Put(key, data, s.p.nowFunc())
+	return nil
+}
+
+func (s historyStore) ListObjects(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for _, key := range s.p.bucket.Keys() {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (s historyStore) DeleteObject(_ context.Context, key string) error {
+	s.p.bucket.Delete(key)
+	return nil
+}
@@ -0,0 +1,342 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
+)
+
+// Ensure Publisher implements the publisher interfaces.
+var (
+	_ iface.Publisher              = (*Publisher)(nil)
+	_ iface.MultiClusterAggregator = (*Publisher)(nil)
+	_ iface.GroupAggregator        = (*Publisher)(nil)
+	_ iface.ClusterDeleter         = (*Publisher)(nil)
+	_ iface.ManagedObjectStore     = (*Publisher)(nil)
+)
+
+// object is a stored document and the time it was last written.
+type object struct {
+	data         []byte
+	lastModified time.Time
+}
+
+// Publisher implements iface.Publisher by keeping objects in memory.
+// Several Publishers can share one Bucket to simulate clusters writing to the same storage.
+type Publisher struct {
+	config  Config
+	bucket  *Bucket
+	nowFunc func() time.Time
+	// history keeps past JWKS versions (nil when disabled)
+	history *iface.JWKSHistory
+}
+
+// Bucket is an in-memory object store shared by one or more Publishers.
+type Bucket struct {
+	mu      sync.RWMutex
+	objects map[string]object
+}
+
+// NewBucket creates an empty Bucket.
+func NewBucket() *Bucket {
+	return &Bucket{objects: make(map[string]object)}
+}
+
+// New creates an in-memory Publisher writing to bucket.
+func New(cfg Config, bucket *Bucket) (*Publisher, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid memory config: %w", err)
+	}
+	if bucket == nil {
+		bucket = NewBucket()
+	}
+	p := &Publisher{
+		config:  cfg,
+		bucket:  bucket,
+		nowFunc: time.Now,
+	}
+	p.history = iface.NewJWKSHistory(historyStore{p: p}, cfg.GetHistoryPrefix(), cfg.JWKSHistory)
+	return p, nil
+}
+
+// Bucket returns the underlying object store.
+func (p *Publisher) Bucket() *Bucket {
+	return p.bucket
+}
+
+// SetTimeFunc sets the time function used for last-modified times (for testing).
+func (p *Publisher) SetTimeFunc(f func() time.Time) {
+	p.nowFunc = f
+}
+
+// Publish stores the discovery document and JWKS.
+// In multi-cluster mode only the cluster JWKS is written; the root discovery document
+// belongs to the aggregation leader.
+func (p *Publisher) Publish(ctx context.Context, discovery *bridge.DiscoveryDocument, jwks *bridge.JWKS) error {
+	if !p.config.MultiClusterEnabled {
+		if err := p.putDiscovery(discovery); err != nil {
+			return err
+		}
+	}
+	if err := p.put(p.config.GetJWKSPath(), jwks, p.config.MinifyJWKS); err != nil {
+		return fmt.Errorf("failed to store JWKS: %w", err)
+	}
+	if err := p.history.Record(ctx, jwks, p.nowFunc()); err != nil {
+		return fmt.Errorf("failed to record JWKS history: %w", err)
+	}
+	return nil
+}
+
+// Validate always succeeds for the in-memory backend.
+func (p *Publisher) Validate(_ context.Context) error {
+	return nil
+}
+
+// GetPublicURL returns the configured public URL.
+func (p *Publisher) GetPublicURL() string {
+	return p.config.PublicURL
+}
+
+// HealthCheck always succeeds for the in-memory backend.
+func (p *Publisher) HealthCheck(_ context.Context) error {
+	return nil
+}
+
+// Type returns the publisher type.
+func (p *Publisher) Type() iface.PublisherType {
+	return iface.PublisherTypeMemory
+}
+
+// Close is a no-op.
+func (p *Publisher) Close() error {
+	return nil
+}
+
+// ListClusterJWKS returns the JWKS stored under each cluster sub-path.
+func (p *Publisher) ListClusterJWKS(_ context.Context) (map[string]*bridge.JWKS, error) {
+	clusterJWKS := make(map[string]*bridge.JWKS)
+	for _, clusterID := range p.clusterIDs() {
+		data, ok := p.bucket.Get(p.config.GetClusterJWKSPath(clusterID))
+		if !ok {
+			continue
+		}
+		var jwks bridge.JWKS
+		if err := json.Unmarshal(data, &jwks); err != nil {
+			return nil, fmt.Errorf("failed to decode JWKS for cluster %s: %w", clusterID, err)
+		}
+		clusterJWKS[clusterID] = &jwks
+	}
+	return clusterJWKS, nil
+}
+
+// GetClusterLastModified returns the last-modified time of each cluster's JWKS.
+func (p *Publisher) GetClusterLastModified(_ context.Context) (map[string]time.Time, error) {
+	lastModified := make(map[string]time.Time)
+	for _, clusterID := range p.clusterIDs() {
+		if t, ok := p.bucket.LastModified(p.config.GetClusterJWKSPath(clusterID)); ok {
+			lastModified[clusterID] = t
+		}
+	}
+	return lastModified, nil
+}
+
+// DeleteClusterJWKS removes the JWKS and JWKS history stored under the cluster's sub-paths.
+func (p *Publisher) DeleteClusterJWKS(ctx context.Context, clusterID string) error {
+	if err := iface.DeleteHistory(ctx, historyStore{p: p}, p.config.GetClusterHistoryPrefix(clusterID)); err != nil {
+		return err
+	}
+	p.bucket.Delete(p.config.GetClusterJWKSPath(clusterID))
+	return nil
+}
+
+// ListManagedObjects returns the keys of the discovery documents, JWKS and JWKS
+// history still stored for this publisher's issuer.
+func (p *Publisher) ListManagedObjects(ctx context.Context) ([]string, error) {
+	var documents []string
+	if !p.config.MultiClusterEnabled {
+		keys, err := p.config.GetDiscoveryObjectKeys()
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive discovery object keys: %w", err)
+		}
+		documents = keys
+	}
+	documents = append(documents, p.config.GetJWKSPath())
+	return iface.ListExistingObjects(ctx, historyStore{p: p}, documents, p.config.GetHistoryPrefix())
+}
+
+// DeleteManagedObject removes key.
+func (p *Publisher) DeleteManagedObject(_ context.Context, key string) error {
+	p.bucket.Delete(key)
+	return nil
+}
+
+// PublishAggregatedJWKS stores the merged JWKS at the root JWKS path.
+func (p *Publisher) PublishAggregatedJWKS(_ context.Context, merged *bridge.JWKS) error {
+	return p.put(p.config.GetRootJWKSPath(), merged, p.config.MinifyJWKS)
+}
+
+// PublishRootDiscovery stores the group's discovery document at the root discovery path.
+func (p *Publisher) PublishRootDiscovery(_ context.Context, discovery *bridge.DiscoveryDocument) error {
+	return p.putDiscovery(discovery)
+}
+
+// ListGroupJWKS returns the root JWKS of each group stored next to this publisher's group.
+func (p *Publisher) ListGroupJWKS(_ context.Context) (map[string]*bridge.JWKS, error) {
+	groupJWKS := make(map[string]*bridge.JWKS)
+	for _, group := range p.groups() {
+		data, ok := p.bucket.Get(p.config.GetGroupJWKSPath(group))
+		if !ok {
+			continue
+		}
+		var jwks bridge.JWKS
+		if err := json.Unmarshal(data, &jwks); err != nil {
+			return nil, fmt.Errorf("failed to decode JWKS for group %s: %w", group, err)
+		}
+		groupJWKS[group] = &jwks
+	}
+	return groupJWKS, nil
+}
+
+// GetGroupLastModified returns the last-modified time of each group's root JWKS.
+func (p *Publisher) GetGroupLastModified(_ context.Context) (map[string]time.Time, error) {
+	lastModified := make(map[string]time.Time)
+	for _, group := range p.groups() {
+		if t, ok := p.bucket.LastModified(p.config.GetGroupJWKSPath(group)); ok {
+			lastModified[group] = t
+		}
+	}
+	return lastModified, nil
+}
+
+// PublishTopLevelJWKS stores the merged JWKS of all groups at the top-level JWKS path.
+func (p *Publisher) PublishTopLevelJWKS(_ context.Context, merged *bridge.JWKS) error {
+	return p.put(p.config.topLevel().GetRootJWKSPath(), merged, p.config.MinifyJWKS)
+}
+
+// PublishTopLevelDiscovery stores the top-level discovery document.
+func (p *Publisher) PublishTopLevelDiscovery(_ context.Context, discovery *bridge.DiscoveryDocument) error {
+	return p.putDiscoveryAt(p.config.topLevel(), discovery)
+}
+
+// putDiscovery stores the discovery document at every path of the configured key layout.
+func (p *Publisher) putDiscovery(discovery *bridge.DiscoveryDocument) error {
+	return p.putDiscoveryAt(p.config, discovery)
+}
+
+// putDiscoveryAt stores the discovery document at every discovery object key of cfg.
+func (p *Publisher) putDiscoveryAt(cfg Config, discovery *bridge.DiscoveryDocument) error {
+	keys, err := cfg.GetDiscoveryObjectKeys()
+	if err != nil {
+		return fmt.Errorf("failed to derive discovery object keys: %w", err)
+	}
+	for _, key := range keys {
+		if err := p.put(key, discovery, p.config.MinifyDiscovery); err != nil {
+			return fmt.Errorf("failed to store discovery document: %w", err)
+		}
+	}
+	return nil
+}
+
+// put marshals v (compact when minify is set) and stores it under key.
+func (p *Publisher) put(key string, v interface{}, minify bool) error {
+	data, err := iface.MarshalDocument(v, minify)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", key, err)
+	}
+	p.bucket.Put(key, data, p.nowFunc())
+	return nil
+}
+
+// clusterIDs returns the sorted IDs of clusters that have objects under the clusters prefix.
+func (p *Publisher) clusterIDs() []string {
+	clustersPrefix := p.config.getClustersPrefix()
+	seen := make(map[string]struct{})
+	for _, key := range p.bucket.Keys() {
+		rest, ok := strings.CutPrefix(key, clustersPrefix)
+		if !ok {
+			continue
+		}
+		clusterID, _, _ := strings.Cut(rest, "/")
+		if clusterID != "" {
+			seen[clusterID] = struct{}{}
+		}
+	}
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// groups returns the sorted names of groups that have a root JWKS under the top level.
+func (p *Publisher) groups() []string {
+	topPrefix := p.config.topLevel().Prefix
+	if topPrefix != "" {
+		topPrefix += "/"
+	}
+	var groups []string
+	for _, key := range p.bucket.Keys() {
+		rest, ok := strings.CutPrefix(key, topPrefix)
+		if !ok {
+			continue
+		}
+		group, jwksPath, _ := strings.Cut(rest, "/")
+		if group != "" && jwksPath == rootJWKSPath {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// Put stores data under key with the given last-modified time.
+func (b *Bucket) Put(key string, data []byte, lastModified time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.objects[key] = object{data: append([]byte(nil), data...), lastModified: lastModified}
+}
+
+// Get returns the data stored under key.
+func (b *Bucket) Get(key string) ([]byte, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	obj, ok := b.objects[key]
+	if !ok {
+		return nil, false
+	}
+	return append([]byte(nil), obj.data...), true
+}
+
+// LastModified returns the last-modified time of the object stored under key.
+func (b *Bucket) LastModified(key string) (time.Time, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	obj, ok := b.objects[key]
+	return obj.lastModified, ok
+}
+
+// Delete removes the object stored under key.
+func (b *Bucket) Delete(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.objects, key)
+}
+
+// Keys returns the sorted keys of all stored objects.
+func (b *Bucket) Keys() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	keys := make([]string, 0, len(b.objects))
+	for key := range b.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
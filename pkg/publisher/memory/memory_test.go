@@ -0,0 +1,305 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
+)
+
+func testDocs() (*bridge.DiscoveryDocument, *bridge.JWKS) {
+	discovery := &bridge.DiscoveryDocument{Issuer: "https://oidc.example.com/prod", JWKSURI: "https://oidc.example.com/prod/openid/v1/jwks"}
+	jwks := &bridge.JWKS{Keys: []bridge.JWK{{Kid: "key-1", Kty: "RSA"}}}
+	return discovery, jwks
+}
+
+func TestNew_RequiresPublicURL(t *testing.T) {
+	_, err := New(Config{}, nil)
+	assert.Error(t, err)
+
+	// The issuer must be served below the prefix the objects are stored at
+	_, err = New(Config{PublicURL: "https://oidc.example.com", Prefix: "oidc"}, nil)
+	assert.ErrorContains(t, err, "must end with the prefix")
+}
+
+func TestClose_IsNoOp(t *testing.T) {
+	pub, err := New(Config{PublicURL: "https://oidc.example.com/prod"}, nil)
+	require.NoError(t, err)
+	assert.NoError(t, pub.Close())
+}
+
+func TestPublish_SingleCluster(t *testing.T) {
+	pub, err := New(Config{PublicURL: "https://oidc.example.com/oidc", Prefix: "oidc"}, nil)
+	require.NoError(t, err)
+
+	discovery, jwks := testDocs()
+	require.NoError(t, pub.Publish(context.Background(), discovery, jwks))
+
+	assert.Equal(t, []string{"oidc/.well-known/openid-configuration", "oidc/openid/v1/jwks"}, pub.Bucket().Keys())
+}
+
+func TestPublish_MultiClusterSkipsRootDiscovery(t *testing.T) {
+	pub, err := New(Config{PublicURL: "https://oidc.example.com/prod", Prefix: "prod", MultiClusterEnabled: true, ClusterID: "cluster-a"}, nil)
+	require.NoError(t, err)
+
+	discovery, jwks := testDocs()
+	require.NoError(t, pub.Publish(context.Background(), discovery, jwks))
+
+	assert.Equal(t, []string{"prod/clusters/cluster-a/openid/v1/jwks"}, pub.Bucket().Keys())
+}
+
+func TestAggregator_RoundTrip(t *testing.T) {
+	bucket := NewBucket()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	for i, clusterID := range []string{"cluster-a", "cluster-b"} {
+		pub, err := New(Config{PublicURL: "https://oidc.example.com/prod", Prefix: "prod", MultiClusterEnabled: true, ClusterID: clusterID}, bucket)
+		require.NoError(t, err)
+		modified := now.Add(time.Duration(i) * time.Hour)
+		pub.SetTimeFunc(func() time.Time { return modified })
+		require.NoError(t, pub.Publish(ctx, nil, &bridge.JWKS{Keys: []bridge.JWK{{Kid: clusterID + "-key"}}}))
+	}
+
+	leader, err := New(Config{PublicURL: "https://oidc.example.com/prod", Prefix: "prod", MultiClusterEnabled: true, ClusterID: "cluster-a"}, bucket)
+	require.NoError(t, err)
+
+	clusterJWKS, err := leader.ListClusterJWKS(ctx)
+	require.NoError(t, err)
+	require.Len(t, clusterJWKS, 2)
+	assert.Equal(t, "cluster-b-key", clusterJWKS["cluster-b"].Keys[0].Kid)
+
+	lastModified, err := leader.GetClusterLastModified(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, now, lastModified["cluster-a"])
+	assert.Equal(t, now.Add(time.Hour), lastModified["cluster-b"])
+
+	require.NoError(t, leader.PublishAggregatedJWKS(ctx, &bridge.JWKS{}))
+	_, ok := bucket.Get("prod/openid/v1/jwks")
+	assert.True(t, ok)
+}
+
+func TestDeleteClusterJWKS(t *testing.T) {
+	bucket := NewBucket()
+	ctx := context.Background()
+	for _, clusterID := range []string{"cluster-a", "cluster-b"} {
+		pub, err := New(Config{PublicURL: "https://oidc.example.com/prod", Prefix: "prod", MultiClusterEnabled: true, ClusterID: clusterID, JWKSHistory: 1}, bucket)
+		require.NoError(t, err)
+		_, jwks := testDocs()
+		require.NoError(t, pub.Publish(ctx, nil, jwks))
+	}
+
+	leader, err := New(Config{PublicURL: "https://oidc.example.com/prod", Prefix: "prod", MultiClusterEnabled: true, ClusterID: "cluster-a"}, bucket)
+	require.NoError(t, err)
+	require.NoError(t, leader.DeleteClusterJWKS(ctx, "cluster-b"))
+	// Deleting a missing cluster is not an error
+	require.NoError(t, leader.DeleteClusterJWKS(ctx, "cluster-c"))
+
+	// cluster-b's history goes with its JWKS; cluster-a's is kept
+	keys := bucket.Keys()
+	require.Len(t, keys, 2)
+	assert.Equal(t, "prod/clusters/cluster-a/openid/v1/jwks", keys[0])
+	assert.True(t, strings.HasPrefix(keys[1], "prod/history/cluster-a/"), keys[1])
+}
+
+func TestListManagedObjects_MultiClusterListsOwnObjects(t *testing.T) {
+	bucket := NewBucket()
+	ctx := context.Background()
+	discovery, jwks := testDocs()
+	var pubs []*Publisher
+	for _, clusterID := range []string{"cluster-a", "cluster-b"} {
+		pub, err := New(Config{PublicURL: "https://oidc.example.com/prod", Prefix: "prod", MultiClusterEnabled: true, ClusterID: clusterID, JWKSHistory: 1}, bucket)
+		require.NoError(t, err)
+		require.NoError(t, pub.Publish(ctx, nil, jwks))
+		pubs = append(pubs, pub)
+	}
+	require.NoError(t, pubs[0].PublishRootDiscovery(ctx, discovery))
+
+	// The group's discovery document and the other cluster's objects are not listed
+	keys, err := pubs[0].ListManagedObjects(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, "prod/clusters/cluster-a/openid/v1/jwks", keys[0])
+	assert.True(t, strings.HasPrefix(keys[1], "prod/history/cluster-a/"), keys[1])
+}
+
+func TestGroupAggregator_RoundTrip(t *testing.T) {
+	bucket := NewBucket()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	for _, group := range []string{"prod", "staging"} {
+		pub, err := New(Config{PublicURL: "https://oidc.example.com/" + group, Prefix: group, MultiClusterEnabled: true, ClusterID: group + "-a"}, bucket)
+		require.NoError(t, err)
+		pub.SetTimeFunc(func() time.Time { return now })
+		require.NoError(t, pub.Publish(ctx, nil, &bridge.JWKS{Keys: []bridge.JWK{{Kid: group + "-cluster-key"}}}))
+		require.NoError(t, pub.PublishAggregatedJWKS(ctx, &bridge.JWKS{Keys: []bridge.JWK{{Kid: group + "-key"}}}))
+	}
+
+	leader, err := New(Config{PublicURL: "https://oidc.example.com/prod", Prefix: "prod", MultiClusterEnabled: true, ClusterID: "prod-a"}, bucket)
+	require.NoError(t, err)
+
+	// Only group root JWKS count; cluster sub-paths are not groups
+	groupJWKS, err := leader.ListGroupJWKS(ctx)
+	require.NoError(t, err)
+	require.Len(t, groupJWKS, 2)
+	assert.Equal(t, "staging-key", groupJWKS["staging"].Keys[0].Kid)
+
+	lastModified, err := leader.GetGroupLastModified(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Time{"prod": now, "staging": now}, lastModified)
+
+	discovery, _ := testDocs()
+	require.NoError(t, leader.PublishTopLevelJWKS(ctx, &bridge.JWKS{}))
+	require.NoError(t, leader.PublishTopLevelDiscovery(ctx, discovery))
+	for _, key := range []string{"openid/v1/jwks", ".well-known/openid-configuration"} {
+		_, ok := bucket.Get(key)
+		assert.True(t, ok, key)
+	}
+
+	// The top-level JWKS is not mistaken for a group
+	groupJWKS, err = leader.ListGroupJWKS(ctx)
+	require.NoError(t, err)
+	assert.Len(t, groupJWKS, 2)
+}
+
+func TestPublish_KeyLayout(t *testing.T) {
+	tests := []struct {
+		name      string
+		keyLayout iface.KeyLayout
+		wantKeys  []string
+	}{
+		{
+			name:      "well-known layout",
+			keyLayout: iface.KeyLayoutWellKnown,
+			wantKeys:  []string{"oidc/.well-known/openid-configuration", "oidc/openid/v1/jwks"},
+		},
+		{
+			name:      "flat layout",
+			keyLayout: iface.KeyLayoutFlat,
+			wantKeys:  []string{"oidc/.well-known/openid-configuration", "oidc/openid-configuration", "oidc/openid/v1/jwks"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pub, err := New(Config{PublicURL: "https://oidc.example.com/oidc", Prefix: "oidc", KeyLayout: tt.keyLayout}, nil)
+			require.NoError(t, err)
+
+			discovery, jwks := testDocs()
+			require.NoError(t, pub.Publish(context.Background(), discovery, jwks))
+			assert.Equal(t, tt.wantKeys, pub.Bucket().Keys())
+
+			// Every discovery copy carries the same issuer and JWKS URI.
+			canonical, ok := pub.Bucket().Get("oidc/.well-known/openid-configuration")
+			require.True(t, ok)
+			keys, err := pub.config.GetDiscoveryObjectKeys()
+			require.NoError(t, err)
+			for _, key := range keys {
+				data, ok := pub.Bucket().Get(key)
+				require.True(t, ok)
+				assert.Equal(t, canonical, data)
+			}
+		})
+	}
+}
+
+func TestPublishRootDiscovery_FlatLayout(t *testing.T) {
+	pub, err := New(Config{
+		PublicURL:           "https://oidc.example.com/prod",
+		Prefix:              "prod",
+		MultiClusterEnabled: true,
+		ClusterID:           "cluster-a",
+		KeyLayout:           iface.KeyLayoutFlat,
+	}, nil)
+	require.NoError(t, err)
+
+	discovery, _ := testDocs()
+	require.NoError(t, pub.PublishRootDiscovery(context.Background(), discovery))
+	assert.Equal(t, []string{"prod/.well-known/openid-configuration", "prod/openid-configuration"}, pub.Bucket().Keys())
+}
+
+func TestPublish_Minify(t *testing.T) {
+	pub, err := New(Config{PublicURL: "https://oidc.example.com/oidc", Prefix: "oidc", MinifyJWKS: true}, nil)
+	require.NoError(t, err)
+
+	discovery, jwks := testDocs()
+	require.NoError(t, pub.Publish(context.Background(), discovery, jwks))
+
+	jwksData, ok := pub.Bucket().Get("oidc/openid/v1/jwks")
+	require.True(t, ok)
+	assert.NotContains(t, string(jwksData), "\n")
+
+	discoveryData, ok := pub.Bucket().Get("oidc/.well-known/openid-configuration")
+	require.True(t, ok)
+	assert.Contains(t, string(discoveryData), "\n  ", "discovery stays indented unless MinifyDiscovery is set")
+}
+
+func TestPublish_JWKSHistory(t *testing.T) {
+	pub, err := New(Config{PublicURL: "https://oidc.example.com/oidc", Prefix: "oidc", JWKSHistory: 2}, nil)
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+	pub.SetTimeFunc(func() time.Time { return now })
+	ctx := context.Background()
+
+	discovery, _ := testDocs()
+	for _, kid := range []string{"key-1", "key-2", "key-3"} {
+		now = now.Add(time.Minute)
+		require.NoError(t, pub.Publish(ctx, discovery, &bridge.JWKS{Keys: []bridge.JWK{{Kid: kid, Kty: "RSA"}}}))
+	}
+
+	// The canonical JWKS is still updated on every publish
+	canonical, ok := pub.Bucket().Get("oidc/openid/v1/jwks")
+	require.True(t, ok)
+	assert.Contains(t, string(canonical), "key-3")
+
+	// Only the newest snapshots are kept
+	assert.Equal(t, []string{
+		"oidc/.well-known/openid-configuration",
+		"oidc/history/1700000120/jwks.json",
+		"oidc/history/1700000180/jwks.json",
+		"oidc/openid/v1/jwks",
+	}, pub.Bucket().Keys())
+}
+
+func TestPublish_JWKSHistoryMultiCluster(t *testing.T) {
+	bucket := NewBucket()
+	pub, err := New(Config{PublicURL: "https://oidc.example.com/prod", Prefix: "prod", MultiClusterEnabled: true, ClusterID: "cluster-a", JWKSHistory: 1}, bucket)
+	require.NoError(t, err)
+	pub.SetTimeFunc(func() time.Time { return time.Unix(1700000000, 0) })
+
+	_, jwks := testDocs()
+	require.NoError(t, pub.Publish(context.Background(), nil, jwks))
+
+	// Cluster history lives outside clusters/, so it does not look like a cluster
+	assert.Equal(t, []string{"prod/clusters/cluster-a/openid/v1/jwks", "prod/history/cluster-a/1700000000/jwks.json"}, bucket.Keys())
+	assert.Equal(t, []string{"cluster-a"}, pub.clusterIDs())
+}
+
+func TestPublish_Concurrent(t *testing.T) {
+	pub, err := New(Config{PublicURL: "https://oidc.example.com/prod", Prefix: "prod", JWKSHistory: 3}, nil)
+	require.NoError(t, err)
+
+	// Run with -race to check concurrent publishes on one publisher do not race
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			discovery, _ := testDocs()
+			jwks := &bridge.JWKS{Keys: []bridge.JWK{{Kid: fmt.Sprintf("key-%d", i), Kty: "RSA"}}}
+			assert.NoError(t, pub.Publish(context.Background(), discovery, jwks))
+		}()
+	}
+	wg.Wait()
+
+	assert.Contains(t, pub.Bucket().Keys(), "prod/openid/v1/jwks")
+}
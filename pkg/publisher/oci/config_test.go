@@ -1,10 +1,13 @@
 package oci
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
 )
 
 func TestConfig_Validate(t *testing.T) {
@@ -122,7 +125,7 @@ func TestConfig_GetPublicURL(t *testing.T) {
 	}
 }
 
-func TestConfig_GetDiscoveryPath(t *testing.T) {
+func TestConfig_GetJWKSPath(t *testing.T) {
 	tests := []struct {
 		name     string
 		prefix   string
@@ -131,57 +134,68 @@ func TestConfig_GetDiscoveryPath(t *testing.T) {
 		{
 			name:     "with prefix",
 			prefix:   "oidc",
-			expected: "oidc/.well-known/openid-configuration",
+			expected: "oidc/openid/v1/jwks",
 		},
 		{
 			name:     "without prefix",
 			prefix:   "",
-			expected: ".well-known/openid-configuration",
+			expected: "openid/v1/jwks",
 		},
 		{
 			name:     "with nested prefix",
 			prefix:   "v1/oidc",
-			expected: "v1/oidc/.well-known/openid-configuration",
+			expected: "v1/oidc/openid/v1/jwks",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			config := Config{Prefix: tt.prefix}
-			result := config.GetDiscoveryPath()
+			result := config.GetJWKSPath()
 			assert.Equal(t, tt.expected, result)
 		})
 	}
 }
 
-func TestConfig_GetJWKSPath(t *testing.T) {
+func TestConfig_GetDiscoveryObjectKeys(t *testing.T) {
 	tests := []struct {
-		name     string
-		prefix   string
-		expected string
+		name      string
+		prefix    string
+		keyLayout iface.KeyLayout
+		expected  []string
 	}{
+		{
+			name:     "without prefix",
+			expected: []string{".well-known/openid-configuration"},
+		},
 		{
 			name:     "with prefix",
 			prefix:   "oidc",
-			expected: "oidc/openid/v1/jwks",
+			expected: []string{"oidc/.well-known/openid-configuration"},
 		},
 		{
-			name:     "without prefix",
-			prefix:   "",
-			expected: "openid/v1/jwks",
+			name:     "with nested prefix",
+			prefix:   "prod/group-a",
+			expected: []string{"prod/group-a/.well-known/openid-configuration"},
 		},
 		{
-			name:     "with nested prefix",
-			prefix:   "v1/oidc",
-			expected: "v1/oidc/openid/v1/jwks",
+			name:      "flat layout adds dot-free copy",
+			prefix:    "oidc",
+			keyLayout: iface.KeyLayoutFlat,
+			expected:  []string{"oidc/.well-known/openid-configuration", "oidc/openid-configuration"},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			config := Config{Prefix: tt.prefix}
-			result := config.GetJWKSPath()
-			assert.Equal(t, tt.expected, result)
+			config := Config{Region: "us-ashburn-1", Namespace: "tenancy", Bucket: "oidc-bucket", Prefix: tt.prefix, KeyLayout: tt.keyLayout}
+			keys, err := config.GetDiscoveryObjectKeys()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, keys)
+
+			// The well-known document is served exactly below the issuer
+			served := strings.TrimSuffix(config.BucketLayout().BaseURL, "/") + "/" + keys[0]
+			assert.Equal(t, strings.TrimSuffix(config.GetPublicURL(), "/")+"/.well-known/openid-configuration", served)
 		})
 	}
 }
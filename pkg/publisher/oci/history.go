@@ -0,0 +1,78 @@
+package oci
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/oracle/oci-go-sdk/v65/common"
+	"github.com/oracle/oci-go-sdk/v65/objectstorage"
+
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
+)
+
+// historyStore keeps JWKS snapshots in the bucket.
+type historyStore struct {
+	o *ociPublisher
+}
+
+var _ iface.HistoryStore = historyStore{}
+
+func (s historyStore) PutObject(ctx context.Context, key string, data []byte) error {
+	contentType := s.o.config.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	_, err := s.o.client.PutObject(ctx, objectstorage.PutObjectRequest{
+		NamespaceName: common.String(s.o.config.Namespace),
+		BucketName:    common.String(s.o.config.Bucket),
+		ObjectName:    common.String(key),
+		PutObjectBody: io.NopCloser(bytes.NewReader(data)),
+		ContentLength: common.Int64(int64(len(data))),
+		ContentType:   common.String(contentType),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload object %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s historyStore) ListObjects(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	req := objectstorage.ListObjectsRequest{
+		NamespaceName: common.String(s.o.config.Namespace),
+		BucketName:    common.String(s.o.config.Bucket),
+		Prefix:        common.String(prefix),
+	}
+	for {
+		resp, err := s.o.client.ListObjects(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", prefix, err)
+		}
+		for _, obj := range resp.Objects {
+			if obj.Name != nil {
+				keys = append(keys, *obj.Name)
+			}
+		}
+		if resp.NextStartWith == nil {
+			return keys, nil
+		}
+		req.Start = resp.NextStartWith
+	}
+}
+
+func (s historyStore) DeleteObject(ctx context.Context, key string) error {
+	_, err := s.o.client.DeleteObject(ctx, objectstorage.DeleteObjectRequest{
+		NamespaceName: common.String(s.o.config.Namespace),
+		BucketName:    common.String(s.o.config.Bucket),
+		ObjectName:    common.String(key),
+	})
+	if err != nil {
+		if serviceErr, ok := common.IsServiceError(err); ok && serviceErr.GetHTTPStatusCode() == 404 {
+			return nil
+		}
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
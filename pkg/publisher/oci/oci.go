@@ -26,6 +26,8 @@ type ociPublisher struct {
 	client objectstorage.ObjectStorageClient
 	config Config
 	logger *slog.Logger
+	// history keeps past JWKS versions (nil when disabled)
+	history *iface.JWKSHistory
 }
 
 // New creates a new OCI Object Storage publisher.
@@ -53,33 +55,37 @@ func New(ctx context.Context, config Config, logger *slog.Logger) (iface.Publish
 		return nil, fmt.Errorf("failed to create OCI client: %w", err)
 	}
 
-	return &ociPublisher{
+	publisher := &ociPublisher{
 		client: client,
 		config: config,
 		logger: logger,
-	}, nil
+	}
+	publisher.history = iface.NewJWKSHistory(historyStore{o: publisher}, config.GetHistoryPrefix(), config.JWKSHistory)
+	return publisher, nil
 }
 
 // Publish uploads the discovery document and JWKS to OCI Object Storage.
 func (o *ociPublisher) Publish(ctx context.Context, discovery *bridge.DiscoveryDocument, jwks *bridge.JWKS) error {
 	o.logger.Debug("OCI publisher: publishing discovery document and JWKS")
 
-	discoveryPath := o.config.GetDiscoveryPath()
 	jwksPath := o.config.GetJWKSPath()
 
-	// Publish discovery document
-	if err := o.uploadObject(ctx, discoveryPath, discovery); err != nil {
-		return fmt.Errorf("failed to upload discovery document to OCI: %w", err)
+	// Publish discovery document; in multi-cluster mode the aggregation leader owns it
+	if !o.config.MultiClusterEnabled {
+		if err := o.uploadDiscovery(ctx, discovery); err != nil {
+			return err
+		}
 	}
-	o.logger.Debug("OCI publisher: successfully uploaded discovery document",
-		"bucket", o.config.Bucket,
-		"path", discoveryPath,
-	)
 
 	// Publish JWKS
-	if err := o.uploadObject(ctx, jwksPath, jwks); err != nil {
+	if err := o.uploadObject(ctx, jwksPath, jwks, o.config.MinifyJWKS); err != nil {
 		return fmt.Errorf("failed to upload JWKS to OCI: %w", err)
 	}
+
+	// Snapshot the JWKS; history is kept for forensics only, so a failure does not fail the publish
+	if err := o.history.Record(ctx, jwks, time.Now()); err != nil {
+		o.logger.Warn("failed to record JWKS history", "error", err)
+	}
 	o.logger.Debug("OCI publisher: successfully uploaded JWKS",
 		"bucket", o.config.Bucket,
 		"path", jwksPath,
@@ -88,9 +94,27 @@ func (o *ociPublisher) Publish(ctx context.Context, discovery *bridge.DiscoveryD
 	return nil
 }
 
-// uploadObject marshals the object to JSON and uploads it to OCI Object Storage.
-func (o *ociPublisher) uploadObject(ctx context.Context, objectName string, data interface{}) error {
-	jsonData, err := json.MarshalIndent(data, "", "  ")
+// uploadDiscovery uploads the discovery document to every path of the configured key layout.
+func (o *ociPublisher) uploadDiscovery(ctx context.Context, discovery *bridge.DiscoveryDocument) error {
+	keys, err := o.config.GetDiscoveryObjectKeys()
+	if err != nil {
+		return fmt.Errorf("failed to derive discovery object keys: %w", err)
+	}
+	for _, key := range keys {
+		if err := o.uploadObject(ctx, key, discovery, o.config.MinifyDiscovery); err != nil {
+			return fmt.Errorf("failed to upload discovery document to OCI: %w", err)
+		}
+		o.logger.Debug("OCI publisher: successfully uploaded discovery document",
+			"bucket", o.config.Bucket,
+			"path", key,
+		)
+	}
+	return nil
+}
+
+// uploadObject marshals the object to JSON (compact when minify is set) and uploads it to OCI Object Storage.
+func (o *ociPublisher) uploadObject(ctx context.Context, objectName string, data interface{}, minify bool) error {
+	jsonData, err := iface.MarshalDocument(data, minify)
 	if err != nil {
 		return fmt.Errorf("failed to marshal data to JSON: %w", err)
 	}
@@ -105,6 +129,12 @@ func (o *ociPublisher) uploadObject(ctx context.Context, objectName string, data
 		cacheControl = "max-age=300"
 	}
 
+	tags := iface.DocumentTags(o.config.ObjectTags, data, o.config.KeyThumbprintTag)
+	var hash string
+	if o.config.SkipUnchangedUploads {
+		hash = iface.ContentHash(jsonData, contentType, cacheControl, tags)
+	}
+
 	// Get object metadata for optimistic locking
 	getReq := objectstorage.GetObjectRequest{
 		NamespaceName: common.String(o.config.Namespace),
@@ -118,6 +148,10 @@ func (o *ociPublisher) uploadObject(ctx context.Context, objectName string, data
 		// Object doesn't exist, use nil If-Match
 		ifMatchEtag = nil
 	} else {
+		if hash != "" && iface.ContentHashMatches(getResp.OpcMeta, hash) {
+			o.logger.Debug("Object is already up to date, skipping upload", "key", objectName)
+			return nil
+		}
 		ifMatchEtag = getResp.ETag
 	}
 
@@ -128,7 +162,10 @@ func (o *ociPublisher) uploadObject(ctx context.Context, objectName string, data
 		ObjectName:    common.String(objectName),
 		PutObjectBody: io.NopCloser(bytes.NewReader(jsonData)),
 		ContentType:   common.String(contentType),
-		OpcMeta:       map[string]string{"Cache-Control": cacheControl},
+		OpcMeta:       objectMetadata(cacheControl, tags),
+	}
+	if hash != "" {
+		putReq.OpcMeta[iface.MetadataContentHash] = hash
 	}
 
 	if ifMatchEtag != nil {
@@ -149,6 +186,16 @@ func (o *ociPublisher) uploadObject(ctx context.Context, objectName string, data
 	return nil
 }
 
+// objectMetadata builds the opc-meta-* metadata for an upload: the cache
+// control value plus object tags with keys rewritten into valid header names.
+func objectMetadata(cacheControl string, tags map[string]string) map[string]string {
+	metadata := map[string]string{"Cache-Control": cacheControl}
+	for k, v := range tags {
+		metadata[iface.MetadataKey(k)] = v
+	}
+	return metadata
+}
+
 // Validate checks configuration and permissions.
 func (o *ociPublisher) Validate(ctx context.Context) error {
 	o.logger.Debug("OCI publisher: validating configuration and permissions")
@@ -242,8 +289,17 @@ func (o *ociPublisher) Type() iface.PublisherType {
 	return iface.PublisherTypeOCI
 }
 
+// Close is a no-op: the OCI SDK client holds no resources that need releasing.
+func (o *ociPublisher) Close() error {
+	return nil
+}
+
 // Ensure ociPublisher implements iface.MultiClusterAggregator.
-var _ iface.MultiClusterAggregator = (*ociPublisher)(nil)
+var (
+	_ iface.MultiClusterAggregator = (*ociPublisher)(nil)
+	_ iface.ClusterDeleter         = (*ociPublisher)(nil)
+	_ iface.ManagedObjectStore     = (*ociPublisher)(nil)
+)
 
 // clusterListPrefix returns the prefix for listing cluster sub-paths.
 func (o *ociPublisher) clusterListPrefix() string {
@@ -288,7 +344,7 @@ func (o *ociPublisher) ListClusterJWKS(ctx context.Context) (map[string]*bridge.
 			o.logger.Warn("failed to fetch cluster JWKS, skipping", "clusterID", clusterID, "error", err)
 			continue
 		}
-		data, err := io.ReadAll(getResp.Content)
+		data, err := bridge.ReadLimited(getResp.Content, o.config.MaxReadBytes)
 		_ = getResp.Content.Close()
 		if err != nil {
 			o.logger.Warn("failed to read cluster JWKS body, skipping", "clusterID", clusterID, "error", err)
@@ -346,8 +402,53 @@ func (o *ociPublisher) GetClusterLastModified(ctx context.Context) (map[string]t
 	return lastModified, nil
 }
 
+// DeleteClusterJWKS deletes the cluster's JWKS history and JWKS object.
+func (o *ociPublisher) DeleteClusterJWKS(ctx context.Context, clusterID string) error {
+	if err := iface.DeleteHistory(ctx, historyStore{o: o}, o.config.GetClusterHistoryPrefix(clusterID)); err != nil {
+		return err
+	}
+	objectName := o.config.GetClusterJWKSPath(clusterID)
+	_, err := o.client.DeleteObject(ctx, objectstorage.DeleteObjectRequest{
+		NamespaceName: common.String(o.config.Namespace),
+		BucketName:    common.String(o.config.Bucket),
+		ObjectName:    common.String(objectName),
+	})
+	if err != nil {
+		if serviceErr, ok := common.IsServiceError(err); ok && serviceErr.GetHTTPStatusCode() == 404 {
+			return nil
+		}
+		return fmt.Errorf("failed to delete %s: %w", objectName, err)
+	}
+	return nil
+}
+
+// ListManagedObjects returns the names of the discovery documents, JWKS and JWKS
+// history objects still in the bucket.
+func (o *ociPublisher) ListManagedObjects(ctx context.Context) ([]string, error) {
+	var documents []string
+	if !o.config.MultiClusterEnabled {
+		keys, err := o.config.GetDiscoveryObjectKeys()
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive discovery object keys: %w", err)
+		}
+		documents = keys
+	}
+	documents = append(documents, o.config.GetJWKSPath())
+	return iface.ListExistingObjects(ctx, historyStore{o: o}, documents, o.config.GetHistoryPrefix())
+}
+
+// DeleteManagedObject deletes the object key.
+func (o *ociPublisher) DeleteManagedObject(ctx context.Context, key string) error {
+	return historyStore{o: o}.DeleteObject(ctx, key)
+}
+
 // PublishAggregatedJWKS writes the merged JWKS to the root JWKS path using optimistic locking.
 func (o *ociPublisher) PublishAggregatedJWKS(ctx context.Context, merged *bridge.JWKS) error {
 	rootPath := o.config.GetRootJWKSPath()
-	return o.uploadObject(ctx, rootPath, merged)
+	return o.uploadObject(ctx, rootPath, merged, o.config.MinifyJWKS)
+}
+
+// PublishRootDiscovery writes the group's discovery document to the root discovery path.
+func (o *ociPublisher) PublishRootDiscovery(ctx context.Context, discovery *bridge.DiscoveryDocument) error {
+	return o.uploadDiscovery(ctx, discovery)
 }
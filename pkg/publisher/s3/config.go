@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
 )
 
 // Config holds configuration for S3 publisher.
@@ -38,6 +40,50 @@ type Config struct {
 
 	// ClusterID is the unique identifier for this cluster within the group
 	ClusterID string
+
+	// KeyLayout selects where the discovery document is written (default: well-known only)
+	KeyLayout iface.KeyLayout
+
+	// MinifyJWKS publishes the JWKS as compact JSON instead of indented JSON
+	MinifyJWKS bool
+
+	// MinifyDiscovery publishes the discovery document as compact JSON instead of indented JSON
+	MinifyDiscovery bool
+
+	// MaxReadBytes caps the size of a cluster JWKS read during aggregation
+	// (0 uses bridge.DefaultMaxResponseBytes)
+	MaxReadBytes int64
+
+	// ObjectTags are applied to every uploaded object (nil applies none)
+	ObjectTags map[string]string
+
+	// KeyThumbprintTag adds the RFC 7638 thumbprints of the published keys as
+	// a tag on JWKS objects
+	KeyThumbprintTag bool
+
+	// JWKSHistory is the number of past JWKS versions kept below history/ (0 disables)
+	JWKSHistory int
+
+	// SkipUnchangedUploads records a content hash in object metadata and skips
+	// uploads whose hash the stored object already has
+	SkipUnchangedUploads bool
+
+	// Secondary is an optional bucket, usually in another region, that every
+	// object is also written to (nil disables)
+	Secondary *SecondaryConfig
+}
+
+// SecondaryConfig identifies the secondary bucket written alongside the primary.
+// Objects are written under the same keys; the issuer URL stays the primary's.
+type SecondaryConfig struct {
+	// Bucket is the secondary S3 bucket name (required)
+	Bucket string
+
+	// Region is the secondary bucket's AWS region (required)
+	Region string
+
+	// Endpoint is an optional custom S3 endpoint for the secondary bucket
+	Endpoint string
 }
 
 // Validate validates the S3 configuration.
@@ -57,19 +103,47 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid bucket name: %w", err)
 	}
 
+	if c.Secondary != nil {
+		if err := c.validateSecondary(); err != nil {
+			return fmt.Errorf("invalid secondary bucket: %w", err)
+		}
+	}
+
 	return nil
 }
 
-// GetPublicURL constructs the public URL for the bucket, including prefix if set.
-func (c *Config) GetPublicURL() string {
-	var base string
+// validateSecondary checks the secondary bucket is complete and is not the
+// primary bucket. Bucket names are global in AWS, so only a different custom
+// endpoint makes the same name a different bucket.
+func (c *Config) validateSecondary() error {
+	if c.Secondary.Bucket == "" {
+		return fmt.Errorf("bucket name is required")
+	}
+	if c.Secondary.Region == "" {
+		return fmt.Errorf("region is required")
+	}
+	if err := validateBucketName(c.Secondary.Bucket); err != nil {
+		return err
+	}
+	if c.Secondary.Bucket == c.Bucket && c.Secondary.Endpoint == c.Endpoint {
+		return fmt.Errorf("bucket %s must differ from the primary bucket", c.Secondary.Bucket)
+	}
+	return nil
+}
+
+// BucketLayout returns how the bucket is served: objects live below the bucket URL.
+func (c *Config) BucketLayout() iface.BucketLayout {
 	// Handle custom endpoint case
 	if c.Endpoint != "" {
-		base = fmt.Sprintf("%s/%s", c.Endpoint, c.Bucket)
-	} else {
-		// Format: https://BUCKET.s3.REGION.amazonaws.com
-		base = fmt.Sprintf("https://%s.s3.%s.amazonaws.com", c.Bucket, c.Region)
+		return iface.BucketLayout{BaseURL: fmt.Sprintf("%s/%s", c.Endpoint, c.Bucket)}
 	}
+	// Format: https://BUCKET.s3.REGION.amazonaws.com
+	return iface.BucketLayout{BaseURL: fmt.Sprintf("https://%s.s3.%s.amazonaws.com", c.Bucket, c.Region)}
+}
+
+// GetPublicURL constructs the public URL for the bucket, including prefix if set.
+func (c *Config) GetPublicURL() string {
+	base := c.BucketLayout().BaseURL
 	if c.Prefix != "" {
 		return base + "/" + c.Prefix
 	}
@@ -78,7 +152,14 @@ func (c *Config) GetPublicURL() string {
 
 // GetDiscoveryPath returns the path for the discovery document (no prefix — prefix is in GetPublicURL).
 func (c *Config) GetDiscoveryPath() string {
-	return ".well-known/openid-configuration"
+	return iface.WellKnownDiscoveryPath
+}
+
+// GetDiscoveryObjectKeys returns every object key the discovery document is
+// written to, derived from the issuer URL so the well-known document is served
+// exactly below it.
+func (c *Config) GetDiscoveryObjectKeys() ([]string, error) {
+	return iface.DiscoveryObjectKeys(c.GetPublicURL(), c.BucketLayout(), c.KeyLayout)
 }
 
 // GetJWKSPath returns the path for the JWKS
@@ -95,6 +176,21 @@ func (c *Config) GetRootJWKSPath() string {
 	return "openid/v1/jwks"
 }
 
+// GetHistoryPrefix returns the directory JWKS snapshots are written below. In
+// multi-cluster mode each cluster keeps its own history, outside clusters/ so
+// pruned clusters are not listed again.
+func (c *Config) GetHistoryPrefix() string {
+	if c.MultiClusterEnabled {
+		return c.GetClusterHistoryPrefix(c.ClusterID)
+	}
+	return iface.HistoryDir
+}
+
+// GetClusterHistoryPrefix returns the directory the given cluster's JWKS snapshots are written below.
+func (c *Config) GetClusterHistoryPrefix(clusterID string) string {
+	return iface.HistoryDir + "/" + clusterID
+}
+
 // GetClusterJWKSPath returns the cluster-specific JWKS path for the given clusterID.
 func (c *Config) GetClusterJWKSPath(clusterID string) string {
 	return "clusters/" + clusterID + "/openid/v1/jwks"
@@ -0,0 +1,53 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
+)
+
+// historyStore keeps JWKS snapshots in the primary bucket. They are not written
+// to the secondary bucket, which only exists to serve relying parties.
+type historyStore struct {
+	p *Publisher
+}
+
+var _ iface.HistoryStore = historyStore{}
+
+func (s historyStore) PutObject(ctx context.Context, key string, data []byte) error {
+	return s.p.putObject(ctx, s.p.client, s.p.config.Bucket, key, data, s.p.config.ObjectTags)
+}
+
+func (s historyStore) ListObjects(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	paginator := s3.NewListObjectsV2Paginator(s.p.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.p.config.Bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", prefix, err)
+		}
+		for _, obj := range page.Contents {
+			if obj.Key != nil {
+				keys = append(keys, *obj.Key)
+			}
+		}
+	}
+	return keys, nil
+}
+
+func (s historyStore) DeleteObject(ctx context.Context, key string) error {
+	if _, err := s.p.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.p.config.Bucket),
+		Key:    aws.String(key),
+	}); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
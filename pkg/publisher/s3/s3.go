@@ -8,7 +8,10 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"path"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -30,8 +33,18 @@ var _ iface.Publisher = (*Publisher)(nil)
 type Publisher struct {
 	client *s3.Client
 
+	// secondary is the client for the secondary bucket (nil when none is configured)
+	secondary *s3.Client
+
+	// replicated is set when the primary bucket replicates to the secondary
+	// bucket, which makes writing to the secondary unnecessary
+	replicated atomic.Bool
+
 	config Config
 
+	// history keeps past JWKS versions (nil when disabled)
+	history *iface.JWKSHistory
+
 	logger *slog.Logger
 }
 
@@ -52,37 +65,49 @@ func New(ctx context.Context, cfg Config, logger *slog.Logger) (iface.Publisher,
 	// Create S3 client
 	client := createS3Client(awsCfg, cfg.Endpoint, cfg.ForcePathStyle)
 
-	return &Publisher{
+	p := &Publisher{
 		client: client,
 		config: cfg,
 		logger: logger,
-	}, nil
+	}
+
+	if cfg.Secondary != nil {
+		secondaryCfg, err := loadAWSConfig(ctx, cfg.Secondary.Region, cfg.Secondary.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AWS config for secondary bucket: %w", err)
+		}
+		p.secondary = createS3Client(secondaryCfg, cfg.Secondary.Endpoint, cfg.ForcePathStyle)
+	}
+	p.history = iface.NewJWKSHistory(historyStore{p: p}, p.prefixedKey(cfg.GetHistoryPrefix()), cfg.JWKSHistory)
+
+	return p, nil
 }
 
 // Publish uploads the discovery document and JWKS to S3.
 func (p *Publisher) Publish(ctx context.Context, discovery *bridge.DiscoveryDocument, jwks *bridge.JWKS) error {
-	// Marshal discovery document to JSON
-	discoveryData, err := marshalJSON(discovery)
-	if err != nil {
-		return fmt.Errorf("failed to marshal discovery document: %w", err)
-	}
-
-	// Upload discovery document to .well-known/openid-configuration (prefixed)
-	if err := p.uploadObject(ctx, p.prefixedKey(p.config.GetDiscoveryPath()), discoveryData); err != nil {
-		return fmt.Errorf("failed to upload discovery document: %w", err)
+	// In multi-cluster mode the aggregation leader owns the root discovery document
+	if !p.config.MultiClusterEnabled {
+		if err := p.uploadDiscovery(ctx, discovery); err != nil {
+			return err
+		}
 	}
 
 	// Marshal JWKS to JSON
-	jwksData, err := marshalJSON(jwks)
+	jwksData, err := marshalJSON(jwks, p.config.MinifyJWKS)
 	if err != nil {
 		return fmt.Errorf("failed to marshal JWKS: %w", err)
 	}
 
 	// Upload JWKS (in multi-cluster mode, writes to cluster sub-path)
-	if err := p.uploadObject(ctx, p.prefixedKey(p.config.GetJWKSPath()), jwksData); err != nil {
+	if err := p.uploadObject(ctx, p.prefixedKey(p.config.GetJWKSPath()), jwksData, p.jwksTags(jwks)); err != nil {
 		return fmt.Errorf("failed to upload JWKS: %w", err)
 	}
 
+	// Snapshot the JWKS; history is kept for forensics only, so a failure does not fail the publish
+	if err := p.history.Record(ctx, jwks, time.Now()); err != nil {
+		p.logger.Warn("failed to record JWKS history", "error", err)
+	}
+
 	// Log successful publish
 	p.logger.Info("Successfully published OIDC metadata to S3",
 		"bucket", p.config.Bucket,
@@ -93,6 +118,33 @@ func (p *Publisher) Publish(ctx context.Context, discovery *bridge.DiscoveryDocu
 	return nil
 }
 
+// uploadDiscovery uploads the discovery document to every path of the configured key layout.
+func (p *Publisher) uploadDiscovery(ctx context.Context, discovery *bridge.DiscoveryDocument) error {
+	return p.uploadDiscoveryAt(ctx, discovery, p.config)
+}
+
+// uploadDiscoveryAt uploads the discovery document to every discovery object key of the issuer configured by cfg.
+func (p *Publisher) uploadDiscoveryAt(ctx context.Context, discovery *bridge.DiscoveryDocument, cfg Config) error {
+	keys, err := cfg.GetDiscoveryObjectKeys()
+	if err != nil {
+		return fmt.Errorf("failed to derive discovery object keys: %w", err)
+	}
+
+	// Marshal discovery document to JSON
+	discoveryData, err := marshalJSON(discovery, p.config.MinifyDiscovery)
+	if err != nil {
+		return fmt.Errorf("failed to marshal discovery document: %w", err)
+	}
+
+	// Upload discovery document to .well-known/openid-configuration below the issuer, plus the flat copy if enabled
+	for _, key := range keys {
+		if err := p.uploadObject(ctx, key, discoveryData, p.config.ObjectTags); err != nil {
+			return fmt.Errorf("failed to upload discovery document: %w", err)
+		}
+	}
+	return nil
+}
+
 // prefixedKey prepends the configured prefix to a relative object key.
 func (p *Publisher) prefixedKey(key string) string {
 	if p.config.Prefix != "" {
@@ -101,41 +153,125 @@ func (p *Publisher) prefixedKey(key string) string {
 	return key
 }
 
-// Validate checks that the publisher is properly configured.
+// Validate checks that the publisher is properly configured. With a secondary
+// bucket it also detects whether the primary already replicates to it, and
+// checks the secondary is writable when it does not.
 func (p *Publisher) Validate(ctx context.Context) error {
+	if err := validateBucket(ctx, p.client, p.config.Bucket, p.config.ObjectTags); err != nil {
+		return err
+	}
+
+	if p.secondary != nil {
+		p.detectReplication(ctx)
+		if !p.replicated.Load() {
+			if err := validateBucket(ctx, p.secondary, p.config.Secondary.Bucket, p.config.ObjectTags); err != nil {
+				return fmt.Errorf("secondary: %w", err)
+			}
+		}
+	}
+
+	// Check bucket has public read policy by getting bucket policy status
+	// Note: We can't directly check if objects are public, but we can validate the setup
+	p.logger.Info("S3 bucket validation successful",
+		"bucket", p.config.Bucket,
+		"public_url", p.GetPublicURL(),
+	)
+
+	return nil
+}
+
+// validateBucket checks that bucket exists and is writable with tags, so a
+// missing s3:PutObjectTagging permission fails validation rather than every publish.
+func validateBucket(ctx context.Context, client *s3.Client, bucket string, tags map[string]string) error {
 	// Check bucket exists and is accessible
-	_, err := p.client.HeadBucket(ctx, &s3.HeadBucketInput{
-		Bucket: aws.String(p.config.Bucket),
+	_, err := client.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket: aws.String(bucket),
 	})
 	if err != nil {
-		return fmt.Errorf("bucket %s is not accessible: %w", p.config.Bucket, err)
+		return fmt.Errorf("bucket %s is not accessible: %w", bucket, err)
 	}
 
 	// Check write permissions by attempting a test upload
 	testKey := ".kubeassume/validation-test"
-	_, err = p.client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket: aws.String(p.config.Bucket),
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
 		Key:    aws.String(testKey),
 		Body:   bytes.NewReader([]byte("test")),
-	})
-	if err != nil {
-		return fmt.Errorf("write permission check failed for bucket %s: %w", p.config.Bucket, err)
+	}
+	if len(tags) > 0 {
+		input.Tagging = aws.String(encodeTagging(tags))
+	}
+	if _, err = client.PutObject(ctx, input); err != nil {
+		if len(tags) > 0 {
+			return fmt.Errorf("write permission check failed for bucket %s (tagged objects require s3:PutObjectTagging; set publisher.disableObjectTags to publish untagged): %w", bucket, err)
+		}
+		return fmt.Errorf("write permission check failed for bucket %s: %w", bucket, err)
 	}
 
 	// Clean up test object
-	_, _ = p.client.DeleteObject(ctx, &s3.DeleteObjectInput{
-		Bucket: aws.String(p.config.Bucket),
+	_, _ = client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
 		Key:    aws.String(testKey),
 	})
+	return nil
+}
 
-	// Check bucket has public read policy by getting bucket policy status
-	// Note: We can't directly check if objects are public, but we can validate the setup
-	p.logger.Info("S3 bucket validation successful",
-		"bucket", p.config.Bucket,
-		"public_url", p.GetPublicURL(),
-	)
+// detectReplication records whether the primary bucket replicates the
+// published objects to the secondary bucket. A missing or unreadable
+// replication configuration means writing to both buckets.
+func (p *Publisher) detectReplication(ctx context.Context) {
+	out, err := p.client.GetBucketReplication(ctx, &s3.GetBucketReplicationInput{
+		Bucket: aws.String(p.config.Bucket),
+	})
+	if err != nil {
+		p.logger.Debug("no readable replication configuration on primary bucket, writing to both buckets",
+			"bucket", p.config.Bucket,
+			"error", err,
+		)
+		p.replicated.Store(false)
+		return
+	}
 
-	return nil
+	replicated := replicatesTo(out.ReplicationConfiguration, p.config.Secondary.Bucket, p.prefixedKey(""))
+	if replicated {
+		p.logger.Info("primary bucket replicates to the secondary bucket, skipping writes to the secondary",
+			"bucket", p.config.Bucket,
+			"secondary_bucket", p.config.Secondary.Bucket,
+		)
+	}
+	p.replicated.Store(replicated)
+}
+
+// replicatesTo reports whether an enabled replication rule copies every object
+// under keyPrefix to bucket. Rules filtered by tag are ignored because published
+// objects are not guaranteed to carry the tag.
+func replicatesTo(cfg *types.ReplicationConfiguration, bucket, keyPrefix string) bool {
+	if cfg == nil {
+		return false
+	}
+	for _, rule := range cfg.Rules {
+		if rule.Status != types.ReplicationRuleStatusEnabled || rule.Destination == nil {
+			continue
+		}
+		if aws.ToString(rule.Destination.Bucket) != "arn:aws:s3:::"+bucket {
+			continue
+		}
+
+		rulePrefix := aws.ToString(rule.Prefix)
+		if f := rule.Filter; f != nil {
+			if f.Tag != nil || (f.And != nil && len(f.And.Tags) > 0) {
+				continue
+			}
+			rulePrefix = aws.ToString(f.Prefix)
+			if f.And != nil {
+				rulePrefix = aws.ToString(f.And.Prefix)
+			}
+		}
+		if strings.HasPrefix(keyPrefix, rulePrefix) {
+			return true
+		}
+	}
+	return false
 }
 
 // GetPublicURL returns the public URL for the OIDC issuer.
@@ -148,7 +284,13 @@ func (p *Publisher) Type() iface.PublisherType {
 	return iface.PublisherTypeS3
 }
 
-// HealthCheck verifies S3 is accessible.
+// Close is a no-op: the AWS SDK client holds no resources that need releasing.
+func (p *Publisher) Close() error {
+	return nil
+}
+
+// HealthCheck verifies S3 is accessible, including the secondary bucket when
+// it is written to.
 func (p *Publisher) HealthCheck(ctx context.Context) error {
 	// Perform HeadBucket operation to check accessibility
 	_, err := p.client.HeadBucket(ctx, &s3.HeadBucketInput{
@@ -157,11 +299,40 @@ func (p *Publisher) HealthCheck(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("S3 health check failed: %w", err)
 	}
+
+	if p.secondary != nil && !p.replicated.Load() {
+		_, err := p.secondary.HeadBucket(ctx, &s3.HeadBucketInput{
+			Bucket: aws.String(p.config.Secondary.Bucket),
+		})
+		if err != nil {
+			return fmt.Errorf("S3 health check failed for secondary bucket %s: %w", p.config.Secondary.Bucket, err)
+		}
+	}
+	return nil
+}
+
+// jwksTags returns the tags for uploading jwks.
+func (p *Publisher) jwksTags(jwks *bridge.JWKS) map[string]string {
+	return iface.DocumentTags(p.config.ObjectTags, jwks, p.config.KeyThumbprintTag)
+}
+
+// uploadObject uploads a JSON object to S3 with optimistic locking, tagged with
+// tags, then to the secondary bucket unless the primary replicates to it.
+func (p *Publisher) uploadObject(ctx context.Context, key string, data []byte, tags map[string]string) error {
+	if err := p.putObject(ctx, p.client, p.config.Bucket, key, data, tags); err != nil {
+		return err
+	}
+	if p.secondary == nil || p.replicated.Load() {
+		return nil
+	}
+	if err := p.putObject(ctx, p.secondary, p.config.Secondary.Bucket, key, data, tags); err != nil {
+		return fmt.Errorf("secondary bucket %s: %w", p.config.Secondary.Bucket, err)
+	}
 	return nil
 }
 
-// uploadObject uploads a JSON object to S3 with optimistic locking.
-func (p *Publisher) uploadObject(ctx context.Context, key string, data []byte) error {
+// putObject uploads a JSON object to bucket with optimistic locking, tagged with tags.
+func (p *Publisher) putObject(ctx context.Context, client *s3.Client, bucket, key string, data []byte, tags map[string]string) error {
 	contentType := p.config.ContentType
 	if contentType == "" {
 		contentType = "application/json"
@@ -172,14 +343,23 @@ func (p *Publisher) uploadObject(ctx context.Context, key string, data []byte) e
 		cacheControl = "max-age=300"
 	}
 
+	var hash string
+	if p.config.SkipUnchangedUploads {
+		hash = iface.ContentHash(data, contentType, cacheControl, tags)
+	}
+
 	// Get current ETag for optimistic locking
-	head, err := p.client.HeadObject(ctx, &s3.HeadObjectInput{
-		Bucket: aws.String(p.config.Bucket),
+	head, err := client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
 	})
 
 	var ifMatch *string
 	if err == nil {
+		if hash != "" && iface.ContentHashMatches(head.Metadata, hash) {
+			p.logger.Debug("Object is already up to date, skipping upload", "bucket", bucket, "key", key)
+			return nil
+		}
 		ifMatch = head.ETag
 	} else {
 		// We expect a NotFound error if the object doesn't exist yet.
@@ -191,16 +371,22 @@ func (p *Publisher) uploadObject(ctx context.Context, key string, data []byte) e
 
 	// Create PutObjectInput with correct headers
 	input := &s3.PutObjectInput{
-		Bucket:       aws.String(p.config.Bucket),
+		Bucket:       aws.String(bucket),
 		Key:          aws.String(key),
 		Body:         bytes.NewReader(data),
 		ContentType:  aws.String(contentType),
 		CacheControl: aws.String(cacheControl),
 		IfMatch:      ifMatch,
 	}
+	if len(tags) > 0 {
+		input.Tagging = aws.String(encodeTagging(tags))
+	}
+	if hash != "" {
+		input.Metadata = map[string]string{iface.MetadataContentHash: hash}
+	}
 
 	// Execute PutObject
-	_, err = p.client.PutObject(ctx, input)
+	_, err = client.PutObject(ctx, input)
 	if err != nil {
 		var responseError *awshttp.ResponseError
 		if errors.As(err, &responseError) && responseError.HTTPStatusCode() == http.StatusPreconditionFailed {
@@ -212,7 +398,7 @@ func (p *Publisher) uploadObject(ctx context.Context, key string, data []byte) e
 
 	// Log upload details
 	p.logger.Debug("Uploaded object to S3",
-		"bucket", p.config.Bucket,
+		"bucket", bucket,
 		"key", key,
 		"size", len(data),
 	)
@@ -220,9 +406,19 @@ func (p *Publisher) uploadObject(ctx context.Context, key string, data []byte) e
 	return nil
 }
 
-// marshalJSON marshals an object to JSON with proper formatting.
-func marshalJSON(v interface{}) ([]byte, error) {
-	return json.MarshalIndent(v, "", "  ")
+// encodeTagging encodes tags as the URL query string expected by the
+// x-amz-tagging header, so tags are set in the same request as the upload.
+func encodeTagging(tags map[string]string) string {
+	values := url.Values{}
+	for k, v := range tags {
+		values.Set(k, v)
+	}
+	return values.Encode()
+}
+
+// marshalJSON marshals an object to JSON, indented unless minify is set.
+func marshalJSON(v interface{}, minify bool) ([]byte, error) {
+	return iface.MarshalDocument(v, minify)
 }
 
 // loadAWSConfig loads AWS configuration with optional custom endpoint.
@@ -281,7 +477,11 @@ func (p *Publisher) GetBucketPolicy(ctx context.Context) (*types.PolicyStatus, e
 }
 
 // Ensure Publisher implements iface.MultiClusterAggregator when in multi-cluster mode.
-var _ iface.MultiClusterAggregator = (*Publisher)(nil)
+var (
+	_ iface.MultiClusterAggregator = (*Publisher)(nil)
+	_ iface.ClusterDeleter         = (*Publisher)(nil)
+	_ iface.ManagedObjectStore     = (*Publisher)(nil)
+)
 
 // ListClusterJWKS lists all cluster sub-paths under "clusters/" and returns parsed JWKS per clusterID.
 func (p *Publisher) ListClusterJWKS(ctx context.Context) (map[string]*bridge.JWKS, error) {
@@ -316,10 +516,14 @@ func (p *Publisher) ListClusterJWKS(ctx context.Context) (map[string]*bridge.JWK
 			p.logger.Warn("failed to fetch cluster JWKS, skipping", "clusterID", clusterID, "error", err)
 			continue
 		}
-		defer func() { _ = getOut.Body.Close() }()
-
+		data, err := bridge.ReadLimited(getOut.Body, p.config.MaxReadBytes)
+		_ = getOut.Body.Close()
+		if err != nil {
+			p.logger.Warn("failed to read cluster JWKS body, skipping", "clusterID", clusterID, "error", err)
+			continue
+		}
 		var jwks bridge.JWKS
-		if err := json.NewDecoder(getOut.Body).Decode(&jwks); err != nil {
+		if err := json.Unmarshal(data, &jwks); err != nil {
 			p.logger.Warn("failed to decode cluster JWKS, skipping", "clusterID", clusterID, "error", err)
 			continue
 		}
@@ -367,12 +571,198 @@ func (p *Publisher) GetClusterLastModified(ctx context.Context) (map[string]time
 	return lastModified, nil
 }
 
+// DeleteClusterJWKS deletes the cluster's JWKS history and JWKS object, from the secondary bucket
+// too unless the primary replicates to it. S3 deletes of missing keys succeed.
+func (p *Publisher) DeleteClusterJWKS(ctx context.Context, clusterID string) error {
+	if err := iface.DeleteHistory(ctx, historyStore{p: p}, p.prefixedKey(p.config.GetClusterHistoryPrefix(clusterID))); err != nil {
+		return err
+	}
+	return p.deleteObject(ctx, p.prefixedKey(p.config.GetClusterJWKSPath(clusterID)))
+}
+
+// ListManagedObjects returns the keys of the discovery documents, JWKS and JWKS
+// history still in the primary bucket.
+func (p *Publisher) ListManagedObjects(ctx context.Context) ([]string, error) {
+	var documents []string
+	if !p.config.MultiClusterEnabled {
+		keys, err := p.config.GetDiscoveryObjectKeys()
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive discovery object keys: %w", err)
+		}
+		documents = keys
+	}
+	documents = append(documents, p.prefixedKey(p.config.GetJWKSPath()))
+	return iface.ListExistingObjects(ctx, historyStore{p: p}, documents, p.prefixedKey(p.config.GetHistoryPrefix()))
+}
+
+// DeleteManagedObject deletes key, from the secondary bucket too unless the
+// primary replicates to it.
+func (p *Publisher) DeleteManagedObject(ctx context.Context, key string) error {
+	return p.deleteObject(ctx, key)
+}
+
+// deleteObject deletes key from the secondary bucket, unless the primary
+// replicates to it, and then from the primary bucket. The primary copy goes
+// last so a failed delete leaves the key listed by ListManagedObjects for a
+// retry.
+func (p *Publisher) deleteObject(ctx context.Context, key string) error {
+	if p.secondary != nil && !p.replicated.Load() {
+		if _, err := p.secondary.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(p.config.Secondary.Bucket),
+			Key:    aws.String(key),
+		}); err != nil {
+			return fmt.Errorf("secondary bucket %s: failed to delete %s: %w", p.config.Secondary.Bucket, key, err)
+		}
+	}
+	if _, err := p.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(p.config.Bucket),
+		Key:    aws.String(key),
+	}); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
 // PublishAggregatedJWKS writes the merged JWKS to the root JWKS path using optimistic locking.
 func (p *Publisher) PublishAggregatedJWKS(ctx context.Context, merged *bridge.JWKS) error {
-	data, err := marshalJSON(merged)
+	data, err := marshalJSON(merged, p.config.MinifyJWKS)
 	if err != nil {
 		return fmt.Errorf("failed to marshal aggregated JWKS: %w", err)
 	}
 	rootKey := p.prefixedKey(p.config.GetRootJWKSPath())
-	return p.uploadObject(ctx, rootKey, data)
+	return p.uploadObject(ctx, rootKey, data, p.jwksTags(merged))
+}
+
+// PublishRootDiscovery writes the group's discovery document to the root discovery path.
+func (p *Publisher) PublishRootDiscovery(ctx context.Context, discovery *bridge.DiscoveryDocument) error {
+	return p.uploadDiscovery(ctx, discovery)
+}
+
+// Ensure Publisher implements iface.GroupAggregator for group-of-groups aggregation.
+var _ iface.GroupAggregator = (*Publisher)(nil)
+
+// topLevelPrefix returns the key prefix above the group prefix ("" for the bucket root).
+func (p *Publisher) topLevelPrefix() string {
+	top := path.Dir(p.config.Prefix)
+	if top == "." || top == "/" {
+		return ""
+	}
+	return top
+}
+
+// topLevelKey prepends the top-level prefix to a relative object key.
+func (p *Publisher) topLevelKey(key string) string {
+	if top := p.topLevelPrefix(); top != "" {
+		return top + "/" + key
+	}
+	return key
+}
+
+// listGroups returns the group prefixes under the top level.
+func (p *Publisher) listGroups(ctx context.Context) ([]string, error) {
+	topPrefix := p.topLevelKey("")
+	result, err := p.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(p.config.Bucket),
+		Prefix:    aws.String(topPrefix),
+		Delimiter: aws.String("/"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list group prefixes: %w", err)
+	}
+
+	groups := make([]string, 0, len(result.CommonPrefixes))
+	for _, cp := range result.CommonPrefixes {
+		if cp.Prefix == nil {
+			continue
+		}
+		group := strings.TrimSuffix(strings.TrimPrefix(*cp.Prefix, topPrefix), "/")
+		if group != "" {
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+// ListGroupJWKS reads the root JWKS of every group prefix under the top level.
+// Prefixes without a root JWKS (for example ".well-known/") are skipped. Any other
+// read failure is returned, so a transient error aborts the aggregation instead of
+// publishing a top-level JWKS that lacks the group's keys.
+func (p *Publisher) ListGroupJWKS(ctx context.Context) (map[string]*bridge.JWKS, error) {
+	groups, err := p.listGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	groupJWKS := make(map[string]*bridge.JWKS)
+	for _, group := range groups {
+		jwksKey := p.topLevelKey(group + "/" + p.config.GetRootJWKSPath())
+		getOut, err := p.client.GetObject(ctx, &s3.GetObjectInput{
+			Bucket: aws.String(p.config.Bucket),
+			Key:    aws.String(jwksKey),
+		})
+		if err != nil {
+			var noSuchKey *types.NoSuchKey
+			if errors.As(err, &noSuchKey) {
+				p.logger.Debug("no root JWKS under prefix, skipping", "group", group)
+				continue
+			}
+			return nil, fmt.Errorf("failed to fetch root JWKS of group %s: %w", group, err)
+		}
+		data, err := bridge.ReadLimited(getOut.Body, p.config.MaxReadBytes)
+		_ = getOut.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read root JWKS of group %s: %w", group, err)
+		}
+		var jwks bridge.JWKS
+		if err := json.Unmarshal(data, &jwks); err != nil {
+			p.logger.Warn("failed to decode group JWKS, skipping", "group", group, "error", err)
+			continue
+		}
+		groupJWKS[group] = &jwks
+	}
+	return groupJWKS, nil
+}
+
+// GetGroupLastModified returns the last-modified time of each group's root JWKS object.
+// Prefixes without a root JWKS are skipped; any other failure is returned.
+func (p *Publisher) GetGroupLastModified(ctx context.Context) (map[string]time.Time, error) {
+	groups, err := p.listGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lastModified := make(map[string]time.Time)
+	for _, group := range groups {
+		head, err := p.client.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket: aws.String(p.config.Bucket),
+			Key:    aws.String(p.topLevelKey(group + "/" + p.config.GetRootJWKSPath())),
+		})
+		if err != nil {
+			var notFound *types.NotFound
+			if errors.As(err, &notFound) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to head root JWKS of group %s: %w", group, err)
+		}
+		if head.LastModified != nil {
+			lastModified[group] = *head.LastModified
+		}
+	}
+	return lastModified, nil
+}
+
+// PublishTopLevelJWKS writes the merged JWKS of all groups to the top-level JWKS path.
+func (p *Publisher) PublishTopLevelJWKS(ctx context.Context, merged *bridge.JWKS) error {
+	data, err := marshalJSON(merged, p.config.MinifyJWKS)
+	if err != nil {
+		return fmt.Errorf("failed to marshal top-level JWKS: %w", err)
+	}
+	return p.uploadObject(ctx, p.topLevelKey(p.config.GetRootJWKSPath()), data, p.jwksTags(merged))
+}
+
+// PublishTopLevelDiscovery writes the top-level discovery document.
+func (p *Publisher) PublishTopLevelDiscovery(ctx context.Context, discovery *bridge.DiscoveryDocument) error {
+	top := p.config
+	top.Prefix = p.topLevelPrefix()
+	return p.uploadDiscoveryAt(ctx, discovery, top)
 }
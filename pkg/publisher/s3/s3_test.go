@@ -3,8 +3,19 @@ package s3
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -40,6 +51,43 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid secondary",
+			config: Config{
+				Bucket:    "my-bucket",
+				Region:    "us-east-1",
+				Secondary: &SecondaryConfig{Bucket: "my-bucket-west", Region: "us-west-2"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "secondary missing region",
+			config: Config{
+				Bucket:    "my-bucket",
+				Region:    "us-east-1",
+				Secondary: &SecondaryConfig{Bucket: "my-bucket-west"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "secondary same as primary",
+			config: Config{
+				Bucket:    "my-bucket",
+				Region:    "us-east-1",
+				Secondary: &SecondaryConfig{Bucket: "my-bucket", Region: "us-west-2"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "same bucket name on another endpoint",
+			config: Config{
+				Bucket:    "my-bucket",
+				Region:    "us-east-1",
+				Endpoint:  "http://minio-a:9000",
+				Secondary: &SecondaryConfig{Bucket: "my-bucket", Region: "us-east-1", Endpoint: "http://minio-b:9000"},
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -190,7 +238,7 @@ func TestMarshalJSON(t *testing.T) {
 		JWKSURI: "https://example.com/jwks",
 	}
 
-	data, err := marshalJSON(dd)
+	data, err := marshalJSON(dd, false)
 	require.NoError(t, err)
 
 	// Verify it's valid JSON and properly formatted
@@ -198,3 +246,341 @@ func TestMarshalJSON(t *testing.T) {
 	require.NoError(t, json.Unmarshal(data, &result))
 	assert.Equal(t, "https://example.com", result["issuer"])
 }
+
+func TestEncodeTagging(t *testing.T) {
+	encoded := encodeTagging(map[string]string{
+		"kube-iam-assume/cluster": "prod-a",
+		"team":                    "platform & infra",
+	})
+
+	values, err := url.ParseQuery(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, "prod-a", values.Get("kube-iam-assume/cluster"))
+	assert.Equal(t, "platform & infra", values.Get("team"))
+}
+
+func TestConfig_GetDiscoveryObjectKeys(t *testing.T) {
+	tests := []struct {
+		name      string
+		endpoint  string
+		prefix    string
+		keyLayout iface.KeyLayout
+		expected  []string
+	}{
+		{
+			name:     "without prefix",
+			expected: []string{".well-known/openid-configuration"},
+		},
+		{
+			name:     "with prefix",
+			prefix:   "oidc",
+			expected: []string{"oidc/.well-known/openid-configuration"},
+		},
+		{
+			name:     "with nested prefix",
+			prefix:   "prod/group-a",
+			expected: []string{"prod/group-a/.well-known/openid-configuration"},
+		},
+		{
+			name:     "custom endpoint",
+			endpoint: "http://localhost:9000",
+			prefix:   "oidc",
+			expected: []string{"oidc/.well-known/openid-configuration"},
+		},
+		{
+			name:      "flat layout adds dot-free copy",
+			prefix:    "oidc",
+			keyLayout: iface.KeyLayoutFlat,
+			expected:  []string{"oidc/.well-known/openid-configuration", "oidc/openid-configuration"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := Config{Bucket: "oidc-bucket", Region: "us-east-1", Endpoint: tt.endpoint, Prefix: tt.prefix, KeyLayout: tt.keyLayout}
+			keys, err := config.GetDiscoveryObjectKeys()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, keys)
+
+			// The well-known document is served exactly below the issuer
+			served := strings.TrimSuffix(config.BucketLayout().BaseURL, "/") + "/" + keys[0]
+			assert.Equal(t, strings.TrimSuffix(config.GetPublicURL(), "/")+"/.well-known/openid-configuration", served)
+			assert.Equal(t, config.GetFullDiscoveryURL(), served)
+		})
+	}
+}
+
+func TestReplicatesTo(t *testing.T) {
+	rule := func(status types.ReplicationRuleStatus, bucket string, filter *types.ReplicationRuleFilter) types.ReplicationRule {
+		return types.ReplicationRule{
+			Status:      status,
+			Destination: &types.Destination{Bucket: aws.String("arn:aws:s3:::" + bucket)},
+			Filter:      filter,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		rules     []types.ReplicationRule
+		keyPrefix string
+		expected  bool
+	}{
+		{
+			name:     "whole bucket replicated",
+			rules:    []types.ReplicationRule{rule(types.ReplicationRuleStatusEnabled, "oidc-west", &types.ReplicationRuleFilter{})},
+			expected: true,
+		},
+		{
+			name:     "disabled rule",
+			rules:    []types.ReplicationRule{rule(types.ReplicationRuleStatusDisabled, "oidc-west", nil)},
+			expected: false,
+		},
+		{
+			name:     "other destination",
+			rules:    []types.ReplicationRule{rule(types.ReplicationRuleStatusEnabled, "backups", nil)},
+			expected: false,
+		},
+		{
+			name:      "prefix covers published objects",
+			rules:     []types.ReplicationRule{rule(types.ReplicationRuleStatusEnabled, "oidc-west", &types.ReplicationRuleFilter{Prefix: aws.String("prod/")})},
+			keyPrefix: "prod/",
+			expected:  true,
+		},
+		{
+			name:      "prefix excludes published objects",
+			rules:     []types.ReplicationRule{rule(types.ReplicationRuleStatusEnabled, "oidc-west", &types.ReplicationRuleFilter{Prefix: aws.String("staging/")})},
+			keyPrefix: "prod/",
+			expected:  false,
+		},
+		{
+			name: "tag filter ignored",
+			rules: []types.ReplicationRule{rule(types.ReplicationRuleStatusEnabled, "oidc-west", &types.ReplicationRuleFilter{
+				Tag: &types.Tag{Key: aws.String("replicate"), Value: aws.String("true")},
+			})},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &types.ReplicationConfiguration{Rules: tt.rules}
+			assert.Equal(t, tt.expected, replicatesTo(cfg, "oidc-west", tt.keyPrefix))
+		})
+	}
+}
+
+func TestPublisher_ConcurrentPublish(t *testing.T) {
+	// A bucket that has no objects yet and accepts every upload
+	var puts atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodHead:
+			w.WriteHeader(http.StatusNotFound)
+		case http.MethodPut:
+			puts.Add(1)
+			w.Header().Set("ETag", `"etag"`)
+		case http.MethodGet:
+			// JWKS history listing
+			_, _ = w.Write([]byte(`<ListBucketResult></ListBucketResult>`))
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	pub, err := New(context.Background(), Config{
+		Bucket:         "oidc-bucket",
+		Region:         "us-west-2",
+		Endpoint:       srv.URL,
+		ForcePathStyle: true,
+		JWKSHistory:    3,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	defer func() { _ = pub.Close() }()
+
+	// Every call shares the publisher's client; run with -race to check they do not race
+	const publishes = 8
+	var wg sync.WaitGroup
+	for i := 0; i < publishes; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			discovery := &bridge.DiscoveryDocument{Issuer: pub.GetPublicURL()}
+			jwks := &bridge.JWKS{Keys: []bridge.JWK{{Kid: fmt.Sprintf("key-%d", i), Kty: "RSA"}}}
+			assert.NoError(t, pub.Publish(context.Background(), discovery, jwks))
+		}()
+	}
+	wg.Wait()
+
+	// The discovery document and JWKS of every publish were uploaded
+	assert.GreaterOrEqual(t, puts.Load(), int64(2*publishes))
+}
+
+func TestPublisher_SkipUnchangedUploads(t *testing.T) {
+	// A bucket that keeps the content hash metadata of every uploaded object
+	var (
+		mu     sync.Mutex
+		hashes = map[string]string{}
+		puts   []string
+	)
+	metadataHeader := "X-Amz-Meta-" + iface.MetadataContentHash
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodHead:
+			hash, ok := hashes[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("ETag", `"etag"`)
+			w.Header().Set(metadataHeader, hash)
+		case http.MethodPut:
+			hashes[r.URL.Path] = r.Header.Get(metadataHeader)
+			puts = append(puts, r.URL.Path)
+			w.Header().Set("ETag", `"etag"`)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	pub, err := New(context.Background(), Config{
+		Bucket:               "oidc-bucket",
+		Region:               "us-west-2",
+		Endpoint:             srv.URL,
+		ForcePathStyle:       true,
+		SkipUnchangedUploads: true,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	defer func() { _ = pub.Close() }()
+
+	ctx := context.Background()
+	discovery := &bridge.DiscoveryDocument{Issuer: pub.GetPublicURL()}
+	jwks := &bridge.JWKS{Keys: []bridge.JWK{{Kid: "key-1", Kty: "RSA"}}}
+
+	require.NoError(t, pub.Publish(ctx, discovery, jwks))
+	assert.Len(t, puts, 2)
+	for _, hash := range hashes {
+		assert.NotEmpty(t, hash)
+	}
+
+	// The stored objects already have the intended content
+	require.NoError(t, pub.Publish(ctx, discovery, jwks))
+	assert.Len(t, puts, 2)
+
+	// Only the changed JWKS is uploaded
+	jwks.Keys = append(jwks.Keys, bridge.JWK{Kid: "key-2", Kty: "RSA"})
+	require.NoError(t, pub.Publish(ctx, discovery, jwks))
+	assert.Equal(t, "/oidc-bucket/openid/v1/jwks", puts[len(puts)-1])
+	assert.Len(t, puts, 3)
+}
+
+func TestValidateBucket_WritesWithTags(t *testing.T) {
+	var tagging []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			tagging = append(tagging, r.Header.Get("X-Amz-Tagging"))
+			// A role without s3:PutObjectTagging
+			if r.Header.Get("X-Amz-Tagging") != "" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	pub, err := New(context.Background(), Config{
+		Bucket:         "oidc-bucket",
+		Region:         "us-west-2",
+		Endpoint:       srv.URL,
+		ForcePathStyle: true,
+		ObjectTags:     map[string]string{iface.TagManagedBy: "kube-iam-assume"},
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	defer func() { _ = pub.Close() }()
+
+	err = pub.Validate(context.Background())
+	require.ErrorContains(t, err, "s3:PutObjectTagging")
+	assert.Equal(t, []string{"kube-iam-assume%2Fmanaged-by=kube-iam-assume"}, tagging)
+}
+
+func TestListGroupJWKS_FailsOnReadErrors(t *testing.T) {
+	// A bucket with the prod and staging groups plus a prefix without a root JWKS
+	var (
+		mu      sync.Mutex
+		denied  = map[string]bool{}
+		objects = map[string]string{
+			"/oidc-bucket/prod/openid/v1/jwks":    `{"keys":[{"kty":"RSA","kid":"prod-key"}]}`,
+			"/oidc-bucket/staging/openid/v1/jwks": `{"keys":[{"kty":"RSA","kid":"staging-key"}]}`,
+		}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Query().Get("list-type") == "2" {
+			_, _ = io.WriteString(w, `<ListBucketResult><Name>oidc-bucket</Name><IsTruncated>false</IsTruncated>`+
+				`<CommonPrefixes><Prefix>.well-known/</Prefix></CommonPrefixes>`+
+				`<CommonPrefixes><Prefix>prod/</Prefix></CommonPrefixes>`+
+				`<CommonPrefixes><Prefix>staging/</Prefix></CommonPrefixes></ListBucketResult>`)
+			return
+		}
+		if denied[r.URL.Path] {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = io.WriteString(w, `<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+			return
+		}
+		body, ok := objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `<Error><Code>NoSuchKey</Code><Message>missing</Message></Error>`)
+			return
+		}
+		w.Header().Set("Last-Modified", "Thu, 01 Jan 2026 00:00:00 GMT")
+		if r.Method == http.MethodGet {
+			_, _ = io.WriteString(w, body)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	pub, err := New(context.Background(), Config{
+		Bucket:              "oidc-bucket",
+		Region:              "us-west-2",
+		Endpoint:            srv.URL,
+		ForcePathStyle:      true,
+		Prefix:              "prod",
+		MultiClusterEnabled: true,
+		ClusterID:           "prod-a",
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+	defer func() { _ = pub.Close() }()
+	aggregator := pub.(iface.GroupAggregator)
+	ctx := context.Background()
+
+	// The prefix without a root JWKS is skipped
+	groupJWKS, err := aggregator.ListGroupJWKS(ctx)
+	require.NoError(t, err)
+	assert.Len(t, groupJWKS, 2)
+	assert.Equal(t, "staging-key", groupJWKS["staging"].Keys[0].Kid)
+	lastModified, err := aggregator.GetGroupLastModified(ctx)
+	require.NoError(t, err)
+	assert.Len(t, lastModified, 2)
+
+	// An unreadable group fails the listing instead of being left out
+	mu.Lock()
+	denied["/oidc-bucket/staging/openid/v1/jwks"] = true
+	mu.Unlock()
+	_, err = aggregator.ListGroupJWKS(ctx)
+	assert.ErrorContains(t, err, "group staging")
+	_, err = aggregator.GetGroupLastModified(ctx)
+	assert.ErrorContains(t, err, "group staging")
+}
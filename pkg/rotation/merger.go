@@ -1,6 +1,9 @@
 package rotation
 
 import (
+	"cmp"
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/hixichen/kube-iam-assume/pkg/bridge"
@@ -8,7 +11,10 @@ import (
 
 // Merger handles merging JWKS for rotation overlap periods.
 type Merger struct {
-	overlapPeriod time.Duration
+	overlapPeriod      time.Duration
+	missingThreshold   int
+	missingGracePeriod time.Duration
+	keyOrder           KeyOrder
 }
 
 // NewMerger creates a new Merger with the specified overlap period.
@@ -18,6 +24,20 @@ func NewMerger(overlapPeriod time.Duration) *Merger {
 	}
 }
 
+// WithMissingGrace sets how long a key must be absent before it is marked for removal:
+// at least threshold consecutive syncs and at least gracePeriod since it was last seen.
+func (m *Merger) WithMissingGrace(threshold int, gracePeriod time.Duration) *Merger {
+	m.missingThreshold = threshold
+	m.missingGracePeriod = gracePeriod
+	return m
+}
+
+// WithKeyOrder sets the order of keys in merged and publishable JWKS.
+func (m *Merger) WithKeyOrder(order KeyOrder) *Merger {
+	m.keyOrder = order
+	return m
+}
+
 // Merge combines current JWKS with stored keys that are still in overlap period.
 func (m *Merger) Merge(current *bridge.JWKS, state *State, now time.Time) *bridge.JWKS {
 	if current == nil && len(state.Keys) == 0 {
@@ -47,9 +67,22 @@ func (m *Merger) Merge(current *bridge.JWKS, state *State, now time.Time) *bridg
 		}
 	}
 
+	m.sortKeys(merged.Keys, state)
 	return merged
 }
 
+// Process applies current to state as one sync does: it records new and missing
+// keys, removes keys whose overlap period has ended and returns the JWKS to
+// publish with the rotation events. It modifies state but does not save it.
+func (m *Merger) Process(current *bridge.JWKS, state *State, now time.Time) (*bridge.JWKS, []Event, error) {
+	events, err := m.UpdateState(current, state, now)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to update rotation state: %w", err)
+	}
+	events = append(events, m.CleanupExpired(state, now)...)
+	return m.Merge(current, state, now), events, nil
+}
+
 // UpdateState updates the rotation state based on current JWKS
 // Returns events for any detected changes.
 func (m *Merger) UpdateState(current *bridge.JWKS, state *State, now time.Time) ([]Event, error) {
@@ -71,8 +104,11 @@ func (m *Merger) UpdateState(current *bridge.JWKS, state *State, now time.Time)
 	if current != nil {
 		for _, key := range current.Keys {
 			if existingState, exists := state.Keys[key.Kid]; exists {
-				// Existing key: update LastSeen
+				// Existing key: refresh the stored JWK, which may have gained
+				// certificate material, update LastSeen and reset the absence streak
+				existingState.Key = key
 				existingState.LastSeen = now
+				existingState.MissingCount = 0
 				state.Keys[key.Kid] = existingState
 			} else {
 				// New key: add to state
@@ -92,12 +128,16 @@ func (m *Merger) UpdateState(current *bridge.JWKS, state *State, now time.Time)
 		}
 	}
 
-	// Mark keys not in current as for removal
+	// Mark keys not in current for removal once they have been missing long enough
 	for keyID, keyState := range state.Keys {
-		if !currentKeyIDs[keyID] && keyState.MarkedForRemoval == nil {
+		if currentKeyIDs[keyID] || keyState.MarkedForRemoval != nil {
+			continue
+		}
+		keyState.MissingCount++
+		if m.missingLongEnough(keyState, now) {
 			keyState.MarkedForRemoval = &now
-			state.Keys[keyID] = keyState
 		}
+		state.Keys[keyID] = keyState
 	}
 
 	// Update version and timestamp
@@ -152,9 +192,35 @@ func (m *Merger) GetPublishableJWKS(state *State) *bridge.JWKS {
 		jwks.Keys = append(jwks.Keys, keyState.Key)
 	}
 
+	m.sortKeys(jwks.Keys, state)
 	return jwks
 }
 
+// sortKeys orders keys by the configured key order, using the FirstSeen and LastSeen
+// recorded in state. Keys sharing a kid keep their relative order.
+func (m *Merger) sortKeys(keys []bridge.JWK, state *State) {
+	if m.keyOrder != KeyOrderNewestFirst {
+		slices.SortStableFunc(keys, func(a, b bridge.JWK) int { return cmp.Compare(a.Kid, b.Kid) })
+		return
+	}
+
+	seen := func(kid string) (first, last time.Time) {
+		if keyState, ok := state.Keys[kid]; ok {
+			return keyState.FirstSeen, keyState.LastSeen
+		}
+		return time.Time{}, time.Time{}
+	}
+	slices.SortStableFunc(keys, func(a, b bridge.JWK) int {
+		aFirst, aLast := seen(a.Kid)
+		bFirst, bLast := seen(b.Kid)
+		return cmp.Or(
+			bFirst.Compare(aFirst),
+			bLast.Compare(aLast),
+			cmp.Compare(a.Kid, b.Kid),
+		)
+	})
+}
+
 // shouldKeepKey determines if a key should still be published.
 func (m *Merger) shouldKeepKey(keyState *KeyState, now time.Time) bool {
 	// Key is current (not marked for removal)
@@ -168,6 +234,14 @@ func (m *Merger) shouldKeepKey(keyState *KeyState, now time.Time) bool {
 	return false
 }
 
+// missingLongEnough reports whether an absent key has passed the missing threshold and grace period.
+func (m *Merger) missingLongEnough(keyState *KeyState, now time.Time) bool {
+	if keyState.MissingCount < m.missingThreshold {
+		return false
+	}
+	return now.Sub(keyState.LastSeen) >= m.missingGracePeriod
+}
+
 // hasKey checks if a JWKS contains a key with the given ID.
 func hasKey(jwks *bridge.JWKS, keyID string) bool {
 	for _, key := range jwks.Keys {
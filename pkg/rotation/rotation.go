@@ -23,6 +23,15 @@ type Manager interface {
 
 	// GetState returns the current rotation state
 	GetState(ctx context.Context) (*State, error)
+
+	// HealthCheck verifies the rotation state store is readable and, where supported, writable
+	HealthCheck(ctx context.Context) error
+
+	// GetPublishedHash returns the persisted content hash of the last publish ("" if none)
+	GetPublishedHash(ctx context.Context) (string, error)
+
+	// SetPublishedHash persists the content hash of the last publish
+	SetPublishedHash(ctx context.Context, hash string) error
 }
 
 // RotationManager implements Manager.
@@ -38,7 +47,7 @@ type RotationManager struct {
 func NewManager(store Store, cfg Config, logger *slog.Logger) *RotationManager {
 	return &RotationManager{
 		store:   store,
-		merger:  NewMerger(cfg.OverlapPeriod),
+		merger:  NewMerger(cfg.OverlapPeriod).WithMissingGrace(cfg.MissingThreshold, cfg.MissingGracePeriod).WithKeyOrder(cfg.KeyOrder),
 		config:  cfg,
 		logger:  logger,
 		nowFunc: time.Now,
@@ -55,27 +64,28 @@ func (m *RotationManager) ProcessJWKS(ctx context.Context, current *bridge.JWKS)
 		return nil, nil, fmt.Errorf("failed to load rotation state: %w", err)
 	}
 
+	// Detect new/missing keys, clean up expired keys and merge JWKS for publishing.
+	// Staleness is judged by the previous sync, before this one refreshes it.
+	var merged *bridge.JWKS
 	var allEvents []Event
-
-	// Update state with current JWKS (detect new/missing keys)
-	events, err := m.merger.UpdateState(current, state, now)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to update rotation state: %w", err)
+	if m.cleanupAllowed(state, now) {
+		merged, allEvents, err = m.merger.Process(current, state, now)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		allEvents, err = m.merger.UpdateState(current, state, now)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to update rotation state: %w", err)
+		}
+		merged = m.merger.Merge(current, state, now)
 	}
-	allEvents = append(allEvents, events...)
-
-	// Cleanup expired keys
-	expiredEvents := m.merger.CleanupExpired(state, now)
-	allEvents = append(allEvents, expiredEvents...)
 
 	// Save updated state
 	if err := m.store.Save(ctx, state); err != nil {
 		return nil, nil, fmt.Errorf("failed to save rotation state: %w", err)
 	}
 
-	// Merge JWKS for publishing
-	merged := m.merger.Merge(current, state, now)
-
 	// Log events
 	for _, event := range allEvents {
 		m.logEvent(event)
@@ -97,6 +107,8 @@ func (m *RotationManager) GetPublishableJWKS(ctx context.Context) (*bridge.JWKS,
 }
 
 // CleanupExpiredKeys removes keys that have exceeded the overlap period.
+// It removes nothing while the source JWKS has not been synced for longer than
+// Config.CleanupMaxSourceAge, as ProcessJWKS does.
 func (m *RotationManager) CleanupExpiredKeys(ctx context.Context) ([]Event, error) {
 	now := m.nowFunc()
 
@@ -106,6 +118,10 @@ func (m *RotationManager) CleanupExpiredKeys(ctx context.Context) ([]Event, erro
 		return nil, fmt.Errorf("failed to load rotation state: %w", err)
 	}
 
+	if !m.cleanupAllowed(state, now) {
+		return nil, nil
+	}
+
 	// Run cleanup
 	events := m.merger.CleanupExpired(state, now)
 
@@ -129,6 +145,46 @@ func (m *RotationManager) GetState(ctx context.Context) (*State, error) {
 	return m.store.Load(ctx)
 }
 
+// GetPublishedHash returns the content hash of the last publish from the rotation state.
+func (m *RotationManager) GetPublishedHash(ctx context.Context) (string, error) {
+	state, err := m.store.Load(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to load rotation state: %w", err)
+	}
+	return state.PublishedHash, nil
+}
+
+// SetPublishedHash records the content hash of the last publish in the rotation state.
+func (m *RotationManager) SetPublishedHash(ctx context.Context, hash string) error {
+	state, err := m.store.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load rotation state: %w", err)
+	}
+	if state.PublishedHash == hash {
+		return nil
+	}
+
+	state.PublishedHash = hash
+	if err := m.store.Save(ctx, state); err != nil {
+		return fmt.Errorf("failed to save rotation state: %w", err)
+	}
+	return nil
+}
+
+// HealthCheck verifies the rotation state store is readable and, when the store
+// implements WriteChecker, that it would accept a write.
+func (m *RotationManager) HealthCheck(ctx context.Context) error {
+	if _, err := m.store.Load(ctx); err != nil {
+		return fmt.Errorf("failed to load rotation state: %w", err)
+	}
+	if wc, ok := m.store.(WriteChecker); ok {
+		if err := wc.CheckWrite(ctx); err != nil {
+			return fmt.Errorf("rotation state is not writable: %w", err)
+		}
+	}
+	return nil
+}
+
 // SetTimeFunc sets the time function (for testing).
 func (m *RotationManager) SetTimeFunc(f func() time.Time) {
 	m.nowFunc = f
@@ -162,3 +218,18 @@ func createKeyExpiredEvent(keyID string, now time.Time) Event {
 		Message:   fmt.Sprintf("Key expired and removed: %s", keyID),
 	}
 }
+
+// cleanupAllowed reports whether expired keys may be removed from state at now.
+// Keys marked for removal while the source was unreachable may still be valid, so
+// cleanup is skipped while the last sync is older than Config.CleanupMaxSourceAge.
+func (m *RotationManager) cleanupAllowed(state *State, now time.Time) bool {
+	lastSynced := state.LastSynced()
+	if m.config.CleanupMaxSourceAge <= 0 || lastSynced.IsZero() || now.Sub(lastSynced) <= m.config.CleanupMaxSourceAge {
+		return true
+	}
+	m.logger.Warn("skipping cleanup of expired keys: the source JWKS has not been synced recently",
+		"lastSynced", lastSynced,
+		"maxSourceAge", m.config.CleanupMaxSourceAge,
+	)
+	return false
+}
@@ -2,7 +2,9 @@ package rotation
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"io"
 	"log/slog"
 	"os"
 	"testing"
@@ -192,6 +194,254 @@ func TestRotationManager_CleanupExpiredKeys_NoExpired(t *testing.T) {
 	assert.Len(t, events, 0)
 }
 
+func TestRotationManager_CleanupExpiredKeys_StaleSource(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	now := time.Now()
+	markedAt := now.Add(-25 * time.Hour) // Past the 24h overlap
+
+	tests := []struct {
+		name        string
+		lastSynced  time.Time
+		maxAge      time.Duration
+		wantRemoved bool
+	}{
+		{name: "fresh source", lastSynced: now.Add(-time.Minute), maxAge: time.Hour, wantRemoved: true},
+		{name: "stale source", lastSynced: now.Add(-2 * time.Hour), maxAge: time.Hour, wantRemoved: false},
+		{name: "guard disabled", lastSynced: now.Add(-2 * time.Hour), wantRemoved: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &mockStore{
+				state: &State{
+					Keys: map[string]*KeyState{
+						"old": {KeyID: "old", Key: bridge.JWK{Kid: "old", Kty: "RSA"}, LastSeen: markedAt, MarkedForRemoval: &markedAt},
+						"new": {KeyID: "new", Key: bridge.JWK{Kid: "new", Kty: "RSA"}, LastSeen: tt.lastSynced},
+					},
+				},
+			}
+			manager := NewManager(store, Config{OverlapPeriod: 24 * time.Hour, CleanupMaxSourceAge: tt.maxAge}, logger)
+			manager.SetTimeFunc(func() time.Time { return now })
+
+			events, err := manager.CleanupExpiredKeys(context.Background())
+			require.NoError(t, err)
+			if tt.wantRemoved {
+				require.Len(t, events, 1)
+				assert.Equal(t, "old", events[0].KeyID)
+				assert.NotContains(t, store.state.Keys, "old")
+			} else {
+				assert.Empty(t, events)
+				assert.Contains(t, store.state.Keys, "old")
+			}
+		})
+	}
+}
+
+func TestRotationManager_ProcessJWKS_StaleSource(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	now := time.Now()
+	markedAt := now.Add(-25 * time.Hour) // Past the 24h overlap
+	current := &bridge.JWKS{Keys: []bridge.JWK{{Kid: "new", Kty: "RSA"}}}
+
+	tests := []struct {
+		name        string
+		lastSynced  time.Time
+		wantRemoved bool
+	}{
+		{name: "fresh source", lastSynced: now.Add(-time.Minute), wantRemoved: true},
+		{name: "stale source", lastSynced: now.Add(-2 * time.Hour), wantRemoved: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &mockStore{
+				state: &State{
+					Keys: map[string]*KeyState{
+						"old": {KeyID: "old", Key: bridge.JWK{Kid: "old", Kty: "RSA"}, LastSeen: markedAt, MarkedForRemoval: &markedAt},
+						"new": {KeyID: "new", Key: bridge.JWK{Kid: "new", Kty: "RSA"}, LastSeen: tt.lastSynced},
+					},
+				},
+			}
+			manager := NewManager(store, Config{OverlapPeriod: 24 * time.Hour, CleanupMaxSourceAge: time.Hour}, logger)
+			manager.SetTimeFunc(func() time.Time { return now })
+
+			_, _, err := manager.ProcessJWKS(context.Background(), current)
+			require.NoError(t, err)
+			if tt.wantRemoved {
+				assert.NotContains(t, store.state.Keys, "old")
+			} else {
+				assert.Contains(t, store.state.Keys, "old")
+			}
+			// The sync itself is recorded either way, so the next one cleans up
+			assert.Equal(t, now, store.state.Keys["new"].LastSeen)
+		})
+	}
+}
+
+func TestRotationManager_ProcessJWKS_MissingGrace(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	both := &bridge.JWKS{Keys: []bridge.JWK{{Kid: "key1", Kty: "RSA"}, {Kid: "key2", Kty: "RSA"}}}
+	partial := &bridge.JWKS{Keys: []bridge.JWK{{Kid: "key2", Kty: "RSA"}}}
+
+	tests := []struct {
+		name       string
+		config     Config
+		syncs      []*bridge.JWKS
+		wantMarked bool
+	}{
+		{
+			name:       "absent once then present is never marked",
+			config:     Config{OverlapPeriod: 24 * time.Hour, MissingThreshold: 2},
+			syncs:      []*bridge.JWKS{both, partial, both, partial},
+			wantMarked: false,
+		},
+		{
+			name:       "absent for the threshold is marked",
+			config:     Config{OverlapPeriod: 24 * time.Hour, MissingThreshold: 2},
+			syncs:      []*bridge.JWKS{both, partial, partial},
+			wantMarked: true,
+		},
+		{
+			name:       "zero threshold marks on first absence",
+			config:     Config{OverlapPeriod: 24 * time.Hour},
+			syncs:      []*bridge.JWKS{both, partial},
+			wantMarked: true,
+		},
+		{
+			name:       "grace period not yet elapsed",
+			config:     Config{OverlapPeriod: 24 * time.Hour, MissingThreshold: 1, MissingGracePeriod: 30 * time.Minute},
+			syncs:      []*bridge.JWKS{both, partial},
+			wantMarked: false,
+		},
+		{
+			name:       "grace period elapsed",
+			config:     Config{OverlapPeriod: 24 * time.Hour, MissingThreshold: 1, MissingGracePeriod: 30 * time.Minute},
+			syncs:      []*bridge.JWKS{both, partial, partial},
+			wantMarked: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &mockStore{state: emptyState()}
+			manager := NewManager(store, tt.config, logger)
+			now := time.Now()
+			ctx := context.Background()
+
+			// Each sync happens 15 minutes after the previous one
+			for _, jwks := range tt.syncs {
+				manager.SetTimeFunc(func() time.Time { return now })
+				merged, _, err := manager.ProcessJWKS(ctx, jwks)
+				require.NoError(t, err)
+				assert.True(t, hasKey(merged, "key1"), "missing key must still be published")
+				now = now.Add(15 * time.Minute)
+			}
+
+			key1 := store.state.Keys["key1"]
+			require.NotNil(t, key1)
+			assert.Equal(t, tt.wantMarked, key1.MarkedForRemoval != nil)
+		})
+	}
+}
+
+func TestRotationManager_PreservesCertificateChain(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	store := &mockStore{state: emptyState()}
+	manager := NewManager(store, Config{OverlapPeriod: 24 * time.Hour}, logger)
+	ctx := context.Background()
+
+	var source bridge.JWKS
+	require.NoError(t, json.Unmarshal([]byte(`{"keys":[`+
+		`{"kty":"RSA","kid":"key1","n":"AQAB","e":"AQAB"},`+
+		`{"kty":"RSA","kid":"key2","n":"AQAB","e":"AQAB","x5c":["MIIBszCCAVmgAwIBAgIU"],"x5t":"dGh1bWJwcmludA"}]}`), &source))
+
+	// The chain is picked up for a key the state already tracks
+	_, _, err := manager.ProcessJWKS(ctx, &bridge.JWKS{Keys: []bridge.JWK{{Kid: "key2", Kty: "RSA", N: "AQAB", E: "AQAB"}}})
+	require.NoError(t, err)
+	_, _, err = manager.ProcessJWKS(ctx, &source)
+	require.NoError(t, err)
+
+	// The persisted state keeps the chain for the overlap period after key2 leaves the source
+	data, err := json.Marshal(store.state)
+	require.NoError(t, err)
+	store.state, err = DecodeState(data)
+	require.NoError(t, err)
+	merged, _, err := manager.ProcessJWKS(ctx, &bridge.JWKS{Keys: source.Keys[:1]})
+	require.NoError(t, err)
+
+	published, err := json.Marshal(merged)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"keys":[`+
+		`{"kty":"RSA","kid":"key1","n":"AQAB","e":"AQAB"},`+
+		`{"kty":"RSA","kid":"key2","n":"AQAB","e":"AQAB","x5c":["MIIBszCCAVmgAwIBAgIU"],"x5t":"dGh1bWJwcmludA"}]}`, string(published))
+}
+
+func TestRotationManager_KeyOrder(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	keyState := func(kid string, firstSeen, lastSeen time.Time) *KeyState {
+		return &KeyState{KeyID: kid, Key: bridge.JWK{Kid: kid, Kty: "RSA"}, FirstSeen: firstSeen, LastSeen: lastSeen}
+	}
+	newState := func() *State {
+		return &State{Keys: map[string]*KeyState{
+			"key-a": keyState("key-a", base.Add(2*time.Hour), base.Add(3*time.Hour)),
+			"key-b": keyState("key-b", base, base.Add(3*time.Hour)),
+			"key-c": keyState("key-c", base.Add(2*time.Hour), base.Add(3*time.Hour)),
+			"key-d": keyState("key-d", base.Add(2*time.Hour), base.Add(4*time.Hour)),
+		}}
+	}
+
+	tests := []struct {
+		name  string
+		order KeyOrder
+		want  []string
+	}{
+		{name: "default sorts by kid", want: []string{"key-a", "key-b", "key-c", "key-d"}},
+		{name: "kid", order: KeyOrderKid, want: []string{"key-a", "key-b", "key-c", "key-d"}},
+		// key-d shares FirstSeen with key-a and key-c but was seen last; key-a and key-c tie on both
+		{name: "newest first", order: KeyOrderNewestFirst, want: []string{"key-d", "key-a", "key-c", "key-b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewManager(&mockStore{state: newState()}, Config{OverlapPeriod: 24 * time.Hour, KeyOrder: tt.order}, logger)
+
+			result, err := manager.GetPublishableJWKS(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, bridge.GetKeyIDs(result))
+
+			// Equal inputs publish byte-identical JWKS regardless of map iteration order
+			first, err := result.ToJSON()
+			require.NoError(t, err)
+			for i := 0; i < 10; i++ {
+				again, err := manager.GetPublishableJWKS(context.Background())
+				require.NoError(t, err)
+				data, err := again.ToJSON()
+				require.NoError(t, err)
+				assert.Equal(t, first, data)
+			}
+		})
+	}
+}
+
+func TestRotationManager_ProcessJWKS_NewestFirst(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	store := &mockStore{state: emptyState()}
+	manager := NewManager(store, Config{OverlapPeriod: 24 * time.Hour, MissingThreshold: 1, KeyOrder: KeyOrderNewestFirst}, logger)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	manager.SetTimeFunc(func() time.Time { return now })
+	_, _, err := manager.ProcessJWKS(ctx, &bridge.JWKS{Keys: []bridge.JWK{{Kid: "old", Kty: "RSA"}}})
+	require.NoError(t, err)
+
+	// The rotated-in key is listed first; the retired key stays published in its overlap period
+	now = now.Add(time.Hour)
+	merged, _, err := manager.ProcessJWKS(ctx, &bridge.JWKS{Keys: []bridge.JWK{{Kid: "new", Kty: "RSA"}}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new", "old"}, bridge.GetKeyIDs(merged))
+}
+
 func TestRotationManager_GetState(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	now := time.Now()
@@ -220,6 +470,79 @@ func TestRotationManager_GetState(t *testing.T) {
 	assert.Len(t, state.Keys, 1)
 }
 
+func TestRotationManager_PublishedHash(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	store := &mockStore{state: emptyState()}
+	manager := NewManager(store, DefaultConfig(), logger)
+	ctx := context.Background()
+
+	hash, err := manager.GetPublishedHash(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, hash)
+
+	require.NoError(t, manager.SetPublishedHash(ctx, "abc123"))
+
+	// Key rotation processing must not drop the persisted hash
+	_, _, err = manager.ProcessJWKS(ctx, &bridge.JWKS{Keys: []bridge.JWK{{Kid: "key1", Kty: "RSA"}}})
+	require.NoError(t, err)
+
+	hash, err = manager.GetPublishedHash(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", hash)
+}
+
+// writeCheckStore is a mockStore that also implements WriteChecker.
+type writeCheckStore struct {
+	mockStore
+	writeErr error
+}
+
+func (w *writeCheckStore) CheckWrite(context.Context) error {
+	return w.writeErr
+}
+
+func TestRotationManager_HealthCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		store   Store
+		wantErr string
+	}{
+		{
+			name:  "readable store",
+			store: &mockStore{},
+		},
+		{
+			name:    "load fails",
+			store:   &mockStore{err: errors.New("configmaps is forbidden")},
+			wantErr: "failed to load rotation state",
+		},
+		{
+			name:  "writable store",
+			store: &writeCheckStore{},
+		},
+		{
+			name:    "write check fails",
+			store:   &writeCheckStore{writeErr: errors.New("update is forbidden")},
+			wantErr: "rotation state is not writable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+			manager := NewManager(tt.store, DefaultConfig(), logger)
+
+			err := manager.HealthCheck(context.Background())
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
 func TestCreateNewKeyEvent(t *testing.T) {
 	now := time.Now()
 	event := createNewKeyEvent("key123", now)
@@ -1,6 +1,7 @@
 package rotation
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -20,6 +21,12 @@ type Store interface {
 	Save(ctx context.Context, state *State) error
 }
 
+// WriteChecker is optionally implemented by stores that can verify write access
+// without modifying the persisted state.
+type WriteChecker interface {
+	CheckWrite(ctx context.Context) error
+}
+
 // ConfigMapStore implements Store using a Kubernetes ConfigMap.
 type ConfigMapStore struct {
 	client    kubernetes.Interface
@@ -98,6 +105,30 @@ func (s *ConfigMapStore) Save(ctx context.Context, state *State) error {
 	return s.updateConfigMap(ctx, cm, data)
 }
 
+// CheckWrite verifies the ConfigMap can be written by issuing a server-side dry-run
+// update (or create, when the ConfigMap does not exist yet). Nothing is persisted.
+func (s *ConfigMapStore) CheckWrite(ctx context.Context) error {
+	configMaps := s.client.CoreV1().ConfigMaps(s.namespace)
+	dryRun := []string{metav1.DryRunAll}
+
+	cm, err := configMaps.Get(ctx, s.name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get ConfigMap: %w", err)
+		}
+		cm = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: s.name, Namespace: s.namespace}}
+		if _, err := configMaps.Create(ctx, cm, metav1.CreateOptions{DryRun: dryRun}); err != nil {
+			return fmt.Errorf("dry-run create of ConfigMap failed: %w", err)
+		}
+		return nil
+	}
+
+	if _, err := configMaps.Update(ctx, cm, metav1.UpdateOptions{DryRun: dryRun}); err != nil {
+		return fmt.Errorf("dry-run update of ConfigMap failed: %w", err)
+	}
+	return nil
+}
+
 // createConfigMap creates a new ConfigMap for rotation state.
 func (s *ConfigMapStore) createConfigMap(ctx context.Context, data []byte) error {
 	cm := &corev1.ConfigMap{
@@ -136,6 +167,44 @@ func (s *ConfigMapStore) updateConfigMap(ctx context.Context, cm *corev1.ConfigM
 	return nil
 }
 
+// DecodeState parses serialized rotation state, as written by ConfigMapStore, and
+// validates it. Unknown fields are rejected so that a file from an incompatible
+// version is not silently truncated.
+func DecodeState(data []byte) (*State, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var state State
+	if err := dec.Decode(&state); err != nil {
+		return nil, fmt.Errorf("failed to decode state: %w", err)
+	}
+	if err := state.Validate(); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// Validate checks that the state is well formed: keys are present and indexed by
+// their own key ID, and the version is not negative.
+func (s *State) Validate() error {
+	if s.Keys == nil {
+		return fmt.Errorf("invalid state: keys is missing")
+	}
+	if s.Version < 0 {
+		return fmt.Errorf("invalid state: version must not be negative, got %d", s.Version)
+	}
+	for kid, key := range s.Keys {
+		switch {
+		case key == nil:
+			return fmt.Errorf("invalid state: key %q is null", kid)
+		case kid == "" || key.KeyID != kid:
+			return fmt.Errorf("invalid state: key %q has keyId %q", kid, key.KeyID)
+		case key.Key.Kid != "" && key.Key.Kid != kid:
+			return fmt.Errorf("invalid state: key %q holds a JWK with kid %q", kid, key.Key.Kid)
+		}
+	}
+	return nil
+}
+
 // emptyState returns an empty rotation state.
 func emptyState() *State {
 	return &State{
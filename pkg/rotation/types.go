@@ -44,6 +44,8 @@ type KeyState struct {
 	LastSeen time.Time `json:"lastSeen"`
 	// MarkedForRemoval is set when key disappears from source
 	MarkedForRemoval *time.Time `json:"markedForRemoval,omitempty"`
+	// MissingCount is the number of consecutive syncs the key has been absent from the source JWKS
+	MissingCount int `json:"missingCount,omitempty"`
 }
 
 // State represents the complete rotation state.
@@ -54,6 +56,37 @@ type State struct {
 	LastUpdated time.Time `json:"lastUpdated"`
 	// Version is for optimistic locking
 	Version int64 `json:"version"`
+	// PublishedHash is the content hash of the last successful publish, kept so a
+	// restarted controller can skip re-uploading unchanged metadata
+	PublishedHash string `json:"publishedHash,omitempty"`
+}
+
+// Clone returns a deep copy of the state, for processing it without changing the original.
+func (s *State) Clone() *State {
+	clone := *s
+	clone.Keys = make(map[string]*KeyState, len(s.Keys))
+	for keyID, keyState := range s.Keys {
+		keyClone := *keyState
+		if keyState.MarkedForRemoval != nil {
+			markedForRemoval := *keyState.MarkedForRemoval
+			keyClone.MarkedForRemoval = &markedForRemoval
+		}
+		clone.Keys[keyID] = &keyClone
+	}
+	return &clone
+}
+
+// LastSynced returns when a source JWKS was last applied to the state: the newest
+// LastSeen of any key, since every synced JWKS has at least one key. It is zero
+// when the state has no keys.
+func (s *State) LastSynced() time.Time {
+	var last time.Time
+	for _, keyState := range s.Keys {
+		if keyState.LastSeen.After(last) {
+			last = keyState.LastSeen
+		}
+	}
+	return last
 }
 
 // Config holds configuration for the rotation manager.
@@ -61,17 +94,47 @@ type Config struct {
 	// OverlapPeriod is how long to keep old keys after they disappear
 	// Default: 24 hours
 	OverlapPeriod time.Duration
+	// MissingThreshold is how many consecutive syncs a key must be absent
+	// before it is marked for removal, so one partial fetch does not start the clock.
+	// Values below 1 mark on the first absence. Default: 2
+	MissingThreshold int
+	// MissingGracePeriod is how long a key must have been absent (since LastSeen)
+	// before it is marked for removal. Applied in addition to MissingThreshold.
+	// Default: 0
+	MissingGracePeriod time.Duration
 	// Namespace is the K8s namespace for the state ConfigMap
 	Namespace string
 	// ConfigMapName is the name of the ConfigMap for storing state
 	ConfigMapName string
+	// KeyOrder is the order of keys in the published JWKS
+	// Default: KeyOrderKid
+	KeyOrder KeyOrder
+	// CleanupMaxSourceAge makes ProcessJWKS and CleanupExpiredKeys skip cleanup when
+	// the source JWKS was last synced longer ago than this: keys marked for removal
+	// while the source was unreachable may still be valid. 0 never skips.
+	// Default: 0
+	CleanupMaxSourceAge time.Duration
 }
 
+// KeyOrder selects the order of keys in the published JWKS.
+type KeyOrder string
+
+const (
+	// KeyOrderKid sorts keys by kid.
+	KeyOrderKid KeyOrder = "kid"
+	// KeyOrderNewestFirst sorts the most recently first-seen keys first, so relying
+	// parties that try keys in order reach the key signing fresh tokens sooner.
+	// Ties are broken by LastSeen, then by kid.
+	KeyOrderNewestFirst KeyOrder = "newestFirst"
+)
+
 // DefaultConfig returns a Config with sensible defaults.
 func DefaultConfig() Config {
 	return Config{
-		OverlapPeriod: 24 * time.Hour,
-		Namespace:     "kubeassume-system",
-		ConfigMapName: "kubeassume-rotation-state",
+		OverlapPeriod:    24 * time.Hour,
+		MissingThreshold: 2,
+		Namespace:        "kubeassume-system",
+		ConfigMapName:    "kubeassume-rotation-state",
+		KeyOrder:         KeyOrderKid,
 	}
 }
@@ -13,8 +13,10 @@ func TestDefaultConfig(t *testing.T) {
 	config := DefaultConfig()
 
 	assert.Equal(t, 24*time.Hour, config.OverlapPeriod)
+	assert.Equal(t, 2, config.MissingThreshold)
 	assert.Equal(t, "kubeassume-system", config.Namespace)
 	assert.Equal(t, "kubeassume-rotation-state", config.ConfigMapName)
+	assert.Equal(t, KeyOrderKid, config.KeyOrder)
 }
 
 func TestEventType_Constants(t *testing.T) {
@@ -74,6 +76,27 @@ func TestState(t *testing.T) {
 	assert.Equal(t, int64(1), state.Version)
 }
 
+func TestState_Clone(t *testing.T) {
+	now := time.Now()
+	removal := now.Add(-time.Hour)
+	state := &State{
+		Keys: map[string]*KeyState{
+			"key1": {KeyID: "key1", Key: bridge.JWK{Kid: "key1", Kty: "RSA"}, MarkedForRemoval: &removal},
+		},
+		Version: 3,
+	}
+
+	clone := state.Clone()
+	assert.Equal(t, state, clone)
+
+	clone.Keys["key1"].MissingCount = 5
+	*clone.Keys["key1"].MarkedForRemoval = now
+	clone.Keys["key2"] = &KeyState{KeyID: "key2"}
+	assert.Len(t, state.Keys, 1)
+	assert.Zero(t, state.Keys["key1"].MissingCount)
+	assert.Equal(t, removal, *state.Keys["key1"].MarkedForRemoval)
+}
+
 func TestState_EmptyKeys(t *testing.T) {
 	now := time.Now()
 	state := &State{
@@ -141,3 +164,36 @@ func TestConfig_CustomValues(t *testing.T) {
 	assert.Equal(t, "custom-ns", config.Namespace)
 	assert.Equal(t, "custom-state", config.ConfigMapName)
 }
+
+func TestDecodeState(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{
+			name: "valid state",
+			data: `{"keys":{"key-1":{"keyId":"key-1","key":{"kid":"key-1","kty":"RSA"},"markedForRemoval":"2026-01-01T00:00:00Z"}},"version":3}`,
+		},
+		{name: "empty keys", data: `{"keys":{},"version":0}`},
+		{name: "missing keys", data: `{"version":1}`, wantErr: "keys is missing"},
+		{name: "negative version", data: `{"keys":{},"version":-1}`, wantErr: "version"},
+		{name: "key ID mismatch", data: `{"keys":{"key-1":{"keyId":"key-2"}},"version":1}`, wantErr: `key "key-1" has keyId "key-2"`},
+		{name: "JWK kid mismatch", data: `{"keys":{"key-1":{"keyId":"key-1","key":{"kid":"key-2"}}},"version":1}`, wantErr: "JWK with kid"},
+		{name: "null key", data: `{"keys":{"key-1":null},"version":1}`, wantErr: "is null"},
+		{name: "unknown field", data: `{"keys":{},"version":1,"owner":"x"}`, wantErr: "unknown field"},
+		{name: "not JSON", data: `keys: {}`, wantErr: "failed to decode state"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := DecodeState([]byte(tt.data))
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, state.Keys)
+		})
+	}
+}
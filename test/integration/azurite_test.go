@@ -0,0 +1,89 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/azure"
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
+)
+
+const (
+	azuriteServiceURL = "http://localhost:10000/devstoreaccount1"
+	azuriteContainer  = "oidc"
+
+	// azuriteAccount and azuriteAccountKey are Azurite's well-known development credentials
+	azuriteAccount    = "devstoreaccount1"
+	azuriteAccountKey = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+)
+
+// ensureAzuriteContainer creates the test container with anonymous blob read access
+// if it does not exist.
+func ensureAzuriteContainer(t *testing.T) {
+	t.Helper()
+	cred, err := azblob.NewSharedKeyCredential(azuriteAccount, azuriteAccountKey)
+	require.NoError(t, err)
+	client, err := azblob.NewClientWithSharedKeyCredential(azuriteServiceURL+"/", cred, nil)
+	require.NoError(t, err)
+
+	_, err = client.CreateContainer(context.Background(), azuriteContainer, &azblob.CreateContainerOptions{
+		Access: to.Ptr(container.PublicAccessTypeBlob),
+	})
+	if err != nil && !bloberror.HasCode(err, bloberror.ContainerAlreadyExists) {
+		require.NoError(t, err, "create container %s", azuriteContainer)
+	}
+}
+
+func newAzuritePublisher(t *testing.T, prefix string, multiCluster bool, clusterID string) iface.Publisher {
+	t.Helper()
+	ensureAzuriteContainer(t)
+	pub, err := azure.New(context.Background(), azure.Config{
+		StorageAccount:      azuriteAccount,
+		Container:           azuriteContainer,
+		Prefix:              prefix,
+		ServiceURL:          azuriteServiceURL,
+		AccountKey:          azuriteAccountKey,
+		MultiClusterEnabled: multiCluster,
+		ClusterID:           clusterID,
+	}, slog.Default())
+	require.NoError(t, err)
+	return pub
+}
+
+// TestAzurite_SingleCluster_Publish verifies that a single-cluster publish results
+// in correct discovery and JWKS documents accessible via HTTP.
+func TestAzurite_SingleCluster_Publish(t *testing.T) {
+	pub := newAzuritePublisher(t, uniquePrefix("single-cluster-test"), false, "")
+	require.NoError(t, pub.Validate(context.Background()))
+	require.NoError(t, pub.HealthCheck(context.Background()))
+	assertSingleClusterPublish(t, pub)
+}
+
+// TestAzurite_ConcurrentPublish verifies that replicas publishing at the same time
+// are resolved by ETag preconditions without errors.
+func TestAzurite_ConcurrentPublish(t *testing.T) {
+	prefix := uniquePrefix("concurrent-test")
+	pubs := make([]iface.Publisher, 5)
+	for i := range pubs {
+		pubs[i] = newAzuritePublisher(t, prefix, false, "")
+	}
+	assertConcurrentPublish(t, pubs)
+}
+
+// TestAzurite_MultiCluster_Aggregation verifies the per-cluster JWKS written by three
+// clusters are aggregated into the root JWKS.
+func TestAzurite_MultiCluster_Aggregation(t *testing.T) {
+	clusterGroup := uniquePrefix("prod-test")
+	assertAggregationRoundTrip(t, func(clusterID string) iface.Publisher {
+		return newAzuritePublisher(t, clusterGroup, true, clusterID)
+	})
+}
@@ -0,0 +1,89 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/gcs"
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
+)
+
+const (
+	fakeGCSEndpoint = "http://localhost:4443"
+	fakeGCSBucket   = "oidc"
+	fakeGCSProject  = "kubeassume-test"
+)
+
+// ensureFakeGCSBucket creates the test bucket in fake-gcs-server if it does not exist.
+func ensureFakeGCSBucket(t *testing.T) {
+	t.Helper()
+	ctx := context.Background()
+	client, err := storage.NewClient(ctx,
+		option.WithEndpoint(fakeGCSEndpoint+"/storage/v1/"),
+		option.WithoutAuthentication(),
+	)
+	require.NoError(t, err)
+	defer client.Close()
+
+	err = client.Bucket(fakeGCSBucket).Create(ctx, fakeGCSProject, nil)
+	var gerr *googleapi.Error
+	if err != nil && !(errors.As(err, &gerr) && gerr.Code == http.StatusConflict) {
+		require.NoError(t, err, "create bucket %s", fakeGCSBucket)
+	}
+}
+
+func newFakeGCSPublisher(t *testing.T, prefix string, multiCluster bool, clusterID string) iface.Publisher {
+	t.Helper()
+	ensureFakeGCSBucket(t)
+	cfg := gcs.DefaultConfig()
+	cfg.Bucket = fakeGCSBucket
+	cfg.Project = fakeGCSProject
+	cfg.Endpoint = fakeGCSEndpoint
+	cfg.Prefix = prefix
+	cfg.MultiClusterEnabled = multiCluster
+	cfg.ClusterID = clusterID
+
+	pub, err := gcs.New(context.Background(), cfg, slog.Default())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = pub.Close() })
+	return pub
+}
+
+// TestFakeGCS_SingleCluster_Publish verifies that a single-cluster publish results
+// in correct discovery and JWKS documents accessible via HTTP.
+func TestFakeGCS_SingleCluster_Publish(t *testing.T) {
+	pub := newFakeGCSPublisher(t, uniquePrefix("single-cluster-test"), false, "")
+	require.NoError(t, pub.Validate(context.Background()))
+	require.NoError(t, pub.HealthCheck(context.Background()))
+	assertSingleClusterPublish(t, pub)
+}
+
+// TestFakeGCS_ConcurrentPublish verifies that replicas publishing at the same time
+// are resolved by generation preconditions without errors.
+func TestFakeGCS_ConcurrentPublish(t *testing.T) {
+	prefix := uniquePrefix("concurrent-test")
+	pubs := make([]iface.Publisher, 5)
+	for i := range pubs {
+		pubs[i] = newFakeGCSPublisher(t, prefix, false, "")
+	}
+	assertConcurrentPublish(t, pubs)
+}
+
+// TestFakeGCS_MultiCluster_Aggregation verifies the per-cluster JWKS written by three
+// clusters are aggregated into the root JWKS.
+func TestFakeGCS_MultiCluster_Aggregation(t *testing.T) {
+	clusterGroup := uniquePrefix("prod-test")
+	assertAggregationRoundTrip(t, func(clusterID string) iface.Publisher {
+		return newFakeGCSPublisher(t, clusterGroup, true, clusterID)
+	})
+}
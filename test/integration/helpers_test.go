@@ -0,0 +1,133 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hixichen/kube-iam-assume/pkg/bridge"
+	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
+)
+
+// newDiscovery returns a discovery document for the given issuer.
+func newDiscovery(issuer string) *bridge.DiscoveryDocument {
+	return &bridge.DiscoveryDocument{
+		Issuer:                  issuer,
+		JWKSURI:                 issuer + "/openid/v1/jwks",
+		ResponseTypesSupported:  []string{"id_token"},
+		SubjectTypesSupported:   []string{"public"},
+		IDTokenSigningAlgValues: []string{"RS256"},
+	}
+}
+
+// uniquePrefix returns a storage prefix that does not collide with other test runs.
+func uniquePrefix(name string) string {
+	return fmt.Sprintf("%s-%d", name, time.Now().UnixNano())
+}
+
+// assertSingleClusterPublish publishes one key and verifies the discovery document
+// and JWKS are publicly readable under the publisher's public URL.
+func assertSingleClusterPublish(t *testing.T, pub iface.Publisher) {
+	t.Helper()
+	ctx := context.Background()
+
+	jwks := &bridge.JWKS{Keys: []bridge.JWK{{Kid: "test-key-1", Kty: "RSA", N: "abc123", E: "AQAB"}}}
+	require.NoError(t, pub.Publish(ctx, newDiscovery(pub.GetPublicURL()), jwks))
+
+	var gotDiscovery bridge.DiscoveryDocument
+	fetchJSON(t, pub.GetPublicURL()+"/.well-known/openid-configuration", &gotDiscovery)
+	assert.Equal(t, pub.GetPublicURL(), gotDiscovery.Issuer, "issuer must match public URL")
+
+	var gotJWKS bridge.JWKS
+	fetchJSON(t, pub.GetPublicURL()+"/openid/v1/jwks", &gotJWKS)
+	require.Len(t, gotJWKS.Keys, 1)
+	assert.Equal(t, "test-key-1", gotJWKS.Keys[0].Kid)
+}
+
+// assertConcurrentPublish publishes a different key from every publisher at the same
+// time. All publishers share one path; losing a precondition race is not an error,
+// and the object must end up holding one complete write.
+func assertConcurrentPublish(t *testing.T, pubs []iface.Publisher) {
+	t.Helper()
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(pubs))
+	for i, pub := range pubs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			jwks := &bridge.JWKS{Keys: []bridge.JWK{{Kid: fmt.Sprintf("replica-%d", i), Kty: "RSA", N: "abc", E: "AQAB"}}}
+			errs[i] = pub.Publish(ctx, newDiscovery(pub.GetPublicURL()), jwks)
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		assert.NoError(t, err, "replica %d", i)
+	}
+
+	// The winning write is intact, not interleaved with a losing one
+	var gotJWKS bridge.JWKS
+	fetchJSON(t, pubs[0].GetPublicURL()+"/openid/v1/jwks", &gotJWKS)
+	require.Len(t, gotJWKS.Keys, 1)
+	assert.Regexp(t, `^replica-\d+$`, gotJWKS.Keys[0].Kid)
+}
+
+// assertAggregationRoundTrip has three clusters of one group publish their JWKS, then
+// aggregates them through the publisher returned for the first cluster and verifies
+// the root JWKS and discovery document.
+func assertAggregationRoundTrip(t *testing.T, newPublisher func(clusterID string) iface.Publisher) {
+	t.Helper()
+	ctx := context.Background()
+
+	clusters := []struct {
+		id  string
+		kid string
+	}{
+		{"cluster-a", "key-a"},
+		{"cluster-b", "key-b"},
+		{"cluster-c", "key-c"},
+	}
+	for _, cl := range clusters {
+		pub := newPublisher(cl.id)
+		jwks := &bridge.JWKS{Keys: []bridge.JWK{{Kid: cl.kid, Kty: "RSA", N: "abc", E: "AQAB"}}}
+		require.NoError(t, pub.Publish(ctx, newDiscovery(pub.GetPublicURL()), jwks), "cluster %s", cl.id)
+	}
+
+	aggPub := newPublisher(clusters[0].id)
+	agg, ok := aggPub.(iface.MultiClusterAggregator)
+	require.True(t, ok, "%s publisher must implement MultiClusterAggregator", aggPub.Type())
+
+	clusterJWKS, err := agg.ListClusterJWKS(ctx)
+	require.NoError(t, err)
+	require.Len(t, clusterJWKS, len(clusters))
+	lastModified, err := agg.GetClusterLastModified(ctx)
+	require.NoError(t, err)
+	assert.Len(t, lastModified, len(clusters))
+
+	merged := &bridge.JWKS{}
+	for _, cl := range clusters {
+		require.Contains(t, clusterJWKS, cl.id)
+		merged.Keys = append(merged.Keys, clusterJWKS[cl.id].Keys...)
+	}
+	require.NoError(t, agg.PublishAggregatedJWKS(ctx, merged))
+	require.NoError(t, agg.PublishRootDiscovery(ctx, newDiscovery(aggPub.GetPublicURL())))
+
+	var gotJWKS bridge.JWKS
+	fetchJSON(t, aggPub.GetPublicURL()+"/openid/v1/jwks", &gotJWKS)
+	kids := bridge.GetKeyIDs(&gotJWKS)
+	for _, cl := range clusters {
+		assert.Contains(t, kids, cl.kid)
+	}
+
+	var gotDiscovery bridge.DiscoveryDocument
+	fetchJSON(t, aggPub.GetPublicURL()+"/.well-known/openid-configuration", &gotDiscovery)
+	assert.Equal(t, aggPub.GetPublicURL(), gotDiscovery.Issuer)
+}
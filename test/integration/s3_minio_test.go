@@ -1,7 +1,7 @@
 //go:build integration
 
 // Package integration contains integration tests that require running infrastructure.
-// Start MinIO first: cd local-test && docker compose up -d
+// Start MinIO and the GCS and Azure emulators first: cd local-test && docker compose up -d
 // Then run: go test -tags=integration ./test/integration/...
 package integration
 
@@ -10,11 +10,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
 	"github.com/hixichen/kube-iam-assume/pkg/bridge"
+	"github.com/hixichen/kube-iam-assume/pkg/faultinject"
 	"github.com/hixichen/kube-iam-assume/pkg/publisher/iface"
 	s3pub "github.com/hixichen/kube-iam-assume/pkg/publisher/s3"
 	"github.com/stretchr/testify/assert"
@@ -25,6 +31,9 @@ const (
 	minioEndpoint = "http://localhost:9000"
 	minioBucket   = "oidc"
 	minioRegion   = "us-east-1"
+
+	// minioSecondaryEndpoint stands in for a secondary-region bucket
+	minioSecondaryEndpoint = "http://localhost:9100"
 )
 
 func newMinioPublisher(t *testing.T, prefix string, multiCluster bool, clusterID string) iface.Publisher {
@@ -157,4 +166,149 @@ func TestMinIO_MultiCluster_Aggregation(t *testing.T) {
 	for _, cl := range clusters {
 		assert.Contains(t, kids, cl.kid)
 	}
+	// Only the aggregation leader writes the root discovery document in group mode
+	rootDiscovery := &bridge.DiscoveryDocument{
+		Issuer:                  aggPub.GetPublicURL(),
+		JWKSURI:                 aggPub.GetPublicURL() + "/openid/v1/jwks",
+		ResponseTypesSupported:  []string{"id_token"},
+		SubjectTypesSupported:   []string{"public"},
+		IDTokenSigningAlgValues: []string{"RS256"},
+	}
+	require.NoError(t, agg.PublishRootDiscovery(ctx, rootDiscovery))
+
+	var gotDiscovery bridge.DiscoveryDocument
+	fetchJSON(t, aggPub.GetPublicURL()+"/.well-known/openid-configuration", &gotDiscovery)
+	assert.Equal(t, aggPub.GetPublicURL(), gotDiscovery.Issuer)
+}
+
+// TestMinIO_ObjectTags verifies that published discovery and JWKS objects carry
+// the standard management tags plus any configured extra tags.
+func TestMinIO_ObjectTags(t *testing.T) {
+	ctx := context.Background()
+	clusterGroup := fmt.Sprintf("tags-test-%d", time.Now().UnixNano())
+
+	pub, err := s3pub.New(ctx, s3pub.Config{
+		Bucket:              minioBucket,
+		Region:              minioRegion,
+		Endpoint:            minioEndpoint,
+		ForcePathStyle:      true,
+		Prefix:              clusterGroup,
+		MultiClusterEnabled: true,
+		ClusterID:           "cluster-a",
+		ObjectTags:          iface.ObjectTags("cluster-a", clusterGroup, map[string]string{"team": "platform"}),
+	}, nil)
+	require.NoError(t, err)
+
+	discovery := &bridge.DiscoveryDocument{
+		Issuer:                  pub.GetPublicURL(),
+		JWKSURI:                 pub.GetPublicURL() + "/openid/v1/jwks",
+		ResponseTypesSupported:  []string{"id_token"},
+		SubjectTypesSupported:   []string{"public"},
+		IDTokenSigningAlgValues: []string{"RS256"},
+	}
+	jwks := &bridge.JWKS{Keys: []bridge.JWK{{Kid: "key-a", Kty: "RSA", N: "abc", E: "AQAB"}}}
+	require.NoError(t, pub.Publish(ctx, discovery, jwks))
+	agg, ok := pub.(iface.MultiClusterAggregator)
+	require.True(t, ok)
+	require.NoError(t, agg.PublishRootDiscovery(ctx, discovery))
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(minioRegion))
+	require.NoError(t, err)
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(minioEndpoint)
+		o.UsePathStyle = true
+	})
+
+	expected := map[string]string{
+		iface.TagManagedBy: "kube-iam-assume",
+		iface.TagCluster:   "cluster-a",
+		iface.TagGroup:     clusterGroup,
+		"team":             "platform",
+	}
+	for _, key := range []string{
+		clusterGroup + "/.well-known/openid-configuration",
+		clusterGroup + "/clusters/cluster-a/openid/v1/jwks",
+	} {
+		out, err := client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+			Bucket: aws.String(minioBucket),
+			Key:    aws.String(key),
+		})
+		require.NoError(t, err, "get tagging for %s", key)
+
+		got := make(map[string]string, len(out.TagSet))
+		for _, tag := range out.TagSet {
+			got[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+		assert.Equal(t, expected, got, "tags on %s", key)
+	}
+}
+
+// TestMinIO_SecondaryBucket_DualWrite verifies that with a secondary bucket every
+// object is written to both MinIO endpoints and the health check covers both.
+func TestMinIO_SecondaryBucket_DualWrite(t *testing.T) {
+	ctx := context.Background()
+	prefix := fmt.Sprintf("secondary-test-%d", time.Now().UnixNano())
+
+	pub, err := s3pub.New(ctx, s3pub.Config{
+		Bucket:         minioBucket,
+		Region:         minioRegion,
+		Endpoint:       minioEndpoint,
+		ForcePathStyle: true,
+		Prefix:         prefix,
+		Secondary: &s3pub.SecondaryConfig{
+			Bucket:   minioBucket,
+			Region:   minioRegion,
+			Endpoint: minioSecondaryEndpoint,
+		},
+	}, slog.Default())
+	require.NoError(t, err)
+	require.NoError(t, pub.Validate(ctx))
+	require.NoError(t, pub.HealthCheck(ctx))
+
+	discovery := &bridge.DiscoveryDocument{
+		Issuer:                  pub.GetPublicURL(),
+		JWKSURI:                 pub.GetPublicURL() + "/openid/v1/jwks",
+		ResponseTypesSupported:  []string{"id_token"},
+		SubjectTypesSupported:   []string{"public"},
+		IDTokenSigningAlgValues: []string{"RS256"},
+	}
+	jwks := &bridge.JWKS{Keys: []bridge.JWK{{Kid: "key-dual", Kty: "RSA", N: "abc", E: "AQAB"}}}
+	require.NoError(t, pub.Publish(ctx, discovery, jwks))
+
+	for _, endpoint := range []string{minioEndpoint, minioSecondaryEndpoint} {
+		base := endpoint + "/" + minioBucket + "/" + prefix
+
+		var gotDiscovery bridge.DiscoveryDocument
+		fetchJSON(t, base+"/.well-known/openid-configuration", &gotDiscovery)
+		assert.Equal(t, pub.GetPublicURL(), gotDiscovery.Issuer, "issuer on %s must be the primary URL", endpoint)
+
+		var gotJWKS bridge.JWKS
+		fetchJSON(t, base+"/openid/v1/jwks", &gotJWKS)
+		require.Len(t, gotJWKS.Keys, 1, "JWKS on %s", endpoint)
+		assert.Equal(t, "key-dual", gotJWKS.Keys[0].Kid)
+	}
+}
+
+// TestMinIO_FaultInjection_RecoversAfterFailures verifies that a publisher wrapped by
+// the fault injector fails the configured number of times and then publishes for real.
+func TestMinIO_FaultInjection_RecoversAfterFailures(t *testing.T) {
+	ctx := context.Background()
+	faults, err := faultinject.New(faultinject.Config{Faults: []faultinject.Fault{
+		{Operation: faultinject.OperationPublish, Mode: faultinject.ModePermission, Count: 2},
+	}})
+	require.NoError(t, err)
+	pub := faults.WrapPublisher(newMinioPublisher(t, "fault-injection-test", false, ""))
+
+	discovery := &bridge.DiscoveryDocument{Issuer: pub.GetPublicURL(), JWKSURI: pub.GetPublicURL() + "/openid/v1/jwks"}
+	jwks := &bridge.JWKS{Keys: []bridge.JWK{{Kid: "fault-key", Kty: "RSA", N: "abc123", E: "AQAB"}}}
+
+	for i := 0; i < 2; i++ {
+		require.ErrorIs(t, pub.Publish(ctx, discovery, jwks), faultinject.ErrInjected)
+	}
+	require.NoError(t, pub.Publish(ctx, discovery, jwks))
+
+	var gotJWKS bridge.JWKS
+	fetchJSON(t, pub.GetPublicURL()+"/openid/v1/jwks", &gotJWKS)
+	require.Len(t, gotJWKS.Keys, 1)
+	assert.Equal(t, "fault-key", gotJWKS.Keys[0].Kid)
 }